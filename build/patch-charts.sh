@@ -150,6 +150,24 @@ function patchGalley() {
     verbs: ["update"]' "${HELM_DIR}/istio-control/istio-discovery/templates/clusterrole.yaml"
   sed_wrap -i -e '/- apiGroups:.*admissionregistration\.k8s\.io/,/verbs:/ d' "${HELM_DIR}/istio-control/istio-discovery/templates/clusterrole.yaml"
   sed_wrap -i -e '/- apiGroups:.*certificates\.k8s\.io/,/verbs:/ d' "${HELM_DIR}/istio-control/istio-discovery/templates/clusterrole.yaml"
+  # only allow signing through the Kubernetes CSR API for the configured signers
+  sed_wrap -i -e '/# Istiod and bootstrap./ a\
+{{- if .Values.global.certSigners }}\
+  - apiGroups: ["certificates.k8s.io"]\
+    resources:\
+      - "certificatesigningrequests"\
+      - "certificatesigningrequests/approval"\
+      - "certificatesigningrequests/status"\
+    verbs: ["update", "create", "get", "delete", "watch"]\
+  - apiGroups: ["certificates.k8s.io"]\
+    resources:\
+      - "signers"\
+    resourceNames:\
+{{- range .Values.global.certSigners }}\
+    - {{ . | quote }}\
+{{- end }}\
+    verbs: ["approve"]\
+{{- end }}' "${HELM_DIR}/istio-control/istio-discovery/templates/clusterrole.yaml"
   sed_wrap -i -e '/- apiGroups:.*authentication\.k8s\.io/,/verbs:/ d' "${HELM_DIR}/istio-control/istio-discovery/templates/clusterrole.yaml"
 
   # remove istiod-reader ClusterRole and ClusterRoleBindings
@@ -423,6 +441,37 @@ function patchSidecarInjector() {
   # shellcheck disable=SC2016
   sed_wrap -i -e 's$traffic.sidecar.istio.io/excludeInboundPorts: "{{ excludeInboundPort (annotation .ObjectMeta `status.sidecar.istio.io/port` .Values.global.proxy.statusPort) (annotation .ObjectMeta `traffic.sidecar.istio.io/excludeInboundPorts` .Values.global.proxy.excludeInboundPorts) }}"$traffic.sidecar.istio.io/excludeInboundPorts: "15090,{{ excludeInboundPort (annotation .ObjectMeta `status.sidecar.istio.io/port` .Values.global.proxy.statusPort) (annotation .ObjectMeta `traffic.sidecar.istio.io/excludeInboundPorts` .Values.global.proxy.excludeInboundPorts) }}"$' \
       "${HELM_DIR}/istio-control/istio-discovery/files/injection-template.yaml"
+
+  # apply spec.gateways.gatewayAPI.gatewayClassParameters to the pods of the
+  # gateways istiod deploys for Gateway API resources
+  sed_wrap -i -e 's/pick .Values "global" "istio_cni" "sidecarInjectorWebhook" "revision"/pick .Values "global" "istio_cni" "sidecarInjectorWebhook" "revision" "gatewayAPI"/' \
+      "${HELM_DIR}/istio-control/istio-discovery/templates/istiod-injector-configmap.yaml"
+  # shellcheck disable=SC2016
+  sed_wrap -i -e '/^  annotations: {/,/^  }/ {
+    /^  }/ i\
+    {{- with (and (index .ObjectMeta.Labels `gateway.istio.io/managed`) .Values.gatewayAPI.gatewayClassParameters) }}\
+    {{- range $key, $value := .podAnnotations }}\
+    {{ $key | quote }}: {{ $value | quote }},\
+    {{- end }}\
+    {{- end }}
+  }' "${HELM_DIR}/istio-control/istio-discovery/files/gateway-injection-template.yaml"
+  # shellcheck disable=SC2016
+  sed_wrap -i -e '/^spec:/ a\
+  {{- with (and (index .ObjectMeta.Labels `gateway.istio.io/managed`) .Values.gatewayAPI.gatewayClassParameters) }}\
+  {{- with .pod }}\
+  {{- with .nodeSelector }}\
+  nodeSelector:\
+{{ toYaml . | indent 4 }}\
+  {{- end }}\
+  {{- with .tolerations }}\
+  tolerations:\
+{{ toYaml . | indent 4 }}\
+  {{- end }}\
+  {{- with .priorityClassName }}\
+  priorityClassName: {{ . }}\
+  {{- end }}\
+  {{- end }}\
+  {{- end }}' "${HELM_DIR}/istio-control/istio-discovery/files/gateway-injection-template.yaml"
 }
 
 # The following modifications are made to the generated helm template for the Kiali yaml file
@@ -565,6 +614,128 @@ function patchPilotServingCert() {
 
 }
 
+function patchPilotAffinity() {
+  echo "patching istiod affinity"
+
+  # the affinity and tolerations are already rendered by the Maistra specific
+  # scheduling section of the deployment
+  sed_wrap -i -e '/^{{- with .Values.pilot.tolerations }}$/,/^{{- end }}$/ d' \
+      -e '/^{{- with .Values.pilot.affinity }}$/,/^{{- end }}$/ d' \
+      "${HELM_DIR}/istio-control/istio-discovery/templates/deployment.yaml"
+
+  # allow replacing the generated node and pod anti-affinity and adding pod affinity
+  sed_wrap -i -e '/^{{ include "nodeaffinity" .*\.Values\.pilot\.nodeSelector) }}$/ {
+    i\
+{{- if .Values.pilot.affinity.nodeAffinity }}\
+        nodeAffinity:\
+{{ toYaml .Values.pilot.affinity.nodeAffinity | indent 10 }}\
+{{- else }}
+    a\
+{{- end }}\
+{{- with .Values.pilot.affinity.podAffinity }}\
+        podAffinity:\
+{{ toYaml . | indent 10 }}\
+{{- end }}\
+{{- if .Values.pilot.affinity.podAntiAffinity }}\
+        podAntiAffinity:\
+{{ toYaml .Values.pilot.affinity.podAntiAffinity | indent 10 }}\
+{{- else }}
+  }' \
+      -e '/^{{ include "podAntiAffinity" .Values.pilot }}$/ a\
+{{- end }}' \
+      "${HELM_DIR}/istio-control/istio-discovery/templates/deployment.yaml"
+  sed_wrap -i -e '/^  # Specify the pod anti-affinity that allows you/ i\
+  # Node affinity, pod affinity and pod anti-affinity rules for the istiod pod.\
+  # If nodeAffinity is set, it replaces the node affinity generated from\
+  # nodeSelector and global.arch; if podAntiAffinity is set, it replaces the\
+  # rules generated from the label selectors below.\
+  affinity: {}\
+' "${HELM_DIR}/istio-control/istio-discovery/values.yaml"
+}
+
+function patchIstiodRemote() {
+  echo "patching istiod charts for external control planes"
+
+  # add values for using an istiod that is managed outside of this control plane
+  sed_wrap -i -e '/^istiodRemote:/ a\
+  # Maistra: do not deploy istiod; the data plane uses the istiod at\
+  # .Values.global.remotePilotAddress, which is managed outside of this\
+  # control plane.\
+  enabled: false\
+\
+  # Maistra: PEM encoded CA bundle used to verify the webhooks served by the\
+  # external istiod. Only used if enabled is true.\
+  caBundle: ""\
+' "${HELM_DIR}/istio-control/istio-discovery/values.yaml"
+
+  # don't deploy istiod if an external one is used
+  for template in autoscale deployment federation poddisruptionbudget; do
+    sed_wrap -i -e '1 i\
+{{- if not .Values.istiodRemote.enabled }}' -e '$ a\
+{{- end }}' "${HELM_DIR}/istio-control/istio-discovery/templates/${template}.yaml"
+  done
+
+  # point the istiod service to the external istiod, either by name or through
+  # a manually managed endpoint if it is addressed by IP
+  sed_wrap -i -e '/^spec:/ a\
+  {{- if and .Values.istiodRemote.enabled (not (regexMatch "^[0-9.]+$|:" .Values.global.remotePilotAddress)) }}\
+  type: ExternalName\
+  externalName: {{ .Values.global.remotePilotAddress }}\
+  {{- end }}' \
+      -e '/^  selector:/ i\
+  {{- if not .Values.istiodRemote.enabled }}' \
+      -e '/^---$/ i\
+  {{- end }}' \
+      -e '$ a\
+{{- if and .Values.istiodRemote.enabled (regexMatch "^[0-9.]+$|:" .Values.global.remotePilotAddress) }}\
+# Maistra: the external istiod is addressed by IP, so the service is backed by\
+# a manually managed endpoint\
+apiVersion: v1\
+kind: Endpoints\
+metadata:\
+  name: istiod-{{ .Values.revision | default "default" }}\
+  namespace: {{ .Release.Namespace }}\
+  labels:\
+    maistra-version: "'"${MAISTRA_VERSION}"'"\
+    istio.io/rev: {{ .Values.revision | default "default" }}\
+    app: istiod\
+    istio: pilot\
+    release: {{ .Release.Name }}\
+subsets:\
+- addresses:\
+  - ip: {{ .Values.global.remotePilotAddress }}\
+  ports:\
+  - port: 15010\
+    name: grpc-xds\
+    protocol: TCP\
+  - port: 15012\
+    name: https-dns\
+    protocol: TCP\
+  - port: 15017\
+    name: https-webhook\
+    protocol: TCP\
+  - port: 15014\
+    name: http-monitoring\
+    protocol: TCP\
+  - port: 8188\
+    name: http-discovery\
+    protocol: TCP\
+---\
+{{- end }}' "${HELM_DIR}/istio-control/istio-discovery/templates/service.yaml"
+
+  # the webhook CA bundle is provided by the user, not by the webhookca controller
+  sed_wrap -i -e '/^    istio.io\/rev:/ a\
+    {{- if .Values.istiodRemote.enabled }}\
+    maistra.io/ignore-ca-bundle: "true"\
+    {{- end }}' \
+      -e '/path: "\/validate"/,/{{- end }}/ {
+    /{{- end }}/ a\
+      {{- if .Values.istiodRemote.enabled }}\
+      caBundle: {{ .Values.istiodRemote.caBundle | b64enc | quote }}\
+      {{- end }}
+  }' "${HELM_DIR}/istio-control/istio-discovery/templates/validatingwebhookconfiguration.yaml"
+}
+
 function hacks() {
   sed_wrap -i -e '/containers:/,/name: discovery/ {
       /name: discovery/a\
@@ -584,5 +755,7 @@ moveEnvoyFiltersToMeshConfigChart
 copyGlobalValues
 patchPilotServingCert
 patchGrafanaTemplate
+patchPilotAffinity
+patchIstiodRemote
 # TODO: remove this hack once the image is updated to include workingDir
 hacks
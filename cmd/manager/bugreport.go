@@ -0,0 +1,250 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	"sigs.k8s.io/yaml"
+
+	"github.com/maistra/istio-operator/pkg/apis"
+	maistrav1 "github.com/maistra/istio-operator/pkg/apis/maistra/v1"
+	maistrav2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+)
+
+// bugReportCommand is the subcommand that collects the state of the service
+// mesh into a tarball, e.g. "manager bug-report --output report.tar.gz"
+const bugReportCommand = "bug-report"
+
+// istiodDebugPaths are the debug endpoints of istiod included in bug reports
+var istiodDebugPaths = []string{"/debug/syncz", "/debug/configz", "/debug/registryz"}
+
+// bugReportOptions are the flags of the bug-report subcommand
+type bugReportOptions struct {
+	output            string
+	operatorNamespace string
+	logsSince         time.Duration
+}
+
+func (o *bugReportOptions) addFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&o.output, "output", "bug-report.tar.gz", "The file the bug report is written to; - writes it to stdout")
+	flags.StringVar(&o.operatorNamespace, "operatorNamespace", "openshift-operators", "The namespace the operator is deployed in")
+	flags.DurationVar(&o.logsSince, "logsSince", 0, "Only include logs newer than this duration; 0 includes all logs")
+}
+
+// bugReport writes the files of a bug report to a gzipped tarball.  Errors
+// collecting the contents don't abort the report, as it's most useful when
+// the cluster is in a bad state: they are written to errors.txt instead.
+type bugReport struct {
+	tw     *tar.Writer
+	errors []string
+}
+
+func (r *bugReport) addError(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *bugReport) add(name string, data []byte) error {
+	if err := r.tw.WriteHeader(&tar.Header{
+		Name:    path.Join("bug-report", name),
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}); err != nil {
+		return err
+	}
+	_, err := r.tw.Write(data)
+	return err
+}
+
+func (r *bugReport) addYAML(name string, obj interface{}) error {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		r.addError("error marshalling %s: %v", name, err)
+		return nil
+	}
+	return r.add(name, data)
+}
+
+// writeBugReport writes the bug report to the output file, or to stdout if
+// the output is "-"
+func writeBugReport(ctx context.Context, options *bugReportOptions) error {
+	if options.output == "-" {
+		return collectBugReport(ctx, options, os.Stdout)
+	}
+	file, err := os.Create(options.output)
+	if err != nil {
+		return err
+	}
+	if err := collectBugReport(ctx, options, file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	log.Info("Bug report written", "file", options.output)
+	return nil
+}
+
+// collectBugReport collects the logs of the operator, all ServiceMeshControlPlanes,
+// ServiceMeshMemberRolls and ServiceMeshMembers, the manifests rendered for
+// each control plane and the logs, events and debug endpoints of their istiod
+// instances, and writes them to out as a gzipped tarball.
+func collectBugReport(ctx context.Context, options *bugReportOptions, out io.Writer) error {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return err
+	}
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return err
+	}
+	if err := apis.AddToScheme(scheme); err != nil {
+		return err
+	}
+	cl, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(out)
+	report := &bugReport{tw: tar.NewWriter(gz)}
+	if err := report.collect(ctx, scheme, cl, clientset, options); err != nil {
+		return err
+	}
+	if len(report.errors) > 0 {
+		if err := report.add("errors.txt", []byte(strings.Join(report.errors, "\n")+"\n")); err != nil {
+			return err
+		}
+	}
+	if err := report.tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func (r *bugReport) collect(ctx context.Context, scheme *runtime.Scheme, cl client.Client, clientset kubernetes.Interface,
+	options *bugReportOptions) error {
+	kubeVersion := ""
+	if serverVersion, err := clientset.Discovery().ServerVersion(); err == nil {
+		kubeVersion = serverVersion.GitVersion
+		if err := r.addYAML("cluster/version.yaml", serverVersion); err != nil {
+			return err
+		}
+	} else {
+		r.addError("error getting Kubernetes version: %v", err)
+	}
+
+	if err := r.collectPodLogs(ctx, clientset, "operator", options.operatorNamespace, "name=istio-operator", options); err != nil {
+		return err
+	}
+
+	smcpList := &maistrav2.ServiceMeshControlPlaneList{}
+	lists := []struct {
+		name string
+		list runtime.Object
+	}{
+		{name: "servicemeshcontrolplanes", list: smcpList},
+		{name: "servicemeshmemberrolls", list: &maistrav1.ServiceMeshMemberRollList{}},
+		{name: "servicemeshmembers", list: &maistrav1.ServiceMeshMemberList{}},
+	}
+	for _, resources := range lists {
+		if err := cl.List(ctx, resources.list); err != nil {
+			r.addError("error listing %s: %v", resources.name, err)
+			continue
+		}
+		if err := r.addYAML(path.Join("resources", resources.name+".yaml"), resources.list); err != nil {
+			return err
+		}
+	}
+
+	eventNamespaces := map[string]bool{}
+	for index := range smcpList.Items {
+		smcp := &smcpList.Items[index]
+		dir := path.Join("controlplanes", smcp.Namespace, smcp.Name)
+
+		manifests := &bytes.Buffer{}
+		if kubeVersion == "" {
+			r.addError("not rendering manifests of %s/%s, as the Kubernetes version is unknown", smcp.Namespace, smcp.Name)
+		} else if err := renderControlPlaneManifests(ctx, scheme, smcp.DeepCopy(), kubeVersion, manifests); err != nil {
+			r.addError("error rendering manifests of %s/%s: %v", smcp.Namespace, smcp.Name, err)
+		} else if err := r.add(path.Join(dir, "manifests.yaml"), manifests.Bytes()); err != nil {
+			return err
+		}
+
+		if !eventNamespaces[smcp.Namespace] {
+			eventNamespaces[smcp.Namespace] = true
+			events := &corev1.EventList{}
+			if err := cl.List(ctx, events, client.InNamespace(smcp.Namespace)); err != nil {
+				r.addError("error listing events in namespace %s: %v", smcp.Namespace, err)
+			} else if err := r.addYAML(path.Join("controlplanes", smcp.Namespace, "events.yaml"), events); err != nil {
+				return err
+			}
+		}
+
+		if err := r.collectPodLogs(ctx, clientset, dir, smcp.Namespace, "app=istiod,istio.io/rev="+smcp.Name, options); err != nil {
+			return err
+		}
+		for _, debugPath := range istiodDebugPaths {
+			data, err := clientset.CoreV1().Services(smcp.Namespace).
+				ProxyGet("http", "istiod-"+smcp.Name, "15014", debugPath, nil).DoRaw(ctx)
+			if err != nil {
+				r.addError("error getting %s from istiod of %s/%s: %v", debugPath, smcp.Namespace, smcp.Name, err)
+				continue
+			}
+			if err := r.add(path.Join(dir, "istiod"+strings.ReplaceAll(debugPath, "/", "_")+".json"), data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// collectPodLogs adds the logs of all containers of the pods matching the
+// label selector to dir
+func (r *bugReport) collectPodLogs(ctx context.Context, clientset kubernetes.Interface, dir, namespace, selector string,
+	options *bugReportOptions) error {
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		r.addError("error listing pods in namespace %s: %v", namespace, err)
+		return nil
+	}
+	for _, pod := range pods.Items {
+		for _, container := range pod.Spec.Containers {
+			logOptions := &corev1.PodLogOptions{Container: container.Name, Timestamps: true}
+			if options.logsSince > 0 {
+				seconds := int64(options.logsSince.Seconds())
+				logOptions.SinceSeconds = &seconds
+			}
+			data, err := clientset.CoreV1().Pods(namespace).GetLogs(pod.Name, logOptions).DoRaw(ctx)
+			if err != nil {
+				r.addError("error getting logs of container %s of pod %s/%s: %v", container.Name, namespace, pod.Name, err)
+				continue
+			}
+			if err := r.add(path.Join(dir, "logs", pod.Name, container.Name+".log"), data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	"github.com/magiconair/properties"
@@ -18,24 +19,31 @@ import (
 	"github.com/operator-framework/operator-sdk/pkg/leader"
 	"github.com/operator-framework/operator-sdk/pkg/log/zap"
 	"github.com/operator-framework/operator-sdk/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	"github.com/maistra/istio-operator/pkg/apis"
 	maistrav1 "github.com/maistra/istio-operator/pkg/apis/maistra/v1"
 	maistrav2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/bootstrap"
 	"github.com/maistra/istio-operator/pkg/controller"
 	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/featuregates"
 	"github.com/maistra/istio-operator/pkg/version"
 )
 
@@ -49,6 +57,16 @@ var (
 )
 var log = logf.Log.WithName("cmd")
 
+// leaderGauge is 1 on the replica of the operator that holds the leader lease
+var leaderGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "servicemesh_operator_leader",
+	Help: "Whether this replica of the operator is the leader that reconciles resources",
+})
+
+func init() {
+	crmetrics.Registry.MustRegister(leaderGauge)
+}
+
 func main() {
 	// Add the zap logger flag set to the CLI. The flag set must
 	// be added before calling pflag.Parse().
@@ -63,10 +81,92 @@ func main() {
 	pflag.Int("memberRollReconcilers", 1, "The number of concurrent reconcilers for ServiceMeshMemberRoll resources")
 	pflag.Int("memberReconcilers", 10, "The number of concurrent reconcilers for ServiceMeshMember resources")
 
+	// flags to configure the retry budget of control planes
+	pflag.Int("retryBudget", 5, "The number of failed reconciliations of a ServiceMeshControlPlane within the retry budget window, "+
+		"after which its reconciliation backs off. Zero disables the budget")
+	pflag.Duration("retryBudgetWindow", 10*time.Minute, "The duration in which failed reconciliations of a ServiceMeshControlPlane are counted")
+	pflag.Duration("retryBackoff", 5*time.Minute, "How long the reconciliation of a ServiceMeshControlPlane that exhausted its retry budget is suspended")
+
+	// flag to enable or disable features that are still maturing
+	pflag.String("featureGates", "", "A comma-separated list of Feature=true|false pairs that enable or disable features. Options are:\n"+
+		strings.Join(featuregates.Default.KnownFeatures(), "\n"))
+
 	// flags to configure API request throttling
 	pflag.Int("apiBurst", 50, "The number of API requests the operator can make before throttling is activated")
 	pflag.Float32("apiQPS", 25, "The max rate of API requests when throttling is active")
 
+	// flags to configure the management of the Istio CRDs
+	pflag.Bool("crdManagementEnabled", true, "Install and update the Istio CRDs of the newest supported control plane version. "+
+		"Disable if the CRDs are managed externally")
+	pflag.Duration("crdCheckInterval", time.Hour, "How often the Istio CRDs are installed or updated; 0 only installs them at startup")
+
+	// flags to configure monitoring of remote cluster secrets
+	pflag.Duration("remoteSecretExpiryThreshold", 7*24*time.Hour, "Report remote cluster secrets whose credentials expire within this duration")
+	pflag.Duration("remoteSecretCheckInterval", time.Hour, "How often the remote cluster secrets of a control plane are checked")
+	pflag.Bool("remoteSecretAutoRotation", false, "Replace expiring service account tokens in remote cluster secrets using the TokenRequest API. "+
+		"The service account in the remote cluster must be allowed to create serviceaccounts/token for itself, and expired tokens cannot be rotated")
+
+	// flags to configure monitoring of external control planes
+	pflag.Duration("externalControlPlaneCheckInterval", time.Minute, "How often the readiness of the external istiod or istio-csr used by a control plane is checked")
+	pflag.Duration("certificateSignerCheckInterval", time.Minute,
+		"How often the availability of the Kubernetes CSR API signer of the workload certificates of a control plane is checked")
+	pflag.Duration("caRotationCheckInterval", time.Minute, "How often the progress of the intermediate CA rotation of a control plane is checked")
+	pflag.Duration("gatewayClassParametersCheckInterval", time.Minute,
+		"How often the gateway class parameters of a control plane are applied to the gateways deployed for Gateway API resources")
+	pflag.Int("meshNamespacesStatusLimit", 100,
+		"The maximum number of namespaces listed in the status of a control plane; 0 disables the list")
+	pflag.Duration("injectionWebhookCheckInterval", 10*time.Second,
+		"How often the sidecar injection webhook of a control plane is checked while it isn't serving")
+	pflag.Bool("istiodXDSReadinessCheck", false,
+		"Only report a control plane as ready once istiod responds to requests on its debug port through its Service")
+	pflag.Duration("readinessDebounce", 0,
+		"How long a ready control plane must be observed not to be ready before it's reported as not ready; 0 reports it right away")
+	pflag.Duration("deletionBlockedTimeout", 10*time.Minute,
+		"How long the deletion of a control plane is blocked while workloads still use it; 0 disables blocking")
+	pflag.Duration("deletionTimeout", time.Hour,
+		"How long the operator tries to delete the resources of a deleted control plane before removing its finalizer anyway; 0 retries forever")
+	pflag.Int("workloadUpdateMaxConcurrent", 1,
+		"The maximum number of Deployments of a control plane that are restarted at the same time to update their sidecars")
+	pflag.Duration("workloadUpdateCheckInterval", 30*time.Second,
+		"How often the progress of the restart of Deployments to update their sidecars is checked")
+	pflag.String("trafficVerificationImage", "",
+		"Overrides the image of the canary workload deployed to verify the ingress traffic of a control plane, which must serve HTTP on port 8080; by default, the proxy image of the control plane is used, so no image needs to be mirrored")
+	pflag.Duration("trafficVerificationTimeout", 5*time.Minute,
+		"How long the verification of the ingress traffic of a control plane may take before it's reported as failed")
+	pflag.Duration("trafficVerificationCheckInterval", 10*time.Second,
+		"How often the canary workload of a control plane is checked while its ingress traffic is being verified")
+
+	// flags to configure approval of control plane versions
+	pflag.String("versionApprovalURL", "", "The URL of an endpoint that must approve control plane versions before they are applied")
+	pflag.String("versionApprovalFailurePolicy", string(common.VersionApprovalFailurePolicyFail),
+		"How version changes are handled when the version approval endpoint cannot be reached (Fail or Ignore)")
+	pflag.Duration("versionApprovalCacheTTL", 5*time.Minute, "How long the decisions of the version approval endpoint are cached")
+	pflag.Duration("versionApprovalTimeout", 5*time.Second, "Timeout for requests to the version approval endpoint (at most 10s)")
+
+	// flags to configure notifications about control plane state changes
+	pflag.String("notificationURL", "", "The URL of an endpoint that is notified when a control plane becomes ready or not ready, "+
+		"or when its installation or update succeeds or fails")
+	pflag.String("notificationTemplate", "", "Go template used to render the body of notifications. Defaults to a JSON object")
+	pflag.String("notificationContentType", "application/json", "Content type of the notifications rendered by the notification template")
+	pflag.Duration("notificationTimeout", 5*time.Second, "Timeout for requests to the notification endpoint")
+
+	// flags to configure leader election
+	pflag.Bool("leaderElectionLease", false, "Use a renewable lease for leader election, so a standby replica of the operator "+
+		"takes over when the leader fails. Required for running multiple replicas")
+	pflag.Duration("leaderElectionLeaseDuration", 15*time.Second, "How long standby replicas wait before taking over a lease that hasn't been renewed")
+	pflag.Duration("leaderElectionRenewDeadline", 10*time.Second, "How long the leader tries to renew the lease before giving it up")
+	pflag.Duration("leaderElectionRetryPeriod", 2*time.Second, "How long replicas wait between attempts to acquire or renew the lease")
+
+	// flags to configure the defaults of all control planes
+	pflag.String("defaultPilotResources", "", "Default resource requests and limits of istiod, as a YAML or JSON ResourceRequirements object")
+	pflag.String("defaultPriorityClassName", "", "Default priority class of the control plane components")
+	pflag.String("defaultTopologySpreadConstraints", "",
+		"Default topology spread constraints of the istiod pods, as a YAML or JSON list of TopologySpreadConstraint objects")
+
+	// flags to configure profiling of the operator
+	pflag.Bool("pprof", false, "Serve the pprof endpoints below /debug/pprof/ on the metrics port")
+	pflag.Duration("runtimeStatsInterval", 0, "How often the memory usage and number of goroutines of the operator are logged; 0 disables the log")
+
 	// custom flags for istio operator
 	pflag.String("resourceDir", "/usr/local/share/istio-operator", "The location of the resources - helm charts, templates, etc.")
 	pflag.String("chartsDir", "", "The root location of the helm charts.")
@@ -83,7 +183,32 @@ func main() {
 	printVersion := false
 	pflag.BoolVar(&printVersion, "version", printVersion, "Prints version information and exits")
 
-	pflag.Parse()
+	// the render subcommand renders the manifests of a control plane, the
+	// teardown subcommand removes the service mesh from the cluster and the
+	// bug-report subcommand collects the state of the service mesh instead of
+	// running the operator
+	args := os.Args[1:]
+	var render *renderOptions
+	var teardown *teardownOptions
+	var bugReport *bugReportOptions
+	if len(args) > 0 {
+		switch args[0] {
+		case renderCommand:
+			render = &renderOptions{}
+			render.addFlags(pflag.CommandLine)
+			args = args[1:]
+		case teardownCommand:
+			teardown = &teardownOptions{}
+			teardown.addFlags(pflag.CommandLine)
+			args = args[1:]
+		case bugReportCommand:
+			bugReport = &bugReportOptions{}
+			bugReport.addFlags(pflag.CommandLine)
+			args = args[1:]
+		}
+	}
+
+	_ = pflag.CommandLine.Parse(args)
 	if printVersion {
 		fmt.Printf("%s\n", version.Info)
 		os.Exit(0)
@@ -102,6 +227,34 @@ func main() {
 		os.Exit(1)
 	}
 
+	if render != nil {
+		if pflag.NArg() != 1 {
+			fmt.Fprintf(os.Stderr, "Usage: %s %s [flags] FILE\n", os.Args[0], renderCommand)
+			os.Exit(2)
+		}
+		if err := renderControlPlane(context.TODO(), pflag.Arg(0), render, os.Stdout); err != nil {
+			log.Error(err, "error rendering ServiceMeshControlPlane")
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if teardown != nil {
+		if err := teardownMesh(context.TODO(), teardown, os.Stdout); err != nil {
+			log.Error(err, "error tearing down the service mesh")
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if bugReport != nil {
+		if err := writeBugReport(context.TODO(), bugReport); err != nil {
+			log.Error(err, "error collecting bug report")
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	namespace, err := k8sutil.GetWatchNamespace()
 	if err != nil {
 		log.Error(err, "Failed to get watch namespace")
@@ -128,12 +281,15 @@ func main() {
 	}
 
 	ctx := context.Background()
-	// Become the leader before proceeding
-	err = leader.Become(ctx, "istio-operator-lock")
-
-	if err != nil {
-		log.Error(err, "")
-		os.Exit(1)
+	leaderElection := common.Config.LeaderElection
+	if !leaderElection.LeaseEnabled {
+		// Become the leader before proceeding
+		err = leader.Become(ctx, "istio-operator-lock")
+
+		if err != nil {
+			log.Error(err, "")
+			os.Exit(1)
+		}
 	}
 
 	// Set default manager options
@@ -144,6 +300,18 @@ func main() {
 		HealthProbeBindAddress: healthProbeBindAddress,
 	}
 
+	if leaderElection.LeaseEnabled {
+		// all replicas start the manager and serve webhooks, but only the
+		// replica holding the lease runs the controllers
+		log.Info("Leader election using a lease configured", "leaseDuration", leaderElection.LeaseDuration,
+			"renewDeadline", leaderElection.RenewDeadline, "retryPeriod", leaderElection.RetryPeriod)
+		options.LeaderElection = true
+		options.LeaderElectionID = "istio-operator-leader"
+		options.LeaseDuration = &leaderElection.LeaseDuration
+		options.RenewDeadline = &leaderElection.RenewDeadline
+		options.RetryPeriod = &leaderElection.RetryPeriod
+	}
+
 	// Add support for MultiNamespace set in WATCH_NAMESPACE (e.g ns1,ns2)
 	// Note that this is not intended to be used for excluding namespaces, this is better done via a Predicate
 	// Also note that you may face performance issues when using this with a high number of namespaces.
@@ -166,7 +334,15 @@ func main() {
 		os.Exit(1)
 	}
 
-	enhancedMgr := common.NewEnhancedManager(mgr, dc)
+	kubeClient, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		log.Error(err, "")
+		os.Exit(1)
+	}
+
+	// the API groups and resources are cached until a CustomResourceDefinition
+	// changes, see addReadinessController()
+	enhancedMgr := common.NewEnhancedManager(mgr, memory.NewMemCacheClient(dc), kubeClient)
 
 	log.Info("Registering Components.")
 
@@ -182,9 +358,20 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Keep the Istio CRDs up to date
+	if err := bootstrap.AddCRDManager(mgr); err != nil {
+		log.Error(err, "")
+		os.Exit(1)
+	}
+
 	// Add the Metrics Service
 	addMetrics(ctx, cfg)
 
+	if err := addProfiling(mgr); err != nil {
+		log.Error(err, "error adding profiling endpoints")
+		os.Exit(1)
+	}
+
 	err = mgr.AddReadyzCheck("readiness", func(req *http.Request) error {
 		// no need to check anything; the readyz probe succeeds only when the
 		// webhooks are running (which only happens when the serving secret is present)
@@ -194,6 +381,19 @@ func main() {
 		log.Error(err, "error adding readyz check")
 		os.Exit(1)
 	}
+	// standby replicas serve webhooks too, so neither the readiness nor the
+	// liveness check depends on leadership
+	if err := mgr.AddHealthzCheck("ping", healthz.Ping); err != nil {
+		log.Error(err, "error adding healthz check")
+		os.Exit(1)
+	}
+
+	// report which replica is reconciling resources
+	go func() {
+		<-mgr.Elected()
+		log.Info("This replica is the leader and reconciles resources")
+		leaderGauge.Set(1)
+	}()
 
 	log.Info("Starting the Cmd.")
 
@@ -300,6 +500,57 @@ func initializeConfiguration(configFile string) error {
 	v.RegisterAlias("controller.apiBurst", "apiBurst")
 	v.RegisterAlias("controller.apiQPS", "apiQPS")
 	v.RegisterAlias("controller.webhookManagementEnabled", "webhookManagementEnabled")
+	v.RegisterAlias("controller.crdManagementEnabled", "crdManagementEnabled")
+	v.RegisterAlias("controller.crdCheckInterval", "crdCheckInterval")
+	v.RegisterAlias("controller.remoteSecretExpiryThreshold", "remoteSecretExpiryThreshold")
+	v.RegisterAlias("controller.remoteSecretCheckInterval", "remoteSecretCheckInterval")
+	v.RegisterAlias("controller.remoteSecretAutoRotation", "remoteSecretAutoRotation")
+	v.RegisterAlias("controller.externalControlPlaneCheckInterval", "externalControlPlaneCheckInterval")
+	v.RegisterAlias("controller.certificateSignerCheckInterval", "certificateSignerCheckInterval")
+	v.RegisterAlias("controller.caRotationCheckInterval", "caRotationCheckInterval")
+	v.RegisterAlias("controller.gatewayClassParametersCheckInterval", "gatewayClassParametersCheckInterval")
+	v.RegisterAlias("controller.meshNamespacesStatusLimit", "meshNamespacesStatusLimit")
+	v.RegisterAlias("controller.injectionWebhookCheckInterval", "injectionWebhookCheckInterval")
+	v.RegisterAlias("controller.istiodXDSReadinessCheck", "istiodXDSReadinessCheck")
+	v.RegisterAlias("controller.readinessDebounce", "readinessDebounce")
+	v.RegisterAlias("controller.deletionBlockedTimeout", "deletionBlockedTimeout")
+	v.RegisterAlias("controller.deletionTimeout", "deletionTimeout")
+	v.RegisterAlias("controller.workloadUpdateMaxConcurrent", "workloadUpdateMaxConcurrent")
+	v.RegisterAlias("controller.workloadUpdateCheckInterval", "workloadUpdateCheckInterval")
+	v.RegisterAlias("controller.trafficVerificationImage", "trafficVerificationImage")
+	v.RegisterAlias("controller.trafficVerificationTimeout", "trafficVerificationTimeout")
+	v.RegisterAlias("controller.trafficVerificationCheckInterval", "trafficVerificationCheckInterval")
+	v.RegisterAlias("controller.retryBudget", "retryBudget")
+	v.RegisterAlias("controller.retryBudgetWindow", "retryBudgetWindow")
+	v.RegisterAlias("controller.retryBackoff", "retryBackoff")
+	v.RegisterAlias("controller.featureGates", "featureGates")
+
+	// version approval settings
+	v.RegisterAlias("versionApproval.url", "versionApprovalURL")
+	v.RegisterAlias("versionApproval.failurePolicy", "versionApprovalFailurePolicy")
+	v.RegisterAlias("versionApproval.cacheTTL", "versionApprovalCacheTTL")
+	v.RegisterAlias("versionApproval.timeout", "versionApprovalTimeout")
+
+	// notification settings
+	v.RegisterAlias("notification.url", "notificationURL")
+	v.RegisterAlias("notification.template", "notificationTemplate")
+	v.RegisterAlias("notification.contentType", "notificationContentType")
+	v.RegisterAlias("notification.timeout", "notificationTimeout")
+
+	// leader election settings
+	v.RegisterAlias("leaderElection.leaseEnabled", "leaderElectionLease")
+	v.RegisterAlias("leaderElection.leaseDuration", "leaderElectionLeaseDuration")
+	v.RegisterAlias("leaderElection.renewDeadline", "leaderElectionRenewDeadline")
+	v.RegisterAlias("leaderElection.retryPeriod", "leaderElectionRetryPeriod")
+
+	// control plane defaults
+	v.RegisterAlias("controlPlaneDefaults.pilotResources", "defaultPilotResources")
+	v.RegisterAlias("controlPlaneDefaults.priorityClassName", "defaultPriorityClassName")
+	v.RegisterAlias("controlPlaneDefaults.topologySpreadConstraints", "defaultTopologySpreadConstraints")
+
+	// profiling settings
+	v.RegisterAlias("profiling.pprofEnabled", "pprof")
+	v.RegisterAlias("profiling.runtimeStatsInterval", "runtimeStatsInterval")
 
 	// rendering settings
 	v.RegisterAlias("rendering.resourceDir", "resourceDir")
@@ -326,6 +577,19 @@ func initializeConfiguration(configFile string) error {
 	}); err != nil {
 		return err
 	}
+	if err := common.Config.LeaderElection.Validate(); err != nil {
+		return err
+	}
+	if err := common.Config.ControlPlaneDefaults.Validate(); err != nil {
+		return err
+	}
+	if err := common.Config.CNI.Validate(); err != nil {
+		return err
+	}
+	if err := featuregates.Default.Set(common.Config.Controller.FeatureGates); err != nil {
+		return err
+	}
+	log.Info("feature gates initialized", "featureGates", featuregates.Default.String())
 	log.Info("configuration successfully initialized", "config", common.Config)
 	return nil
 }
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/maistra/istio-operator/pkg/controller/common"
+)
+
+// addProfiling serves the pprof endpoints on the metrics port and starts
+// logging runtime stats, if enabled in the configuration
+func addProfiling(mgr manager.Manager) error {
+	config := common.Config.Profiling
+	if config.PprofEnabled {
+		log.Info("Serving pprof endpoints on the metrics port")
+		handlers := map[string]http.HandlerFunc{
+			"/debug/pprof/":        pprof.Index,
+			"/debug/pprof/cmdline": pprof.Cmdline,
+			"/debug/pprof/profile": pprof.Profile,
+			"/debug/pprof/symbol":  pprof.Symbol,
+			"/debug/pprof/trace":   pprof.Trace,
+		}
+		for path, handler := range handlers {
+			if err := mgr.AddMetricsExtraHandler(path, handler); err != nil {
+				return err
+			}
+		}
+	}
+	if config.RuntimeStatsInterval > 0 {
+		go logRuntimeStats(config.RuntimeStatsInterval)
+	}
+	return nil
+}
+
+// logRuntimeStats periodically logs the memory usage and number of goroutines
+// of the operator, so growth can be correlated with the operator's activity
+func logRuntimeStats(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		log.Info("Runtime stats",
+			"heapAllocBytes", stats.HeapAlloc,
+			"heapObjects", stats.HeapObjects,
+			"sysBytes", stats.Sys,
+			"numGC", stats.NumGC,
+			"goroutines", runtime.NumGoroutine())
+	}
+}
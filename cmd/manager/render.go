@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/helm/pkg/manifest"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/maistra/istio-operator/pkg/apis"
+	maistrav1 "github.com/maistra/istio-operator/pkg/apis/maistra/v1"
+	maistrav2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/common/cni"
+	"github.com/maistra/istio-operator/pkg/controller/versions"
+)
+
+const (
+	// renderCommand is the subcommand that renders the manifests of a
+	// ServiceMeshControlPlane, e.g. "manager render smcp.yaml"
+	renderCommand = "render"
+
+	// defaultRenderOperatorNamespace is the operator namespace assumed when
+	// rendering outside of a cluster and POD_NAMESPACE isn't set
+	defaultRenderOperatorNamespace = "openshift-operators"
+)
+
+// renderOptions are the flags of the render subcommand
+type renderOptions struct {
+	namespace   string
+	kubeVersion string
+}
+
+func (o *renderOptions) addFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&o.namespace, "namespace", "istio-system", "The namespace of the ServiceMeshControlPlane, if it doesn't specify one")
+	flags.StringVar(&o.kubeVersion, "kubeVersion", "v1.25.0", "The Kubernetes version the manifests are rendered for")
+}
+
+// renderControlPlane renders the charts for the ServiceMeshControlPlane in
+// fileName the same way the operator does when reconciling it and writes the
+// resulting manifests to out. No cluster is contacted: lookups performed
+// during rendering are answered by an in-memory client that only contains the
+// control plane namespace, so the output reflects a fresh installation.
+func renderControlPlane(ctx context.Context, fileName string, options *renderOptions, out io.Writer) error {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return err
+	}
+	if err := apis.AddToScheme(scheme); err != nil {
+		return err
+	}
+
+	obj, _, err := serializer.NewCodecFactory(scheme).UniversalDeserializer().Decode(data, nil, nil)
+	if err != nil {
+		return fmt.Errorf("error decoding %s: %v", fileName, err)
+	}
+	smcp := &maistrav2.ServiceMeshControlPlane{}
+	switch typedObj := obj.(type) {
+	case *maistrav2.ServiceMeshControlPlane:
+		smcp = typedObj
+	case *maistrav1.ServiceMeshControlPlane:
+		if err := scheme.Convert(typedObj, smcp, nil); err != nil {
+			return fmt.Errorf("error converting ServiceMeshControlPlane to v2: %v", err)
+		}
+	default:
+		return fmt.Errorf("%s does not contain a ServiceMeshControlPlane", fileName)
+	}
+
+	if smcp.Namespace == "" {
+		smcp.Namespace = options.namespace
+	}
+	return renderControlPlaneManifests(ctx, scheme, smcp, options.kubeVersion, out)
+}
+
+// renderControlPlaneManifests renders the charts for the spec of smcp for the
+// given Kubernetes version and writes the resulting manifests to out
+func renderControlPlaneManifests(ctx context.Context, scheme *runtime.Scheme, smcp *maistrav2.ServiceMeshControlPlane,
+	kubeVersion string, out io.Writer) error {
+	if smcp.Spec.Version == "" {
+		smcp.Spec.Version = versions.DefaultVersion.String()
+	}
+	ver, err := versions.ParseVersion(smcp.Spec.Version)
+	if err != nil {
+		return err
+	}
+
+	// the operator namespace is normally read from the pod's service account
+	if os.Getenv("POD_NAMESPACE") == "" {
+		if err := os.Setenv("POD_NAMESPACE", defaultRenderOperatorNamespace); err != nil {
+			return err
+		}
+	}
+
+	cr := &common.ControllerResources{
+		Client: fake.NewFakeClientWithScheme(scheme, &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: smcp.Namespace},
+		}),
+		Scheme:            scheme,
+		OperatorNamespace: common.GetOperatorNamespace(),
+		DiscoveryClient: &fakediscovery.FakeDiscovery{
+			Fake:               &clienttesting.Fake{},
+			FakedServerVersion: &version.Info{GitVersion: kubeVersion},
+		},
+	}
+	if err := ver.Strategy().ValidateV2(ctx, cr.Client, &smcp.ObjectMeta, &smcp.Spec); err != nil {
+		return err
+	}
+	renderings, err := ver.Strategy().Render(ctx, cr, cni.Config{Enabled: common.Config.OLM.CNIEnabled}, smcp)
+	if err != nil {
+		return err
+	}
+
+	return writeRenderings(renderings, out)
+}
+
+// writeRenderings writes the manifests of each chart to out, ordered by chart
+// and manifest name so the output of successive runs can be compared.
+func writeRenderings(renderings map[string][]manifest.Manifest, out io.Writer) error {
+	charts := make([]string, 0, len(renderings))
+	for chart := range renderings {
+		charts = append(charts, chart)
+	}
+	sort.Strings(charts)
+
+	for _, chart := range charts {
+		manifests := renderings[chart]
+		sort.SliceStable(manifests, func(i, j int) bool {
+			return manifests[i].Name < manifests[j].Name
+		})
+		for _, m := range manifests {
+			content := strings.TrimSpace(m.Content)
+			if content == "" {
+				continue
+			}
+			if _, err := fmt.Fprintf(out, "---\n# Source: %s\n%s\n", m.Name, content); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
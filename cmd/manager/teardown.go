@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+
+	"github.com/maistra/istio-operator/pkg/apis"
+	"github.com/maistra/istio-operator/pkg/bootstrap"
+)
+
+// teardownCommand is the subcommand that removes the service mesh from the
+// cluster, e.g. "manager teardown --removeCRDs".  It's meant to be run in a
+// Job while the operator is still running, because the operator finalizes
+// the control planes.
+const teardownCommand = "teardown"
+
+// teardownOptions are the flags of the teardown subcommand
+type teardownOptions struct {
+	removeCRDs   bool
+	timeout      time.Duration
+	pollInterval time.Duration
+}
+
+func (o *teardownOptions) addFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&o.removeCRDs, "removeCRDs", false, "Also remove the Istio CRDs and all Istio resources")
+	flags.DurationVar(&o.timeout, "timeout", 5*time.Minute, "How long to wait for the control planes to be deleted")
+	flags.DurationVar(&o.pollInterval, "pollInterval", 5*time.Second, "How often to check whether the control planes have been deleted")
+}
+
+// teardownMesh removes the service mesh from the cluster and writes a report
+// of the removed and remaining objects to out.  It returns an error if the
+// cluster isn't mesh-free afterwards.
+func teardownMesh(ctx context.Context, options *teardownOptions, out io.Writer) error {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return err
+	}
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return err
+	}
+	if err := apis.AddToScheme(scheme); err != nil {
+		return err
+	}
+	cl, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+
+	report, err := bootstrap.Teardown(ctx, cl, bootstrap.TeardownOptions{
+		RemoveCRDs:   options.removeCRDs,
+		Timeout:      options.timeout,
+		PollInterval: options.pollInterval,
+	})
+	if writeErr := report.Write(out); writeErr != nil && err == nil {
+		err = writeErr
+	}
+	if err != nil {
+		return err
+	}
+	if !report.MeshFree() {
+		return fmt.Errorf("%d mesh object(s) remain in the cluster", len(report.Remaining))
+	}
+	return nil
+}
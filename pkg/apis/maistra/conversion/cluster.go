@@ -48,6 +48,29 @@ func populateClusterValues(in *v2.ControlPlaneSpec, namespace string, values map
 			return err
 		}
 	}
+	if cluster.MeshID != "" {
+		if err := setHelmStringValue(values, "global.meshID", cluster.MeshID); err != nil {
+			return err
+		}
+	}
+
+	if cluster.ExternalControlPlane != nil {
+		if cluster.ExternalControlPlane.Enabled != nil {
+			if err := setHelmBoolValue(values, "istiodRemote.enabled", *cluster.ExternalControlPlane.Enabled); err != nil {
+				return err
+			}
+		}
+		if cluster.ExternalControlPlane.Address != "" {
+			if err := setHelmStringValue(values, "global.remotePilotAddress", cluster.ExternalControlPlane.Address); err != nil {
+				return err
+			}
+		}
+		if cluster.ExternalControlPlane.CABundle != "" {
+			if err := setHelmStringValue(values, "istiodRemote.caBundle", cluster.ExternalControlPlane.CABundle); err != nil {
+				return err
+			}
+		}
+	}
 
 	multiClusterEnabled := false
 	multiClusterOverrides := v1.NewHelmValues(make(map[string]interface{}))
@@ -390,6 +413,12 @@ func populateClusterConfig(in *v1.HelmValues, out *v2.ControlPlaneSpec) error {
 	} else if err != nil {
 		return err
 	}
+	if meshID, ok, err := in.GetAndRemoveString("global.meshID"); ok {
+		clusterConfig.MeshID = meshID
+		setClusterConfig = true
+	} else if err != nil {
+		return err
+	}
 
 	// patchup gateways
 	if rawMultiClusterOverrides, ok, err := in.GetMap("global.multiCluster.multiClusterOverrides"); ok && len(rawMultiClusterOverrides) > 0 {
@@ -606,6 +635,32 @@ func populateClusterConfig(in *v1.HelmValues, out *v2.ControlPlaneSpec) error {
 		setClusterConfig = true
 	}
 
+	externalControlPlane := &v2.ExternalControlPlaneConfig{}
+	setExternalControlPlane := false
+	if externalControlPlaneEnabled, ok, err := in.GetAndRemoveBool("istiodRemote.enabled"); ok {
+		externalControlPlane.Enabled = &externalControlPlaneEnabled
+		setExternalControlPlane = true
+	} else if err != nil {
+		return err
+	}
+	if address, ok, err := in.GetAndRemoveString("global.remotePilotAddress"); ok {
+		externalControlPlane.Address = address
+		setExternalControlPlane = true
+	} else if err != nil {
+		return err
+	}
+	if caBundle, ok, err := in.GetAndRemoveString("istiodRemote.caBundle"); ok {
+		externalControlPlane.CABundle = caBundle
+		setExternalControlPlane = true
+	} else if err != nil {
+		return err
+	}
+
+	if setExternalControlPlane {
+		clusterConfig.ExternalControlPlane = externalControlPlane
+		setClusterConfig = true
+	}
+
 	// clear out defaults
 	in.RemoveField("gateways.istio-ilbgateway.enabled")
 	in.RemoveField("global.meshExpansion.enabled")
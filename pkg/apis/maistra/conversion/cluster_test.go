@@ -73,6 +73,70 @@ func clusterTestCasesV2(version versions.Version) []conversionTestCase {
 			}),
 			completeIstio: v1.NewHelmValues(map[string]interface{}{}),
 		},
+		{
+			name:      "meshID." + ver,
+			namespace: clusterTestNamespace,
+			spec: &v2.ControlPlaneSpec{
+				Version: ver,
+				Cluster: &v2.ControlPlaneClusterConfig{
+					MeshID: "my-mesh",
+				},
+			},
+			isolatedIstio: v1.NewHelmValues(map[string]interface{}{
+				"global": map[string]interface{}{
+					"meshID": "my-mesh",
+					"multiCluster": map[string]interface{}{
+						"enabled": false,
+						"multiClusterOverrides": map[string]interface{}{
+							"expansionEnabled":    nil,
+							"multiClusterEnabled": nil,
+						},
+					},
+					"meshExpansion": map[string]interface{}{
+						"enabled": false,
+						"useILB":  false,
+					},
+				},
+			}),
+			completeIstio: v1.NewHelmValues(map[string]interface{}{}),
+		},
+		{
+			name:      "externalControlPlane." + ver,
+			namespace: clusterTestNamespace,
+			spec: &v2.ControlPlaneSpec{
+				Version: ver,
+				Cluster: &v2.ControlPlaneClusterConfig{
+					ExternalControlPlane: &v2.ExternalControlPlaneConfig{
+						Enablement: v2.Enablement{
+							Enabled: &featureEnabled,
+						},
+						Address:  "istiod.example.com",
+						CABundle: "my-ca-bundle",
+					},
+				},
+			},
+			isolatedIstio: v1.NewHelmValues(map[string]interface{}{
+				"global": map[string]interface{}{
+					"multiCluster": map[string]interface{}{
+						"enabled": false,
+						"multiClusterOverrides": map[string]interface{}{
+							"expansionEnabled":    nil,
+							"multiClusterEnabled": nil,
+						},
+					},
+					"meshExpansion": map[string]interface{}{
+						"enabled": false,
+						"useILB":  false,
+					},
+					"remotePilotAddress": "istiod.example.com",
+				},
+				"istiodRemote": map[string]interface{}{
+					"enabled":  true,
+					"caBundle": "my-ca-bundle",
+				},
+			}),
+			completeIstio: v1.NewHelmValues(map[string]interface{}{}),
+		},
 		{
 			name:      "multicluster.simple." + ver,
 			namespace: clusterTestNamespace,
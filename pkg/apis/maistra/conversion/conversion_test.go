@@ -1384,6 +1384,10 @@ func TestCompleteTelemetryConversionFromV2(t *testing.T) {
 	runTestCasesFromV2(telemetryTestCases, t)
 }
 
+func TestCompleteTelemetryDefaultsConversionFromV2(t *testing.T) {
+	runTestCasesFromV2(telemetryDefaultsTestCases, t)
+}
+
 func TestCompleteSecurityConversionFromV2(t *testing.T) {
 	runTestCasesFromV2(securityTestCases, t)
 }
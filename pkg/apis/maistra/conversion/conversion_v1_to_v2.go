@@ -116,6 +116,7 @@ func Convert_v1_ControlPlaneSpec_To_v2_ControlPlaneSpec(in *v1.ControlPlaneSpec,
 			out.Runtime = nil
 			out.Security = nil
 			out.Telemetry = nil
+			out.TelemetryDefaults = nil
 			out.Tracing = nil
 		}
 		err = nil
@@ -207,6 +208,9 @@ func Convert_v1_ControlPlaneSpec_To_v2_ControlPlaneSpec(in *v1.ControlPlaneSpec,
 	if err := populateTelemetryConfig(values, out, version); err != nil {
 		return err
 	}
+	if err := populateTelemetryDefaultsConfig(values, out); err != nil {
+		return err
+	}
 
 	// Tracing
 	if err := populateTracingConfig(values, out); err != nil {
@@ -235,6 +239,16 @@ func Convert_v1_ControlPlaneSpec_To_v2_ControlPlaneSpec(in *v1.ControlPlaneSpec,
 		}
 	}
 
+	// Overlays
+	if err := populateOverlaysConfig(values, out); err != nil {
+		return err
+	}
+
+	// Verify
+	if err := populateVerifyConfig(values, out); err != nil {
+		return err
+	}
+
 	// Runtime
 	if err := populateControlPlaneRuntimeConfig(values, out); err != nil {
 		return err
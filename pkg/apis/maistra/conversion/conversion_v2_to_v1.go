@@ -197,6 +197,9 @@ func Convert_v2_ControlPlaneSpec_To_v1_ControlPlaneSpec(in *v2.ControlPlaneSpec,
 	if err := populateTelemetryValues(in, values); err != nil {
 		return err
 	}
+	if err := populateTelemetryDefaultsValues(in, values); err != nil {
+		return err
+	}
 
 	// Tracing
 	if err := populateTracingValues(in, values); err != nil {
@@ -225,6 +228,16 @@ func Convert_v2_ControlPlaneSpec_To_v1_ControlPlaneSpec(in *v2.ControlPlaneSpec,
 		}
 	}
 
+	// Overlays
+	if err := populateOverlaysValues(in, values); err != nil {
+		return err
+	}
+
+	// Verify
+	if err := populateVerifyValues(in, values); err != nil {
+		return err
+	}
+
 	// Runtime - must run last as this will add values to existing child maps
 	if err := populateControlPlaneRuntimeValues(in.Runtime, values); err != nil {
 		return err
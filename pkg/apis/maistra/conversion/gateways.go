@@ -120,6 +120,30 @@ func populateGatewaysValues(in *v2.ControlPlaneSpec, values map[string]interface
 		}
 	}
 
+	if gateways.GatewayAPI != nil {
+		if gateways.GatewayAPI.Enabled != nil {
+			if err := setHelmBoolValue(values, "gatewayAPI.enabled", *gateways.GatewayAPI.Enabled); err != nil {
+				return err
+			}
+		}
+		if gateways.GatewayAPI.ControllerMode != nil {
+			if err := setHelmBoolValue(values, "gatewayAPI.controllerMode", *gateways.GatewayAPI.ControllerMode); err != nil {
+				return err
+			}
+		}
+		if gateways.GatewayAPI.GatewayClassParameters != nil {
+			if parametersValues, err := toValues(gateways.GatewayAPI.GatewayClassParameters); err == nil {
+				if len(parametersValues) > 0 {
+					if err := setHelmValue(values, "gatewayAPI.gatewayClassParameters", parametersValues); err != nil {
+						return err
+					}
+				}
+			} else {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -413,6 +437,40 @@ func populateGatewaysConfig(in *v1.HelmValues, out *v2.ControlPlaneSpec) error {
 	} else if err != nil {
 		return err
 	}
+
+	gatewayAPI := &v2.GatewayAPIConfig{}
+	setGatewayAPI := false
+	if enabled, ok, err := in.GetAndRemoveBool("gatewayAPI.enabled"); ok {
+		gatewayAPI.Enabled = &enabled
+		setGatewayAPI = true
+	} else if err != nil {
+		return err
+	}
+	if controllerMode, ok, err := in.GetAndRemoveBool("gatewayAPI.controllerMode"); ok {
+		gatewayAPI.ControllerMode = &controllerMode
+		setGatewayAPI = true
+	} else if err != nil {
+		return err
+	}
+	if rawParameters, ok, err := in.GetMap("gatewayAPI.gatewayClassParameters"); ok && len(rawParameters) > 0 {
+		gatewayAPI.GatewayClassParameters = &v2.GatewayClassParametersConfig{}
+		if err := decodeAndRemoveFromValues(rawParameters, gatewayAPI.GatewayClassParameters); err != nil {
+			return err
+		}
+		setGatewayAPI = true
+		if len(rawParameters) == 0 {
+			in.RemoveField("gatewayAPI.gatewayClassParameters")
+		} else if err := in.SetField("gatewayAPI.gatewayClassParameters", rawParameters); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+	if setGatewayAPI {
+		gatewaysConfig.GatewayAPI = gatewayAPI
+		setGatewaysConfig = true
+	}
+
 	if setGatewaysConfig {
 		if len(gatewaysConfig.EgressGateways) == 0 {
 			gatewaysConfig.EgressGateways = nil
@@ -182,6 +182,110 @@ func gatewaysTestCasesV2(version versions.Version) []conversionTestCase {
 				},
 			}),
 		},
+		{
+			name: "gatewayAPI." + ver,
+			spec: &v2.ControlPlaneSpec{
+				Version: ver,
+				Gateways: &v2.GatewaysConfig{
+					GatewayAPI: &v2.GatewayAPIConfig{
+						Enablement: v2.Enablement{
+							Enabled: &featureEnabled,
+						},
+						ControllerMode: &featureDisabled,
+					},
+				},
+			},
+			isolatedIstio: v1.NewHelmValues(map[string]interface{}{
+				"gatewayAPI": map[string]interface{}{
+					"enabled":        true,
+					"controllerMode": false,
+				},
+			}),
+			completeIstio: v1.NewHelmValues(map[string]interface{}{
+				"global": map[string]interface{}{
+					"multiCluster":  globalMultiClusterDefaults,
+					"meshExpansion": globalMeshExpansionDefaults,
+				},
+			}),
+		},
+		{
+			name: "gatewayAPI.gatewayClassParameters." + ver,
+			spec: &v2.ControlPlaneSpec{
+				Version: ver,
+				Gateways: &v2.GatewaysConfig{
+					GatewayAPI: &v2.GatewayAPIConfig{
+						Enablement: v2.Enablement{
+							Enabled: &featureEnabled,
+						},
+						GatewayClassParameters: &v2.GatewayClassParametersConfig{
+							Pod: &v2.CommonPodRuntimeConfig{
+								NodeSelector: map[string]string{
+									"node-role.kubernetes.io/infra": "",
+								},
+								Tolerations: []corev1.Toleration{
+									{
+										Key:      "node-role.kubernetes.io/infra",
+										Operator: corev1.TolerationOpExists,
+										Effect:   corev1.TaintEffectNoSchedule,
+									},
+								},
+								PriorityClassName: "gateway-priority",
+							},
+							PodAnnotations: map[string]string{
+								"sidecar.istio.io/proxyCPU": "200m",
+							},
+							ServiceAnnotations: map[string]string{
+								"service.beta.kubernetes.io/aws-load-balancer-type": "nlb",
+							},
+							Autoscaling: &v2.AutoScalerConfig{
+								Enablement:                     v2.Enablement{Enabled: &featureEnabled},
+								MinReplicas:                    &replicaCount1,
+								MaxReplicas:                    &replicaCount5,
+								TargetCPUUtilizationPercentage: &cpuUtilization80,
+							},
+						},
+					},
+				},
+			},
+			isolatedIstio: v1.NewHelmValues(map[string]interface{}{
+				"gatewayAPI": map[string]interface{}{
+					"enabled": true,
+					"gatewayClassParameters": map[string]interface{}{
+						"pod": map[string]interface{}{
+							"nodeSelector": map[string]interface{}{
+								"node-role.kubernetes.io/infra": "",
+							},
+							"tolerations": []interface{}{
+								map[string]interface{}{
+									"key":      "node-role.kubernetes.io/infra",
+									"operator": "Exists",
+									"effect":   "NoSchedule",
+								},
+							},
+							"priorityClassName": "gateway-priority",
+						},
+						"podAnnotations": map[string]interface{}{
+							"sidecar.istio.io/proxyCPU": "200m",
+						},
+						"serviceAnnotations": map[string]interface{}{
+							"service.beta.kubernetes.io/aws-load-balancer-type": "nlb",
+						},
+						"autoscaling": map[string]interface{}{
+							"enabled":                        true,
+							"minReplicas":                    1,
+							"maxReplicas":                    5,
+							"targetCPUUtilizationPercentage": 80,
+						},
+					},
+				},
+			}),
+			completeIstio: v1.NewHelmValues(map[string]interface{}{
+				"global": map[string]interface{}{
+					"multiCluster":  globalMultiClusterDefaults,
+					"meshExpansion": globalMeshExpansionDefaults,
+				},
+			}),
+		},
 		{
 			name: "ingress.service.basic." + ver,
 			spec: &v2.ControlPlaneSpec{
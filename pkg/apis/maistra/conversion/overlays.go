@@ -0,0 +1,37 @@
+package conversion
+
+import (
+	v1 "github.com/maistra/istio-operator/pkg/apis/maistra/v1"
+	v2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+)
+
+// overlays are not consumed by the charts, but are stored in the values so
+// they survive the round trip through v1
+func populateOverlaysValues(in *v2.ControlPlaneSpec, out map[string]interface{}) error {
+	if in.Overlays == nil {
+		return nil
+	}
+
+	untypedSlice := make([]interface{}, len(in.Overlays))
+	for index, value := range in.Overlays {
+		untypedSlice[index] = value
+	}
+	overlays, err := sliceToValues(untypedSlice)
+	if err != nil {
+		return err
+	}
+	return setHelmValue(out, "overlays", overlays)
+}
+
+func populateOverlaysConfig(in *v1.HelmValues, out *v2.ControlPlaneSpec) error {
+	if rawOverlays, ok, err := in.GetAndRemoveSlice("overlays"); ok {
+		var overlays []v2.OverlayConfig
+		if err := fromValues(rawOverlays, &overlays); err != nil {
+			return err
+		}
+		out.Overlays = overlays
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package conversion
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "github.com/maistra/istio-operator/pkg/apis/maistra/v1"
+	v2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/versions"
+)
+
+var overlaysTestCases []conversionOverlaysTestCase
+
+type conversionOverlaysTestCase struct {
+	name       string
+	spec       *v2.ControlPlaneSpec
+	helmValues string
+}
+
+func init() {
+	for _, v := range versions.TestedVersions {
+		overlaysTestCases = append(overlaysTestCases, overlaysTestCasesV2(v)...)
+	}
+}
+
+func TestOverlaysConversionFromV2(t *testing.T) {
+	for _, tc := range overlaysTestCases {
+		t.Run(tc.name, func(t *testing.T) {
+			specCopy := tc.spec.DeepCopy()
+			actualHelmValues := v1.NewHelmValues(make(map[string]interface{}))
+			if err := populateOverlaysValues(specCopy, actualHelmValues.GetContent()); err != nil {
+				t.Errorf("error converting to values: %s", err)
+			}
+
+			expectedHelmValues := v1.HelmValues{}
+			if err := expectedHelmValues.UnmarshalYAML([]byte(tc.helmValues)); err != nil {
+				t.Fatalf("failed to parse helm values: %s", err)
+			}
+			if !reflect.DeepEqual(expectedHelmValues.DeepCopy(), actualHelmValues.DeepCopy()) {
+				t.Errorf("unexpected output converting v2 to values:\n\texpected:\n%#v\n\tgot:\n%#v", expectedHelmValues.GetContent(), actualHelmValues.GetContent())
+			}
+			specv2 := v2.ControlPlaneSpec{}
+			if err := populateOverlaysConfig(expectedHelmValues.DeepCopy(), &specv2); err != nil {
+				t.Errorf("error converting from values: %s", err)
+			}
+			assertEquals(t, tc.spec.Overlays, specv2.Overlays)
+		})
+	}
+}
+
+func overlaysTestCasesV2(version versions.Version) []conversionOverlaysTestCase {
+	ver := version.String()
+	return []conversionOverlaysTestCase{
+		{
+			name: "nil." + ver,
+			spec: &v2.ControlPlaneSpec{
+				Version: ver,
+			},
+			helmValues: "{}",
+		},
+		{
+			name: "overlays." + ver,
+			spec: &v2.ControlPlaneSpec{
+				Version: ver,
+				Overlays: []v2.OverlayConfig{
+					{
+						Kind:  "Deployment",
+						Name:  "istiod-basic",
+						Patch: "spec:\n  replicas: 2\n",
+					},
+					{
+						Kind:      "Service",
+						Name:      "istio-ingressgateway",
+						Namespace: "istio-system",
+						Type:      v2.OverlayPatchTypeJSON6902,
+						Patch:     `[{"op": "remove", "path": "/spec/loadBalancerIP"}]`,
+					},
+				},
+			},
+			helmValues: `
+overlays:
+- kind: Deployment
+  name: istiod-basic
+  patch: |
+    spec:
+      replicas: 2
+- kind: Service
+  name: istio-ingressgateway
+  namespace: istio-system
+  type: JSON6902
+  patch: '[{"op": "remove", "path": "/spec/loadBalancerIP"}]'
+`,
+		},
+	}
+}
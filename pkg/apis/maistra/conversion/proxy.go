@@ -347,6 +347,14 @@ func populateProxyValues(in *v2.ControlPlaneSpec, values map[string]interface{})
 		}
 	}
 
+	// the update of workloads is performed by the operator, the value is only
+	// used for converting back to v2
+	if proxy.UpdateWorkloads != nil {
+		if err := setHelmBoolValue(proxyValues, "updateWorkloads", *proxy.UpdateWorkloads); err != nil {
+			return err
+		}
+	}
+
 	// set proxy values
 	if len(proxyValues) > 0 {
 		if err := overwriteHelmValues(values, proxyValues, "global", "proxy"); err != nil {
@@ -935,6 +943,13 @@ func populateProxyConfig(in *v1.HelmValues, out *v2.ControlPlaneSpec) error {
 		setProxy = true
 	}
 
+	if updateWorkloads, ok, err := proxyValues.GetAndRemoveBool("updateWorkloads"); ok {
+		proxy.UpdateWorkloads = &updateWorkloads
+		setProxy = true
+	} else if err != nil {
+		return err
+	}
+
 	if setProxy {
 		out.Proxy = proxy
 	}
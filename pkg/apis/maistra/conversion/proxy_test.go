@@ -105,6 +105,28 @@ func proxyTestCasesV2(version versions.Version) []conversionTestCase {
 				},
 			}),
 		},
+		{
+			name: "updateWorkloads." + ver,
+			spec: &v2.ControlPlaneSpec{
+				Version: ver,
+				Proxy: &v2.ProxyConfig{
+					UpdateWorkloads: &featureEnabled,
+				},
+			},
+			isolatedIstio: v1.NewHelmValues(map[string]interface{}{
+				"global": map[string]interface{}{
+					"proxy": map[string]interface{}{
+						"updateWorkloads": true,
+					},
+				},
+			}),
+			completeIstio: v1.NewHelmValues(map[string]interface{}{
+				"global": map[string]interface{}{
+					"multiCluster":  globalMultiClusterDefaults,
+					"meshExpansion": globalMeshExpansionDefaults,
+				},
+			}),
+		},
 		{
 			name: "networking.misc." + ver,
 			spec: &v2.ControlPlaneSpec{
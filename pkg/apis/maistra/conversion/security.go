@@ -1,6 +1,7 @@
 package conversion
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
@@ -207,6 +208,21 @@ func populateSecurityValues(in *v2.ControlPlaneSpec, values map[string]interface
 				return fmt.Errorf("cert-manager ca config: failed setting extraVolumes in helm chart: %s", err.Error())
 			}
 
+		case v2.CertificateAuthorityTypeKubernetes:
+			if err := populatePilotCAImplementation(values, security); err != nil {
+				return err
+			}
+			addEnvToComponent(in, "pilot", "EXTERNAL_CA", kubernetesCSRExternalCA)
+
+			kubernetes := security.CertificateAuthority.Kubernetes
+			if kubernetes == nil || kubernetes.SignerName == "" {
+				break
+			}
+			addEnvToComponent(in, "pilot", "K8S_SIGNER", kubernetes.SignerName)
+			// allows istiod to approve the requests for the signer
+			if err := setHelmStringSliceValue(values, "global.certSigners", []string{kubernetes.SignerName}); err != nil {
+				return err
+			}
 		case "":
 			// don't configure any ca settings
 		default:
@@ -240,12 +256,32 @@ func populateSecurityValues(in *v2.ControlPlaneSpec, values map[string]interface
 					return err
 				}
 			}
+			if tpi.Audience != "" || len(tpi.TrustedAudiences) > 0 {
+				// istiod only accepts tokens for istio-ca by default
+				audience := tpi.Audience
+				if audience == "" {
+					audience = v2.DefaultTokenAudience
+				}
+				audiences := append([]string{audience}, tpi.TrustedAudiences...)
+				addEnvToComponent(in, "pilot", "TOKEN_AUDIENCES", strings.Join(audiences, ","))
+			}
 			// XXX: TokenPath is not currently supported
 		case "":
 			// don't configure any identity settings
 		default:
 			return fmt.Errorf("unknown Identity type: %s", security.Identity.Type)
 		}
+		if oidc := security.Identity.OIDC; oidc != nil {
+			jwtRule, err := json.Marshal(&istiodJWTRule{
+				Issuer:    oidc.Issuer,
+				JwksURI:   oidc.JwksURI,
+				Audiences: oidc.Audiences,
+			})
+			if err != nil {
+				return err
+			}
+			addEnvToComponent(in, "pilot", "JWT_RULE", string(jwtRule))
+		}
 	}
 
 	// Control Plane Security
@@ -298,9 +334,27 @@ func populateSecurityValues(in *v2.ControlPlaneSpec, values map[string]interface
 		}
 	}
 
+	// CA rotation is performed by the operator, the values are only used for
+	// converting back to v2
+	if security.CARotation != nil {
+		if err := setHelmStringValue(values, "global.caRotation.secretName", security.CARotation.SecretName); err != nil {
+			return err
+		}
+		if security.CARotation.WorkloadCertRefreshPeriod != "" {
+			if err := setHelmStringValue(values, "global.caRotation.workloadCertRefreshPeriod",
+				security.CARotation.WorkloadCertRefreshPeriod); err != nil {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
 
+// kubernetesCSRExternalCA configures istiod to act as registration authority
+// for a signer of the Kubernetes CSR API
+const kubernetesCSRExternalCA = "ISTIOD_RA_KUBERNETES_API"
+
 // `pilot.ca.implementation` is no longer used by helm charts, but it's necessary to be set to convert v1 to v2.
 // Otherwise, we wouldn't know which CA type should be set in v2 during conversion from v1.
 func populatePilotCAImplementation(values map[string]interface{}, security *v2.SecurityConfig) error {
@@ -508,6 +562,23 @@ func populateSecurityConfig(in *v1.HelmValues, out *v2.ControlPlaneSpec, version
 			return err
 		}
 
+	case v2.CertificateAuthorityTypeKubernetes:
+		setCA = true
+		ca.Type = v2.CertificateAuthorityTypeKubernetes
+		if _, _, err := getAndClearComponentEnv(in, "pilot", "EXTERNAL_CA"); err != nil {
+			return err
+		}
+		if signerName, ok, err := getAndClearComponentEnv(in, "pilot", "K8S_SIGNER"); ok {
+			ca.Kubernetes = &v2.KubernetesCertificateAuthorityConfig{
+				SignerName: signerName,
+			}
+		} else if err != nil {
+			return err
+		}
+		if _, _, err := in.GetAndRemoveStringSlice("global.certSigners"); err != nil {
+			return err
+		}
+
 	case "":
 		// don't configure CA
 	}
@@ -544,6 +615,23 @@ func populateSecurityConfig(in *v1.HelmValues, out *v2.ControlPlaneSpec, version
 				} else if err != nil {
 					return err
 				}
+				if rawAudiences, ok, err := getAndClearComponentEnv(in, "pilot", "TOKEN_AUDIENCES"); ok {
+					audience := thirdPartyConfig.Audience
+					if audience == "" {
+						audience = v2.DefaultTokenAudience
+					}
+					audiences := strings.Split(rawAudiences, ",")
+					if audiences[0] == audience {
+						audiences = audiences[1:]
+					}
+					if len(audiences) > 0 {
+						thirdPartyConfig.TrustedAudiences = audiences
+						setThirdParty = true
+						setSecurity = true
+					}
+				} else if err != nil {
+					return err
+				}
 				if setThirdParty {
 					identity.ThirdParty = thirdPartyConfig
 				}
@@ -554,6 +642,20 @@ func populateSecurityConfig(in *v1.HelmValues, out *v2.ControlPlaneSpec, version
 	} else if err != nil {
 		return err
 	}
+	if rawJWTRule, ok, err := getAndClearComponentEnv(in, "pilot", "JWT_RULE"); ok {
+		jwtRule := &istiodJWTRule{}
+		if err := json.Unmarshal([]byte(rawJWTRule), jwtRule); err != nil {
+			return fmt.Errorf("error parsing pilot JWT_RULE: %s", err)
+		}
+		identity.OIDC = &v2.OIDCIdentityConfig{
+			Issuer:    jwtRule.Issuer,
+			JwksURI:   jwtRule.JwksURI,
+			Audiences: jwtRule.Audiences,
+		}
+		setIdentity = true
+	} else if err != nil {
+		return err
+	}
 	if setIdentity {
 		security.Identity = identity
 		setSecurity = true
@@ -630,6 +732,25 @@ func populateSecurityConfig(in *v1.HelmValues, out *v2.ControlPlaneSpec, version
 		return err
 	}
 
+	caRotation := &v2.CARotationConfig{}
+	setCARotation := false
+	if secretName, ok, err := in.GetAndRemoveString("global.caRotation.secretName"); ok {
+		caRotation.SecretName = secretName
+		setCARotation = true
+	} else if err != nil {
+		return err
+	}
+	if refreshPeriod, ok, err := in.GetAndRemoveString("global.caRotation.workloadCertRefreshPeriod"); ok {
+		caRotation.WorkloadCertRefreshPeriod = refreshPeriod
+		setCARotation = true
+	} else if err != nil {
+		return err
+	}
+	if setCARotation {
+		security.CARotation = caRotation
+		setSecurity = true
+	}
+
 	if setSecurity {
 		out.Security = security
 	}
@@ -637,6 +758,14 @@ func populateSecurityConfig(in *v1.HelmValues, out *v2.ControlPlaneSpec, version
 	return nil
 }
 
+// istiodJWTRule is the subset of the Istio JWTRule that is read by istiod
+// from the JWT_RULE environment variable.
+type istiodJWTRule struct {
+	Issuer    string   `json:"issuer"`
+	JwksURI   string   `json:"jwks_uri,omitempty"`
+	Audiences []string `json:"audiences,omitempty"`
+}
+
 func getIdentityTypeFromJWTPolicy(jwtPolicy string) (v2.IdentityConfigType, error) {
 	switch jwtPolicy {
 	case "first-party-jwt":
@@ -239,6 +239,36 @@ func securityTestCasesV2(version versions.Version) []conversionTestCase {
 				},
 			}),
 		},
+		{
+			name: "security.caRotation." + ver,
+			spec: &v2.ControlPlaneSpec{
+				Version: ver,
+				Security: &v2.SecurityConfig{
+					CARotation: &v2.CARotationConfig{
+						SecretName:                "cacerts-new",
+						WorkloadCertRefreshPeriod: "48h",
+					},
+				},
+			},
+			isolatedIstio: v1.NewHelmValues(map[string]interface{}{
+				"global": map[string]interface{}{
+					"caRotation": map[string]interface{}{
+						"secretName":                "cacerts-new",
+						"workloadCertRefreshPeriod": "48h",
+					},
+				},
+			}),
+			completeIstio: v1.NewHelmValues(map[string]interface{}{
+				"global": map[string]interface{}{
+					"multiCluster":  globalMultiClusterDefaults,
+					"meshExpansion": globalMeshExpansionDefaults,
+					"caRotation": map[string]interface{}{
+						"secretName":                "cacerts-new",
+						"workloadCertRefreshPeriod": "48h",
+					},
+				},
+			}),
+		},
 		{
 			name: "ca.istiod.nil." + ver,
 			spec: &v2.ControlPlaneSpec{
@@ -706,7 +736,76 @@ func securityTestCasesV2(version versions.Version) []conversionTestCase {
 				},
 				"pilot": map[string]interface{}{
 					"env": map[string]interface{}{
-						"TOKEN_ISSUER": "https://my-issuer.example.com",
+						"TOKEN_ISSUER":    "https://my-issuer.example.com",
+						"TOKEN_AUDIENCES": "istio-ca",
+					},
+				},
+			}),
+		},
+		{
+			name: "identity.thirdparty.trustedAudiences." + ver,
+			spec: &v2.ControlPlaneSpec{
+				Version: ver,
+				Security: &v2.SecurityConfig{
+					Identity: &v2.IdentityConfig{
+						Type: v2.IdentityConfigTypeThirdParty,
+						ThirdParty: &v2.ThirdPartyIdentityConfig{
+							Audience:         "my-audience",
+							TrustedAudiences: []string{"istio-ca"},
+						},
+					},
+				},
+			},
+			isolatedIstio: v1.NewHelmValues(map[string]interface{}{
+				"global": map[string]interface{}{
+					"jwtPolicy": "third-party-jwt",
+					"sds": map[string]interface{}{
+						"token": map[string]interface{}{
+							"aud": "my-audience",
+						},
+					},
+				},
+			}),
+			completeIstio: v1.NewHelmValues(map[string]interface{}{
+				"global": map[string]interface{}{
+					"multiCluster":  globalMultiClusterDefaults,
+					"meshExpansion": globalMeshExpansionDefaults,
+				},
+				"pilot": map[string]interface{}{
+					"env": map[string]interface{}{
+						"TOKEN_AUDIENCES": "my-audience,istio-ca",
+					},
+				},
+			}),
+		},
+		{
+			name: "identity.oidc." + ver,
+			spec: &v2.ControlPlaneSpec{
+				Version: ver,
+				Security: &v2.SecurityConfig{
+					Identity: &v2.IdentityConfig{
+						Type: v2.IdentityConfigTypeKubernetes,
+						OIDC: &v2.OIDCIdentityConfig{
+							Issuer:    "https://my-issuer.example.com",
+							JwksURI:   "https://my-issuer.example.com/keys",
+							Audiences: []string{"istio-ca"},
+						},
+					},
+				},
+			},
+			isolatedIstio: v1.NewHelmValues(map[string]interface{}{
+				"global": map[string]interface{}{
+					"jwtPolicy": "first-party-jwt",
+				},
+			}),
+			completeIstio: v1.NewHelmValues(map[string]interface{}{
+				"global": map[string]interface{}{
+					"multiCluster":  globalMultiClusterDefaults,
+					"meshExpansion": globalMeshExpansionDefaults,
+				},
+				"pilot": map[string]interface{}{
+					"env": map[string]interface{}{
+						"JWT_RULE": `{"issuer":"https://my-issuer.example.com","jwks_uri":"https://my-issuer.example.com/keys","audiences":["istio-ca"]}`,
 					},
 				},
 			}),
@@ -1004,6 +1103,31 @@ pilot:
     implementation: cert-manager
   env:
     ENABLE_CA_SERVER: "false"
+`),
+	},
+	{
+		name: "ca.kubernetes.v2_4",
+		spec: &v2.ControlPlaneSpec{
+			Version: versions.V2_4.String(),
+			Security: &v2.SecurityConfig{
+				CertificateAuthority: &v2.CertificateAuthorityConfig{
+					Type: v2.CertificateAuthorityTypeKubernetes,
+					Kubernetes: &v2.KubernetesCertificateAuthorityConfig{
+						SignerName: "clusterissuers.cert-manager.io/istio-ca",
+					},
+				},
+			},
+		},
+		expectedHelmValues: buildHelmValues(`
+global:
+  certSigners:
+  - clusterissuers.cert-manager.io/istio-ca
+pilot:
+  ca:
+    implementation: Kubernetes
+  env:
+    EXTERNAL_CA: ISTIOD_RA_KUBERNETES_API
+    K8S_SIGNER: clusterissuers.cert-manager.io/istio-ca
 `),
 	},
 }
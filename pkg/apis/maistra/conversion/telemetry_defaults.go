@@ -0,0 +1,135 @@
+package conversion
+
+import (
+	"math"
+
+	v1 "github.com/maistra/istio-operator/pkg/apis/maistra/v1"
+	v2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+)
+
+func populateTelemetryDefaultsValues(in *v2.ControlPlaneSpec, values map[string]interface{}) error {
+	defaults := in.TelemetryDefaults
+	if defaults == nil {
+		return nil
+	}
+
+	if tracing := defaults.Tracing; tracing != nil {
+		if len(tracing.Providers) > 0 {
+			if err := setHelmStringSliceValue(values, "telemetryDefaults.tracing.providers", tracing.Providers); err != nil {
+				return err
+			}
+		}
+		if tracing.Sampling != nil {
+			if err := setHelmFloatValue(values, "telemetryDefaults.tracing.randomSamplingPercentage", float64(*tracing.Sampling)/100.0); err != nil {
+				return err
+			}
+		}
+		if tracing.DisableSpanReporting != nil {
+			if err := setHelmBoolValue(values, "telemetryDefaults.tracing.disableSpanReporting", *tracing.DisableSpanReporting); err != nil {
+				return err
+			}
+		}
+	}
+
+	if metrics := defaults.Metrics; metrics != nil {
+		if len(metrics.Providers) > 0 {
+			if err := setHelmStringSliceValue(values, "telemetryDefaults.metrics.providers", metrics.Providers); err != nil {
+				return err
+			}
+		}
+	}
+
+	if accessLogging := defaults.AccessLogging; accessLogging != nil {
+		if len(accessLogging.Providers) > 0 {
+			if err := setHelmStringSliceValue(values, "telemetryDefaults.accessLogging.providers", accessLogging.Providers); err != nil {
+				return err
+			}
+		}
+		if accessLogging.Disabled != nil {
+			if err := setHelmBoolValue(values, "telemetryDefaults.accessLogging.disabled", *accessLogging.Disabled); err != nil {
+				return err
+			}
+		}
+		if accessLogging.Filter != "" {
+			if err := setHelmStringValue(values, "telemetryDefaults.accessLogging.filter", accessLogging.Filter); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func populateTelemetryDefaultsConfig(in *v1.HelmValues, out *v2.ControlPlaneSpec) error {
+	defaults := &v2.TelemetryDefaultsConfig{}
+	setDefaults := false
+
+	tracing := &v2.TelemetryDefaultsTracingConfig{}
+	setTracing := false
+	if providers, ok, err := in.GetAndRemoveStringSlice("telemetryDefaults.tracing.providers"); ok {
+		tracing.Providers = providers
+		setTracing = true
+	} else if err != nil {
+		return err
+	}
+	if rawSampling, ok, err := in.GetAndRemoveFloat64("telemetryDefaults.tracing.randomSamplingPercentage"); ok {
+		// sampling: 0 - 100% = 0 - 10000, i.e. 1% = 100
+		sampling := int32(math.Round(rawSampling * 100.0))
+		tracing.Sampling = &sampling
+		setTracing = true
+	} else if rawSampling, ok, intErr := in.GetAndRemoveInt64("telemetryDefaults.tracing.randomSamplingPercentage"); ok {
+		sampling := int32(rawSampling * 100)
+		tracing.Sampling = &sampling
+		setTracing = true
+	} else if err != nil && intErr != nil {
+		return err
+	}
+	if disableSpanReporting, ok, err := in.GetAndRemoveBool("telemetryDefaults.tracing.disableSpanReporting"); ok {
+		tracing.DisableSpanReporting = &disableSpanReporting
+		setTracing = true
+	} else if err != nil {
+		return err
+	}
+	if setTracing {
+		defaults.Tracing = tracing
+		setDefaults = true
+	}
+
+	if providers, ok, err := in.GetAndRemoveStringSlice("telemetryDefaults.metrics.providers"); ok {
+		defaults.Metrics = &v2.TelemetryDefaultsMetricsConfig{Providers: providers}
+		setDefaults = true
+	} else if err != nil {
+		return err
+	}
+
+	accessLogging := &v2.TelemetryDefaultsAccessLoggingConfig{}
+	setAccessLogging := false
+	if providers, ok, err := in.GetAndRemoveStringSlice("telemetryDefaults.accessLogging.providers"); ok {
+		accessLogging.Providers = providers
+		setAccessLogging = true
+	} else if err != nil {
+		return err
+	}
+	if disabled, ok, err := in.GetAndRemoveBool("telemetryDefaults.accessLogging.disabled"); ok {
+		accessLogging.Disabled = &disabled
+		setAccessLogging = true
+	} else if err != nil {
+		return err
+	}
+	if filter, ok, err := in.GetAndRemoveString("telemetryDefaults.accessLogging.filter"); ok {
+		accessLogging.Filter = filter
+		setAccessLogging = true
+	} else if err != nil {
+		return err
+	}
+	if setAccessLogging {
+		defaults.AccessLogging = accessLogging
+		setDefaults = true
+	}
+
+	if setDefaults {
+		out.TelemetryDefaults = defaults
+	}
+
+	return nil
+}
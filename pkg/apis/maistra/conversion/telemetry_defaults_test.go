@@ -0,0 +1,106 @@
+package conversion
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	v1 "github.com/maistra/istio-operator/pkg/apis/maistra/v1"
+	v2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/versions"
+)
+
+var telemetryDefaultsTestCases []conversionTestCase
+
+func telemetryDefaultsTestCasesV2(version versions.Version) []conversionTestCase {
+	ver := version.String()
+	sampling := int32(150)
+	return []conversionTestCase{
+		{
+			name: "nil." + ver,
+			spec: &v2.ControlPlaneSpec{
+				Version: ver,
+			},
+			isolatedIstio: v1.NewHelmValues(map[string]interface{}{}),
+			completeIstio: v1.NewHelmValues(map[string]interface{}{
+				"global": map[string]interface{}{
+					"multiCluster":  globalMultiClusterDefaults,
+					"meshExpansion": globalMeshExpansionDefaults,
+				},
+			}),
+		},
+		{
+			name: "full." + ver,
+			spec: &v2.ControlPlaneSpec{
+				Version: ver,
+				TelemetryDefaults: &v2.TelemetryDefaultsConfig{
+					Tracing: &v2.TelemetryDefaultsTracingConfig{
+						Providers:            []string{"otel"},
+						Sampling:             &sampling,
+						DisableSpanReporting: &featureDisabled,
+					},
+					Metrics: &v2.TelemetryDefaultsMetricsConfig{
+						Providers: []string{"prometheus"},
+					},
+					AccessLogging: &v2.TelemetryDefaultsAccessLoggingConfig{
+						Providers: []string{"envoy"},
+						Disabled:  &featureDisabled,
+						Filter:    "response.code >= 400",
+					},
+				},
+			},
+			isolatedIstio: v1.NewHelmValues(map[string]interface{}{
+				"telemetryDefaults": map[string]interface{}{
+					"tracing": map[string]interface{}{
+						"providers":                []interface{}{"otel"},
+						"randomSamplingPercentage": 1.5,
+						"disableSpanReporting":     false,
+					},
+					"metrics": map[string]interface{}{
+						"providers": []interface{}{"prometheus"},
+					},
+					"accessLogging": map[string]interface{}{
+						"providers": []interface{}{"envoy"},
+						"disabled":  false,
+						"filter":    "response.code >= 400",
+					},
+				},
+			}),
+			completeIstio: v1.NewHelmValues(map[string]interface{}{
+				"global": map[string]interface{}{
+					"multiCluster":  globalMultiClusterDefaults,
+					"meshExpansion": globalMeshExpansionDefaults,
+				},
+			}),
+		},
+	}
+}
+
+func init() {
+	for _, v := range versions.TestedVersions {
+		telemetryDefaultsTestCases = append(telemetryDefaultsTestCases, telemetryDefaultsTestCasesV2(v)...)
+	}
+}
+
+func TestTelemetryDefaultsConversionFromV2(t *testing.T) {
+	for _, tc := range telemetryDefaultsTestCases {
+		t.Run(tc.name, func(t *testing.T) {
+			specCopy := tc.spec.DeepCopy()
+			helmValues := v1.NewHelmValues(make(map[string]interface{}))
+			if err := populateTelemetryDefaultsValues(specCopy, helmValues.GetContent()); err != nil {
+				t.Fatalf("error converting to values: %s", err)
+			}
+			if diff := cmp.Diff(tc.isolatedIstio.GetContent(), helmValues.GetContent()); diff != "" {
+				t.Errorf("unexpected output converting v2 to values:\n%s", diff)
+			}
+			specv2 := &v2.ControlPlaneSpec{}
+			// use expected values
+			helmValues = tc.isolatedIstio.DeepCopy()
+			mergeMaps(tc.completeIstio.DeepCopy().GetContent(), helmValues.GetContent())
+			if err := populateTelemetryDefaultsConfig(helmValues.DeepCopy(), specv2); err != nil {
+				t.Fatalf("error converting from values: %s", err)
+			}
+			assertEquals(t, tc.spec.TelemetryDefaults, specv2.TelemetryDefaults)
+		})
+	}
+}
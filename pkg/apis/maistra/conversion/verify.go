@@ -0,0 +1,24 @@
+package conversion
+
+import (
+	v1 "github.com/maistra/istio-operator/pkg/apis/maistra/v1"
+	v2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+)
+
+// verify is not consumed by the charts, but is stored in the values so it
+// survives the round trip through v1
+func populateVerifyValues(in *v2.ControlPlaneSpec, out map[string]interface{}) error {
+	if in.Verify == nil {
+		return nil
+	}
+	return setHelmBoolValue(out, "verify", *in.Verify)
+}
+
+func populateVerifyConfig(in *v1.HelmValues, out *v2.ControlPlaneSpec) error {
+	if verify, ok, err := in.GetAndRemoveBool("verify"); ok {
+		out.Verify = &verify
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package conversion
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "github.com/maistra/istio-operator/pkg/apis/maistra/v1"
+	v2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/versions"
+)
+
+var verifyTestCases []conversionVerifyTestCase
+
+type conversionVerifyTestCase struct {
+	name       string
+	spec       *v2.ControlPlaneSpec
+	helmValues string
+}
+
+func init() {
+	for _, v := range versions.TestedVersions {
+		verifyTestCases = append(verifyTestCases, verifyTestCasesV2(v)...)
+	}
+}
+
+func TestVerifyConversionFromV2(t *testing.T) {
+	for _, tc := range verifyTestCases {
+		t.Run(tc.name, func(t *testing.T) {
+			specCopy := tc.spec.DeepCopy()
+			actualHelmValues := v1.NewHelmValues(make(map[string]interface{}))
+			if err := populateVerifyValues(specCopy, actualHelmValues.GetContent()); err != nil {
+				t.Errorf("error converting to values: %s", err)
+			}
+
+			expectedHelmValues := v1.HelmValues{}
+			if err := expectedHelmValues.UnmarshalYAML([]byte(tc.helmValues)); err != nil {
+				t.Fatalf("failed to parse helm values: %s", err)
+			}
+			if !reflect.DeepEqual(expectedHelmValues.DeepCopy(), actualHelmValues.DeepCopy()) {
+				t.Errorf("unexpected output converting v2 to values:\n\texpected:\n%#v\n\tgot:\n%#v", expectedHelmValues.GetContent(), actualHelmValues.GetContent())
+			}
+			specv2 := v2.ControlPlaneSpec{}
+			if err := populateVerifyConfig(expectedHelmValues.DeepCopy(), &specv2); err != nil {
+				t.Errorf("error converting from values: %s", err)
+			}
+			assertEquals(t, tc.spec.Verify, specv2.Verify)
+		})
+	}
+}
+
+func verifyTestCasesV2(version versions.Version) []conversionVerifyTestCase {
+	ver := version.String()
+	return []conversionVerifyTestCase{
+		{
+			name: "nil." + ver,
+			spec: &v2.ControlPlaneSpec{
+				Version: ver,
+			},
+			helmValues: "{}",
+		},
+		{
+			name: "enabled." + ver,
+			spec: &v2.ControlPlaneSpec{
+				Version: ver,
+				Verify:  &featureEnabled,
+			},
+			helmValues: "verify: true",
+		},
+		{
+			name: "disabled." + ver,
+			spec: &v2.ControlPlaneSpec{
+				Version: ver,
+				Verify:  &featureDisabled,
+			},
+			helmValues: "verify: false",
+		},
+	}
+}
@@ -100,6 +100,37 @@ const (
 	// ConditionTypeReady signifies the whether or not any Deployment, StatefulSet,
 	// etc. resources are Ready.
 	ConditionTypeReady ConditionType = "Ready"
+	// ConditionTypeRemoteSecretExpiring signifies whether or not any of the
+	// remote cluster secrets used by istiod expire soon or have expired.
+	ConditionTypeRemoteSecretExpiring ConditionType = "RemoteSecretExpiring"
+	// ConditionTypeInUse signifies whether or not any workloads in the mesh
+	// still use the control plane.
+	ConditionTypeInUse ConditionType = "InUse"
+	// ConditionTypeDryRun signifies whether or not the last dry run of the
+	// spec succeeded.  Its message summarizes the changes that would be applied.
+	ConditionTypeDryRun ConditionType = "DryRun"
+	// ConditionTypeDependenciesHealthy signifies whether or not the optional
+	// CustomResourceDefinitions used by the control plane are installed.
+	ConditionTypeDependenciesHealthy ConditionType = "DependenciesHealthy"
+	// ConditionTypeCertificateSignerAvailable signifies whether or not the
+	// signer of the Kubernetes CSR API, to which istiod delegates signing
+	// workload certificates, issues the requested certificates.
+	ConditionTypeCertificateSignerAvailable ConditionType = "CertificateSignerAvailable"
+	// ConditionTypeBackoff signifies whether or not the reconciliation is
+	// suspended, because it failed too often.
+	ConditionTypeBackoff ConditionType = "Backoff"
+	// ConditionTypeTerminating signifies whether or not the resource is being
+	// deleted.  Its reason tells whether deleting its resources failed.
+	ConditionTypeTerminating ConditionType = "Terminating"
+	// ConditionTypeDeletionBlocked signifies whether or not the deletion of
+	// the resource is blocked, because workloads still use it.
+	ConditionTypeDeletionBlocked ConditionType = "DeletionBlocked"
+	// ConditionTypePaused signifies whether or not the reconciliation of the
+	// resource is paused by the user.
+	ConditionTypePaused ConditionType = "Paused"
+	// ConditionTypeTrafficVerified signifies whether or not a request was
+	// routed through the ingress gateway to a canary workload in the mesh.
+	ConditionTypeTrafficVerified ConditionType = "TrafficVerified"
 )
 
 // ConditionStatus represents the status of the condition
@@ -163,6 +194,48 @@ const (
 	ConditionReasonDeleting ConditionReason = "Deleting"
 	// ConditionReasonDeleted ...
 	ConditionReasonDeleted ConditionReason = "Deleted"
+	// ConditionReasonRemoteSecretsValid ...
+	ConditionReasonRemoteSecretsValid ConditionReason = "RemoteSecretsValid"
+	// ConditionReasonRemoteSecretExpiring ...
+	ConditionReasonRemoteSecretExpiring ConditionReason = "RemoteSecretExpiring"
+	// ConditionReasonRemoteSecretError ...
+	ConditionReasonRemoteSecretError ConditionReason = "RemoteSecretError"
+	// ConditionReasonWorkloadsPresent ...
+	ConditionReasonWorkloadsPresent ConditionReason = "WorkloadsPresent"
+	// ConditionReasonNoWorkloads ...
+	ConditionReasonNoWorkloads ConditionReason = "NoWorkloads"
+	// ConditionReasonDryRunSuccessful ...
+	ConditionReasonDryRunSuccessful ConditionReason = "DryRunSuccessful"
+	// ConditionReasonDependenciesAvailable ...
+	ConditionReasonDependenciesAvailable ConditionReason = "DependenciesAvailable"
+	// ConditionReasonDependenciesMissing ...
+	ConditionReasonDependenciesMissing ConditionReason = "DependenciesMissing"
+	// ConditionReasonSignerAvailable ...
+	ConditionReasonSignerAvailable ConditionReason = "SignerAvailable"
+	// ConditionReasonSignerUnavailable ...
+	ConditionReasonSignerUnavailable ConditionReason = "SignerUnavailable"
+	// ConditionReasonRetryBudgetExhausted ...
+	ConditionReasonRetryBudgetExhausted ConditionReason = "RetryBudgetExhausted"
+	// ConditionReasonWebhookNotReady ...
+	ConditionReasonWebhookNotReady ConditionReason = "WebhookNotReady"
+	// ConditionReasonXDSNotReady ...
+	ConditionReasonXDSNotReady ConditionReason = "XDSNotReady"
+	// ConditionReasonDeletionForced ...
+	ConditionReasonDeletionForced ConditionReason = "DeletionForced"
+	// ConditionReasonPausedByUser ...
+	ConditionReasonPausedByUser ConditionReason = "PausedByUser"
+	// ConditionReasonResumed ...
+	ConditionReasonResumed ConditionReason = "Resumed"
+	// ConditionReasonNamespaceTerminating ...
+	ConditionReasonNamespaceTerminating ConditionReason = "NamespaceTerminating"
+	// ConditionReasonVerificationInProgress ...
+	ConditionReasonVerificationInProgress ConditionReason = "VerificationInProgress"
+	// ConditionReasonRequestSucceeded ...
+	ConditionReasonRequestSucceeded ConditionReason = "RequestSucceeded"
+	// ConditionReasonRequestFailed ...
+	ConditionReasonRequestFailed ConditionReason = "RequestFailed"
+	// ConditionReasonIngressGatewayNotFound ...
+	ConditionReasonIngressGatewayNotFound ConditionReason = "IngressGatewayNotFound"
 )
 
 // A Condition represents a specific observation of the object's state.
@@ -317,6 +317,101 @@ func (h *HelmValues) RemoveField(path string) {
 	unstructured.RemoveNestedField(h.data, strings.Split(path, ".")...)
 }
 
+// ListMergeStrategy defines how HelmValues.Merge combines lists that are
+// present in both values
+type ListMergeStrategy string
+
+const (
+	// ListMergeReplace keeps the list of the values being merged into
+	ListMergeReplace ListMergeStrategy = "Replace"
+	// ListMergeAppend appends the items of the merged list to the list of the
+	// values being merged into
+	ListMergeAppend ListMergeStrategy = "Append"
+	// ListMergeByName merges items with the same name field and appends the
+	// items with new names.  Lists containing items without a name are
+	// replaced, as with ListMergeReplace.
+	ListMergeByName ListMergeStrategy = "MergeByName"
+)
+
+// Merge merges the values in other beneath the values in h, i.e. values set
+// in h take precedence.  Maps present in both are merged recursively, and
+// lists present in both are combined according to the strategy.  The content
+// of h is modified, while other is left untouched, though the result may
+// share nested values with other.  If h is nil, new values are returned.
+func (h *HelmValues) Merge(other *HelmValues, strategy ListMergeStrategy) *HelmValues {
+	if h == nil {
+		h = NewHelmValues(nil)
+	} else if h.data == nil {
+		h.data = map[string]interface{}{}
+	}
+	mergeMaps(h.data, other.GetContent(), strategy)
+	return h
+}
+
+func mergeMaps(base, input map[string]interface{}, strategy ListMergeStrategy) {
+	for key, value := range input {
+		baseValue, exists := base[key]
+		if !exists {
+			base[key] = value
+			continue
+		}
+		switch baseValue := baseValue.(type) {
+		case map[string]interface{}:
+			if inputMap, ok := value.(map[string]interface{}); ok {
+				mergeMaps(baseValue, inputMap, strategy)
+			}
+		case []interface{}:
+			if inputList, ok := value.([]interface{}); ok {
+				base[key] = mergeLists(baseValue, inputList, strategy)
+			}
+		}
+	}
+}
+
+func mergeLists(base, input []interface{}, strategy ListMergeStrategy) []interface{} {
+	switch strategy {
+	case ListMergeAppend:
+		return append(base, input...)
+	case ListMergeByName:
+		baseIndexes, ok := indexByName(base)
+		if !ok {
+			return base
+		}
+		if _, ok := indexByName(input); !ok {
+			return base
+		}
+		for _, item := range input {
+			itemMap := item.(map[string]interface{})
+			if index, exists := baseIndexes[itemMap["name"].(string)]; exists {
+				mergeMaps(base[index].(map[string]interface{}), itemMap, strategy)
+			} else {
+				base = append(base, item)
+			}
+		}
+		return base
+	default:
+		return base
+	}
+}
+
+// indexByName returns the index of each item in the list by its name field,
+// or false if any of the items isn't a map with a name
+func indexByName(list []interface{}) (map[string]int, bool) {
+	indexes := make(map[string]int, len(list))
+	for index, item := range list {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		name, ok := itemMap["name"].(string)
+		if !ok {
+			return nil, false
+		}
+		indexes[name] = index
+	}
+	return indexes, true
+}
+
 func (h *HelmValues) UnmarshalJSON(in []byte) error {
 	err := json.Unmarshal(in, &h.data)
 	if err != nil {
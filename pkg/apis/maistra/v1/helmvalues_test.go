@@ -159,6 +159,260 @@ func TestSetField(t *testing.T) {
 	}
 }
 
+func TestMerge(t *testing.T) {
+	testCases := []struct {
+		name     string
+		base     *HelmValues
+		input    *HelmValues
+		strategy ListMergeStrategy
+		expected *HelmValues
+	}{
+		{
+			name:     "nil-values",
+			base:     nil,
+			input:    nil,
+			strategy: ListMergeReplace,
+			expected: NewHelmValues(map[string]interface{}{}),
+		},
+		{
+			name: "nil-base",
+			base: nil,
+			input: NewHelmValues(map[string]interface{}{
+				"a": 3,
+			}),
+			strategy: ListMergeReplace,
+			expected: NewHelmValues(map[string]interface{}{
+				"a": 3,
+			}),
+		},
+		{
+			name: "nil-input",
+			base: NewHelmValues(map[string]interface{}{
+				"a": 3,
+			}),
+			input:    nil,
+			strategy: ListMergeReplace,
+			expected: NewHelmValues(map[string]interface{}{
+				"a": 3,
+			}),
+		},
+		{
+			name: "base-takes-precedence",
+			base: NewHelmValues(map[string]interface{}{
+				"a": 1,
+			}),
+			input: NewHelmValues(map[string]interface{}{
+				"a": 2,
+				"b": 2,
+			}),
+			strategy: ListMergeReplace,
+			expected: NewHelmValues(map[string]interface{}{
+				"a": 1,
+				"b": 2,
+			}),
+		},
+		{
+			name: "maps-are-merged",
+			base: NewHelmValues(map[string]interface{}{
+				"a": map[string]interface{}{
+					"b": 1,
+				},
+			}),
+			input: NewHelmValues(map[string]interface{}{
+				"a": map[string]interface{}{
+					"b": 2,
+					"c": 2,
+				},
+			}),
+			strategy: ListMergeReplace,
+			expected: NewHelmValues(map[string]interface{}{
+				"a": map[string]interface{}{
+					"b": 1,
+					"c": 2,
+				},
+			}),
+		},
+		{
+			name: "map-does-not-replace-scalar",
+			base: NewHelmValues(map[string]interface{}{
+				"a": "scalar",
+			}),
+			input: NewHelmValues(map[string]interface{}{
+				"a": map[string]interface{}{
+					"b": 1,
+				},
+			}),
+			strategy: ListMergeReplace,
+			expected: NewHelmValues(map[string]interface{}{
+				"a": "scalar",
+			}),
+		},
+		{
+			name: "list-does-not-replace-map",
+			base: NewHelmValues(map[string]interface{}{
+				"a": map[string]interface{}{
+					"b": 1,
+				},
+			}),
+			input: NewHelmValues(map[string]interface{}{
+				"a": []interface{}{"c"},
+			}),
+			strategy: ListMergeAppend,
+			expected: NewHelmValues(map[string]interface{}{
+				"a": map[string]interface{}{
+					"b": 1,
+				},
+			}),
+		},
+		{
+			name: "replace-keeps-base-list",
+			base: NewHelmValues(map[string]interface{}{
+				"a": []interface{}{"b"},
+			}),
+			input: NewHelmValues(map[string]interface{}{
+				"a": []interface{}{"c"},
+			}),
+			strategy: ListMergeReplace,
+			expected: NewHelmValues(map[string]interface{}{
+				"a": []interface{}{"b"},
+			}),
+		},
+		{
+			name: "append-appends-input-list",
+			base: NewHelmValues(map[string]interface{}{
+				"a": []interface{}{"b"},
+			}),
+			input: NewHelmValues(map[string]interface{}{
+				"a": []interface{}{"c", "b"},
+			}),
+			strategy: ListMergeAppend,
+			expected: NewHelmValues(map[string]interface{}{
+				"a": []interface{}{"b", "c", "b"},
+			}),
+		},
+		{
+			name: "append-in-nested-map",
+			base: NewHelmValues(map[string]interface{}{
+				"a": map[string]interface{}{
+					"b": []interface{}{"c"},
+				},
+			}),
+			input: NewHelmValues(map[string]interface{}{
+				"a": map[string]interface{}{
+					"b": []interface{}{"d"},
+				},
+			}),
+			strategy: ListMergeAppend,
+			expected: NewHelmValues(map[string]interface{}{
+				"a": map[string]interface{}{
+					"b": []interface{}{"c", "d"},
+				},
+			}),
+		},
+		{
+			name: "merge-by-name",
+			base: NewHelmValues(map[string]interface{}{
+				"ports": []interface{}{
+					map[string]interface{}{"name": "http", "port": 80},
+					map[string]interface{}{"name": "https", "port": 443},
+				},
+			}),
+			input: NewHelmValues(map[string]interface{}{
+				"ports": []interface{}{
+					map[string]interface{}{"name": "tcp", "port": 31400},
+					map[string]interface{}{"name": "http", "port": 8080, "targetPort": 8080},
+				},
+			}),
+			strategy: ListMergeByName,
+			expected: NewHelmValues(map[string]interface{}{
+				"ports": []interface{}{
+					map[string]interface{}{"name": "http", "port": 80, "targetPort": 8080},
+					map[string]interface{}{"name": "https", "port": 443},
+					map[string]interface{}{"name": "tcp", "port": 31400},
+				},
+			}),
+		},
+		{
+			name: "merge-by-name-merges-nested-lists-by-name",
+			base: NewHelmValues(map[string]interface{}{
+				"gateways": []interface{}{
+					map[string]interface{}{
+						"name": "ingress",
+						"ports": []interface{}{
+							map[string]interface{}{"name": "http", "port": 80},
+						},
+					},
+				},
+			}),
+			input: NewHelmValues(map[string]interface{}{
+				"gateways": []interface{}{
+					map[string]interface{}{
+						"name": "ingress",
+						"ports": []interface{}{
+							map[string]interface{}{"name": "https", "port": 443},
+						},
+					},
+				},
+			}),
+			strategy: ListMergeByName,
+			expected: NewHelmValues(map[string]interface{}{
+				"gateways": []interface{}{
+					map[string]interface{}{
+						"name": "ingress",
+						"ports": []interface{}{
+							map[string]interface{}{"name": "http", "port": 80},
+							map[string]interface{}{"name": "https", "port": 443},
+						},
+					},
+				},
+			}),
+		},
+		{
+			name: "merge-by-name-replaces-unnamed-base-items",
+			base: NewHelmValues(map[string]interface{}{
+				"a": []interface{}{"b"},
+			}),
+			input: NewHelmValues(map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"name": "c"},
+				},
+			}),
+			strategy: ListMergeByName,
+			expected: NewHelmValues(map[string]interface{}{
+				"a": []interface{}{"b"},
+			}),
+		},
+		{
+			name: "merge-by-name-replaces-unnamed-input-items",
+			base: NewHelmValues(map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"name": "b"},
+				},
+			}),
+			input: NewHelmValues(map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"port": 80},
+				},
+			}),
+			strategy: ListMergeByName,
+			expected: NewHelmValues(map[string]interface{}{
+				"a": []interface{}{
+					map[string]interface{}{"name": "b"},
+				},
+			}),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := tc.base.Merge(tc.input, tc.strategy)
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Fatalf("Unexpected values;\nexpected:\n---\n%v---\n\nactual:\n---\n%v---", toYAML(tc.expected), toYAML(actual))
+			}
+		})
+	}
+}
+
 func toYAML(values *HelmValues) string {
 	bytes, err := yaml.Marshal(values)
 	if err != nil {
@@ -153,10 +153,15 @@ func (s *ServiceMeshMemberStatus) GetCondition(conditionType ServiceMeshMemberCo
 
 // SetCondition sets a specific condition in the list of conditions
 func (s *ServiceMeshMemberStatus) SetCondition(condition ServiceMeshMemberCondition) *ServiceMeshMemberStatus {
+	return s.SetConditionAt(condition, metav1.Now())
+}
+
+// SetConditionAt sets a specific condition in the list of conditions, using
+// now as the lastTransitionTime if the status of the condition changes
+func (s *ServiceMeshMemberStatus) SetConditionAt(condition ServiceMeshMemberCondition, now metav1.Time) *ServiceMeshMemberStatus {
 	if s == nil {
 		return nil
 	}
-	now := metav1.Now()
 	for i := range s.Conditions {
 		if s.Conditions[i].Type == condition.Type {
 			if s.Conditions[i].Status != condition.Status {
@@ -260,10 +260,15 @@ func (s *ServiceMeshMemberRollStatus) GetCondition(conditionType ServiceMeshMemb
 
 // SetCondition sets a specific condition in the list of conditions
 func (s *ServiceMeshMemberRollStatus) SetCondition(condition ServiceMeshMemberRollCondition) *ServiceMeshMemberRollStatus {
+	return s.SetConditionAt(condition, metav1.Now())
+}
+
+// SetConditionAt sets a specific condition in the list of conditions, using
+// now as the lastTransitionTime if the status of the condition changes
+func (s *ServiceMeshMemberRollStatus) SetConditionAt(condition ServiceMeshMemberRollCondition, now metav1.Time) *ServiceMeshMemberRollStatus {
 	if s == nil {
 		return nil
 	}
-	now := metav1.Now()
 	for i := range s.Conditions {
 		if s.Conditions[i].Type == condition.Type {
 			if s.Conditions[i].Status != condition.Status {
@@ -9,6 +9,12 @@ type ControlPlaneClusterConfig struct {
 	// XXX: not sure what the difference is between this and cluster name
 	// +optional
 	Network string `json:"network,omitempty"`
+	// MeshID identifies the mesh the control plane belongs to.  Control
+	// planes in different clusters with the same ID form a single mesh and
+	// must use the same trust domain.
+	// .Values.global.meshID
+	// +optional
+	MeshID string `json:"meshID,omitempty"`
 	// .Values.global.multiCluster.enabled, if not null
 	// +optional
 	MultiCluster *MultiClusterConfig `json:"multiCluster,omitempty"`
@@ -18,6 +24,12 @@ type ControlPlaneClusterConfig struct {
 	// the ingress gateway?
 	// +optional
 	MeshExpansion *MeshExpansionConfig `json:"meshExpansion,omitempty"`
+	// .Values.istiodRemote.enabled, if not null
+	// When enabled, istiod is not deployed; the data plane components
+	// (injection webhook, CNI, gateways) are configured to use the istiod
+	// managed outside of this control plane.
+	// +optional
+	ExternalControlPlane *ExternalControlPlaneConfig `json:"externalControlPlane,omitempty"`
 }
 
 // MultiClusterConfig configures aspects related to multi-cluster.
@@ -46,6 +58,22 @@ type MeshExpansionConfig struct {
 	ILBGateway *GatewayConfig `json:"ilbGateway,omitempty"`
 }
 
+// ExternalControlPlaneConfig configures the istiod that is managed outside of
+// this control plane.
+type ExternalControlPlaneConfig struct {
+	Enablement `json:",inline"`
+	// Address of the external istiod, i.e. a host name or IP address without
+	// scheme or port. Proxies connect to it on port 15012.
+	// .Values.global.remotePilotAddress
+	// +optional
+	Address string `json:"address,omitempty"`
+	// CABundle is the PEM encoded CA bundle used to verify the certificates
+	// served by the external istiod's injection and validation webhooks.
+	// .Values.istiodRemote.caBundle
+	// +optional
+	CABundle string `json:"caBundle,omitempty"`
+}
+
 // MeshNetworkConfig configures mesh networks for a multi-cluster mesh.
 type MeshNetworkConfig struct {
 	Endpoints []MeshEndpointConfig `json:"endpoints,omitempty"`
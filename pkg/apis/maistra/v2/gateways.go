@@ -27,6 +27,12 @@ type GatewaysConfig struct {
 	EgressGateways map[string]*EgressGatewayConfig `json:"additionalEgress,omitempty"`
 	// Route configures the Gateway ↔ OpenShift Route integration
 	OpenShiftRoute *OpenShiftRouteConfig `json:"openshiftRoute,omitempty"`
+	// GatewayAPI configures support for the Kubernetes Gateway API. It is
+	// independent of Enabled, which only applies to the gateways above.
+	// The Gateway API CRDs must be installed in the cluster.
+	// Supported in v2.4+
+	// +optional
+	GatewayAPI *GatewayAPIConfig `json:"gatewayAPI,omitempty"`
 }
 
 // OpenShiftRouteConfig represents the Gateway ↔ OpenShift Route integration
@@ -34,6 +40,47 @@ type OpenShiftRouteConfig struct {
 	Enablement `json:",inline"`
 }
 
+// GatewayAPIConfig configures support for the Kubernetes Gateway API
+type GatewayAPIConfig struct {
+	// Enabled enables the Gateway API controllers in istiod, which configure
+	// the mesh from Gateway API resources and deploy gateways for them.
+	// .Values.gatewayAPI.enabled
+	Enablement `json:",inline"`
+	// ControllerMode makes istiod act as the controller of the OpenShift
+	// gateway class instead of the istio gateway class.
+	// .Values.gatewayAPI.controllerMode
+	// +optional
+	ControllerMode *bool `json:"controllerMode,omitempty"`
+	// GatewayClassParameters configures defaults for the gateways istiod
+	// deploys for the Gateway resources of its gateway class, so that they
+	// inherit settings approved for the platform.
+	// .Values.gatewayAPI.gatewayClassParameters
+	// +optional
+	GatewayClassParameters *GatewayClassParametersConfig `json:"gatewayClassParameters,omitempty"`
+}
+
+// GatewayClassParametersConfig configures defaults for the gateways deployed by
+// istiod for Gateway API Gateway resources.
+type GatewayClassParametersConfig struct {
+	// Pod configures the scheduling of the gateway pods.  It is applied by
+	// the gateway injection template when the pods are created.
+	// +optional
+	Pod *CommonPodRuntimeConfig `json:"pod,omitempty"`
+	// PodAnnotations are added to the gateway pods when they are created,
+	// e.g. sidecar.istio.io/proxyCPU to configure the resources of the
+	// gateway proxies.
+	// +optional
+	PodAnnotations map[string]string `json:"podAnnotations,omitempty"`
+	// ServiceAnnotations are added to the Services of the gateways, e.g. to
+	// configure the cloud load balancer.  Annotations already present on a
+	// Service are not overridden.
+	// +optional
+	ServiceAnnotations map[string]string `json:"serviceAnnotations,omitempty"`
+	// Autoscaling configures a HorizontalPodAutoscaler for each gateway.
+	// +optional
+	Autoscaling *AutoScalerConfig `json:"autoscaling,omitempty"`
+}
+
 // GatewayConfig represents the configuration for a gateway
 // XXX: should standard istio secrets be configured automatically, i.e. should
 // the user be forced to add these manually?
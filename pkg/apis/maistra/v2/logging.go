@@ -2,9 +2,10 @@ package v2
 
 // LoggingConfig for control plane components
 type LoggingConfig struct {
-	// ComponentLevels configures log level for specific envoy components
-	// .Values.global.proxy.componentLogLevel, overridden by sidecar.istio.io/componentLogLevel
-	// map of <component>:<level>
+	// ComponentLevels configures the log level of the logging scopes of the
+	// control plane components, e.g. default: debug for istiod
+	// .Values.global.logging.level
+	// map of <scope>:<level>
 	// +optional
 	ComponentLevels ComponentLogLevels `json:"componentLevels,omitempty"`
 	// LogAsJSON enables JSON logging
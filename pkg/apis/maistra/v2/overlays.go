@@ -0,0 +1,35 @@
+package v2
+
+// OverlayConfig is a patch that is applied to a resource rendered from the
+// charts before the operator creates or updates it.  Overlays allow users to
+// customize rendered resources (e.g. add tolerations to istiod) for which the
+// ServiceMeshControlPlane API exposes no dedicated field.
+type OverlayConfig struct {
+	// Kind of the rendered resource to patch, e.g. Deployment.
+	Kind string `json:"kind"`
+	// Name of the rendered resource to patch.
+	Name string `json:"name"`
+	// Namespace of the rendered resource to patch.  If not set, resources
+	// with the specified kind and name are patched in all namespaces.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// Type of the patch.  Defaults to StrategicMerge.
+	// +optional
+	// +kubebuilder:validation:Enum=StrategicMerge;JSON6902
+	Type OverlayPatchType `json:"type,omitempty"`
+	// Patch is the YAML or JSON patch document.  StrategicMerge patches are
+	// partial resources, JSON6902 patches are lists of patch operations.
+	// Strategic merge patches for kinds that are unknown to the operator
+	// (e.g. custom resources) are applied as JSON merge patches.
+	Patch string `json:"patch"`
+}
+
+// OverlayPatchType represents the format of an overlay patch
+type OverlayPatchType string
+
+const (
+	// OverlayPatchTypeStrategicMerge represents a strategic merge patch
+	OverlayPatchTypeStrategicMerge OverlayPatchType = "StrategicMerge"
+	// OverlayPatchTypeJSON6902 represents an RFC 6902 JSON patch
+	OverlayPatchTypeJSON6902 OverlayPatchType = "JSON6902"
+)
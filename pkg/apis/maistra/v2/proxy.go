@@ -38,6 +38,13 @@ type ProxyConfig struct {
 	// .Values.global.proxy.envoyMetricsService
 	// +optional
 	EnvoyMetricsService *ProxyEnvoyServiceConfig `json:"envoyMetricsService,omitempty"`
+	// UpdateWorkloads enables the rolling restart of the Deployments in the
+	// member namespaces whose sidecars don't match the current proxy image
+	// or configuration, e.g. after the control plane was updated in place.
+	// The progress is reported in status.workloadUpdate.
+	// .Values.global.proxy.updateWorkloads, defaults to false
+	// +optional
+	UpdateWorkloads *bool `json:"updateWorkloads,omitempty"`
 }
 
 // ProxyNetworkingConfig is used to configure networking aspects of the sidecar.
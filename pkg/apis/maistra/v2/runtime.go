@@ -173,8 +173,8 @@ type PodRuntimeConfig struct {
 	// If specified, the pod's scheduling constraints
 	// +optional
 	// .Values.podAntiAffinityLabelSelector, podAntiAffinityTermLabelSelector, nodeSelector
-	// NodeAffinity is not supported at this time
-	// PodAffinity is not supported at this time
+	// .Values.*.affinity.nodeAffinity, podAffinity, podAntiAffinity
+	// NodeAffinity and PodAffinity are only supported for pilot (v2.4+) and kiali at this time
 	Affinity *Affinity `json:"affinity,omitempty"`
 }
 
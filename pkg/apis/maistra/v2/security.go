@@ -26,6 +26,10 @@ type SecurityConfig struct {
 	// JwksResolverCA is the configuration for injecting a trusted CA into the JWKSResolver.
 	// +optional
 	JwksResolverCA string `json:"jwksResolverCA,omitempty"`
+	// CARotation requests a staged rotation of the intermediate CA in the
+	// cacerts secret used by istiod to sign workload certificates.
+	// +optional
+	CARotation *CARotationConfig `json:"caRotation,omitempty"`
 }
 
 // TrustConfig configures trust aspects associated with mutual TLS clients
@@ -57,6 +61,10 @@ type CertificateAuthorityConfig struct {
 	// +optional
 	Custom      *CustomCertificateAuthorityConfig      `json:"custom,omitempty"`
 	CertManager *CertManagerCertificateAuthorityConfig `json:"cert-manager,omitempty"`
+	// Kubernetes is the configuration for signing workload certificates
+	// through the Kubernetes CSR API.
+	// +optional
+	Kubernetes *KubernetesCertificateAuthorityConfig `json:"kubernetes,omitempty"`
 }
 
 // CertificateAuthorityType represents the type of CertificateAuthority implementation.
@@ -69,6 +77,9 @@ const (
 	CertificateAuthorityTypeCustom CertificateAuthorityType = "Custom"
 	// CertificateAuthorityTypeCertManager represents a cert-manager istio-csr certificate authority implementation
 	CertificateAuthorityTypeCertManager CertificateAuthorityType = "cert-manager"
+	// CertificateAuthorityTypeKubernetes represents a signer of the Kubernetes
+	// CSR API, to which istiod delegates signing workload certificates
+	CertificateAuthorityTypeKubernetes CertificateAuthorityType = "Kubernetes"
 )
 
 // IstiodCertificateAuthorityConfig is the configuration for Istio's internal
@@ -186,6 +197,20 @@ func (c *CertManagerCertificateAuthorityConfig) GetRootCAConfigMapName() string
 	return c.RootCAConfigMapName
 }
 
+// KubernetesCertificateAuthorityConfig is the configuration for signing
+// workload certificates through the Kubernetes certificates.k8s.io CSR API.
+// istiod acts as a registration authority: it creates a
+// CertificateSigningRequest for each workload certificate, approves it and
+// waits for the signer to issue the certificate.
+// env EXTERNAL_CA=ISTIOD_RA_KUBERNETES_API
+type KubernetesCertificateAuthorityConfig struct {
+	// SignerName is the name of the signer that issues the certificates, e.g.
+	// clusterissuers.cert-manager.io/istio-ca.  istiod is allowed to approve
+	// requests for this signer only.
+	// env K8S_SIGNER, .Values.global.certSigners
+	SignerName string `json:"signerName,omitempty"`
+}
+
 // IdentityConfig configures the types of user tokens used by clients
 type IdentityConfig struct {
 	// Type is the type of identity tokens being used.
@@ -196,6 +221,29 @@ type IdentityConfig struct {
 	// XXX: this is only supported on OCP 4.4+
 	// +optional
 	ThirdParty *ThirdPartyIdentityConfig `json:"thirdParty,omitempty"`
+	// OIDC configures istiod to also accept JWTs issued by an OIDC provider
+	// when authenticating XDS and certificate signing requests, e.g. for
+	// workloads running outside of the cluster.
+	// env JWT_RULE
+	// +optional
+	OIDC *OIDCIdentityConfig `json:"oidc,omitempty"`
+}
+
+// OIDCIdentityConfig configures an OIDC provider whose tokens are trusted by
+// istiod.
+type OIDCIdentityConfig struct {
+	// Issuer is the URL of the issuer, which must match the iss claim of the
+	// tokens.
+	Issuer string `json:"issuer"`
+	// JwksURI is the URL of the provider's public key set used to verify the
+	// tokens.  If not specified, it is discovered using the issuer's OpenID
+	// configuration.
+	// +optional
+	JwksURI string `json:"jwksUri,omitempty"`
+	// Audiences is the list of audiences accepted in the aud claim of the
+	// tokens.  If not specified, the audience is not checked.
+	// +optional
+	Audiences []string `json:"audiences,omitempty"`
 }
 
 // IdentityConfigType represents the identity implementation being used.
@@ -228,8 +276,19 @@ type ThirdPartyIdentityConfig struct {
 	// .Values.global.sds.token.aud, defaults to istio-ca
 	// +optional
 	Audience string `json:"audience,omitempty"`
+	// TrustedAudiences are additional audiences accepted by istiod, e.g.
+	// while the audience of the tokens projected into the proxies is being
+	// changed.  If either Audience or TrustedAudiences is specified, istiod
+	// accepts tokens for Audience and TrustedAudiences.
+	// env TOKEN_AUDIENCES
+	// +optional
+	TrustedAudiences []string `json:"trustedAudiences,omitempty"`
 }
 
+// DefaultTokenAudience is the audience of the tokens used by the proxies if
+// no audience is specified.
+const DefaultTokenAudience = "istio-ca"
+
 // ControlPlaneSecurityConfig is the mutual TLS configuration specific to the
 // control plane.
 type ControlPlaneSecurityConfig struct {
@@ -300,3 +359,26 @@ type ControlPlaneTLSConfig struct {
 	// +optional
 	MaxProtocolVersion string `json:"maxProtocolVersion,omitempty"`
 }
+
+// CARotationConfig configures the rotation of the intermediate CA in the
+// cacerts secret.  The rotation is performed in stages: the cacerts secret is
+// updated with the new CA, trusting both the old and the new root, and istiod
+// is restarted.  Once the new root was distributed to the mesh namespaces and
+// the workloads had time to refresh their certificates, the old root is
+// retired and istiod is restarted again.  The progress is reported in
+// status.caRotation.
+type CARotationConfig struct {
+	// SecretName is the name of the secret in the control plane namespace that
+	// contains the new CA, with the same keys as the cacerts secret:
+	// ca-cert.pem, ca-key.pem, cert-chain.pem and root-cert.pem.
+	// .Values.global.caRotation.secretName
+	SecretName string `json:"secretName"`
+	// WorkloadCertRefreshPeriod is how long to wait for the workloads to
+	// refresh their certificates after istiod was restarted with the new CA,
+	// before the old root is retired.  It should not be shorter than the TTL
+	// of the workload certificates.
+	// .Values.global.caRotation.workloadCertRefreshPeriod
+	// defaults to 24 hours
+	// +optional
+	WorkloadCertRefreshPeriod string `json:"workloadCertRefreshPeriod,omitempty"`
+}
@@ -90,6 +90,27 @@ type ControlPlaneStatus struct {
 	// The readiness status of components & owned resources
 	Readiness ReadinessStatus `json:"readiness"`
 
+	// The namespaces in the mesh, whether sidecars are injected into their
+	// workloads and whether their workloads use this control plane.
+	// +optional
+	MeshNamespaces *MeshNamespacesStatus `json:"meshNamespaces,omitempty"`
+
+	// The proxies of the workloads in the mesh namespaces, by revision and
+	// version, which shows whether sidecars still need to be updated after
+	// the control plane was upgraded.
+	// +optional
+	DataPlane *DataPlaneStatus `json:"dataPlane,omitempty"`
+
+	// The progress of the rotation of the intermediate CA requested in
+	// spec.security.caRotation.
+	// +optional
+	CARotation *CARotationStatus `json:"caRotation,omitempty"`
+
+	// The progress of the restart of the workloads with outdated sidecars
+	// requested in spec.proxy.updateWorkloads.
+	// +optional
+	WorkloadUpdate *WorkloadUpdateStatus `json:"workloadUpdate,omitempty"`
+
 	// The resulting specification of the configuration options after all profiles
 	// have been applied.
 	// +optional
@@ -100,11 +121,222 @@ type ControlPlaneStatus struct {
 	AppliedValues v1.ControlPlaneSpec `json:"appliedValues,omitempty"`
 }
 
+// MeshNamespacesStatus summarizes the membership of the mesh.  The list of
+// namespaces is capped, so that the status of large meshes doesn't grow
+// without bound.
+type MeshNamespacesStatus struct {
+	// The number of namespaces in the mesh, including the control plane
+	// namespace.
+	Total int32 `json:"total"`
+
+	// The number of member namespaces containing workloads with sidecars
+	// injected by this control plane.
+	// +optional
+	InUse int32 `json:"inUse,omitempty"`
+
+	// Whether Namespaces only lists the first namespaces of the mesh, sorted
+	// by name.
+	// +optional
+	Truncated bool `json:"truncated,omitempty"`
+
+	// The namespaces in the mesh, sorted by name.
+	// +optional
+	Namespaces []MeshNamespaceStatus `json:"namespaces,omitempty"`
+}
+
+// MeshNamespaceStatus describes the injection settings of a namespace in the
+// mesh.
+type MeshNamespaceStatus struct {
+	// The name of the namespace.
+	Name string `json:"name"`
+
+	// The value of the istio-injection label of the namespace.
+	// +optional
+	InjectionLabel string `json:"injectionLabel,omitempty"`
+
+	// The value of the istio.io/rev label of the namespace.
+	// +optional
+	Revision string `json:"revision,omitempty"`
+
+	// Whether sidecars are injected into the pods of the namespace, as
+	// derived from its labels.
+	Injection MeshNamespaceInjection `json:"injection"`
+
+	// Whether the namespace contains workloads with injected sidecars.  The
+	// control plane namespace itself is never in use.
+	// +optional
+	InUse bool `json:"inUse,omitempty"`
+
+	// The revisions found in the istio.io/rev label of the injected pods.
+	// +optional
+	InjectedRevisions []string `json:"injectedRevisions,omitempty"`
+
+	// Whether any of the pods was injected by a revision other than this
+	// control plane, e.g. by a control plane that no longer exists.
+	// +optional
+	RevisionMismatch bool `json:"revisionMismatch,omitempty"`
+}
+
+// MeshNamespaceInjection describes whether sidecars are injected into the
+// pods of a mesh namespace.
+type MeshNamespaceInjection string
+
+const (
+	// MeshNamespaceInjectionEnabled means sidecars are injected into all pods
+	// of the namespace by this control plane.
+	MeshNamespaceInjectionEnabled MeshNamespaceInjection = "Enabled"
+	// MeshNamespaceInjectionDisabled means sidecars are not injected into
+	// the pods of the namespace.
+	MeshNamespaceInjectionDisabled MeshNamespaceInjection = "Disabled"
+	// MeshNamespaceInjectionOtherRevision means the namespace is bound to a
+	// revision other than this control plane.
+	MeshNamespaceInjectionOtherRevision MeshNamespaceInjection = "OtherRevision"
+	// MeshNamespaceInjectionPerPod means the namespace has no injection
+	// labels, so sidecars are only injected into pods that request it with
+	// the sidecar.istio.io/inject annotation.
+	MeshNamespaceInjectionPerPod MeshNamespaceInjection = "PerPod"
+)
+
+// DataPlaneStatus counts the pods with sidecars injected in the mesh
+// namespaces.
+type DataPlaneStatus struct {
+	// The number of pods with injected sidecars.
+	Total int32 `json:"total"`
+
+	// The number of pods with sidecars injected by this control plane whose
+	// proxy image differs from the image the control plane injects.
+	// +optional
+	Outdated int32 `json:"outdated,omitempty"`
+
+	// The pods grouped by revision and proxy image, sorted by revision and
+	// image.
+	// +optional
+	Proxies []DataPlaneProxiesStatus `json:"proxies,omitempty"`
+}
+
+// DataPlaneProxiesStatus counts the pods of a revision whose sidecars use the
+// same proxy image.
+type DataPlaneProxiesStatus struct {
+	// The value of the istio.io/rev label of the pods, which is empty for
+	// pods injected without a revision.
+	// +optional
+	Revision string `json:"revision,omitempty"`
+
+	// The image of the istio-proxy container.
+	ProxyImage string `json:"proxyImage"`
+
+	// The tag of the proxy image, which is empty if the image is referenced
+	// by digest.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Whether the image is the one the control plane injects.
+	// +optional
+	Current bool `json:"current,omitempty"`
+
+	// The number of pods.
+	Pods int32 `json:"pods"`
+}
+
+// CARotationStatus describes the progress of the rotation of the intermediate
+// CA.
+type CARotationStatus struct {
+	// The name of the secret containing the CA that is being rotated to.
+	SecretName string `json:"secretName"`
+
+	// The stage of the rotation.
+	Phase CARotationPhase `json:"phase"`
+
+	// When istiod was last restarted by the rotation.
+	// +optional
+	IstiodRestartedAt *metav1.Time `json:"istiodRestartedAt,omitempty"`
+
+	// The number of mesh namespaces that received both root certificates.
+	// +optional
+	UpdatedNamespaces int32 `json:"updatedNamespaces,omitempty"`
+
+	// The total number of mesh namespaces.
+	// +optional
+	TotalNamespaces int32 `json:"totalNamespaces,omitempty"`
+
+	// A human readable description of the progress.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// WorkloadUpdateStatus describes the progress of the restart of the
+// Deployments whose sidecars don't match the current proxy image or
+// configuration.
+type WorkloadUpdateStatus struct {
+	// The proxy image the sidecars are updated to.
+	ProxyImage string `json:"proxyImage"`
+
+	// A hash of the image and the configuration of the proxies, which is
+	// recorded on the pod template of the restarted Deployments.
+	ProxyHash string `json:"proxyHash"`
+
+	// The hash of the proxies when the sidecars of all Deployments were last
+	// up to date.  Pods without the hash annotation are assumed to use it.
+	BaselineProxyHash string `json:"baselineProxyHash"`
+
+	// The number of Deployments whose sidecars are up to date.
+	// +optional
+	UpToDate int32 `json:"upToDate,omitempty"`
+
+	// The number of Deployments being restarted.
+	// +optional
+	Restarting int32 `json:"restarting,omitempty"`
+
+	// The number of Deployments waiting to be restarted.
+	// +optional
+	Pending int32 `json:"pending,omitempty"`
+}
+
+// CARotationPhase is the stage of the rotation of the intermediate CA.
+type CARotationPhase string
+
+const (
+	// CARotationPhasePending means that the new CA hasn't been staged yet,
+	// e.g. because its secret doesn't exist.
+	CARotationPhasePending CARotationPhase = "Pending"
+	// CARotationPhaseDistributingRoots means that istiod still signs
+	// certificates with the old CA, but trusts both roots, while the combined
+	// roots are distributed to the mesh namespaces.
+	CARotationPhaseDistributingRoots CARotationPhase = "DistributingRoots"
+	// CARotationPhaseRefreshingCertificates means that istiod signs
+	// certificates with the new CA and trusts both roots, while the workloads
+	// refresh their certificates.
+	CARotationPhaseRefreshingCertificates CARotationPhase = "RefreshingCertificates"
+	// CARotationPhaseRetiringOldRoot means that the old root was removed from
+	// the cacerts secret and istiod is being restarted.
+	CARotationPhaseRetiringOldRoot CARotationPhase = "RetiringOldRoot"
+	// CARotationPhaseComplete means that istiod only trusts the new root.
+	CARotationPhaseComplete CARotationPhase = "Complete"
+)
+
 // ReadinessStatus contains readiness information for each deployed component.
 type ReadinessStatus struct {
 	// The readiness status of components
 	// +optional
 	Components ReadinessMap `json:"components,omitempty"`
+
+	// The images used by the workloads of each component
+	// +optional
+	Workloads []ComponentWorkloadStatus `json:"workloads,omitempty"`
+}
+
+// ComponentWorkloadStatus describes the Deployments, StatefulSets and
+// DaemonSets of a component.
+type ComponentWorkloadStatus struct {
+	// The name of the component.
+	Component string `json:"component"`
+
+	// The images used by the containers of the component's workloads.
+	// +optional
+	Images []string `json:"images,omitempty"`
+
+	// Whether all of the component's workloads are ready.
+	Ready bool `json:"ready"`
 }
 
 type ReadinessMap map[string][]string
@@ -170,6 +402,11 @@ type ControlPlaneSpec struct {
 	// .Values.mixer.telemetry.enabled, true if not null.  1.6, .Values.telemetry.enabled
 	// +optional
 	Telemetry *TelemetryConfig `json:"telemetry,omitempty"`
+	// TelemetryDefaults configures the mesh-wide Telemetry resource, which
+	// sets the default tracing, metrics and access logging of the workloads.
+	// .Values.telemetryDefaults
+	// +optional
+	TelemetryDefaults *TelemetryDefaultsConfig `json:"telemetryDefaults,omitempty"`
 	// Tracing configures tracing for the mesh.
 	// +optional
 	Tracing *TracingConfig `json:"tracing,omitempty"`
@@ -184,6 +421,16 @@ type ControlPlaneSpec struct {
 	// components, e.g. visualization, metric storage, etc.
 	// +optional
 	Addons *AddonsConfig `json:"addons,omitempty"`
+	// Overlays are patches applied to the resources rendered from the charts
+	// before they are created or updated.
+	// +optional
+	Overlays []OverlayConfig `json:"overlays,omitempty"`
+	// Verify enables the verification of ingress traffic once the control
+	// plane is ready.  The operator deploys a canary workload, routes a
+	// request to it through the ingress gateway and reports the result in
+	// the TrafficVerified condition.  The canary is removed afterwards.
+	// +optional
+	Verify *bool `json:"verify,omitempty"`
 	// TechPreview contains switches for features that are not GA yet.
 	// +optional
 	TechPreview *v1.HelmValues `json:"techPreview,omitempty"`
@@ -220,3 +467,10 @@ func (s ControlPlaneSpec) IsGrafanaEnabled() bool {
 func (s ControlPlaneSpec) IsJaegerEnabled() bool {
 	return s.Tracing != nil && s.Tracing.Type == TracerTypeJaeger
 }
+
+func (s ControlPlaneSpec) IsExternalControlPlaneEnabled() bool {
+	return s.Cluster != nil &&
+		s.Cluster.ExternalControlPlane != nil &&
+		s.Cluster.ExternalControlPlane.Enabled != nil &&
+		*s.Cluster.ExternalControlPlane.Enabled
+}
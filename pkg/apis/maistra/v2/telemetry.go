@@ -117,3 +117,71 @@ type RemoteTelemetryConfig struct {
 	// +optional
 	Batching *TelemetryBatchingConfig `json:"batching,omitempty"`
 }
+
+// TelemetryDefaultsConfig configures the mesh-wide Telemetry resource, which
+// sets the default tracing, metrics and access logging configuration of all
+// workloads in the mesh.  The resource is created in the control plane
+// namespace.  Telemetry resources in the member namespaces override it.
+// .Values.telemetryDefaults
+type TelemetryDefaultsConfig struct {
+	// Tracing configures the default tracing of the workloads.
+	// +optional
+	Tracing *TelemetryDefaultsTracingConfig `json:"tracing,omitempty"`
+	// Metrics configures the default metrics of the workloads.
+	// +optional
+	Metrics *TelemetryDefaultsMetricsConfig `json:"metrics,omitempty"`
+	// AccessLogging configures the default access logging of the workloads.
+	// +optional
+	AccessLogging *TelemetryDefaultsAccessLoggingConfig `json:"accessLogging,omitempty"`
+}
+
+// TelemetryDefaultsTracingConfig configures the default tracing of the mesh
+type TelemetryDefaultsTracingConfig struct {
+	// Providers are the names of the extension providers in
+	// meshConfig.extensionProviders that receive the spans.  If not set, the
+	// default provider of the mesh is used.
+	// .Values.telemetryDefaults.tracing.providers
+	// +optional
+	Providers []string `json:"providers,omitempty"`
+	// Sampling sets the percentage of requests that are traced. Should be
+	// between 0.0 - 100.0. Precision to 0.01, scaled as 0 to 10000, e.g.:
+	// 100% = 10000, 1% = 100
+	// .Values.telemetryDefaults.tracing.randomSamplingPercentage
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10000
+	// +optional
+	Sampling *int32 `json:"sampling,omitempty"`
+	// DisableSpanReporting disables the reporting of spans, while the trace
+	// context is still propagated.
+	// .Values.telemetryDefaults.tracing.disableSpanReporting
+	// +optional
+	DisableSpanReporting *bool `json:"disableSpanReporting,omitempty"`
+}
+
+// TelemetryDefaultsMetricsConfig configures the default metrics of the mesh
+type TelemetryDefaultsMetricsConfig struct {
+	// Providers are the names of the extension providers in
+	// meshConfig.extensionProviders that receive the metrics, e.g. prometheus.
+	// .Values.telemetryDefaults.metrics.providers
+	// +optional
+	Providers []string `json:"providers,omitempty"`
+}
+
+// TelemetryDefaultsAccessLoggingConfig configures the default access logging
+// of the mesh
+type TelemetryDefaultsAccessLoggingConfig struct {
+	// Providers are the names of the extension providers in
+	// meshConfig.extensionProviders that receive the access logs, e.g. envoy.
+	// .Values.telemetryDefaults.accessLogging.providers
+	// +optional
+	Providers []string `json:"providers,omitempty"`
+	// Disabled disables access logging.
+	// .Values.telemetryDefaults.accessLogging.disabled
+	// +optional
+	Disabled *bool `json:"disabled,omitempty"`
+	// Filter is a CEL expression selecting the requests that are logged,
+	// e.g. response.code >= 400.
+	// .Values.telemetryDefaults.accessLogging.filter
+	// +optional
+	Filter string `json:"filter,omitempty"`
+}
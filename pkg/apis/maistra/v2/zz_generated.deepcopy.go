@@ -118,6 +118,42 @@ func (in *AutoScalerConfig) DeepCopy() *AutoScalerConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CARotationConfig) DeepCopyInto(out *CARotationConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CARotationConfig.
+func (in *CARotationConfig) DeepCopy() *CARotationConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CARotationConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CARotationStatus) DeepCopyInto(out *CARotationStatus) {
+	*out = *in
+	if in.IstiodRestartedAt != nil {
+		in, out := &in.IstiodRestartedAt, &out.IstiodRestartedAt
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CARotationStatus.
+func (in *CARotationStatus) DeepCopy() *CARotationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CARotationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CertManagerCertificateAuthorityConfig) DeepCopyInto(out *CertManagerCertificateAuthorityConfig) {
 	*out = *in
@@ -152,6 +188,11 @@ func (in *CertificateAuthorityConfig) DeepCopyInto(out *CertificateAuthorityConf
 		*out = new(CertManagerCertificateAuthorityConfig)
 		**out = **in
 	}
+	if in.Kubernetes != nil {
+		in, out := &in.Kubernetes, &out.Kubernetes
+		*out = new(KubernetesCertificateAuthorityConfig)
+		**out = **in
+	}
 	return
 }
 
@@ -408,6 +449,27 @@ func (in *ComponentServiceConfig) DeepCopy() *ComponentServiceConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentWorkloadStatus) DeepCopyInto(out *ComponentWorkloadStatus) {
+	*out = *in
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentWorkloadStatus.
+func (in *ComponentWorkloadStatus) DeepCopy() *ComponentWorkloadStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentWorkloadStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ContainerConfig) DeepCopyInto(out *ContainerConfig) {
 	*out = *in
@@ -445,6 +507,11 @@ func (in *ControlPlaneClusterConfig) DeepCopyInto(out *ControlPlaneClusterConfig
 		*out = new(MeshExpansionConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ExternalControlPlane != nil {
+		in, out := &in.ExternalControlPlane, &out.ExternalControlPlane
+		*out = new(ExternalControlPlaneConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -563,6 +630,11 @@ func (in *ControlPlaneSpec) DeepCopyInto(out *ControlPlaneSpec) {
 		*out = new(TelemetryConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.TelemetryDefaults != nil {
+		in, out := &in.TelemetryDefaults, &out.TelemetryDefaults
+		*out = new(TelemetryDefaultsConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Tracing != nil {
 		in, out := &in.Tracing, &out.Tracing
 		*out = new(TracingConfig)
@@ -583,6 +655,16 @@ func (in *ControlPlaneSpec) DeepCopyInto(out *ControlPlaneSpec) {
 		*out = new(AddonsConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Overlays != nil {
+		in, out := &in.Overlays, &out.Overlays
+		*out = make([]OverlayConfig, len(*in))
+		copy(*out, *in)
+	}
+	if in.Verify != nil {
+		in, out := &in.Verify, &out.Verify
+		*out = new(bool)
+		**out = **in
+	}
 	if in.TechPreview != nil {
 		in, out := &in.TechPreview, &out.TechPreview
 		*out = (*in).DeepCopy()
@@ -607,6 +689,26 @@ func (in *ControlPlaneStatus) DeepCopyInto(out *ControlPlaneStatus) {
 	in.StatusType.DeepCopyInto(&out.StatusType)
 	in.ComponentStatusList.DeepCopyInto(&out.ComponentStatusList)
 	in.Readiness.DeepCopyInto(&out.Readiness)
+	if in.MeshNamespaces != nil {
+		in, out := &in.MeshNamespaces, &out.MeshNamespaces
+		*out = new(MeshNamespacesStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DataPlane != nil {
+		in, out := &in.DataPlane, &out.DataPlane
+		*out = new(DataPlaneStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CARotation != nil {
+		in, out := &in.CARotation, &out.CARotation
+		*out = new(CARotationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WorkloadUpdate != nil {
+		in, out := &in.WorkloadUpdate, &out.WorkloadUpdate
+		*out = new(WorkloadUpdateStatus)
+		**out = **in
+	}
 	in.AppliedSpec.DeepCopyInto(&out.AppliedSpec)
 	in.AppliedValues.DeepCopyInto(&out.AppliedValues)
 	return
@@ -690,6 +792,43 @@ func (in *DataPlaneSecurityConfig) DeepCopy() *DataPlaneSecurityConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataPlaneProxiesStatus) DeepCopyInto(out *DataPlaneProxiesStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPlaneProxiesStatus.
+func (in *DataPlaneProxiesStatus) DeepCopy() *DataPlaneProxiesStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DataPlaneProxiesStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataPlaneStatus) DeepCopyInto(out *DataPlaneStatus) {
+	*out = *in
+	if in.Proxies != nil {
+		in, out := &in.Proxies, &out.Proxies
+		*out = make([]DataPlaneProxiesStatus, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPlaneStatus.
+func (in *DataPlaneStatus) DeepCopy() *DataPlaneStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DataPlaneStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DatadogTracerConfig) DeepCopyInto(out *DatadogTracerConfig) {
 	*out = *in
@@ -1030,6 +1169,90 @@ func (in *ExtensionProviderPrometheusConfig) DeepCopy() *ExtensionProviderPromet
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalControlPlaneConfig) DeepCopyInto(out *ExternalControlPlaneConfig) {
+	*out = *in
+	in.Enablement.DeepCopyInto(&out.Enablement)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalControlPlaneConfig.
+func (in *ExternalControlPlaneConfig) DeepCopy() *ExternalControlPlaneConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalControlPlaneConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayAPIConfig) DeepCopyInto(out *GatewayAPIConfig) {
+	*out = *in
+	in.Enablement.DeepCopyInto(&out.Enablement)
+	if in.ControllerMode != nil {
+		in, out := &in.ControllerMode, &out.ControllerMode
+		*out = new(bool)
+		**out = **in
+	}
+	if in.GatewayClassParameters != nil {
+		in, out := &in.GatewayClassParameters, &out.GatewayClassParameters
+		*out = new(GatewayClassParametersConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayAPIConfig.
+func (in *GatewayAPIConfig) DeepCopy() *GatewayAPIConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayAPIConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayClassParametersConfig) DeepCopyInto(out *GatewayClassParametersConfig) {
+	*out = *in
+	if in.Pod != nil {
+		in, out := &in.Pod, &out.Pod
+		*out = new(CommonPodRuntimeConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodAnnotations != nil {
+		in, out := &in.PodAnnotations, &out.PodAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ServiceAnnotations != nil {
+		in, out := &in.ServiceAnnotations, &out.ServiceAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(AutoScalerConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayClassParametersConfig.
+func (in *GatewayClassParametersConfig) DeepCopy() *GatewayClassParametersConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayClassParametersConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GatewayConfig) DeepCopyInto(out *GatewayConfig) {
 	*out = *in
@@ -1157,6 +1380,11 @@ func (in *GatewaysConfig) DeepCopyInto(out *GatewaysConfig) {
 		*out = new(OpenShiftRouteConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.GatewayAPI != nil {
+		in, out := &in.GatewayAPI, &out.GatewayAPI
+		*out = new(GatewayAPIConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1312,7 +1540,12 @@ func (in *IdentityConfig) DeepCopyInto(out *IdentityConfig) {
 	if in.ThirdParty != nil {
 		in, out := &in.ThirdParty, &out.ThirdParty
 		*out = new(ThirdPartyIdentityConfig)
-		**out = **in
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OIDC != nil {
+		in, out := &in.OIDC, &out.OIDC
+		*out = new(OIDCIdentityConfig)
+		(*in).DeepCopyInto(*out)
 	}
 	return
 }
@@ -1691,6 +1924,22 @@ func (in *KialiInstallConfig) DeepCopy() *KialiInstallConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubernetesCertificateAuthorityConfig) DeepCopyInto(out *KubernetesCertificateAuthorityConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubernetesCertificateAuthorityConfig.
+func (in *KubernetesCertificateAuthorityConfig) DeepCopy() *KubernetesCertificateAuthorityConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(KubernetesCertificateAuthorityConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LightstepTracerConfig) DeepCopyInto(out *LightstepTracerConfig) {
 	*out = *in
@@ -1827,6 +2076,50 @@ func (in *MeshGatewayConfig) DeepCopy() *MeshGatewayConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshNamespaceStatus) DeepCopyInto(out *MeshNamespaceStatus) {
+	*out = *in
+	if in.InjectedRevisions != nil {
+		in, out := &in.InjectedRevisions, &out.InjectedRevisions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshNamespaceStatus.
+func (in *MeshNamespaceStatus) DeepCopy() *MeshNamespaceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshNamespaceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshNamespacesStatus) DeepCopyInto(out *MeshNamespacesStatus) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]MeshNamespaceStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshNamespacesStatus.
+func (in *MeshNamespacesStatus) DeepCopy() *MeshNamespacesStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshNamespacesStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MeshNetworkConfig) DeepCopyInto(out *MeshNetworkConfig) {
 	*out = *in
@@ -2058,6 +2351,27 @@ func (in *MultiClusterConfig) DeepCopy() *MultiClusterConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OIDCIdentityConfig) DeepCopyInto(out *OIDCIdentityConfig) {
+	*out = *in
+	if in.Audiences != nil {
+		in, out := &in.Audiences, &out.Audiences
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OIDCIdentityConfig.
+func (in *OIDCIdentityConfig) DeepCopy() *OIDCIdentityConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(OIDCIdentityConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OpenShiftRouteConfig) DeepCopyInto(out *OpenShiftRouteConfig) {
 	*out = *in
@@ -2075,6 +2389,22 @@ func (in *OpenShiftRouteConfig) DeepCopy() *OpenShiftRouteConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OverlayConfig) DeepCopyInto(out *OverlayConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OverlayConfig.
+func (in *OverlayConfig) DeepCopy() *OverlayConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(OverlayConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodAntiAffinity) DeepCopyInto(out *PodAntiAffinity) {
 	*out = *in
@@ -2329,6 +2659,11 @@ func (in *ProxyConfig) DeepCopyInto(out *ProxyConfig) {
 		*out = new(ProxyEnvoyServiceConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.UpdateWorkloads != nil {
+		in, out := &in.UpdateWorkloads, &out.UpdateWorkloads
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -2761,6 +3096,13 @@ func (in *ReadinessStatus) DeepCopyInto(out *ReadinessStatus) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.Workloads != nil {
+		in, out := &in.Workloads, &out.Workloads
+		*out = make([]ComponentWorkloadStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -2886,6 +3228,11 @@ func (in *SecurityConfig) DeepCopyInto(out *SecurityConfig) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.CARotation != nil {
+		in, out := &in.CARotation, &out.CARotation
+		*out = new(CARotationConfig)
+		**out = **in
+	}
 	return
 }
 
@@ -3153,6 +3500,115 @@ func (in *TelemetryConfig) DeepCopy() *TelemetryConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TelemetryDefaultsAccessLoggingConfig) DeepCopyInto(out *TelemetryDefaultsAccessLoggingConfig) {
+	*out = *in
+	if in.Providers != nil {
+		in, out := &in.Providers, &out.Providers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Disabled != nil {
+		in, out := &in.Disabled, &out.Disabled
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TelemetryDefaultsAccessLoggingConfig.
+func (in *TelemetryDefaultsAccessLoggingConfig) DeepCopy() *TelemetryDefaultsAccessLoggingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TelemetryDefaultsAccessLoggingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TelemetryDefaultsConfig) DeepCopyInto(out *TelemetryDefaultsConfig) {
+	*out = *in
+	if in.Tracing != nil {
+		in, out := &in.Tracing, &out.Tracing
+		*out = new(TelemetryDefaultsTracingConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = new(TelemetryDefaultsMetricsConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AccessLogging != nil {
+		in, out := &in.AccessLogging, &out.AccessLogging
+		*out = new(TelemetryDefaultsAccessLoggingConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TelemetryDefaultsConfig.
+func (in *TelemetryDefaultsConfig) DeepCopy() *TelemetryDefaultsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TelemetryDefaultsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TelemetryDefaultsMetricsConfig) DeepCopyInto(out *TelemetryDefaultsMetricsConfig) {
+	*out = *in
+	if in.Providers != nil {
+		in, out := &in.Providers, &out.Providers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TelemetryDefaultsMetricsConfig.
+func (in *TelemetryDefaultsMetricsConfig) DeepCopy() *TelemetryDefaultsMetricsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TelemetryDefaultsMetricsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TelemetryDefaultsTracingConfig) DeepCopyInto(out *TelemetryDefaultsTracingConfig) {
+	*out = *in
+	if in.Providers != nil {
+		in, out := &in.Providers, &out.Providers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Sampling != nil {
+		in, out := &in.Sampling, &out.Sampling
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DisableSpanReporting != nil {
+		in, out := &in.DisableSpanReporting, &out.DisableSpanReporting
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TelemetryDefaultsTracingConfig.
+func (in *TelemetryDefaultsTracingConfig) DeepCopy() *TelemetryDefaultsTracingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TelemetryDefaultsTracingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TelemetryLoadSheddingConfig) DeepCopyInto(out *TelemetryLoadSheddingConfig) {
 	*out = *in
@@ -3172,6 +3628,11 @@ func (in *TelemetryLoadSheddingConfig) DeepCopy() *TelemetryLoadSheddingConfig {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ThirdPartyIdentityConfig) DeepCopyInto(out *ThirdPartyIdentityConfig) {
 	*out = *in
+	if in.TrustedAudiences != nil {
+		in, out := &in.TrustedAudiences, &out.TrustedAudiences
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -3442,6 +3903,22 @@ func (in *VolumeConfig) DeepCopy() *VolumeConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadUpdateStatus) DeepCopyInto(out *WorkloadUpdateStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadUpdateStatus.
+func (in *WorkloadUpdateStatus) DeepCopy() *WorkloadUpdateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadUpdateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ZipkinTracerConfig) DeepCopyInto(out *ZipkinTracerConfig) {
 	*out = *in
@@ -61,7 +61,7 @@ func internalRenderCNI(ctx context.Context, cl client.Client, config cni.Config,
 	cni["configMap_v2_3"] = "cni_network_config"
 	cni["configMap_v2_4"] = "cni_network_config"
 
-	cni["chained"] = !config.UseMultus
+	cni["chained"] = config.Chained
 	if config.UseMultus {
 		cni["cniBinDir"] = "/opt/multus/bin"
 		cni["cniConfDir"] = "/etc/cni/multus/net.d"
@@ -72,6 +72,14 @@ func internalRenderCNI(ctx context.Context, cl client.Client, config cni.Config,
 		cni["cniConfFileName_v2_3"] = "v2-3-istio-cni.conf"
 		cni["cniConfFileName_v2_4"] = "v2-4-istio-cni.conf"
 	}
+	if config.BinDir != "" {
+		cni["cniBinDir"] = config.BinDir
+	}
+	if config.ConfDir != "" {
+		cni["cniConfDir"] = config.ConfDir
+		cni["mountedCniConfDir"] = path.Join("/host", config.ConfDir)
+	}
+	cni["excludeNamespaces"] = config.ExcludeNamespaces
 
 	var releases []string
 	if config.UseMultus {
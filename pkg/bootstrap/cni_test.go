@@ -5,6 +5,7 @@ import (
 	"os"
 	"path"
 	goruntime "runtime"
+	"strings"
 	"testing"
 
 	"github.com/ghodss/yaml"
@@ -123,6 +124,45 @@ func TestCNISupportedVersionRendering(t *testing.T) {
 	}
 }
 
+func TestCNIBinDirRendering(t *testing.T) {
+	operatorNamespace := "istio-operator"
+	InitializeGlobals(operatorNamespace)()
+
+	ctx := context.Background()
+	config := cni.Config{
+		Enabled: true,
+		BinDir:  "/home/kubernetes/bin",
+	}
+	cl, tracker := test.CreateClient()
+	dc := fake.FakeDiscovery{Fake: &tracker.Fake, FakedServerVersion: test.DefaultKubeVersion}
+	renderings, err := internalRenderCNI(ctx, cl, config, &dc, versions.GetSupportedVersions(), versions.V2_4.Version())
+	assert.Success(err, "internalRenderCNI", t)
+
+	var foundDaemonSet bool
+	for _, manifest := range renderings["istio_cni"] {
+		if manifest.Head.Kind != "DaemonSet" {
+			continue
+		}
+		foundDaemonSet = true
+		json, err := yaml.YAMLToJSON([]byte(manifest.Content))
+		assert.Success(err, "YAMLToJSON", t)
+		resource := &unstructured.Unstructured{}
+		_, _, err = unstructured.UnstructuredJSONScheme.Decode(json, nil, resource)
+		assert.Success(err, "resource decoding", t)
+
+		volumes, _, err := unstructured.NestedSlice(resource.UnstructuredContent(), "spec", "template", "spec", "volumes")
+		assert.Success(err, "unstructured.NestedSlice", t)
+		var binDir string
+		for _, volume := range volumes {
+			if volume.(map[string]interface{})["name"] == "cni-bin-dir" {
+				binDir, _, _ = unstructured.NestedString(volume.(map[string]interface{}), "hostPath", "path")
+			}
+		}
+		assert.Equals(binDir, config.BinDir, "Unexpected CNI bin dir", t)
+	}
+	assert.True(foundDaemonSet, "Daemon Set was not in Manifest list", t)
+}
+
 // InitializeGlobals returns a function which initializes global variables used
 // by the system under test.  operatorNamespace is the namespace within which
 // the operator is installed.
@@ -140,3 +180,57 @@ func InitializeGlobals(operatorNamespace string) func() {
 		}
 	}
 }
+
+func TestCNIChainedConfigRendering(t *testing.T) {
+	operatorNamespace := "istio-operator"
+	InitializeGlobals(operatorNamespace)()
+
+	ctx := context.Background()
+	config := cni.Config{
+		Enabled:           true,
+		Chained:           true,
+		ConfDir:           "/etc/cni/custom/net.d",
+		ExcludeNamespaces: []string{"kube-system", "monitoring"},
+	}
+	cl, tracker := test.CreateClient()
+	dc := fake.FakeDiscovery{Fake: &tracker.Fake, FakedServerVersion: test.DefaultKubeVersion}
+	renderings, err := internalRenderCNI(ctx, cl, config, &dc, versions.GetSupportedVersions(), versions.V2_4.Version())
+	assert.Success(err, "internalRenderCNI", t)
+
+	var foundDaemonSet, foundConfigMap bool
+	for _, manifest := range renderings["istio_cni"] {
+		if manifest.Head.Kind != "DaemonSet" && manifest.Head.Kind != "ConfigMap" {
+			continue
+		}
+		json, err := yaml.YAMLToJSON([]byte(manifest.Content))
+		assert.Success(err, "YAMLToJSON", t)
+		resource := &unstructured.Unstructured{}
+		_, _, err = unstructured.UnstructuredJSONScheme.Decode(json, nil, resource)
+		assert.Success(err, "resource decoding", t)
+
+		switch manifest.Head.Kind {
+		case "DaemonSet":
+			foundDaemonSet = true
+			containers, _, err := unstructured.NestedSlice(resource.UnstructuredContent(), "spec", "template", "spec", "containers")
+			assert.Success(err, "unstructured.NestedSlice", t)
+			env := map[string]interface{}{}
+			for _, container := range containers {
+				vars, _, _ := unstructured.NestedSlice(container.(map[string]interface{}), "env")
+				for _, envVar := range vars {
+					env[envVar.(map[string]interface{})["name"].(string)] = envVar.(map[string]interface{})["value"]
+				}
+			}
+			assert.Equals(env["CHAINED_CNI_PLUGIN"], "true", "Unexpected CHAINED_CNI_PLUGIN", t)
+			assert.Equals(env["CNI_NET_DIR"], config.ConfDir, "Unexpected CNI_NET_DIR", t)
+			assert.Equals(env["MOUNTED_CNI_NET_DIR"], "/host"+config.ConfDir, "Unexpected MOUNTED_CNI_NET_DIR", t)
+		case "ConfigMap":
+			foundConfigMap = true
+			networkConfig, _, err := unstructured.NestedString(resource.UnstructuredContent(), "data", "cni_network_config")
+			assert.Success(err, "unstructured.NestedString", t)
+			assert.True(strings.Contains(networkConfig, `"exclude_namespaces": [ "istio-operator", "kube-system", "monitoring" ]`),
+				"Expected namespaces to be excluded in "+networkConfig, t)
+		}
+	}
+	assert.True(foundDaemonSet, "Daemon Set was not in Manifest list", t)
+	assert.True(foundConfigMap, "ConfigMap was not in Manifest list", t)
+}
@@ -0,0 +1,63 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/versions"
+)
+
+// AddCRDManager installs or updates the Istio CRDs of the newest supported
+// control plane version once the operator becomes the leader, and again every
+// CRDCheckInterval, so upgrading the operator upgrades the CRDs without
+// waiting for a control plane to be reconciled.  Nothing is added if CRD
+// management is disabled, e.g. because the CRDs are managed externally.
+func AddCRDManager(mgr manager.Manager) error {
+	log := logf.Log.WithName("crd-manager")
+	config := common.Config.Controller
+	if !config.CRDManagementEnabled {
+		log.Info("CRD management is disabled; the Istio CRDs must be installed and updated externally")
+		return nil
+	}
+	ctx := common.NewContextWithLog(common.NewContext(), log)
+	chartsDir := newestSupportedVersion().Strategy().GetChartsDir()
+	return mgr.Add(manager.RunnableFunc(func(stop <-chan struct{}) error {
+		manageCRDs(ctx, mgr.GetClient(), chartsDir, config.CRDCheckInterval, stop)
+		return nil
+	}))
+}
+
+// manageCRDs installs the CRDs from chartsDir every interval until stop is
+// closed.  If interval is zero, they are only installed once.  Errors are
+// retried at the next interval, and when a control plane is reconciled.
+func manageCRDs(ctx context.Context, cl client.Client, chartsDir string, interval time.Duration, stop <-chan struct{}) {
+	log := common.LogFromContext(ctx)
+	for {
+		if err := InstallCRDs(ctx, cl, chartsDir); err != nil {
+			log.Error(err, "error installing Istio CRDs", "chartsDir", chartsDir)
+		}
+		if interval <= 0 {
+			return
+		}
+		select {
+		case <-stop:
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+func newestSupportedVersion() versions.Version {
+	var newest versions.Version
+	for _, version := range versions.GetSupportedVersions() {
+		if newest == nil || version.Compare(newest) > 0 {
+			newest = version
+		}
+	}
+	return newest
+}
@@ -0,0 +1,63 @@
+package bootstrap
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/maistra/istio-operator/pkg/controller/common/test"
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+	"github.com/maistra/istio-operator/pkg/controller/versions"
+)
+
+func TestManageCRDsInstallsCRDsOnceWithoutInterval(t *testing.T) {
+	dir := createTempDirectoryWithCRDFiles(newCRDYAML("test", "1.0.7"))
+	defer deleteDir(dir)
+
+	cl, _ := test.CreateClient()
+	manageCRDs(ctx, cl, dir, 0, make(chan struct{}))
+
+	crdNames := extractNames(listCRDs(cl))
+	assert.DeepEquals(crdNames, sets.NewString("test"), "Unexpected CRDs", t)
+}
+
+func TestManageCRDsReinstallsDeletedCRDs(t *testing.T) {
+	dir := createTempDirectoryWithCRDFiles(newCRDYAML("test", "1.0.7"))
+	defer deleteDir(dir)
+
+	cl, _ := test.CreateClient()
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		manageCRDs(ctx, cl, dir, 10*time.Millisecond, stop)
+		close(done)
+	}()
+	defer func() {
+		close(stop)
+		<-done
+	}()
+
+	waitForCRDs := func() {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			if len(listCRDs(cl).Items) > 0 {
+				return
+			}
+		}
+		t.Fatal("Timed out waiting for CRDs to be installed")
+	}
+	waitForCRDs()
+	crds := listCRDs(cl)
+	for index := range crds.Items {
+		test.PanicOnError(cl.Delete(ctx, &crds.Items[index]))
+	}
+	waitForCRDs()
+}
+
+func TestNewestSupportedVersion(t *testing.T) {
+	newest := newestSupportedVersion()
+	for _, version := range versions.GetSupportedVersions() {
+		assert.True(version.Compare(newest) <= 0, "Expected "+newest.String()+" to be the newest version", t)
+	}
+}
@@ -0,0 +1,294 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	maistrav1 "github.com/maistra/istio-operator/pkg/apis/maistra/v1"
+	maistrav2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+)
+
+const (
+	// cniAppInstance is the app instance label value of the Istio CNI resources
+	cniAppInstance = "istio_cni"
+
+	// sidecarTLSModeLabel is set by the sidecar injector on every injected pod
+	sidecarTLSModeLabel = "security.istio.io/tlsMode"
+)
+
+// TeardownOptions configure Teardown
+type TeardownOptions struct {
+	// RemoveCRDs also removes the Istio CRDs installed by the operator.  All
+	// Istio resources, e.g. VirtualServices, are deleted with them.
+	RemoveCRDs bool
+	// Timeout is how long Teardown waits for the control planes to be deleted
+	Timeout time.Duration
+	// PollInterval is how often Teardown checks whether the control planes
+	// have been deleted
+	PollInterval time.Duration
+}
+
+// TeardownReport lists the objects removed by Teardown and the objects that
+// remain in the cluster and prevent it from being mesh-free
+type TeardownReport struct {
+	Removed   []string
+	Remaining []string
+}
+
+// MeshFree returns true if no mesh objects remain in the cluster
+func (r *TeardownReport) MeshFree() bool {
+	return len(r.Remaining) == 0
+}
+
+// Write writes the report in a human readable form
+func (r *TeardownReport) Write(out io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Removed %d object(s):\n", len(r.Removed))
+	for _, obj := range r.Removed {
+		fmt.Fprintf(&b, "  %s\n", obj)
+	}
+	if r.MeshFree() {
+		b.WriteString("The cluster is mesh-free\n")
+	} else {
+		fmt.Fprintf(&b, "The cluster is not mesh-free, %d object(s) remain:\n", len(r.Remaining))
+		for _, obj := range r.Remaining {
+			fmt.Fprintf(&b, "  %s\n", obj)
+		}
+	}
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+// Teardown removes the service mesh from the cluster: all control planes and
+// their members, the Istio CNI resources and any webhook configurations left
+// behind by control planes, and optionally the Istio CRDs.  The control planes
+// are deleted through the operator, which must be running to finalize them.
+// Once everything is removed, Teardown checks that the cluster is mesh-free.
+// Objects that aren't removed, e.g. pods that still have a sidecar and must
+// be restarted, are listed as remaining in the report.
+func Teardown(ctx context.Context, cl client.Client, options TeardownOptions) (*TeardownReport, error) {
+	log := common.LogFromContext(ctx)
+	report := &TeardownReport{}
+
+	log.Info("deleting control planes and their members")
+	for _, list := range controlPlaneLists() {
+		if err := deleteAll(ctx, cl, list, nil, report); err != nil {
+			return report, err
+		}
+	}
+	log.Info("waiting for control planes to be deleted")
+	err := wait.PollImmediate(options.PollInterval, options.Timeout, func() (bool, error) {
+		for _, list := range controlPlaneLists() {
+			if err := cl.List(ctx, list); err != nil {
+				return false, err
+			}
+			if meta.LenList(list) > 0 {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if err != nil && err != wait.ErrWaitTimeout {
+		return report, err
+	}
+
+	log.Info("deleting Istio CNI resources")
+	for _, list := range cniLists() {
+		if err := deleteAll(ctx, cl, list, cniLabels(), report); err != nil {
+			return report, err
+		}
+	}
+
+	log.Info("deleting webhook configurations")
+	for _, list := range webhookLists() {
+		if err := deleteAll(ctx, cl, list, webhookSelector(), report); err != nil {
+			return report, err
+		}
+	}
+
+	if options.RemoveCRDs {
+		log.Info("deleting Istio CRDs")
+		if err := deleteIstioCRDs(ctx, cl, report); err != nil {
+			return report, err
+		}
+	}
+
+	log.Info("checking that the cluster is mesh-free")
+	if err := findRemainingObjects(ctx, cl, options, report); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+func controlPlaneLists() []runtime.Object {
+	// members are deleted first, so the member roll isn't updated for each of
+	// them after it has been deleted
+	return []runtime.Object{
+		&maistrav1.ServiceMeshMemberList{},
+		&maistrav1.ServiceMeshMemberRollList{},
+		&maistrav2.ServiceMeshControlPlaneList{},
+	}
+}
+
+func cniLists() []runtime.Object {
+	return []runtime.Object{
+		&appsv1.DaemonSetList{},
+		&corev1.ConfigMapList{},
+		&corev1.ServiceAccountList{},
+		&rbacv1.ClusterRoleBindingList{},
+		&rbacv1.ClusterRoleList{},
+	}
+}
+
+func webhookLists() []runtime.Object {
+	return []runtime.Object{
+		&admissionregistrationv1.MutatingWebhookConfigurationList{},
+		&admissionregistrationv1.ValidatingWebhookConfigurationList{},
+	}
+}
+
+func cniLabels() client.MatchingLabels {
+	return client.MatchingLabels{
+		common.KubernetesAppInstanceKey:  cniAppInstance,
+		common.KubernetesAppManagedByKey: common.KubernetesAppManagedByValue,
+	}
+}
+
+// webhookSelector selects the webhook configurations created for control
+// planes, but not the operator's own webhook configurations, which don't
+// have an owner
+func webhookSelector() client.ListOption {
+	return client.HasLabels{common.OwnerKey}
+}
+
+func deleteAll(ctx context.Context, cl client.Client, list runtime.Object, selector client.ListOption, report *TeardownReport) error {
+	var opts []client.ListOption
+	if selector != nil {
+		opts = append(opts, selector)
+	}
+	if err := cl.List(ctx, list, opts...); err != nil {
+		return err
+	}
+	objects, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+	for _, obj := range objects {
+		if err := cl.Delete(ctx, obj, client.PropagationPolicy("Background")); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("error deleting %s: %v", describeObject(list, obj), err)
+		}
+		report.Removed = append(report.Removed, describeObject(list, obj))
+	}
+	return nil
+}
+
+func deleteIstioCRDs(ctx context.Context, cl client.Client, report *TeardownReport) error {
+	list := &apiextensionsv1.CustomResourceDefinitionList{}
+	crds, err := listIstioCRDs(ctx, cl)
+	if err != nil {
+		return err
+	}
+	for i := range crds {
+		crd := &crds[i]
+		if err := cl.Delete(ctx, crd); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("error deleting %s: %v", describeObject(list, crd), err)
+		}
+		report.Removed = append(report.Removed, describeObject(list, crd))
+	}
+	return nil
+}
+
+// listIstioCRDs lists the CRDs of the istio.io API groups installed by
+// InstallCRDs.  The operator's own CRDs aren't included.
+func listIstioCRDs(ctx context.Context, cl client.Client) ([]apiextensionsv1.CustomResourceDefinition, error) {
+	list := &apiextensionsv1.CustomResourceDefinitionList{}
+	if err := cl.List(ctx, list, client.HasLabels{"maistra-version"}); err != nil {
+		return nil, err
+	}
+	var crds []apiextensionsv1.CustomResourceDefinition
+	for _, crd := range list.Items {
+		if crd.Spec.Group == "istio.io" || strings.HasSuffix(crd.Spec.Group, ".istio.io") {
+			crds = append(crds, crd)
+		}
+	}
+	return crds, nil
+}
+
+func findRemainingObjects(ctx context.Context, cl client.Client, options TeardownOptions, report *TeardownReport) error {
+	check := func(list runtime.Object, opts ...client.ListOption) error {
+		if err := cl.List(ctx, list, opts...); err != nil {
+			return err
+		}
+		objects, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+		for _, obj := range objects {
+			report.Remaining = append(report.Remaining, describeObject(list, obj))
+		}
+		return nil
+	}
+	for _, list := range controlPlaneLists() {
+		if err := check(list); err != nil {
+			return err
+		}
+	}
+	for _, list := range cniLists() {
+		if err := check(list, cniLabels()); err != nil {
+			return err
+		}
+	}
+	for _, list := range webhookLists() {
+		if err := check(list, webhookSelector()); err != nil {
+			return err
+		}
+	}
+	if err := check(&corev1.NamespaceList{}, client.HasLabels{common.MemberOfKey}); err != nil {
+		return err
+	}
+	if err := check(&corev1.PodList{}, client.HasLabels{sidecarTLSModeLabel}); err != nil {
+		return err
+	}
+	if options.RemoveCRDs {
+		crds, err := listIstioCRDs(ctx, cl)
+		if err != nil {
+			return err
+		}
+		for i := range crds {
+			report.Remaining = append(report.Remaining, describeObject(&apiextensionsv1.CustomResourceDefinitionList{}, &crds[i]))
+		}
+	}
+	return nil
+}
+
+// describeObject returns the kind and the namespaced name of the object read
+// into list, e.g. "ServiceMeshControlPlane istio-system/basic".  The kind
+// isn't set on objects read by a typed client, so it's derived from the type
+// of the list.
+func describeObject(list, obj runtime.Object) string {
+	kind := strings.TrimSuffix(reflect.TypeOf(list).Elem().Name(), "List")
+	objMeta, err := meta.Accessor(obj)
+	if err != nil {
+		return kind
+	}
+	if objMeta.GetNamespace() == "" {
+		return fmt.Sprintf("%s %s", kind, objMeta.GetName())
+	}
+	return fmt.Sprintf("%s %s/%s", kind, objMeta.GetNamespace(), objMeta.GetName())
+}
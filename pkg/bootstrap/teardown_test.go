@@ -0,0 +1,112 @@
+package bootstrap
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	maistrav1 "github.com/maistra/istio-operator/pkg/apis/maistra/v1"
+	maistrav2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/common/test"
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+)
+
+var teardownOptions = TeardownOptions{
+	Timeout:      time.Second,
+	PollInterval: 10 * time.Millisecond,
+}
+
+func TestTeardown(t *testing.T) {
+	cl, _ := test.CreateClient(teardownObjects()...)
+
+	report, err := Teardown(ctx, cl, teardownOptions)
+	assert.Success(err, "Teardown", t)
+	assert.DeepEquals(report.Removed, []string{
+		"ServiceMeshMember app/default",
+		"ServiceMeshMemberRoll istio-system/default",
+		"ServiceMeshControlPlane istio-system/basic",
+		"DaemonSet istio-operator/istio-cni-node",
+		"MutatingWebhookConfiguration istio-sidecar-injector-basic-istio-system",
+	}, "unexpected removed objects", t)
+	assert.True(report.MeshFree(), "expected the cluster to be mesh-free", t)
+
+	// the operator's own webhook and the Istio CRDs are kept
+	test.AssertObjectExists(ctx, cl, client.ObjectKey{Name: "operator-webhook"}, &admissionregistrationv1.ValidatingWebhookConfiguration{}, "", t)
+	test.AssertObjectExists(ctx, cl, client.ObjectKey{Name: "virtualservices.networking.istio.io"}, &apiextensionsv1.CustomResourceDefinition{}, "", t)
+}
+
+func TestTeardownRemovesCRDs(t *testing.T) {
+	cl, _ := test.CreateClient(teardownObjects()...)
+
+	options := teardownOptions
+	options.RemoveCRDs = true
+	report, err := Teardown(ctx, cl, options)
+	assert.Success(err, "Teardown", t)
+	assert.True(report.MeshFree(), "expected the cluster to be mesh-free", t)
+	assert.Equals(report.Removed[len(report.Removed)-1], "CustomResourceDefinition virtualservices.networking.istio.io",
+		"expected the Istio CRD to be removed", t)
+
+	// the operator's CRDs are kept
+	test.AssertObjectExists(ctx, cl, client.ObjectKey{Name: "servicemeshcontrolplanes.maistra.io"}, &apiextensionsv1.CustomResourceDefinition{}, "", t)
+}
+
+func TestTeardownReportsRemainingObjects(t *testing.T) {
+	objects := append(teardownObjects(),
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   "app",
+			Labels: map[string]string{common.MemberOfKey: "istio-system"},
+		}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "app",
+			Name:      "injected",
+			Labels:    map[string]string{sidecarTLSModeLabel: "istio"},
+		}},
+	)
+	cl, _ := test.CreateClient(objects...)
+
+	report, err := Teardown(ctx, cl, teardownOptions)
+	assert.Success(err, "Teardown", t)
+	assert.False(report.MeshFree(), "expected the cluster not to be mesh-free", t)
+	assert.DeepEquals(report.Remaining, []string{"Namespace app", "Pod app/injected"}, "unexpected remaining objects", t)
+
+	out := &bytes.Buffer{}
+	assert.Success(report.Write(out), "Write", t)
+	assert.True(strings.Contains(out.String(), "The cluster is not mesh-free, 2 object(s) remain:\n  Namespace app\n  Pod app/injected\n"),
+		"unexpected report: "+out.String(), t)
+}
+
+func teardownObjects() []runtime.Object {
+	cniLabels := map[string]string{
+		common.KubernetesAppInstanceKey:  cniAppInstance,
+		common.KubernetesAppManagedByKey: common.KubernetesAppManagedByValue,
+	}
+	return []runtime.Object{
+		&maistrav2.ServiceMeshControlPlane{ObjectMeta: metav1.ObjectMeta{Namespace: "istio-system", Name: "basic"}},
+		&maistrav1.ServiceMeshMemberRoll{ObjectMeta: metav1.ObjectMeta{Namespace: "istio-system", Name: common.MemberRollName}},
+		&maistrav1.ServiceMeshMember{ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: common.MemberName}},
+		&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Namespace: "istio-operator", Name: "istio-cni-node", Labels: cniLabels}},
+		&admissionregistrationv1.MutatingWebhookConfiguration{ObjectMeta: metav1.ObjectMeta{
+			Name:   "istio-sidecar-injector-basic-istio-system",
+			Labels: map[string]string{common.OwnerKey: "istio-system"},
+		}},
+		&admissionregistrationv1.ValidatingWebhookConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "operator-webhook"}},
+		&apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "virtualservices.networking.istio.io", Labels: map[string]string{"maistra-version": "2.4.3"}},
+			Spec:       apiextensionsv1.CustomResourceDefinitionSpec{Group: "networking.istio.io"},
+		},
+		&apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "servicemeshcontrolplanes.maistra.io", Labels: map[string]string{"maistra-version": "2.4.3"}},
+			Spec:       apiextensionsv1.CustomResourceDefinitionSpec{Group: "maistra.io"},
+		},
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/platform"
 )
 
 type Config struct {
@@ -19,8 +20,22 @@ type Config struct {
 	// UseMultus specifies whether the Istio CNI plugin should be called via Multus CNI
 	UseMultus bool
 
+	// Chained specifies whether the Istio CNI plugin is added to the plugin
+	// chain of the cluster's network plugin.  Otherwise, it's a standalone
+	// plugin called via Multus.
+	Chained bool
+
 	// ImagePullSecrets is the list of image pull secret names for the Istio CNI DaemonSet
 	ImagePullSecrets []string
+	// BinDir is the directory on the nodes into which the Istio CNI plugin is
+	// installed, if it differs from the chart default
+	BinDir string
+	// ConfDir is the directory on the nodes that contains the CNI network
+	// configuration, if it differs from the chart default
+	ConfDir string
+	// ExcludeNamespaces are the namespaces whose pods are ignored by the Istio
+	// CNI plugin, in addition to the operator namespace
+	ExcludeNamespaces []string
 }
 
 // InitConfig initializes the CNI support variable
@@ -59,5 +74,39 @@ func InitConfig(m manager.Manager) (Config, error) {
 		config.UseMultus = false
 	}
 
+	if !config.UseMultus {
+		dcProvider, ok := m.(common.DiscoveryClientProvider)
+		if !ok {
+			return config, fmt.Errorf("expected manager to be a DiscoveryClientProvider")
+		}
+		dc, err := dcProvider.GetDiscoveryClient()
+		if err != nil {
+			return config, err
+		}
+		p, err := platform.Detect(dc)
+		if err != nil {
+			return config, err
+		}
+		config.BinDir = p.CNIBinDir()
+		log.Info("Detected platform for Istio CNI", "platform", p, "binDir", config.BinDir)
+	}
+
+	// the plugin is called via Multus on OpenShift and has to be chained to
+	// the network plugin elsewhere
+	config.Chained = !config.UseMultus
+	if chained := common.Config.CNI.Chained; chained != nil && *chained != config.Chained {
+		if config.UseMultus {
+			return config, fmt.Errorf("configuration cni.chained cannot be enabled on clusters with Multus")
+		}
+		return config, fmt.Errorf("configuration cni.chained cannot be disabled on clusters without Multus")
+	}
+	if common.Config.CNI.BinDir != "" {
+		config.BinDir = common.Config.CNI.BinDir
+	}
+	config.ConfDir = common.Config.CNI.ConfDir
+	config.ExcludeNamespaces = common.Config.CNI.ExcludeNamespaces
+	log.Info("Istio CNI configuration", "chained", config.Chained, "binDir", config.BinDir, "confDir", config.ConfDir,
+		"excludeNamespaces", config.ExcludeNamespaces)
+
 	return config, nil
 }
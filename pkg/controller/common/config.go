@@ -2,10 +2,15 @@ package common
 
 import (
 	"fmt"
+	"path"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
 )
 
 // Config is the config used to drive the operator
@@ -13,7 +18,34 @@ var Config = &config{}
 
 func init() {
 	Config.Controller.WebhookManagementEnabled = true
+	Config.Controller.CRDManagementEnabled = true
+	Config.Controller.CRDCheckInterval = time.Hour
 	Config.OLM.CNIEnabled = true
+	Config.Controller.RemoteSecretExpiryThreshold = 7 * 24 * time.Hour
+	Config.Controller.RemoteSecretCheckInterval = time.Hour
+	Config.Controller.ExternalControlPlaneCheckInterval = time.Minute
+	Config.Controller.CertificateSignerCheckInterval = time.Minute
+	Config.Controller.CARotationCheckInterval = time.Minute
+	Config.Controller.GatewayClassParametersCheckInterval = time.Minute
+	Config.Controller.MeshNamespacesStatusLimit = 100
+	Config.Controller.InjectionWebhookCheckInterval = 10 * time.Second
+	Config.Controller.DeletionBlockedTimeout = 10 * time.Minute
+	Config.Controller.DeletionTimeout = time.Hour
+	Config.Controller.WorkloadUpdateMaxConcurrent = 1
+	Config.Controller.WorkloadUpdateCheckInterval = 30 * time.Second
+	Config.Controller.TrafficVerificationTimeout = 5 * time.Minute
+	Config.Controller.TrafficVerificationCheckInterval = 10 * time.Second
+	Config.Controller.RetryBudget = 5
+	Config.Controller.RetryBudgetWindow = 10 * time.Minute
+	Config.Controller.RetryBackoff = 5 * time.Minute
+	Config.VersionApproval.FailurePolicy = VersionApprovalFailurePolicyFail
+	Config.VersionApproval.CacheTTL = 5 * time.Minute
+	Config.VersionApproval.Timeout = 5 * time.Second
+	Config.Notification.ContentType = "application/json"
+	Config.Notification.Timeout = 5 * time.Second
+	Config.LeaderElection.LeaseDuration = 15 * time.Second
+	Config.LeaderElection.RenewDeadline = 10 * time.Second
+	Config.LeaderElection.RetryPeriod = 2 * time.Second
 }
 
 // config for the operator
@@ -22,6 +54,14 @@ type config struct {
 	OAuthProxy oauthProxy       `json:"oauth-proxy,omitempty"`
 	Rendering  renderingOptions `json:"rendering,omitempty"`
 	Controller controller       `json:"controller,omitempty"`
+
+	VersionApproval versionApproval `json:"versionApproval,omitempty"`
+	LeaderElection  leaderElection  `json:"leaderElection,omitempty"`
+	Notification    notification    `json:"notification,omitempty"`
+
+	ControlPlaneDefaults controlPlaneDefaults `json:"controlPlaneDefaults,omitempty"`
+	Profiling            profiling            `json:"profiling,omitempty"`
+	CNI                  cniConfig            `json:"cni,omitempty"`
 }
 
 // OLM is intermediate struct for serialization
@@ -155,6 +195,315 @@ type controller struct {
 	// If set to false, the controller does not create and manage webhookconfigurations by itself.
 	// Defaults to 'true'
 	WebhookManagementEnabled bool `json:"webhookManagementEnabled,omitempty"`
+
+	// If set to false, the controller does not install and update the Istio
+	// CRDs, e.g. because they are managed externally.  Defaults to 'true'
+	CRDManagementEnabled bool `json:"crdManagementEnabled,omitempty"`
+
+	// How often the Istio CRDs of the newest supported control plane version
+	// are installed or updated.  Zero only installs them when the operator
+	// starts and when a control plane is reconciled.
+	CRDCheckInterval time.Duration `json:"crdCheckInterval,omitempty"`
+
+	// Remote cluster secrets whose credentials expire within this duration are
+	// reported in the RemoteSecretExpiring condition of the control plane
+	RemoteSecretExpiryThreshold time.Duration `json:"remoteSecretExpiryThreshold,omitempty"`
+
+	// How often the remote cluster secrets of a control plane are checked
+	RemoteSecretCheckInterval time.Duration `json:"remoteSecretCheckInterval,omitempty"`
+
+	// If set to true, expiring service account tokens in remote cluster
+	// secrets are replaced with new tokens obtained through the TokenRequest API.
+	// The new token is requested with the expiring one, so the service account
+	// must be allowed to create serviceaccounts/token for itself in the remote
+	// cluster.  Tokens that have already expired cannot be rotated.
+	RemoteSecretAutoRotation bool `json:"remoteSecretAutoRotation,omitempty"`
+
+	// How often the readiness of the external istiod or istio-csr used by a
+	// control plane is checked
+	ExternalControlPlaneCheckInterval time.Duration `json:"externalControlPlaneCheckInterval,omitempty"`
+
+	// How often the availability of the signer of the Kubernetes CSR API
+	// used by a control plane is checked
+	CertificateSignerCheckInterval time.Duration `json:"certificateSignerCheckInterval,omitempty"`
+
+	// The interval in which the progress of intermediate CA rotations is
+	// checked, e.g. whether the new root was distributed to the mesh
+	// namespaces
+	CARotationCheckInterval time.Duration `json:"caRotationCheckInterval,omitempty"`
+
+	// How often the gateways deployed by istiod for Gateway API resources
+	// are checked, so that the gateway class parameters of the control plane
+	// are applied to new gateways
+	GatewayClassParametersCheckInterval time.Duration `json:"gatewayClassParametersCheckInterval,omitempty"`
+
+	// The maximum number of namespaces listed in status.meshNamespaces of a
+	// control plane.  Larger meshes are only reported by their total number
+	// of namespaces beyond this limit.  Zero disables the list.
+	MeshNamespacesStatusLimit int `json:"meshNamespacesStatusLimit,omitempty"`
+
+	// How often the sidecar injection webhook of a control plane is checked
+	// while it isn't serving, e.g. until the endpoints of istiod are ready
+	InjectionWebhookCheckInterval time.Duration `json:"injectionWebhookCheckInterval,omitempty"`
+
+	// Whether the readiness of a control plane includes a request to the
+	// debug endpoint of istiod through its Service, which fails if istiod
+	// isn't serving XDS even though its pods are ready.  The operator must be
+	// allowed to reach istiod on port 15014.
+	IstiodXDSReadinessCheck bool `json:"istiodXDSReadinessCheck,omitempty"`
+
+	// How long a ready control plane must be observed not to be ready before
+	// its Ready condition is changed, so that components that are briefly
+	// unavailable, e.g. istiod during a rollout, don't cause the status to
+	// churn.  Regaining readiness is reported right away.  Zero disables the
+	// delay.
+	ReadinessDebounce time.Duration `json:"readinessDebounce,omitempty"`
+
+	// How long the deletion of a control plane is blocked while workloads
+	// in its member namespaces still use it.  Once the timeout expires, the
+	// control plane is deleted regardless.  Zero disables blocking.
+	DeletionBlockedTimeout time.Duration `json:"deletionBlockedTimeout,omitempty"`
+
+	// How long after the deletion of a control plane was requested the
+	// operator keeps trying to delete its resources.  Once the timeout
+	// expires, resources that can't be deleted are left behind and the
+	// finalizer is removed, so the control plane doesn't remain terminating
+	// forever.  Zero retries until the resources are deleted.
+	DeletionTimeout time.Duration `json:"deletionTimeout,omitempty"`
+
+	// The maximum number of Deployments of a control plane that are
+	// restarted at the same time to update their sidecars, see
+	// spec.proxy.updateWorkloads.  The next Deployment is only restarted once
+	// the rollout of a previous one completed.
+	WorkloadUpdateMaxConcurrent int `json:"workloadUpdateMaxConcurrent,omitempty"`
+
+	// How often the progress of the restart of the Deployments of a control
+	// plane is checked while sidecars are being updated
+	WorkloadUpdateCheckInterval time.Duration `json:"workloadUpdateCheckInterval,omitempty"`
+
+	// The image of the canary workload that receives the request sent through
+	// the ingress gateway when a control plane verifies its traffic, see
+	// spec.verify.  It must serve HTTP on port 8080 and respond to
+	// /status/200.  If empty, the proxy image of the control plane is used.
+	TrafficVerificationImage string `json:"trafficVerificationImage,omitempty"`
+
+	// How long the verification of the traffic of a control plane may take,
+	// including the startup of the canary workload, before it's reported as
+	// failed
+	TrafficVerificationTimeout time.Duration `json:"trafficVerificationTimeout,omitempty"`
+
+	// How often the canary workload of a control plane is checked while its
+	// traffic is being verified
+	TrafficVerificationCheckInterval time.Duration `json:"trafficVerificationCheckInterval,omitempty"`
+
+	// The number of failed reconciliations of a control plane within
+	// RetryBudgetWindow, after which its reconciliation is suspended for
+	// RetryBackoff, so that it doesn't monopolize the reconcilers.  Zero
+	// disables the budget.
+	RetryBudget int `json:"retryBudget,omitempty"`
+
+	// The duration in which the failed reconciliations are counted
+	RetryBudgetWindow time.Duration `json:"retryBudgetWindow,omitempty"`
+
+	// How long the reconciliation of a control plane that exhausted its retry
+	// budget is suspended, unless its spec changes
+	RetryBackoff time.Duration `json:"retryBackoff,omitempty"`
+
+	// Enables or disables features that are still maturing, as a
+	// comma-separated list of Feature=true|false pairs.  Features that aren't
+	// listed keep their defaults, see pkg/featuregates.
+	FeatureGates string `json:"featureGates,omitempty"`
+}
+
+// VersionApprovalFailurePolicy specifies how version changes are handled when
+// the version approval endpoint cannot be reached
+type VersionApprovalFailurePolicy string
+
+const (
+	// VersionApprovalFailurePolicyFail rejects the version change
+	VersionApprovalFailurePolicyFail VersionApprovalFailurePolicy = "Fail"
+	// VersionApprovalFailurePolicyIgnore allows the version change
+	VersionApprovalFailurePolicyIgnore VersionApprovalFailurePolicy = "Ignore"
+)
+
+// Version approval settings.  If URL is set, the operator consults the
+// endpoint before a control plane is created with, or changed to, a version.
+type versionApproval struct {
+	// URL of the endpoint that approves control plane versions
+	URL string `json:"url,omitempty"`
+
+	// How version changes are handled when the endpoint cannot be reached.
+	// Defaults to 'Fail'
+	FailurePolicy VersionApprovalFailurePolicy `json:"failurePolicy,omitempty"`
+
+	// How long the decisions of the endpoint are cached
+	CacheTTL time.Duration `json:"cacheTTL,omitempty"`
+
+	// Timeout for requests to the endpoint.  The timeout must be lower than
+	// that of the validating webhook, so it is capped at 10s
+	Timeout time.Duration `json:"timeout,omitempty"`
+}
+
+// Notification settings.  If URL is set, the operator posts a notification to
+// the endpoint whenever a control plane becomes ready or not ready, and when an
+// installation or update succeeds or fails.
+type notification struct {
+	// URL of the endpoint that receives the notifications, e.g. a Slack
+	// incoming webhook
+	URL string `json:"url,omitempty"`
+
+	// Go template used to render the body of the notifications.  Defaults to a
+	// JSON object containing the namespace, name, version, type, reason and
+	// message of the notification
+	Template string `json:"template,omitempty"`
+
+	// Content type of the rendered notifications, which must be changed if the
+	// template doesn't render JSON.  Defaults to 'application/json'
+	ContentType string `json:"contentType,omitempty"`
+
+	// Timeout for requests to the endpoint
+	Timeout time.Duration `json:"timeout,omitempty"`
+}
+
+// Leader election settings.  Only the leader reconciles resources; the other
+// replicas of the operator serve webhooks and wait to take over.
+type leaderElection struct {
+	// If set to true, the leader holds a lease that it must renew periodically
+	// and a standby replica takes over when the leader stops renewing it.
+	// Otherwise, the leader holds the lock until its pod is deleted.  Running
+	// multiple replicas of the operator requires the lease.
+	// Defaults to 'false'
+	LeaseEnabled bool `json:"leaseEnabled,omitempty"`
+
+	// How long standby replicas wait before taking over a lease that hasn't
+	// been renewed
+	LeaseDuration time.Duration `json:"leaseDuration,omitempty"`
+
+	// How long the leader tries to renew the lease before giving it up.  Must
+	// be less than LeaseDuration
+	RenewDeadline time.Duration `json:"renewDeadline,omitempty"`
+
+	// How long replicas wait between attempts to acquire or renew the lease
+	RetryPeriod time.Duration `json:"retryPeriod,omitempty"`
+}
+
+// Validate checks that the leader election settings are consistent
+func (le *leaderElection) Validate() error {
+	if !le.LeaseEnabled {
+		return nil
+	}
+	if le.LeaseDuration <= le.RenewDeadline {
+		return fmt.Errorf("leader election lease duration (%s) must be greater than the renew deadline (%s)",
+			le.LeaseDuration, le.RenewDeadline)
+	}
+	if le.RetryPeriod <= 0 || le.RenewDeadline <= le.RetryPeriod {
+		return fmt.Errorf("leader election renew deadline (%s) must be greater than the retry period (%s)",
+			le.RenewDeadline, le.RetryPeriod)
+	}
+	return nil
+}
+
+// Istio CNI settings.  The Istio CNI DaemonSet is shared by all control
+// planes, so it's configured for the operator instead of in the control
+// planes.  Unset fields default to the settings for the platform: a
+// standalone plugin invoked by Multus on OpenShift, and a plugin chained to
+// the network plugin of the cluster elsewhere.
+type cniConfig struct {
+	// Whether the Istio CNI plugin is added to the plugin chain of the
+	// cluster's network plugin instead of being invoked by Multus.  It cannot
+	// be enabled on clusters with Multus.
+	Chained *bool `json:"chained,omitempty"`
+
+	// The directory on the nodes into which the Istio CNI plugin is installed,
+	// in which the container runtime looks for CNI plugins
+	BinDir string `json:"binDir,omitempty"`
+
+	// The directory on the nodes that contains the CNI network configuration.
+	// It must be below /etc/cni, which is mounted into the Istio CNI pods.
+	ConfDir string `json:"confDir,omitempty"`
+
+	// Namespaces whose pods are ignored by the Istio CNI plugin, in addition
+	// to the operator namespace
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"`
+}
+
+// Validate checks that the directories are absolute paths on the nodes that
+// can be mounted into the Istio CNI pods and that the excluded namespaces are
+// valid namespace names
+func (c *cniConfig) Validate() error {
+	if c.BinDir != "" && !path.IsAbs(c.BinDir) {
+		return fmt.Errorf("CNI bin dir must be an absolute path: %s", c.BinDir)
+	}
+	if c.ConfDir != "" && !strings.HasPrefix(path.Clean(c.ConfDir)+"/", "/etc/cni/") {
+		return fmt.Errorf("CNI conf dir must be below /etc/cni: %s", c.ConfDir)
+	}
+	for _, namespace := range c.ExcludeNamespaces {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return fmt.Errorf("invalid namespace excluded from CNI %q: %s", namespace, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+// Profiling settings, to investigate the resource usage of the operator in
+// large clusters without building a custom image
+type profiling struct {
+	// If set to true, the pprof endpoints are served below /debug/pprof/ on
+	// the metrics port.  Profiles expose internals of the operator, so the
+	// metrics port shouldn't be reachable by untrusted clients.
+	// Defaults to 'false'
+	PprofEnabled bool `json:"pprofEnabled,omitempty"`
+
+	// How often the memory usage and number of goroutines of the operator are
+	// logged.  Zero disables the log.
+	RuntimeStatsInterval time.Duration `json:"runtimeStatsInterval,omitempty"`
+}
+
+// Defaults applied to all control planes, so cluster admins can enforce
+// sane settings for control planes created by tenants.  The defaults are
+// merged beneath the values of each control plane, i.e. they take precedence
+// over the profiles, but any value set in the ServiceMeshControlPlane wins.
+type controlPlaneDefaults struct {
+	// Default resource requests and limits of istiod, as a YAML or JSON
+	// ResourceRequirements object
+	PilotResources string `json:"pilotResources,omitempty"`
+
+	// Default priority class of the control plane components
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// Default topology spread constraints of the istiod pods, as a YAML or
+	// JSON list of TopologySpreadConstraint objects.  They're only applied
+	// if the rendered istiod Deployment doesn't specify any.
+	TopologySpreadConstraints string `json:"topologySpreadConstraints,omitempty"`
+
+	// parsed values, set by Validate
+	PilotResourcesValues            map[string]interface{} `json:"-"`
+	TopologySpreadConstraintsValues []interface{}          `json:"-"`
+}
+
+// Validate parses the defaults, which are specified as YAML or JSON
+func (d *controlPlaneDefaults) Validate() error {
+	d.PilotResourcesValues = nil
+	if d.PilotResources != "" {
+		resources := &corev1.ResourceRequirements{}
+		if err := yaml.UnmarshalStrict([]byte(d.PilotResources), resources); err != nil {
+			return fmt.Errorf("invalid default pilot resources: %v", err)
+		}
+		if err := yaml.Unmarshal([]byte(d.PilotResources), &d.PilotResourcesValues); err != nil {
+			return fmt.Errorf("invalid default pilot resources: %v", err)
+		}
+	}
+	d.TopologySpreadConstraintsValues = nil
+	if d.TopologySpreadConstraints != "" {
+		var constraints []corev1.TopologySpreadConstraint
+		if err := yaml.UnmarshalStrict([]byte(d.TopologySpreadConstraints), &constraints); err != nil {
+			return fmt.Errorf("invalid default topology spread constraints: %v", err)
+		}
+		if err := yaml.Unmarshal([]byte(d.TopologySpreadConstraints), &d.TopologySpreadConstraintsValues); err != nil {
+			return fmt.Errorf("invalid default topology spread constraints: %v", err)
+		}
+	}
+	return nil
 }
 
 // NewViper returns a new viper.Viper configured with all the common.Config keys
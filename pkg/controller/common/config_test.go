@@ -0,0 +1,150 @@
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLeaderElectionValidate(t *testing.T) {
+	testCases := []struct {
+		name           string
+		leaderElection leaderElection
+		expectError    bool
+	}{
+		{
+			name:           "lease-disabled",
+			leaderElection: leaderElection{LeaseDuration: time.Second, RenewDeadline: time.Minute},
+		},
+		{
+			name: "valid",
+			leaderElection: leaderElection{
+				LeaseEnabled:  true,
+				LeaseDuration: 15 * time.Second,
+				RenewDeadline: 10 * time.Second,
+				RetryPeriod:   2 * time.Second,
+			},
+		},
+		{
+			name: "renew-deadline-exceeds-lease-duration",
+			leaderElection: leaderElection{
+				LeaseEnabled:  true,
+				LeaseDuration: 10 * time.Second,
+				RenewDeadline: 15 * time.Second,
+				RetryPeriod:   2 * time.Second,
+			},
+			expectError: true,
+		},
+		{
+			name: "retry-period-exceeds-renew-deadline",
+			leaderElection: leaderElection{
+				LeaseEnabled:  true,
+				LeaseDuration: 15 * time.Second,
+				RenewDeadline: 10 * time.Second,
+				RetryPeriod:   10 * time.Second,
+			},
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.leaderElection.Validate()
+			if tc.expectError && err == nil {
+				t.Errorf("expected an error")
+			} else if !tc.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestControlPlaneDefaultsValidate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		defaults    controlPlaneDefaults
+		expectError bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "valid",
+			defaults: controlPlaneDefaults{
+				PilotResources:            `{"requests": {"cpu": "100m", "memory": "512Mi"}}`,
+				PriorityClassName:         "system-cluster-critical",
+				TopologySpreadConstraints: "- maxSkew: 1\n  topologyKey: topology.kubernetes.io/zone\n  whenUnsatisfiable: ScheduleAnyway\n",
+			},
+		},
+		{
+			name:        "unknown-resources-field",
+			defaults:    controlPlaneDefaults{PilotResources: `{"request": {"cpu": "100m"}}`},
+			expectError: true,
+		},
+		{
+			name:        "invalid-topology-spread-constraints",
+			defaults:    controlPlaneDefaults{TopologySpreadConstraints: "maxSkew: 1"},
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.defaults.Validate()
+			if tc.expectError && err == nil {
+				t.Errorf("expected an error")
+			} else if !tc.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestCNIConfigValidate(t *testing.T) {
+	chained := true
+	testCases := []struct {
+		name        string
+		cni         cniConfig
+		expectError bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "valid",
+			cni: cniConfig{
+				Chained:           &chained,
+				BinDir:            "/home/kubernetes/bin",
+				ConfDir:           "/etc/cni/net.d",
+				ExcludeNamespaces: []string{"kube-system", "monitoring"},
+			},
+		},
+		{
+			name:        "relative-bin-dir",
+			cni:         cniConfig{BinDir: "opt/cni/bin"},
+			expectError: true,
+		},
+		{
+			name:        "relative-conf-dir",
+			cni:         cniConfig{ConfDir: "net.d"},
+			expectError: true,
+		},
+		{
+			name:        "conf-dir-outside-etc-cni",
+			cni:         cniConfig{ConfDir: "/var/lib/rancher/k3s/agent/etc/cni/net.d"},
+			expectError: true,
+		},
+		{
+			name:        "invalid-namespace",
+			cni:         cniConfig{ExcludeNamespaces: []string{"Kube_System"}},
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cni.Validate()
+			if tc.expectError && err == nil {
+				t.Errorf("expected an error")
+			} else if !tc.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -5,3 +5,12 @@ import "k8s.io/client-go/discovery"
 type DiscoveryClientProvider interface {
 	GetDiscoveryClient() (discovery.DiscoveryInterface, error)
 }
+
+// InvalidateDiscoveryCache invalidates the API groups and resources cached by
+// dc, so they are fetched from the API server the next time they are used.  It
+// does nothing if dc doesn't cache them.
+func InvalidateDiscoveryCache(dc discovery.DiscoveryInterface) {
+	if cachedDC, ok := dc.(discovery.CachedDiscoveryInterface); ok {
+		cachedDC.Invalidate()
+	}
+}
@@ -0,0 +1,107 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrorCode is a stable identifier of a common failure, which documentation
+// and support tooling can refer to
+type ErrorCode string
+
+const (
+	// ErrorCodeImagePullUnauthorized indicates that an image could not be
+	// pulled, because the registry rejected the credentials
+	ErrorCodeImagePullUnauthorized ErrorCode = "ImagePullUnauthorized"
+	// ErrorCodePodSecurityDenied indicates that pods were rejected by Pod
+	// Security Admission
+	ErrorCodePodSecurityDenied ErrorCode = "PodSecurityDenied"
+	// ErrorCodeSCCMissing indicates that pods were rejected, because their
+	// service account may not use a SecurityContextConstraints that allows them
+	ErrorCodeSCCMissing ErrorCode = "SCCMissing"
+	// ErrorCodeCNIPluginNotFound indicates that the container runtime could
+	// not find the Istio CNI plugin
+	ErrorCodeCNIPluginNotFound ErrorCode = "CNIPluginNotFound"
+	// ErrorCodeVersionNotBundled indicates that the control plane uses a
+	// version that this operator doesn't include
+	ErrorCodeVersionNotBundled ErrorCode = "VersionNotBundled"
+)
+
+// knownError describes how a common failure is recognized in an error message
+// and how it can be fixed
+type knownError struct {
+	code ErrorCode
+	// patterns lists alternative sets of substrings.  The failure is found in
+	// a message that contains all substrings of any of the sets, ignoring case.
+	patterns [][]string
+	hint     string
+}
+
+var errorCatalog = []knownError{
+	{
+		code: ErrorCodeImagePullUnauthorized,
+		patterns: [][]string{
+			{"pull access denied"},
+			{"pull", "unauthorized"},
+			{"pull", "authentication required"},
+		},
+		hint: "check that the image pull secrets of the service account contain valid credentials for the registry",
+	},
+	{
+		code:     ErrorCodePodSecurityDenied,
+		patterns: [][]string{{"violates podsecurity"}},
+		hint:     "label the namespace with a pod-security.kubernetes.io/enforce level that allows the pods, e.g. privileged",
+	},
+	{
+		code:     ErrorCodeSCCMissing,
+		patterns: [][]string{{"unable to validate against any security context constraint"}},
+		hint:     "grant the service account of the pods the use of a SecurityContextConstraints that allows them, e.g. anyuid",
+	},
+	{
+		code:     ErrorCodeCNIPluginNotFound,
+		patterns: [][]string{{"failed to find plugin", "istio-cni"}},
+		hint:     "check that the Istio CNI DaemonSet is running and installs the plugin into the CNI bin directory of the nodes",
+	},
+	{
+		code: ErrorCodeVersionNotBundled,
+		patterns: [][]string{
+			{"invalid version"},
+			{"support for", "has been dropped"},
+			{"versions are supported"},
+		},
+		hint: "set spec.version to a version supported by this operator, or upgrade the operator",
+	},
+}
+
+// LookupError returns the code and remediation hint of the first known failure
+// found in message.  The returned bool is false if no known failure is found.
+func LookupError(message string) (ErrorCode, string, bool) {
+	message = strings.ToLower(message)
+	for _, knownErr := range errorCatalog {
+		for _, pattern := range knownErr.patterns {
+			if containsAll(message, pattern) {
+				return knownErr.code, knownErr.hint, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// WithRemediationHint appends the code and remediation hint of the known
+// failure found in message to it.  It returns message unchanged if no known
+// failure is found.
+func WithRemediationHint(message string) string {
+	if code, hint, ok := LookupError(message); ok {
+		return fmt.Sprintf("%s [%s: %s]", message, code, hint)
+	}
+	return message
+}
+
+func containsAll(s string, substrings []string) bool {
+	for _, substring := range substrings {
+		if !strings.Contains(s, substring) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,76 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+)
+
+func TestLookupError(t *testing.T) {
+	testCases := []struct {
+		name         string
+		message      string
+		expectedCode ErrorCode
+	}{
+		{
+			name: "image-pull-unauthorized",
+			message: `rpc error: code = Unknown desc = failed to pull and unpack image "registry.example.com/maistra/pilot:2.4": ` +
+				`failed to resolve reference: unexpected status code 401 Unauthorized`,
+			expectedCode: ErrorCodeImagePullUnauthorized,
+		},
+		{
+			name:         "image-pull-access-denied",
+			message:      "pull access denied for maistra/pilot, repository does not exist or may require 'docker login'",
+			expectedCode: ErrorCodeImagePullUnauthorized,
+		},
+		{
+			name: "pod-security",
+			message: `pods "istiod-basic-5b4c9b8d7c-abcde" is forbidden: violates PodSecurity "restricted:latest": ` +
+				`allowPrivilegeEscalation != false`,
+			expectedCode: ErrorCodePodSecurityDenied,
+		},
+		{
+			name: "scc",
+			message: `pods "istio-ingressgateway-7d9f-" is forbidden: unable to validate against any security context constraint: ` +
+				`[provider "anyuid": Forbidden: not usable by user or serviceaccount]`,
+			expectedCode: ErrorCodeSCCMissing,
+		},
+		{
+			name:         "cni-plugin",
+			message:      `failed to find plugin "istio-cni" in path [/opt/cni/bin]`,
+			expectedCode: ErrorCodeCNIPluginNotFound,
+		},
+		{
+			name:         "version-invalid",
+			message:      "Error processing component mesh-config: error: invalid version: v3.0",
+			expectedCode: ErrorCodeVersionNotBundled,
+		},
+		{
+			name:         "version-dropped",
+			message:      "support for v1.0 has been dropped",
+			expectedCode: ErrorCodeVersionNotBundled,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			code, hint, ok := LookupError(tc.message)
+			assert.True(ok, "Expected known error to be found", t)
+			assert.Equals(code, tc.expectedCode, "Unexpected error code", t)
+			assert.True(hint != "", "Expected remediation hint", t)
+		})
+	}
+}
+
+func TestLookupErrorUnknown(t *testing.T) {
+	_, _, ok := LookupError("connection refused")
+	assert.False(ok, "Expected no known error to be found", t)
+	assert.Equals(WithRemediationHint("connection refused"), "connection refused", "Expected message to be unchanged", t)
+}
+
+func TestWithRemediationHint(t *testing.T) {
+	message := "support for v1.0 has been dropped"
+	assert.Equals(WithRemediationHint(message),
+		"support for v1.0 has been dropped [VersionNotBundled: set spec.version to a version supported by this operator, or upgrade the operator]",
+		"Unexpected message", t)
+}
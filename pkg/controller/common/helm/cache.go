@@ -0,0 +1,88 @@
+package helm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/helm/pkg/manifest"
+)
+
+// maxCachedRenderings limits the number of renderings kept in the cache.  Each
+// control plane renders a handful of charts, so this comfortably covers
+// several control planes.
+const maxCachedRenderings = 100
+
+// renderCache stores the result of rendering a chart, keyed by the chart path,
+// namespace, kube version and rendered values, so reconciling an unchanged
+// control plane doesn't require the charts to be templated again.
+type renderCache struct {
+	mu      sync.Mutex
+	entries map[string]*renderCacheEntry
+	// keys holds the keys in the order they were added, so the oldest entry
+	// can be evicted once the cache is full
+	keys []string
+}
+
+type renderCacheEntry struct {
+	manifests map[string][]manifest.Manifest
+	release   map[string]interface{}
+}
+
+var defaultRenderCache = newRenderCache()
+
+func newRenderCache() *renderCache {
+	return &renderCache{
+		entries: map[string]*renderCacheEntry{},
+	}
+}
+
+func renderCacheKey(chartPath, namespace, kubeVersion string, rawVals []byte) string {
+	hash := sha256.New()
+	for _, field := range []string{chartPath, namespace, kubeVersion} {
+		hash.Write([]byte(field))
+		hash.Write([]byte{0})
+	}
+	hash.Write(rawVals)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// get returns a copy of the cached rendering, so callers are free to modify
+// the result
+func (c *renderCache) get(key string) (map[string][]manifest.Manifest, map[string]interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, nil, false
+	}
+	return copyManifests(entry.manifests), runtime.DeepCopyJSON(entry.release), true
+}
+
+func (c *renderCache) add(key string, manifests map[string][]manifest.Manifest, release map[string]interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; ok {
+		return
+	}
+	if len(c.keys) >= maxCachedRenderings {
+		delete(c.entries, c.keys[0])
+		c.keys = c.keys[1:]
+	}
+	c.entries[key] = &renderCacheEntry{
+		manifests: copyManifests(manifests),
+		release:   runtime.DeepCopyJSON(release),
+	}
+	c.keys = append(c.keys, key)
+}
+
+// copyManifests copies the manifest slices, so they can be sorted or modified
+// independently.  The parsed headers are only ever read and are shared.
+func copyManifests(manifests map[string][]manifest.Manifest) map[string][]manifest.Manifest {
+	manifestsCopy := make(map[string][]manifest.Manifest, len(manifests))
+	for chartName, chartManifests := range manifests {
+		manifestsCopy[chartName] = append([]manifest.Manifest(nil), chartManifests...)
+	}
+	return manifestsCopy
+}
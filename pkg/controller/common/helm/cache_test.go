@@ -0,0 +1,61 @@
+package helm
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/helm/pkg/manifest"
+
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+)
+
+func TestRenderChartIsCached(t *testing.T) {
+	chartPath, err := ioutil.TempDir("", "cached-chart")
+	assert.Success(err, "TempDir", t)
+	defer os.RemoveAll(chartPath)
+	assert.Success(os.Mkdir(path.Join(chartPath, "templates"), 0755), "Mkdir", t)
+	assert.Success(ioutil.WriteFile(path.Join(chartPath, "Chart.yaml"), []byte("name: cached\nversion: 1.0.0\n"), 0644), "WriteFile", t)
+	writeTemplate := func(content string) {
+		assert.Success(ioutil.WriteFile(path.Join(chartPath, "templates", "configmap.yaml"), []byte(content), 0644), "WriteFile", t)
+	}
+	writeTemplate("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {{ .Values.name }}\n")
+
+	hits := testutil.ToFloat64(renderCacheRequests.WithLabelValues(cacheResultHit))
+	misses := testutil.ToFloat64(renderCacheRequests.WithLabelValues(cacheResultMiss))
+	render := func(name string) []manifest.Manifest {
+		manifests, _, err := RenderChart(chartPath, "istio-system", "v1.20.0", map[string]interface{}{"name": name})
+		assert.Success(err, "RenderChart", t)
+		return manifests["cached"]
+	}
+	first := render("first")
+	assert.Equals(len(first), 1, "unexpected number of manifests", t)
+
+	// the template is not read again when rendering the same values
+	writeTemplate("apiVersion: v1\nkind: Secret\nmetadata:\n  name: {{ .Values.name }}\n")
+	first[0].Content = "modified"
+	cached := render("first")
+	assert.Equals(cached[0].Head.Kind, "ConfigMap", "expected cached rendering", t)
+	assert.True(cached[0].Content != "modified", "expected a copy of the cached rendering", t)
+
+	assert.Equals(render("second")[0].Head.Kind, "Secret", "expected chart to be rendered for new values", t)
+
+	assert.Equals(testutil.ToFloat64(renderCacheRequests.WithLabelValues(cacheResultHit))-hits, 1.0,
+		"unexpected number of cache hits", t)
+	assert.Equals(testutil.ToFloat64(renderCacheRequests.WithLabelValues(cacheResultMiss))-misses, 2.0,
+		"unexpected number of cache misses", t)
+}
+
+func TestRenderCacheEvictsOldestEntry(t *testing.T) {
+	cache := newRenderCache()
+	for i := 0; i <= maxCachedRenderings; i++ {
+		cache.add(fmt.Sprintf("key-%d", i), map[string][]manifest.Manifest{}, map[string]interface{}{})
+	}
+	_, _, ok := cache.get("key-0")
+	assert.False(ok, "expected oldest entry to be evicted", t)
+	_, _, ok = cache.get(fmt.Sprintf("key-%d", maxCachedRenderings))
+	assert.True(ok, "expected newest entry to be cached", t)
+}
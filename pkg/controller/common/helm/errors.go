@@ -0,0 +1,132 @@
+package helm
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+const (
+	// maxRenderErrorMessages limits the number of errors listed by
+	// SummarizeRenderErrors
+	maxRenderErrorMessages = 5
+	// maxRenderErrorMessageLength limits the length of each error listed by
+	// SummarizeRenderErrors
+	maxRenderErrorMessageLength = 300
+)
+
+var (
+	// templateErrorRegexp matches the errors returned by helm when parsing or
+	// executing a template, e.g.
+	//   render error in "istio/charts/pilot/templates/deployment.yaml": template: istio/charts/pilot/templates/deployment.yaml:45:23:
+	//   executing "istio/charts/pilot/templates/deployment.yaml" at <.Values.global.foo.bar>: nil pointer evaluating interface {}.bar
+	templateErrorRegexp = regexp.MustCompile(`^(?:render|parse) error in "[^"]*": template: ([^:]+):(\d+)(?::\d+)?: (?:executing "[^"]*" at <(.*?)>: )?(.*)$`)
+	valuesPathRegexp    = regexp.MustCompile(`\.Values((?:\.[\w-]+)+)`)
+)
+
+// RenderError is returned by RenderChart if a template of a chart fails to
+// render.  It identifies the chart and template that failed and, if the failing
+// action references the values, the values path it references, so users can
+// fix the values without reading the operator logs.
+type RenderError struct {
+	// Chart is the chart containing the template, e.g. istio/charts/pilot
+	Chart string
+	// Template is the path of the template within the chart, e.g.
+	// templates/deployment.yaml, or empty if the error doesn't identify it
+	Template string
+	// Line is the line of the template that failed, or zero if unknown
+	Line int
+	// ValuesPath is the path of the values referenced by the failing action,
+	// e.g. global.proxy.image, or empty if unknown
+	ValuesPath string
+	// Reason describes the failure
+	Reason string
+	// Err is the error returned by helm
+	Err error
+}
+
+func newRenderError(chartName string, err error) *RenderError {
+	renderErr := &RenderError{Chart: chartName, Reason: err.Error(), Err: err}
+	match := templateErrorRegexp.FindStringSubmatch(err.Error())
+	if match == nil {
+		return renderErr
+	}
+	renderErr.Chart, renderErr.Template = splitTemplateName(match[1])
+	renderErr.Line, _ = strconv.Atoi(match[2])
+	if valuesPath := valuesPathRegexp.FindStringSubmatch(match[3]); valuesPath != nil {
+		renderErr.ValuesPath = strings.TrimPrefix(valuesPath[1], ".")
+	}
+	renderErr.Reason = match[4]
+	return renderErr
+}
+
+// splitTemplateName splits the name of a template into the chart containing
+// it and its path within the chart.  Templates of subcharts are named
+// <root-name>/charts/<subchart-name>/templates/...
+func splitTemplateName(name string) (chart, template string) {
+	pathSegments := strings.Split(name, "/")
+	chartSegments := 1
+	for chartSegments+1 < len(pathSegments) && pathSegments[chartSegments] == "charts" {
+		chartSegments += 2
+	}
+	if chartSegments >= len(pathSegments) {
+		return name, ""
+	}
+	return strings.Join(pathSegments[:chartSegments], "/"), strings.Join(pathSegments[chartSegments:], "/")
+}
+
+func (e *RenderError) Error() string {
+	location := []string{"chart " + e.Chart}
+	if e.Template != "" {
+		location = append(location, "template "+e.Template)
+	}
+	if e.Line > 0 {
+		location = append(location, fmt.Sprintf("line %d", e.Line))
+	}
+	if e.ValuesPath != "" {
+		location = append(location, "values path "+e.ValuesPath)
+	}
+	return fmt.Sprintf("%s: %s", strings.Join(location, ", "), e.Reason)
+}
+
+func (e *RenderError) Unwrap() error {
+	return e.Err
+}
+
+// SummarizeRenderErrors returns an error with a concise message for the
+// errors returned when rendering charts, suitable for a status condition.
+// Duplicate errors, e.g. the same template failing for several gateways, are
+// listed once, and long lists and messages are truncated.
+func SummarizeRenderErrors(err error) error {
+	if err == nil {
+		return nil
+	}
+	var errs []error
+	if aggregate, ok := err.(utilerrors.Aggregate); ok {
+		errs = utilerrors.Flatten(aggregate).Errors()
+	} else {
+		errs = []error{err}
+	}
+	messages := make([]string, 0, len(errs))
+	seen := map[string]bool{}
+	for _, err := range errs {
+		message := err.Error()
+		if seen[message] {
+			continue
+		}
+		seen[message] = true
+		if len(message) > maxRenderErrorMessageLength {
+			message = message[:maxRenderErrorMessageLength] + "..."
+		}
+		messages = append(messages, message)
+	}
+	if len(messages) > maxRenderErrorMessages {
+		messages = append(messages[:maxRenderErrorMessages],
+			fmt.Sprintf("and %d more errors", len(messages)-maxRenderErrorMessages))
+	}
+	return errors.New(strings.Join(messages, "; "))
+}
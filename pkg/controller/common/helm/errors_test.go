@@ -0,0 +1,82 @@
+package helm
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+)
+
+func TestRenderErrorIdentifiesTemplateAndValues(t *testing.T) {
+	chartPath, err := ioutil.TempDir("", "broken-chart")
+	assert.Success(err, "TempDir", t)
+	defer os.RemoveAll(chartPath)
+	subchartPath := path.Join(chartPath, "charts", "pilot")
+	assert.Success(os.MkdirAll(path.Join(chartPath, "templates"), 0755), "MkdirAll", t)
+	assert.Success(os.MkdirAll(path.Join(subchartPath, "templates"), 0755), "MkdirAll", t)
+	assert.Success(ioutil.WriteFile(path.Join(chartPath, "Chart.yaml"), []byte("name: istio\nversion: 1.0.0\n"), 0644), "WriteFile", t)
+	assert.Success(ioutil.WriteFile(path.Join(subchartPath, "Chart.yaml"), []byte("name: pilot\nversion: 1.0.0\n"), 0644), "WriteFile", t)
+	assert.Success(ioutil.WriteFile(path.Join(subchartPath, "templates", "deployment.yaml"),
+		[]byte("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: {{ .Values.global.proxy.image }}\n"), 0644), "WriteFile", t)
+
+	_, _, err = RenderChart(chartPath, "istio-system", "v1.20.0", map[string]interface{}{})
+	renderErr, ok := err.(*RenderError)
+	assert.True(ok, fmt.Sprintf("expected a RenderError, got %v", err), t)
+	assert.Equals(renderErr.Chart, "istio/charts/pilot", "unexpected chart", t)
+	assert.Equals(renderErr.Template, "templates/deployment.yaml", "unexpected template", t)
+	assert.Equals(renderErr.Line, 4, "unexpected line", t)
+	assert.Equals(renderErr.ValuesPath, "global.proxy.image", "unexpected values path", t)
+	assert.Equals(renderErr.Error(), "chart istio/charts/pilot, template templates/deployment.yaml, line 4, "+
+		"values path global.proxy.image: nil pointer evaluating interface {}.image", "unexpected message", t)
+}
+
+func TestRenderErrorWithoutTemplate(t *testing.T) {
+	renderErr := newRenderError("istio", fmt.Errorf("unexpected error"))
+	assert.Equals(renderErr.Error(), "chart istio: unexpected error", "unexpected message", t)
+}
+
+func TestSummarizeRenderErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name:     "single",
+			err:      fmt.Errorf("error"),
+			expected: "error",
+		},
+		{
+			name: "duplicates",
+			err: utilerrors.NewAggregate([]error{
+				fmt.Errorf("first"),
+				utilerrors.NewAggregate([]error{fmt.Errorf("second"), fmt.Errorf("first")}),
+			}),
+			expected: "first; second",
+		},
+		{
+			name: "too-many",
+			err: utilerrors.NewAggregate([]error{
+				fmt.Errorf("1"), fmt.Errorf("2"), fmt.Errorf("3"), fmt.Errorf("4"), fmt.Errorf("5"), fmt.Errorf("6"), fmt.Errorf("7"),
+			}),
+			expected: "1; 2; 3; 4; 5; and 2 more errors",
+		},
+		{
+			name:     "too-long",
+			err:      errors.New(strings.Repeat("x", maxRenderErrorMessageLength+1)),
+			expected: strings.Repeat("x", maxRenderErrorMessageLength) + "...",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equals(SummarizeRenderErrors(tc.err).Error(), tc.expected, "unexpected message", t)
+		})
+	}
+}
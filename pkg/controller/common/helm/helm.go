@@ -3,6 +3,7 @@ package helm
 import (
 	"encoding/json"
 	"strings"
+	"time"
 
 	"github.com/ghodss/yaml"
 	"k8s.io/helm/pkg/chartutil"
@@ -25,15 +26,25 @@ func init() {
 // RenderChart renders the helm charts, returning a map of rendered templates.
 // key names represent the chart from which the template was processed.  Subcharts
 // will be keyed as <root-name>/charts/<subchart-name>, e.g. istio/charts/galley.
-// The root chart would be simply, istio.
+// The root chart would be simply, istio.  Renderings are cached, so rendering
+// the same chart with the same values again returns a copy of the previous
+// result, including its release time.
 func RenderChart(chartPath, namespace, kubeVersion string, values interface{}) (map[string][]manifest.Manifest, map[string]interface{}, error) {
 	rawVals, err := yaml.Marshal(values)
 	if err != nil {
 		return map[string][]manifest.Manifest{}, nil, err
 	}
+	cacheKey := renderCacheKey(chartPath, namespace, kubeVersion, rawVals)
+	manifests, rawRel, ok := defaultRenderCache.get(cacheKey)
+	recordRenderCacheLookup(ok)
+	if ok {
+		return manifests, rawRel, nil
+	}
 	config := &chart.Config{Raw: string(rawVals), Values: map[string]*chart.Value{}}
 
+	loadStart := time.Now()
 	c, err := chartutil.Load(chartPath)
+	observePhase(phaseLoad, loadStart)
 	if err != nil {
 		return map[string][]manifest.Manifest{}, nil, err
 	}
@@ -49,9 +60,11 @@ func RenderChart(chartPath, namespace, kubeVersion string, values interface{}) (
 		},
 		KubeVersion: kubeVersion,
 	}
+	renderStart := time.Now()
 	renderedTemplates, err := renderutil.Render(c, config, renderOpts)
+	observePhase(phaseRender, renderStart)
 	if err != nil {
-		return map[string][]manifest.Manifest{}, nil, err
+		return map[string][]manifest.Manifest{}, nil, newRenderError(c.GetMetadata().GetName(), err)
 	}
 
 	rel := &release.Release{
@@ -61,12 +74,16 @@ func RenderChart(chartPath, namespace, kubeVersion string, values interface{}) (
 		Namespace: namespace,
 		Info:      &release.Info{LastDeployed: renderOpts.ReleaseOptions.Time},
 	}
-	rawRel := map[string]interface{}{}
+	rawRel = map[string]interface{}{}
 	data, err := json.Marshal(rel)
 	if err == nil {
 		err = json.Unmarshal(data, &rawRel)
 	}
-	return sortManifestsByChart(manifest.SplitManifests(renderedTemplates)), rawRel, err
+	manifests = sortManifestsByChart(manifest.SplitManifests(renderedTemplates))
+	if err == nil {
+		defaultRenderCache.add(cacheKey, manifests, rawRel)
+	}
+	return manifests, rawRel, err
 }
 
 // sortManifestsByChart returns a map of chart->[]manifest.  names for subcharts
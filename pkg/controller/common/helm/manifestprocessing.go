@@ -1,12 +1,16 @@
 package helm
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/ghodss/yaml"
 	errors2 "github.com/pkg/errors"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -14,6 +18,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/helm/pkg/manifest"
 	"k8s.io/helm/pkg/releaseutil"
 	kubectl "k8s.io/kubectl/pkg/util"
@@ -34,6 +39,28 @@ var openshiftSpecificResourceKinds = []schema.GroupVersionKind{
 	},
 }
 
+var (
+	// CRDEstablishedTimeout is how long the processor waits for a
+	// CustomResourceDefinition to become established before applying
+	// resources of the kind it defines
+	CRDEstablishedTimeout = 30 * time.Second
+	// CRDEstablishedPollInterval is how often the CustomResourceDefinition's
+	// status is checked while waiting for it to become established
+	CRDEstablishedPollInterval = 500 * time.Millisecond
+)
+
+var webhookConfigurationKinds = []string{"MutatingWebhookConfiguration", "ValidatingWebhookConfiguration"}
+
+const (
+	// istioOperatorManagedLabel is set by the Istio operator on the objects
+	// it reconciles
+	istioOperatorManagedLabel = "operator.istio.io/managed"
+
+	// istioctlOwningResourceLabel is set by istioctl and the Istio operator
+	// on the objects they install
+	istioctlOwningResourceLabel = "install.operator.istio.io/owning-resource"
+)
+
 type ManifestProcessor struct {
 	common.ControllerResources
 	PatchFactory             *PatchFactory
@@ -43,6 +70,23 @@ type ManifestProcessor struct {
 
 	appInstance, appVersion string
 	owner                   types.NamespacedName
+
+	// DryRun makes the processor record the changes it would make to the
+	// objects in Changes instead of applying them
+	DryRun  bool
+	Changes ManifestChanges
+
+	// Adopt makes the processor take over existing objects installed by
+	// istioctl or the Istio operator.  Otherwise, it refuses to update them.
+	Adopt bool
+}
+
+// ManifestChanges lists the objects a dry run of the ManifestProcessor would
+// create or update and counts the objects that are already up to date
+type ManifestChanges struct {
+	Created   []status.ResourceKey
+	Updated   []status.ResourceKey
+	Unchanged int
 }
 
 func NewManifestProcessor(controllerResources common.ControllerResources, patchFactory *PatchFactory,
@@ -64,29 +108,49 @@ func NewManifestProcessor(controllerResources common.ControllerResources, patchF
 }
 
 func (p *ManifestProcessor) ProcessManifests(ctx context.Context, manifests []manifest.Manifest, component string) (madeChanges bool, err error) {
+	// dry runs only compute the changes, so they would skew the apply times
+	if !p.DryRun {
+		defer observePhase(phaseApply, time.Now())
+	}
 	log := common.LogFromContext(ctx)
 
 	allErrors := []error{}
+	objects := []manifestObject{}
 	for _, man := range manifests {
 		childCtx := common.NewContextWithLog(ctx, log.WithValues("manifest", man.Name))
-		changes, errs := p.ProcessManifest(childCtx, man, component)
-		madeChanges = madeChanges || changes
+		manifestObjects, errs := decodeManifest(childCtx, man)
+		objects = append(objects, manifestObjects...)
 		allErrors = append(allErrors, errs...)
 	}
+	madeChanges, errs := p.processObjects(ctx, objects, component)
+	allErrors = append(allErrors, errs...)
 	return madeChanges, utilerrors.NewAggregate(allErrors)
 }
 
 func (p *ManifestProcessor) ProcessManifest(ctx context.Context, man manifest.Manifest, component string) (madeChanges bool, allErrors []error) {
+	objects, allErrors := decodeManifest(ctx, man)
+	madeChanges, errs := p.processObjects(ctx, objects, component)
+	return madeChanges, append(allErrors, errs...)
+}
+
+// manifestObject is an object decoded from a rendered manifest, along with the
+// name of the manifest it was rendered from.
+type manifestObject struct {
+	manifest string
+	object   *unstructured.Unstructured
+}
+
+func decodeManifest(ctx context.Context, man manifest.Manifest) (objects []manifestObject, allErrors []error) {
 	log := common.LogFromContext(ctx)
 	if !strings.HasSuffix(man.Name, ".yaml") {
 		log.V(2).Info("Skipping rendering of manifest")
-		return false, nil
+		return nil, nil
 	}
 	log.V(2).Info("Processing resources from manifest")
 
 	// split the manifest into individual objects
-	objects := releaseutil.SplitManifests(man.Content)
-	for _, raw := range objects {
+	rawObjects := releaseutil.SplitManifests(man.Content)
+	for _, raw := range rawObjects {
 		if raw == "---" {
 			continue
 		}
@@ -108,17 +172,113 @@ func (p *ManifestProcessor) ProcessManifest(ctx context.Context, man manifest.Ma
 			allErrors = append(allErrors, errors2.Wrap(err, man.Name))
 			continue
 		}
+		objects = append(objects, manifestObject{manifest: man.Name, object: obj})
+	}
+	return objects, allErrors
+}
+
+// processObjects applies the objects in InstallOrder.  Objects of a kind
+// defined by a CustomResourceDefinition that is part of the same set of
+// objects are only applied once the CRD has become Established.  If the CRD
+// doesn't become Established, the remaining objects of that kind are skipped.
+func (p *ManifestProcessor) processObjects(ctx context.Context, objects []manifestObject, component string) (madeChanges bool, allErrors []error) {
+	log := common.LogFromContext(ctx)
 
-		childCtx := common.NewContextWithLog(ctx, log.WithValues("Resource", status.NewResourceKey(obj, obj)))
+	sortObjectsByKind(objects)
+
+	pendingCRDs := map[schema.GroupKind]string{}
+	failedCRDs := map[schema.GroupKind]string{}
+	for _, mo := range objects {
+		obj := mo.object
+		gk := obj.GroupVersionKind().GroupKind()
+		childCtx := common.NewContextWithLog(ctx, log.WithValues("manifest", mo.manifest, "Resource", status.NewResourceKey(obj, obj)))
+		if crdName, ok := failedCRDs[gk]; ok {
+			common.LogFromContext(childCtx).Info("skipping resource, as its CustomResourceDefinition is not established", "CustomResourceDefinition", crdName)
+			continue
+		}
+		if crdName, ok := pendingCRDs[gk]; ok {
+			delete(pendingCRDs, gk)
+			if err := p.waitForCRDEstablished(childCtx, crdName); err != nil {
+				failedCRDs[gk] = crdName
+				allErrors = append(allErrors, errors2.Wrap(err, mo.manifest))
+				continue
+			}
+		}
 		changes, err := p.processObject(childCtx, obj, component)
 		madeChanges = madeChanges || changes
 		if err != nil {
-			allErrors = append(allErrors, errors2.Wrap(err, man.Name))
+			allErrors = append(allErrors, errors2.Wrap(err, mo.manifest))
+		} else if obj.GetKind() == "CustomResourceDefinition" && !p.DryRun {
+			if crdGK, ok := crdGroupKind(obj); ok {
+				pendingCRDs[crdGK] = obj.GetName()
+			}
 		}
 	}
 	return madeChanges, allErrors
 }
 
+// sortObjectsByKind sorts the objects in InstallOrder.  The sort is stable, so
+// objects of the same kind are applied in the order in which they were rendered.
+// Unknown kinds are applied after all known kinds, followed by webhook
+// configurations, which must not be invoked before the resources backing them
+// exist.
+func sortObjectsByKind(objects []manifestObject) {
+	ordering := make(map[string]int, len(InstallOrder)+len(webhookConfigurationKinds))
+	for index, kind := range InstallOrder {
+		ordering[kind] = index
+	}
+	for _, kind := range webhookConfigurationKinds {
+		ordering[kind] = len(InstallOrder) + 1
+	}
+	rank := func(kind string) int {
+		if index, ok := ordering[kind]; ok {
+			return index
+		}
+		return len(InstallOrder)
+	}
+	sort.SliceStable(objects, func(i, j int) bool {
+		return rank(objects[i].object.GetKind()) < rank(objects[j].object.GetKind())
+	})
+}
+
+func crdGroupKind(crd *unstructured.Unstructured) (schema.GroupKind, bool) {
+	group, _, _ := unstructured.NestedString(crd.UnstructuredContent(), "spec", "group")
+	kind, _, _ := unstructured.NestedString(crd.UnstructuredContent(), "spec", "names", "kind")
+	if kind == "" {
+		return schema.GroupKind{}, false
+	}
+	return schema.GroupKind{Group: group, Kind: kind}, true
+}
+
+func (p *ManifestProcessor) waitForCRDEstablished(ctx context.Context, name string) error {
+	log := common.LogFromContext(ctx)
+	log.Info("waiting for CustomResourceDefinition to become established", "CustomResourceDefinition", name)
+	waitCtx, cancel := context.WithTimeout(ctx, CRDEstablishedTimeout)
+	defer cancel()
+	err := wait.PollImmediateUntil(CRDEstablishedPollInterval, func() (bool, error) {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := p.Client.Get(waitCtx, client.ObjectKey{Name: name}, crd); err != nil {
+			if errors.IsNotFound(err) || waitCtx.Err() != nil {
+				return false, nil
+			}
+			return false, err
+		}
+		for _, condition := range crd.Status.Conditions {
+			if condition.Type == apiextensionsv1.Established {
+				return condition.Status == apiextensionsv1.ConditionTrue, nil
+			}
+		}
+		return false, nil
+	}, waitCtx.Done())
+	if err == wait.ErrWaitTimeout {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("timed out waiting for CustomResourceDefinition %s to become established", name)
+	}
+	return err
+}
+
 func (p *ManifestProcessor) processObject(ctx context.Context, obj *unstructured.Unstructured, component string) (madeChanges bool, err error) {
 	log := common.LogFromContext(ctx)
 
@@ -176,6 +336,9 @@ func (p *ManifestProcessor) processObject(ctx context.Context, obj *unstructured
 	var patch Patch
 
 	err = p.Client.Get(ctx, objectKey, receiver)
+	if p.DryRun {
+		return false, p.recordChange(obj, receiver, err)
+	}
 	if err != nil {
 		if errors.IsNotFound(err) {
 			log.Info("creating resource")
@@ -190,6 +353,14 @@ func (p *ManifestProcessor) processObject(ctx context.Context, obj *unstructured
 			}
 		}
 	} else {
+		if err = p.checkOwner(receiver); err != nil {
+			log.Error(err, "refusing to update resource")
+			return madeChanges, err
+		}
+		if installer := p.foreignInstaller(receiver); installer != "" {
+			log.Info(fmt.Sprintf("adopting resource installed by %s", installer))
+			common.SetAnnotation(obj, common.AdoptedFromKey, installer)
+		}
 		var preprocessedObj *unstructured.Unstructured
 		preprocessedObj, err = p.preprocessObjectForPatch(ctx, receiver, obj)
 		if err != nil {
@@ -232,6 +403,73 @@ func (p *ManifestProcessor) processObject(ctx context.Context, obj *unstructured
 	return madeChanges, err
 }
 
+// recordChange records whether obj would be created or updated, based on the
+// result of retrieving the existing object.  Objects are considered unchanged
+// if the configuration last applied to the existing object matches obj.  The
+// kind of a new object may be defined by a CustomResourceDefinition that
+// would be created first, so unknown kinds are reported as created.
+func (p *ManifestProcessor) recordChange(obj, existing *unstructured.Unstructured, getErr error) error {
+	key := status.NewResourceKey(obj, obj)
+	if getErr != nil {
+		if errors.IsNotFound(getErr) || meta.IsNoMatchError(getErr) {
+			p.Changes.Created = append(p.Changes.Created, key)
+			return nil
+		}
+		return getErr
+	}
+	if err := p.checkOwner(existing); err != nil {
+		return err
+	}
+	original, err := kubectl.GetOriginalConfiguration(existing)
+	if err != nil {
+		return err
+	}
+	modified, err := kubectl.GetOriginalConfiguration(obj)
+	if err != nil {
+		return err
+	}
+	if len(original) == 0 || !bytes.Equal(original, modified) {
+		p.Changes.Updated = append(p.Changes.Updated, key)
+	} else {
+		p.Changes.Unchanged++
+	}
+	return nil
+}
+
+// checkOwner returns an error if the existing object is owned by another mesh.
+// Updating it would make it flap between the meshes, as each would overwrite
+// the changes made by the other.  CustomResourceDefinitions are shared by all
+// meshes and are never considered to be owned by another mesh.
+func (p *ManifestProcessor) checkOwner(existing *unstructured.Unstructured) error {
+	if p.owner.Namespace == "" || existing.GetKind() == "CustomResourceDefinition" {
+		return nil
+	}
+	if owner, ok := existing.GetLabels()[common.OwnerKey]; ok && owner != "" && owner != p.owner.Namespace {
+		return fmt.Errorf("%s %s is owned by the service mesh in namespace %s", existing.GetKind(), existing.GetName(), owner)
+	}
+	if installer := p.foreignInstaller(existing); installer != "" && !p.Adopt {
+		return fmt.Errorf("%s %s was installed by %s; set the %s annotation to take it over",
+			existing.GetKind(), existing.GetName(), installer, common.AdoptKey)
+	}
+	return nil
+}
+
+// foreignInstaller returns the installer of an existing object that was
+// installed by istioctl or the Istio operator and hasn't been taken over by
+// the mesh yet, or an empty string otherwise
+func (p *ManifestProcessor) foreignInstaller(existing *unstructured.Unstructured) string {
+	labels := existing.GetLabels()
+	if p.owner.Namespace == "" || labels[common.OwnerKey] == p.owner.Namespace {
+		return ""
+	}
+	if labels[istioOperatorManagedLabel] == "Reconcile" {
+		return "the Istio operator"
+	} else if _, ok := labels[istioctlOwningResourceLabel]; ok {
+		return "istioctl"
+	}
+	return ""
+}
+
 func isOpenShiftSpecificResource(obj *unstructured.Unstructured) bool {
 	for _, gvk := range openshiftSpecificResourceKinds {
 		if gvk == obj.GetObjectKind().GroupVersionKind() {
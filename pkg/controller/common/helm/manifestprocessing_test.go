@@ -3,17 +3,25 @@ package helm
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"testing"
+	"time"
 
 	v1 "k8s.io/api/admissionregistration/v1"
 	"k8s.io/api/admissionregistration/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/helm/pkg/manifest"
 	"k8s.io/helm/pkg/releaseutil"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	"github.com/maistra/istio-operator/pkg/apis/maistra/status"
 	"github.com/maistra/istio-operator/pkg/controller/common"
 	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
 )
@@ -42,6 +50,390 @@ func TestEmptyYAMLBlocks(t *testing.T) {
 	}
 }
 
+func TestSortObjectsByKind(t *testing.T) {
+	newObject := func(kind, name string) manifestObject {
+		obj := &unstructured.Unstructured{}
+		obj.SetKind(kind)
+		obj.SetName(name)
+		return manifestObject{manifest: "test.yaml", object: obj}
+	}
+	objects := []manifestObject{
+		newObject("MutatingWebhookConfiguration", "istiod"),
+		newObject("Deployment", "istiod"),
+		newObject("EnvoyFilter", "metadata-exchange"),
+		newObject("CustomResourceDefinition", "envoyfilters.networking.istio.io"),
+		newObject("ValidatingWebhookConfiguration", "istiod"),
+		newObject("ServiceAccount", "istiod"),
+		newObject("Deployment", "istio-ingressgateway"),
+	}
+
+	sortObjectsByKind(objects)
+
+	expected := []string{
+		"ServiceAccount/istiod",
+		"CustomResourceDefinition/envoyfilters.networking.istio.io",
+		"Deployment/istiod",
+		"Deployment/istio-ingressgateway",
+		"EnvoyFilter/metadata-exchange",
+		"MutatingWebhookConfiguration/istiod",
+		"ValidatingWebhookConfiguration/istiod",
+	}
+	actual := make([]string, len(objects))
+	for index, mo := range objects {
+		actual[index] = mo.object.GetKind() + "/" + mo.object.GetName()
+	}
+	assert.DeepEquals(actual, expected, "Unexpected object ordering", t)
+}
+
+func TestWaitForCRDEstablished(t *testing.T) {
+	defer func(timeout, interval time.Duration) {
+		CRDEstablishedTimeout = timeout
+		CRDEstablishedPollInterval = interval
+	}(CRDEstablishedTimeout, CRDEstablishedPollInterval)
+	CRDEstablishedTimeout = 100 * time.Millisecond
+	CRDEstablishedPollInterval = 10 * time.Millisecond
+	newCRD := func(name string, established apiextensionsv1.ConditionStatus) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: apiextensionsv1.CustomResourceDefinitionStatus{
+				Conditions: []apiextensionsv1.CustomResourceDefinitionCondition{
+					{
+						Type:   apiextensionsv1.Established,
+						Status: established,
+					},
+				},
+			},
+		}
+	}
+	scheme := runtime.NewScheme()
+	assert.Success(apiextensionsv1.AddToScheme(scheme), "AddToScheme", t)
+	cl := fake.NewFakeClientWithScheme(scheme,
+		newCRD("established.example.com", apiextensionsv1.ConditionTrue),
+		newCRD("pending.example.com", apiextensionsv1.ConditionFalse),
+	)
+	processor := NewManifestProcessor(common.ControllerResources{Client: cl}, &PatchFactory{}, "app", "version", types.NamespacedName{}, nil, nil, nil)
+
+	assert.Success(processor.waitForCRDEstablished(context.TODO(), "established.example.com"), "waitForCRDEstablished", t)
+	if err := processor.waitForCRDEstablished(context.TODO(), "pending.example.com"); err == nil {
+		t.Errorf("expected error waiting for CRD that never becomes established")
+	}
+	if err := processor.waitForCRDEstablished(context.TODO(), "missing.example.com"); err == nil {
+		t.Errorf("expected error waiting for CRD that doesn't exist")
+	}
+}
+
+func TestProcessObjectsWaitsForCRDs(t *testing.T) {
+	defer func(timeout, interval time.Duration) {
+		CRDEstablishedTimeout = timeout
+		CRDEstablishedPollInterval = interval
+	}(CRDEstablishedTimeout, CRDEstablishedPollInterval)
+	CRDEstablishedTimeout = 200 * time.Millisecond
+	CRDEstablishedPollInterval = 10 * time.Millisecond
+
+	newObject := func(apiVersion, kind, name string) manifestObject {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind(kind)
+		obj.SetName(name)
+		return manifestObject{manifest: "test.yaml", object: obj}
+	}
+	newCRD := func() manifestObject {
+		crd := newObject("apiextensions.k8s.io/v1", "CustomResourceDefinition", "widgets.example.com")
+		_ = unstructured.SetNestedField(crd.object.Object, "example.com", "spec", "group")
+		_ = unstructured.SetNestedField(crd.object.Object, "Widget", "spec", "names", "kind")
+		return crd
+	}
+
+	testCases := []struct {
+		name             string
+		establishCRD     bool
+		expectedApplied  []string
+		expectedErrCount int
+	}{
+		{
+			name:            "established",
+			establishCRD:    true,
+			expectedApplied: []string{"CustomResourceDefinition/widgets.example.com", "Widget/a", "Widget/b", "Widget/c"},
+		},
+		{
+			name:             "never-established",
+			establishCRD:     false,
+			expectedApplied:  []string{"CustomResourceDefinition/widgets.example.com"},
+			expectedErrCount: 1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			assert.Success(apiextensionsv1.AddToScheme(scheme), "AddToScheme", t)
+			cl := fake.NewFakeClientWithScheme(scheme)
+
+			applied := []string{}
+			preprocess := func(_ context.Context, obj *unstructured.Unstructured) (bool, error) {
+				applied = append(applied, obj.GetKind()+"/"+obj.GetName())
+				return true, nil
+			}
+			postProcess := func(ctx context.Context, obj *unstructured.Unstructured) error {
+				if !tc.establishCRD || obj.GetKind() != "CustomResourceDefinition" {
+					return nil
+				}
+				// simulate the API server establishing the CRD
+				crd := &apiextensionsv1.CustomResourceDefinition{}
+				if err := cl.Get(ctx, client.ObjectKey{Name: obj.GetName()}, crd); err != nil {
+					return err
+				}
+				crd.Status.Conditions = []apiextensionsv1.CustomResourceDefinitionCondition{
+					{Type: apiextensionsv1.Established, Status: apiextensionsv1.ConditionTrue},
+				}
+				return cl.Update(ctx, crd)
+			}
+			processor := NewManifestProcessor(common.ControllerResources{Client: cl}, &PatchFactory{}, "app", "version",
+				types.NamespacedName{}, preprocess, postProcess, nil)
+
+			// custom resources are rendered before their CRD
+			objects := []manifestObject{
+				newObject("example.com/v1", "Widget", "a"),
+				newObject("example.com/v1", "Widget", "b"),
+				newCRD(),
+				newObject("example.com/v1", "Widget", "c"),
+			}
+			start := time.Now()
+			_, errs := processor.processObjects(context.TODO(), objects, "test")
+			elapsed := time.Since(start)
+
+			assert.Equals(len(errs), tc.expectedErrCount, fmt.Sprintf("unexpected errors: %v", errs), t)
+			assert.DeepEquals(applied, tc.expectedApplied, "unexpected objects applied", t)
+			if elapsed >= 2*CRDEstablishedTimeout {
+				t.Errorf("expected processor to wait for CRD only once, but processing took %s", elapsed)
+			}
+		})
+	}
+}
+
+func TestProcessObjectDoesNotUpdateResourceOwnedByOtherMesh(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.Success(v1.AddToScheme(scheme), "AddToScheme", t)
+	existing := &v1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "istiod-basic-mesh-system",
+			Labels: map[string]string{common.OwnerKey: "mesh-system", common.OwnerNameKey: "basic"},
+		},
+	}
+	cl := fake.NewFakeClientWithScheme(scheme, existing)
+	preprocess := func(_ context.Context, obj *unstructured.Unstructured) (bool, error) {
+		return true, nil
+	}
+	processor := NewManifestProcessor(common.ControllerResources{Client: cl}, &PatchFactory{}, "app", "version",
+		types.NamespacedName{Namespace: "system", Name: "basic-mesh"}, preprocess, nil, nil)
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("admissionregistration.k8s.io/v1")
+	obj.SetKind("MutatingWebhookConfiguration")
+	obj.SetName("istiod-basic-mesh-system")
+	_, err := processor.processObject(context.TODO(), obj, "test")
+	assert.Failure(err, "processObject", t)
+
+	webhook := &v1.MutatingWebhookConfiguration{}
+	assert.Success(cl.Get(context.TODO(), client.ObjectKey{Name: existing.Name}, webhook), "Get", t)
+	assert.Equals(webhook.Labels[common.OwnerKey], "mesh-system", "expected owner of existing resource to be unchanged", t)
+}
+
+func TestProcessObjectAdoptsObjectInstalledByIstioctl(t *testing.T) {
+	existing := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "istio-system",
+		Name:      "istio",
+		Labels:    map[string]string{istioctlOwningResourceLabel: "unknown"},
+	}}
+	scheme := runtime.NewScheme()
+	assert.Success(corev1.AddToScheme(scheme), "AddToScheme", t)
+	cl := fake.NewFakeClientWithScheme(scheme, existing)
+	preprocess := func(_ context.Context, obj *unstructured.Unstructured) (bool, error) {
+		return true, nil
+	}
+	preprocessForPatch := func(_ context.Context, _, newObj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+		return newObj, nil
+	}
+	processor := NewManifestProcessor(common.ControllerResources{Client: cl}, NewPatchFactory(cl), "app", "version",
+		types.NamespacedName{Namespace: "istio-system", Name: "basic"}, preprocess, nil, preprocessForPatch)
+	processor.Adopt = true
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetNamespace("istio-system")
+	obj.SetName("istio")
+	_, err := processor.processObject(context.TODO(), obj, "test")
+	assert.Success(err, "processObject", t)
+
+	configMap := &corev1.ConfigMap{}
+	assert.Success(cl.Get(context.TODO(), client.ObjectKey{Namespace: "istio-system", Name: "istio"}, configMap), "Get", t)
+	assert.Equals(configMap.Labels[common.OwnerKey], "istio-system", "expected the object to be owned by the mesh", t)
+	assert.Equals(configMap.Annotations[common.AdoptedFromKey], "istioctl", "expected the object to record its installer", t)
+}
+
+func TestProcessObjectsDryRun(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.Success(corev1.AddToScheme(scheme), "AddToScheme", t)
+	cl := fake.NewFakeClientWithScheme(scheme)
+	preprocess := func(_ context.Context, obj *unstructured.Unstructured) (bool, error) {
+		return true, nil
+	}
+	postProcess := func(_ context.Context, obj *unstructured.Unstructured) error {
+		return nil
+	}
+	newConfigMap := func(name, value string) manifestObject {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("v1")
+		obj.SetKind("ConfigMap")
+		obj.SetNamespace("istio-system")
+		obj.SetName(name)
+		_ = unstructured.SetNestedField(obj.Object, value, "data", "key")
+		return manifestObject{manifest: "test.yaml", object: obj}
+	}
+	owner := types.NamespacedName{Namespace: "istio-system", Name: "basic"}
+
+	processor := NewManifestProcessor(common.ControllerResources{Client: cl}, NewPatchFactory(cl), "app", "version",
+		owner, preprocess, postProcess, nil)
+	_, errs := processor.processObjects(context.TODO(), []manifestObject{
+		newConfigMap("unchanged", "a"),
+		newConfigMap("updated", "a"),
+	}, "test")
+	assert.Equals(len(errs), 0, fmt.Sprintf("unexpected errors: %v", errs), t)
+
+	dryRunProcessor := NewManifestProcessor(common.ControllerResources{Client: cl}, NewPatchFactory(cl), "app", "version",
+		owner, preprocess, postProcess, nil)
+	dryRunProcessor.DryRun = true
+	_, errs = dryRunProcessor.processObjects(context.TODO(), []manifestObject{
+		newConfigMap("unchanged", "a"),
+		newConfigMap("updated", "b"),
+		newConfigMap("created", "a"),
+	}, "test")
+	assert.Equals(len(errs), 0, fmt.Sprintf("unexpected errors: %v", errs), t)
+	assert.DeepEquals(dryRunProcessor.Changes, ManifestChanges{
+		Created:   []status.ResourceKey{"istio-system/created=v1,Kind=ConfigMap"},
+		Updated:   []status.ResourceKey{"istio-system/updated=v1,Kind=ConfigMap"},
+		Unchanged: 1,
+	}, "unexpected changes", t)
+
+	configMap := &corev1.ConfigMap{}
+	assert.Success(cl.Get(context.TODO(), client.ObjectKey{Namespace: "istio-system", Name: "updated"}, configMap), "Get", t)
+	assert.Equals(configMap.Data["key"], "a", "expected dry run not to update the existing object", t)
+	err := cl.Get(context.TODO(), client.ObjectKey{Namespace: "istio-system", Name: "created"}, configMap)
+	assert.True(errors.IsNotFound(err), "expected dry run not to create the new object", t)
+}
+
+func TestCheckOwner(t *testing.T) {
+	newObject := func(kind, owner string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetKind(kind)
+		obj.SetName("test")
+		if owner != "" {
+			obj.SetLabels(map[string]string{common.OwnerKey: owner})
+		}
+		return obj
+	}
+	withLabel := func(obj *unstructured.Unstructured, key, value string) *unstructured.Unstructured {
+		common.SetLabel(obj, key, value)
+		return obj
+	}
+	testCases := []struct {
+		name        string
+		owner       types.NamespacedName
+		adopt       bool
+		existing    *unstructured.Unstructured
+		expectError bool
+	}{
+		{
+			name:     "same-mesh",
+			owner:    types.NamespacedName{Namespace: "mesh-a", Name: "basic"},
+			existing: newObject("ClusterRole", "mesh-a"),
+		},
+		{
+			name:     "unowned",
+			owner:    types.NamespacedName{Namespace: "mesh-a", Name: "basic"},
+			existing: newObject("ClusterRole", ""),
+		},
+		{
+			name:        "other-mesh",
+			owner:       types.NamespacedName{Namespace: "mesh-a", Name: "basic"},
+			existing:    newObject("ClusterRole", "mesh-b"),
+			expectError: true,
+		},
+		{
+			name:     "shared-crd",
+			owner:    types.NamespacedName{Namespace: "mesh-a", Name: "basic"},
+			existing: newObject("CustomResourceDefinition", "mesh-b"),
+		},
+		{
+			name:     "no-owner",
+			existing: newObject("ClusterRole", "mesh-b"),
+		},
+		{
+			name:        "installed-by-istioctl",
+			owner:       types.NamespacedName{Namespace: "mesh-a", Name: "basic"},
+			existing:    withLabel(newObject("ClusterRole", ""), istioctlOwningResourceLabel, "unknown"),
+			expectError: true,
+		},
+		{
+			name:        "installed-by-istio-operator",
+			owner:       types.NamespacedName{Namespace: "mesh-a", Name: "basic"},
+			existing:    withLabel(newObject("ClusterRole", ""), istioOperatorManagedLabel, "Reconcile"),
+			expectError: true,
+		},
+		{
+			name:     "adopt-installed-by-istioctl",
+			owner:    types.NamespacedName{Namespace: "mesh-a", Name: "basic"},
+			adopt:    true,
+			existing: withLabel(newObject("ClusterRole", ""), istioctlOwningResourceLabel, "unknown"),
+		},
+		{
+			name:     "already-adopted",
+			owner:    types.NamespacedName{Namespace: "mesh-a", Name: "basic"},
+			existing: withLabel(newObject("ClusterRole", "mesh-a"), istioctlOwningResourceLabel, "unknown"),
+		},
+		{
+			name:        "adopt-owned-by-other-mesh",
+			owner:       types.NamespacedName{Namespace: "mesh-a", Name: "basic"},
+			adopt:       true,
+			existing:    withLabel(newObject("ClusterRole", "mesh-b"), istioctlOwningResourceLabel, "unknown"),
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			processor := NewManifestProcessor(common.ControllerResources{}, &PatchFactory{}, "app", "version", tc.owner, nil, nil, nil)
+			processor.Adopt = tc.adopt
+			err := processor.checkOwner(tc.existing)
+			if tc.expectError {
+				assert.Failure(err, "checkOwner", t)
+			} else {
+				assert.Success(err, "checkOwner", t)
+			}
+		})
+	}
+}
+
+func TestWaitForCRDEstablishedIsCancellable(t *testing.T) {
+	defer func(timeout time.Duration) {
+		CRDEstablishedTimeout = timeout
+	}(CRDEstablishedTimeout)
+	CRDEstablishedTimeout = time.Minute
+
+	scheme := runtime.NewScheme()
+	assert.Success(apiextensionsv1.AddToScheme(scheme), "AddToScheme", t)
+	processor := NewManifestProcessor(common.ControllerResources{Client: fake.NewFakeClientWithScheme(scheme)}, &PatchFactory{},
+		"app", "version", types.NamespacedName{}, nil, nil, nil)
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := processor.waitForCRDEstablished(ctx, "missing.example.com")
+	assert.Equals(err, context.DeadlineExceeded, "unexpected error", t)
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("expected wait to be cancelled with the context, but it took %s", elapsed)
+	}
+}
+
 func TestConvertWebhookConfigurationFromV1beta1ToV1(t *testing.T) {
 	testCases := []struct {
 		name                            string
@@ -0,0 +1,56 @@
+package helm
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	phaseLoad   = "load"
+	phaseRender = "render"
+	phaseApply  = "apply"
+
+	cacheResultHit  = "hit"
+	cacheResultMiss = "miss"
+)
+
+// phaseDuration records how long loading, rendering and applying charts
+// takes, so performance regressions can be tracked across releases
+var phaseDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "servicemesh_helm_phase_duration_seconds",
+		Help:    "Time spent loading, rendering and applying helm charts, by phase",
+		Buckets: prometheus.ExponentialBuckets(0.005, 2, 12),
+	},
+	[]string{"phase"},
+)
+
+// renderCacheRequests counts the lookups in the render cache, by result, so
+// the hit ratio of the cache can be computed
+var renderCacheRequests = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "servicemesh_helm_render_cache_requests_total",
+		Help: "Number of lookups in the helm render cache, by result",
+	},
+	[]string{"result"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(phaseDuration, renderCacheRequests)
+}
+
+// observePhase records the time elapsed since start for the phase.  It's
+// meant to be deferred, e.g. defer observePhase(phaseApply, time.Now()).
+func observePhase(phase string, start time.Time) {
+	phaseDuration.WithLabelValues(phase).Observe(time.Since(start).Seconds())
+}
+
+func recordRenderCacheLookup(hit bool) {
+	if hit {
+		renderCacheRequests.WithLabelValues(cacheResultHit).Inc()
+	} else {
+		renderCacheRequests.WithLabelValues(cacheResultMiss).Inc()
+	}
+}
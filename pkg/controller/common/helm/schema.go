@@ -0,0 +1,71 @@
+package helm
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// ValuesSchema returns a JSON schema describing the structure of the default
+// values of charts, as returned by DefaultValues.  Each non-empty map becomes
+// an object that only allows the keys present in the defaults.  Any value is
+// accepted for other defaults, as well as below an empty map, e.g.
+// nodeSelector: {}, as charts usually copy such values into the manifests
+// verbatim.  The types of scalar values aren't restricted, as the templates
+// treat e.g. "true" and true alike.  Maps may be set to null, which removes
+// the defaults.
+func ValuesSchema(defaults map[string]interface{}) map[string]interface{} {
+	schema := objectSchema(defaults)
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	return schema
+}
+
+func objectSchema(defaults map[string]interface{}) map[string]interface{} {
+	properties := map[string]interface{}{}
+	for key, value := range defaults {
+		if valueMap, ok := value.(map[string]interface{}); ok {
+			propertySchema := map[string]interface{}{}
+			if len(valueMap) > 0 {
+				propertySchema = objectSchema(valueMap)
+			}
+			propertySchema["type"] = []interface{}{"object", "null"}
+			properties[key] = propertySchema
+		} else {
+			properties[key] = map[string]interface{}{}
+		}
+	}
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+// ValidateValues validates the values against a JSON schema, e.g. one
+// returned by ValuesSchema.  It returns the paths of values that aren't
+// allowed by the schema, e.g. pilot.traceSampling1, separately from the
+// values that violate the schema otherwise, e.g. "pilot: Invalid type.
+// Expected: object, given: string".  Both are sorted alphabetically.
+func ValidateValues(schema *gojsonschema.Schema, values map[string]interface{}) (unknown, invalid []string, err error) {
+	result, err := schema.Validate(gojsonschema.NewGoLoader(values))
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, resultErr := range result.Errors() {
+		field := resultErr.Field()
+		if _, ok := resultErr.(*gojsonschema.AdditionalPropertyNotAllowedError); ok {
+			property := fmt.Sprint(resultErr.Details()["property"])
+			if field == gojsonschema.STRING_CONTEXT_ROOT {
+				unknown = append(unknown, property)
+			} else {
+				unknown = append(unknown, field+"."+property)
+			}
+			continue
+		}
+		invalid = append(invalid, fmt.Sprintf("%s: %s", field, resultErr.Description()))
+	}
+	sort.Strings(unknown)
+	sort.Strings(invalid)
+	return unknown, invalid, nil
+}
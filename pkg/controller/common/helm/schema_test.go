@@ -0,0 +1,46 @@
+package helm
+
+import (
+	"testing"
+
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+)
+
+func TestValidateValues(t *testing.T) {
+	defaults := map[string]interface{}{
+		"pilot": map[string]interface{}{
+			"enabled":       true,
+			"traceSampling": 1.0,
+			"nodeSelector":  map[string]interface{}{},
+		},
+		"global": map[string]interface{}{
+			"proxy": map[string]interface{}{"logLevel": "warning"},
+		},
+	}
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewGoLoader(ValuesSchema(defaults)))
+	assert.Success(err, "NewSchema", t)
+
+	values := map[string]interface{}{
+		"pilot": map[string]interface{}{
+			"enabled":        "false",
+			"traceSampling1": 100,
+			"nodeSelector":   map[string]interface{}{"region": "east"},
+		},
+		"global": map[string]interface{}{
+			"proxy": "debug",
+		},
+		"gateways": map[string]interface{}{"enabled": false},
+	}
+	unknown, invalid, err := ValidateValues(schema, values)
+	assert.Success(err, "ValidateValues", t)
+	assert.DeepEquals(unknown, []string{"gateways", "pilot.traceSampling1"}, "unexpected unknown values", t)
+	assert.DeepEquals(invalid, []string{"global.proxy: Invalid type. Expected: [object,null], given: string"}, "unexpected invalid values", t)
+
+	unknown, invalid, err = ValidateValues(schema, map[string]interface{}{
+		"pilot": map[string]interface{}{"nodeSelector": nil},
+	})
+	assert.Success(err, "ValidateValues", t)
+	assert.True(len(unknown) == 0 && len(invalid) == 0, "expected null values to be valid", t)
+}
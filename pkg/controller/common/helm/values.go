@@ -0,0 +1,68 @@
+package helm
+
+import (
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+)
+
+// DefaultValues returns the default values of the specified charts, merged
+// into a single tree.  The values of subcharts are nested below the name of
+// the subchart, as they would be when rendering the parent chart, and their
+// global values are merged into the top-level global values.
+func DefaultValues(chartPaths ...string) (map[string]interface{}, error) {
+	defaults := map[string]interface{}{}
+	for _, chartPath := range chartPaths {
+		c, err := chartutil.Load(chartPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := mergeChartValues(c, defaults); err != nil {
+			return nil, err
+		}
+	}
+	return defaults, nil
+}
+
+func mergeChartValues(c *chart.Chart, defaults map[string]interface{}) error {
+	values, err := chartutil.ReadValues([]byte(c.GetValues().GetRaw()))
+	if err != nil {
+		return err
+	}
+	MergeValues(defaults, values)
+	for _, subchart := range c.GetDependencies() {
+		subchartDefaults := map[string]interface{}{}
+		if err := mergeChartValues(subchart, subchartDefaults); err != nil {
+			return err
+		}
+		if globals, ok := subchartDefaults[chartutil.GlobalKey]; ok {
+			MergeValues(defaults, map[string]interface{}{chartutil.GlobalKey: globals})
+			delete(subchartDefaults, chartutil.GlobalKey)
+		}
+		MergeValues(defaults, map[string]interface{}{subchart.GetMetadata().GetName(): subchartDefaults})
+	}
+	return nil
+}
+
+// MergeValues merges the default values in src into dst.  Maps present in
+// both are merged, unless either of them is empty, in which case the result is
+// empty, as an empty default accepts any value.  Otherwise, the value in dst
+// is kept.
+func MergeValues(dst, src map[string]interface{}) {
+	for key, srcValue := range src {
+		dstValue, exists := dst[key]
+		if !exists {
+			dst[key] = srcValue
+			continue
+		}
+		dstMap, dstIsMap := dstValue.(map[string]interface{})
+		srcMap, srcIsMap := srcValue.(map[string]interface{})
+		if !dstIsMap || !srcIsMap || len(dstMap) == 0 {
+			continue
+		}
+		if len(srcMap) == 0 {
+			dst[key] = srcMap
+		} else {
+			MergeValues(dstMap, srcMap)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package helm
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+)
+
+func writeChart(t *testing.T, chartPath, name, values string) {
+	assert.Success(os.MkdirAll(path.Join(chartPath, "templates"), 0755), "MkdirAll", t)
+	assert.Success(ioutil.WriteFile(path.Join(chartPath, "Chart.yaml"), []byte("name: "+name+"\nversion: 1.0.0\n"), 0644), "WriteFile", t)
+	assert.Success(ioutil.WriteFile(path.Join(chartPath, "values.yaml"), []byte(values), 0644), "WriteFile", t)
+}
+
+func TestDefaultValuesSchema(t *testing.T) {
+	chartsDir, err := ioutil.TempDir("", "charts")
+	assert.Success(err, "TempDir", t)
+	defer os.RemoveAll(chartsDir)
+	writeChart(t, path.Join(chartsDir, "discovery"), "discovery",
+		"pilot:\n  enabled: true\n  nodeSelector: {}\n  env: {}\nglobal:\n  hub: quay.io/maistra\n")
+	writeChart(t, path.Join(chartsDir, "discovery", "charts", "sidecar"), "sidecar",
+		"image: proxyv2\nglobal:\n  proxy:\n    logLevel: warning\n")
+	writeChart(t, path.Join(chartsDir, "prometheus"), "prometheus",
+		"prometheus:\n  retention: 6h\n  nodeSelector:\n    role: infra\n")
+
+	defaults, err := DefaultValues(path.Join(chartsDir, "discovery"), path.Join(chartsDir, "prometheus"))
+	assert.Success(err, "DefaultValues", t)
+
+	values := map[string]interface{}{
+		"pilot": map[string]interface{}{
+			"enable":       true,
+			"nodeSelector": map[string]interface{}{"region": "east"},
+			"env":          map[string]interface{}{"PILOT_TRACE_SAMPLING": "100"},
+		},
+		"global": map[string]interface{}{
+			"hub":   "quay.io/custom",
+			"proxy": map[string]interface{}{"logLevel": "debug", "loglevel": "debug"},
+		},
+		"sidecar": map[string]interface{}{"image": "proxy", "tag": "latest"},
+		"prometheus": map[string]interface{}{
+			"retention":    "12h",
+			"nodeSelector": map[string]interface{}{"region": "east"},
+		},
+		"kiali": map[string]interface{}{"enabled": true},
+	}
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewGoLoader(ValuesSchema(defaults)))
+	assert.Success(err, "NewSchema", t)
+	unknown, invalid, err := ValidateValues(schema, values)
+	assert.Success(err, "ValidateValues", t)
+	assert.Equals(len(invalid), 0, "unexpected invalid values", t)
+	assert.DeepEquals(unknown,
+		[]string{"global.proxy.loglevel", "kiali", "pilot.enable", "prometheus.nodeSelector.region", "sidecar.tag"},
+		"unexpected unknown values", t)
+}
+
+func TestMergeValues(t *testing.T) {
+	dst := map[string]interface{}{
+		"a": map[string]interface{}{"b": 1},
+		"c": map[string]interface{}{"d": 1},
+		"e": map[string]interface{}{},
+		"f": "value",
+	}
+	MergeValues(dst, map[string]interface{}{
+		"a": map[string]interface{}{"g": 2},
+		"c": map[string]interface{}{},
+		"e": map[string]interface{}{"h": 3},
+		"f": map[string]interface{}{"i": 4},
+		"j": 5,
+	})
+	assert.DeepEquals(dst, map[string]interface{}{
+		"a": map[string]interface{}{"b": 1, "g": 2},
+		"c": map[string]interface{}{},
+		"e": map[string]interface{}{},
+		"f": "value",
+		"j": 5,
+	}, "unexpected merge result", t)
+}
@@ -0,0 +1,158 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+type KubernetesClientProvider interface {
+	GetKubernetesClient() (kubernetes.Interface, error)
+}
+
+// NewLabelSelectedInformer returns an informer for the resource that only
+// caches the objects matching the label selector.  The manager's cache holds
+// every object of a watched type, which is wasteful for types like secrets or
+// pods, of which only a few objects are of interest.  The informer is started
+// by the manager.
+func NewLabelSelectedInformer(mgr manager.Manager, resource schema.GroupVersionResource, labelSelector string) (cache.SharedIndexInformer, error) {
+	clientProvider, ok := mgr.(KubernetesClientProvider)
+	if !ok {
+		return nil, fmt.Errorf("expected mgr to be a KubernetesClientProvider")
+	}
+	kubeClient, err := clientProvider.GetKubernetesClient()
+	if err != nil {
+		return nil, err
+	}
+	factory := informers.NewSharedInformerFactoryWithOptions(kubeClient, 0,
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = labelSelector
+		}))
+	informer, err := factory.ForResource(resource)
+	if err != nil {
+		return nil, err
+	}
+	if err := mgr.Add(manager.RunnableFunc(func(stop <-chan struct{}) error {
+		factory.Start(stop)
+		<-stop
+		return nil
+	})); err != nil {
+		return nil, err
+	}
+	return informer.Informer(), nil
+}
+
+// InformerReader is a client.Reader that reads the objects cached by an
+// informer, e.g. one returned by NewLabelSelectedInformer
+type InformerReader struct {
+	Informer cache.SharedIndexInformer
+	Resource schema.GroupResource
+}
+
+var _ client.Reader = &InformerReader{}
+
+// Get copies the cached object with the key into obj
+func (r *InformerReader) Get(_ context.Context, key client.ObjectKey, obj runtime.Object) error {
+	if !r.Informer.HasSynced() {
+		return r.notSyncedError()
+	}
+	storeKey := key.Name
+	if key.Namespace != "" {
+		storeKey = key.Namespace + "/" + key.Name
+	}
+	item, exists, err := r.Informer.GetIndexer().GetByKey(storeKey)
+	if err != nil {
+		return err
+	} else if !exists {
+		return errors.NewNotFound(r.Resource, key.Name)
+	}
+	cached, ok := item.(runtime.Object)
+	if !ok {
+		return fmt.Errorf("cached item is not a runtime.Object: %T", item)
+	}
+	outValue := reflect.ValueOf(obj)
+	cachedValue := reflect.ValueOf(cached.DeepCopyObject())
+	if outValue.Type() != cachedValue.Type() {
+		return fmt.Errorf("cannot read %T into %T", cached, obj)
+	}
+	outValue.Elem().Set(cachedValue.Elem())
+	return nil
+}
+
+// List sets the items of list to the cached objects matching the options.
+// Only the namespace and label selector options are supported.  An
+// unstructured list receives the cached objects converted to unstructured
+// objects of the kind of its items.
+func (r *InformerReader) List(_ context.Context, list runtime.Object, opts ...client.ListOption) error {
+	if !r.Informer.HasSynced() {
+		return r.notSyncedError()
+	}
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	var items []interface{}
+	var err error
+	if listOpts.Namespace == "" {
+		items = r.Informer.GetIndexer().List()
+	} else if items, err = r.Informer.GetIndexer().ByIndex(cache.NamespaceIndex, listOpts.Namespace); err != nil {
+		return err
+	}
+	selector := listOpts.LabelSelector
+	if selector == nil {
+		selector = labels.Everything()
+	}
+	objects := make([]runtime.Object, 0, len(items))
+	for _, item := range items {
+		obj, ok := item.(runtime.Object)
+		if !ok {
+			return fmt.Errorf("cached item is not a runtime.Object: %T", item)
+		}
+		objMeta, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+		if selector.Matches(labels.Set(objMeta.GetLabels())) {
+			objects = append(objects, obj.DeepCopyObject())
+		}
+	}
+	if unstructuredList, ok := list.(*unstructured.UnstructuredList); ok {
+		return setUnstructuredList(unstructuredList, objects)
+	}
+	return meta.SetList(list, objects)
+}
+
+// setUnstructuredList sets the items of list to the objects, which are
+// converted to unstructured objects.  The objects cached by informers don't
+// have their kind set, so it's derived from the kind of the list.
+func setUnstructuredList(list *unstructured.UnstructuredList, objects []runtime.Object) error {
+	gvk := list.GroupVersionKind()
+	gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+	list.Items = make([]unstructured.Unstructured, 0, len(objects))
+	for _, obj := range objects {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return err
+		}
+		item := unstructured.Unstructured{Object: content}
+		item.SetGroupVersionKind(gvk)
+		list.Items = append(list.Items, item)
+	}
+	return nil
+}
+
+func (r *InformerReader) notSyncedError() error {
+	return fmt.Errorf("the %s have not been synced yet", r.Resource)
+}
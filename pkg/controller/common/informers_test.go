@@ -0,0 +1,80 @@
+package common
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestInformerReader(t *testing.T) {
+	newPod := func(namespace, name string, labels map[string]string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels}}
+	}
+	kubeClient := fake.NewSimpleClientset(
+		newPod("ns1", "injected", map[string]string{"injected": "true", "app": "a"}),
+		newPod("ns1", "other", map[string]string{"injected": "true", "app": "b"}),
+		newPod("ns2", "injected", map[string]string{"injected": "true", "app": "a"}),
+	)
+	factory := informers.NewSharedInformerFactory(kubeClient, 0)
+	informer := factory.Core().V1().Pods().Informer()
+	reader := &InformerReader{Informer: informer, Resource: corev1.Resource("pods")}
+
+	if err := reader.List(context.TODO(), &corev1.PodList{}); err == nil {
+		t.Errorf("expected error when reading before the informer synced")
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	factory.Start(stop)
+	if !cache.WaitForCacheSync(stop, informer.HasSynced) {
+		t.Fatalf("informer did not sync")
+	}
+
+	pods := &corev1.PodList{}
+	if err := reader.List(context.TODO(), pods, client.InNamespace("ns1"), client.MatchingLabels{"app": "a"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pods.Items) != 1 || pods.Items[0].Namespace != "ns1" || pods.Items[0].Name != "injected" {
+		t.Errorf("expected only pod ns1/injected, got %v", pods.Items)
+	}
+
+	if err := reader.List(context.TODO(), pods); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pods.Items) != 3 {
+		t.Errorf("expected all 3 pods, got %d", len(pods.Items))
+	}
+
+	unstructuredPods := &unstructured.UnstructuredList{}
+	unstructuredPods.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("PodList"))
+	if err := reader.List(context.TODO(), unstructuredPods, client.MatchingLabels{"app": "a"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(unstructuredPods.Items) != 2 {
+		t.Errorf("expected 2 unstructured pods, got %d", len(unstructuredPods.Items))
+	}
+	for _, item := range unstructuredPods.Items {
+		if item.GetKind() != "Pod" || item.GetAPIVersion() != "v1" || item.GetLabels()["app"] != "a" {
+			t.Errorf("unexpected unstructured pod: %v", item.Object)
+		}
+	}
+
+	pod := &corev1.Pod{}
+	if err := reader.Get(context.TODO(), client.ObjectKey{Namespace: "ns2", Name: "injected"}, pod); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pod.Namespace != "ns2" || pod.Labels["app"] != "a" {
+		t.Errorf("unexpected pod: %v", pod.ObjectMeta)
+	}
+	if err := reader.Get(context.TODO(), client.ObjectKey{Namespace: "ns2", Name: "other"}, pod); !errors.IsNotFound(err) {
+		t.Errorf("expected NotFound error, got %v", err)
+	}
+}
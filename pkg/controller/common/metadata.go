@@ -29,9 +29,61 @@ const (
 	// InternalKey is used to identify the resource as being internal to the mesh itself (i.e. should not be applied to members)
 	InternalKey = MetadataNamespace + "/internal"
 
+	// IgnoreCABundleKey indicates that the caBundle of a webhook configuration is provided by the chart and should
+	// not be managed by the operator, e.g. when the webhooks are served by an external istiod
+	IgnoreCABundleKey = MetadataNamespace + "/ignore-ca-bundle"
+
+	// DryRunKey is set to "true" on a ServiceMeshControlPlane to render and validate changes to its spec and report the
+	// changes that would be applied in its status, without applying them
+	DryRunKey = MetadataNamespace + "/dry-run"
+
+	// StrictValuesKey is set to "true" on a ServiceMeshControlPlane to reject helm values in spec.techPreview that
+	// aren't recognized by any of the charts, instead of passing them through
+	StrictValuesKey = MetadataNamespace + "/strict-values"
+
+	// PausedKey is set to "true" on a ServiceMeshControlPlane to stop the operator from installing or updating its
+	// components, e.g. during incident response or a maintenance window.  Its status is still updated.
+	PausedKey = MetadataNamespace + "/paused"
+
+	// ConfigDumpKey is set on a ServiceMeshControlPlane to dump the configuration istiod has received into a ConfigMap.
+	// The value identifies the dump, e.g. the current time, and is recorded on the ConfigMap; changing it requests a
+	// new dump.
+	ConfigDumpKey = MetadataNamespace + "/config-dump"
+
+	// AdoptKey is set to "true" on a ServiceMeshControlPlane to take over the objects of an existing Istio installation
+	// that was installed by istioctl or the Istio operator, instead of refusing to update them
+	AdoptKey = MetadataNamespace + "/adopt"
+
+	// AdoptedFromKey is set on the objects that were taken over from an existing Istio installation and records the
+	// installer that created them
+	AdoptedFromKey = MetadataNamespace + "/adopted-from"
+
+	// CARotationRestartedAtKey is set on the pod template of istiod to restart it when the cacerts secret is changed
+	// by the rotation of the intermediate CA
+	CARotationRestartedAtKey = MetadataNamespace + "/ca-rotation-restarted-at"
+
+	// CARotationSecretKey is set on the backup of the cacerts secret taken before the intermediate CA is rotated.  It
+	// records the secret containing the new CA, so the backup is only taken once per rotation.
+	CARotationSecretKey = MetadataNamespace + "/ca-rotation-secret"
+
+	// ProxyHashKey is set on the pod template of the Deployments restarted to update their sidecars, see
+	// spec.proxy.updateWorkloads.  It records the hash of the proxy image and configuration the restarted pods use.
+	ProxyHashKey = MetadataNamespace + "/proxy-hash"
+
+	// VersionAliasKey is set on control planes whose spec.version was set to an alias, e.g. latest, which was
+	// replaced with the version it referred to.  It records the alias, so applying it again doesn't change the version.
+	VersionAliasKey = MetadataNamespace + "/version-alias"
+
 	// FinalizerName is the finalizer name the controllers add to any resources that need to be finalized during deletion
 	FinalizerName = MetadataNamespace + "/istio-operator"
 
+	// IstioRevisionKey is the label injected pods carry to identify the control plane revision that injected them.
+	// The revision of a control plane is the name of its ServiceMeshControlPlane.
+	IstioRevisionKey = "istio.io/rev"
+
+	// IstioInjectionKey is the label that enables or disables sidecar injection for all pods in a namespace
+	IstioInjectionKey = "istio-injection"
+
 	// KubernetesAppNamespace is the common namespace for application information
 	KubernetesAppNamespace    = "app.kubernetes.io"
 	KubernetesAppNameKey      = KubernetesAppNamespace + "/name"
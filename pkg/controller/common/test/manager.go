@@ -137,6 +137,12 @@ func (m *FakeManager) GetEventRecorderFor(name string) record.EventRecorder {
 	return m.recorderProvider.GetEventRecorderFor(name)
 }
 
+// GetAPIReader returns the fake client, as there is no API server to read
+// from directly
+func (m *FakeManager) GetAPIReader() client.Reader {
+	return m.GetClient()
+}
+
 // Add intercepts the Add() call by wrapping the Reconciler for any Controller
 // with code that works with a WaitGroup. This allows reconciles to be tracked
 // by the Manager.  Tests should use FakeManager.WaitForReconcileCompletion()
@@ -174,6 +180,48 @@ func (m *FakeManager) WaitForFirstEvent() {
 	}
 }
 
+// EventCount returns the number of events that have been seen by the manager.
+func (m *FakeManager) EventCount() int {
+	m.requestTracker.cond.L.Lock()
+	defer m.requestTracker.cond.L.Unlock()
+	return m.requestTracker.added
+}
+
+// WaitForEventAfter waits until more than count events have been seen by the
+// manager, or until the timeout expires.  Unlike WaitForFirstEvent, this can be
+// used to wait for the events triggered by each step of a test.  It returns
+// true if an event was seen.
+func (m *FakeManager) WaitForEventAfter(count int, timeout time.Duration) bool {
+	timedOut := false
+	timer := time.AfterFunc(timeout, func() {
+		m.requestTracker.cond.L.Lock()
+		defer m.requestTracker.cond.L.Unlock()
+		timedOut = true
+		m.requestTracker.cond.Broadcast()
+	})
+	defer timer.Stop()
+
+	m.requestTracker.cond.L.Lock()
+	defer m.requestTracker.cond.L.Unlock()
+	for !timedOut && m.requestTracker.added <= count {
+		m.requestTracker.cond.Wait()
+	}
+	return m.requestTracker.added > count
+}
+
+// WaitForIdle waits until all reconciliations have completed and no new events
+// have been seen for the specified period.  Unlike WaitForReconcileCompletion,
+// this doesn't return early if the events caused by the last reconciliations,
+// e.g. status updates, haven't been delivered yet.
+func (m *FakeManager) WaitForIdle(period time.Duration) {
+	for {
+		m.WaitForReconcileCompletion()
+		if !m.WaitForEventAfter(m.EventCount(), period) {
+			return
+		}
+	}
+}
+
 // WaitForReconcileCompletion waits for all active reconciliations to complete.
 // This includes reconciliations that may have started after this function was
 // called, but prior to other active reconciliations completing. For example,
@@ -189,16 +237,17 @@ type requestTracker struct {
 	cond    *sync.Cond
 	count   int
 	started bool
+	// added is the total number of requests that have been added to the queues
+	added int
 }
 
 func (rt *requestTracker) Increment() {
 	rt.cond.L.Lock()
 	defer rt.cond.L.Unlock()
 	rt.count++
-	if !rt.started {
-		rt.started = true
-		rt.cond.Broadcast()
-	}
+	rt.added++
+	rt.started = true
+	rt.cond.Broadcast()
 }
 
 func (rt *requestTracker) Decrement() {
@@ -3,19 +3,27 @@ package test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes"
+	kubefake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/restmapper"
 	clienttesting "k8s.io/client-go/testing"
 
 	"github.com/maistra/istio-operator/pkg/controller/common"
 )
 
+// idlePeriod is the time without new events after which the controllers are
+// considered to have settled
+const idlePeriod = 200 * time.Millisecond
+
 // RunControllerTestCase executes each test case using a new manager.Manager
 func RunControllerTestCase(t *testing.T, testCase ControllerTestCase) {
 	t.Helper()
@@ -40,7 +48,7 @@ func RunControllerTestCase(t *testing.T, testCase ControllerTestCase) {
 	}
 
 	dc := fake.FakeDiscovery{Fake: &tracker.Fake, FakedServerVersion: DefaultKubeVersion}
-	enhancedMgr := common.NewEnhancedManager(mgr, &dc)
+	enhancedMgr := common.NewEnhancedManager(mgr, &dc, NewKubernetesClient(tracker))
 	for _, addController := range testCase.AddControllers {
 		if err := addController(enhancedMgr); err != nil {
 			t.Fatal(err)
@@ -90,12 +98,22 @@ func RunControllerTestCase(t *testing.T, testCase ControllerTestCase) {
 					// add failure for events occurring after validation should be complete
 					tracker.PrependReaction(extraneousActionFilter)
 				}
+				eventCount := mgr.EventCount()
 				if err := event.Execute(mgr, tracker); err != nil {
 					t.Error(err)
 				} else {
 					// wait for the first event to show up on the queue
 					mgr.WaitForFirstEvent()
-					if event.Verifier == nil || !event.Verifier.Wait(event.Timeout) {
+					if event.Verifier == nil {
+						// the queues may still be idle, if the event triggered by
+						// this step hasn't been delivered yet.  the assertions can
+						// only be processed once the controllers have settled.
+						mgr.WaitForEventAfter(eventCount, event.Timeout)
+						mgr.WaitForIdle(idlePeriod)
+						for _, assertion := range event.Assertions {
+							assertion.Assert(t)
+						}
+					} else if !event.Verifier.Wait(event.Timeout) {
 						// no need to process assertions if there was a problem with the event processing
 						// just need to wait for Reconcile() to complete before processing assertions
 						mgr.WaitForReconcileCompletion()
@@ -112,6 +130,21 @@ func RunControllerTestCase(t *testing.T, testCase ControllerTestCase) {
 // NewManagerForControllerTest creates a new FakeManager that can be used for running controller tests.
 // The returned EnhancedTracker is the same tracker used within the FakeManager and can be used for
 // manipulating resources without going through the manager itself.
+// NewKubernetesClient returns a clientset backed by the tracker.  Its actions
+// are not seen by the reactors registered with the tracker.
+func NewKubernetesClient(tracker clienttesting.ObjectTracker) kubernetes.Interface {
+	kubeClient := &kubefake.Clientset{}
+	kubeClient.AddReactor("*", "*", clienttesting.ObjectReaction(tracker))
+	kubeClient.AddWatchReactor("*", func(action clienttesting.Action) (bool, watch.Interface, error) {
+		watcher, err := tracker.Watch(action.GetResource(), action.GetNamespace())
+		if err != nil {
+			return false, nil, err
+		}
+		return true, watcher, nil
+	})
+	return kubeClient
+}
+
 func NewManagerForControllerTest(storageVersions []schema.GroupVersion,
 	groupResources ...*restmapper.APIGroupResources,
 ) (*FakeManager, *EnhancedTracker, error) {
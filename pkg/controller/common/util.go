@@ -20,6 +20,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
@@ -255,16 +256,18 @@ func ConvertUserInfoExtra(extra map[string]authenticationv1.ExtraValue) map[stri
 	return converted
 }
 
-func NewEnhancedManager(mgr manager.Manager, dc discovery.DiscoveryInterface) EnhancedManager {
+func NewEnhancedManager(mgr manager.Manager, dc discovery.DiscoveryInterface, kubeClient kubernetes.Interface) EnhancedManager {
 	return EnhancedManager{
-		delegate: mgr,
-		dc:       dc,
+		delegate:   mgr,
+		dc:         dc,
+		kubeClient: kubeClient,
 	}
 }
 
 type EnhancedManager struct {
-	delegate manager.Manager
-	dc       discovery.DiscoveryInterface
+	delegate   manager.Manager
+	dc         discovery.DiscoveryInterface
+	kubeClient kubernetes.Interface
 }
 
 func (m EnhancedManager) Add(runnable manager.Runnable) error {
@@ -338,3 +341,7 @@ func (m EnhancedManager) GetLogger() logr.Logger {
 func (m EnhancedManager) GetDiscoveryClient() (discovery.DiscoveryInterface, error) {
 	return m.dc, nil
 }
+
+func (m EnhancedManager) GetKubernetesClient() (kubernetes.Interface, error) {
+	return m.kubeClient, nil
+}
@@ -9,6 +9,7 @@ import (
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 
 	"github.com/maistra/istio-operator/pkg/controller/common"
 )
@@ -19,10 +20,15 @@ var CacheSyncWaitDuration = 2 * time.Second
 
 type ContextKey string
 
-const key ContextKey = "earliestReconciliationTimes"
+const (
+	key      ContextKey = "earliestReconciliationTimes"
+	clockKey ContextKey = "clock"
+)
 
-func WrapContext(ctx context.Context, earliestReconciliationTimes map[types.NamespacedName]time.Time) context.Context {
-	return context.WithValue(ctx, key, earliestReconciliationTimes)
+// WrapContext stores the earliest reconciliation times of the reconciler in
+// the context, along with the clock the reconciler compares them to
+func WrapContext(ctx context.Context, earliestReconciliationTimes map[types.NamespacedName]time.Time, clock clock.Clock) context.Context {
+	return context.WithValue(context.WithValue(ctx, key, earliestReconciliationTimes), clockKey, clock)
 }
 
 // SkipReconciliationUntilCacheSynced prevents the object from being reconciled in the next 2 seconds. Call this
@@ -39,7 +45,11 @@ func SkipReconciliationUntilCacheSynced(ctx context.Context, namespacedName type
 	if !ok {
 		panic("No earliestReconciliationTimes map in context; you must invoke hacks.WrapContext() before invoking hacks.SkipReconciliationUntilCacheSynced()")
 	}
-	earliestReconciliationTimes[namespacedName] = time.Now().Add(CacheSyncWaitDuration)
+	now := time.Now()
+	if clock, ok := ctx.Value(clockKey).(clock.Clock); ok {
+		now = clock.Now()
+	}
+	earliestReconciliationTimes[namespacedName] = now.Add(CacheSyncWaitDuration)
 }
 
 // RemoveTypeObjectFieldsFromCRDSchema works around the problem where OpenShift 3.11 doesn't like "type: object"
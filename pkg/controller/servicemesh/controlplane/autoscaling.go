@@ -0,0 +1,53 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+
+	autoscalingv2beta1 "k8s.io/api/autoscaling/v2beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// preserveAutoscaledReplicas keeps the current replicas of a Deployment that
+// is scaled by a HorizontalPodAutoscaler, e.g. because autoscaling is enabled
+// for the component in the values, so the operator doesn't revert the
+// replicas set by the autoscaler.  Otherwise, the patch would reset the
+// replicas whenever they differ from the rendered ones, or remove them if the
+// Deployment was rendered without them, and the operator and the autoscaler
+// would keep fighting over them.
+func (r *controlPlaneInstanceReconciler) preserveAutoscaledReplicas(ctx context.Context,
+	oldObj, newObj *unstructured.Unstructured,
+) (*unstructured.Unstructured, error) {
+	autoscaled, err := r.isAutoscaled(ctx, newObj)
+	if err != nil || !autoscaled {
+		return newObj, err
+	}
+	replicas, found, err := unstructured.NestedInt64(oldObj.UnstructuredContent(), "spec", "replicas")
+	if err != nil || !found {
+		return newObj, err
+	}
+	// we make a copy in case the patch fails and a full CREATE is then
+	// performed, which should use the rendered replicas
+	newObj = newObj.DeepCopy()
+	if err := unstructured.SetNestedField(newObj.UnstructuredContent(), replicas, "spec", "replicas"); err != nil {
+		return nil, err
+	}
+	return newObj, nil
+}
+
+// isAutoscaled returns true if a HorizontalPodAutoscaler in the namespace of
+// the Deployment targets it
+func (r *controlPlaneInstanceReconciler) isAutoscaled(ctx context.Context, deployment *unstructured.Unstructured) (bool, error) {
+	autoscalers := &autoscalingv2beta1.HorizontalPodAutoscalerList{}
+	if err := r.Client.List(ctx, autoscalers, client.InNamespace(deployment.GetNamespace())); err != nil {
+		return false, fmt.Errorf("could not list autoscalers in namespace %s: %s", deployment.GetNamespace(), err)
+	}
+	for _, autoscaler := range autoscalers.Items {
+		target := autoscaler.Spec.ScaleTargetRef
+		if target.Kind == deployment.GetKind() && target.Name == deployment.GetName() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
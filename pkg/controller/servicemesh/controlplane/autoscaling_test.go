@@ -0,0 +1,99 @@
+package controlplane
+
+import (
+	"testing"
+
+	autoscalingv2beta1 "k8s.io/api/autoscaling/v2beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/maistra/istio-operator/pkg/controller/common/test"
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+)
+
+func TestPreprocessObjectForPatchPreservesAutoscaledReplicas(t *testing.T) {
+	testCases := []struct {
+		name             string
+		autoscalerTarget string
+		renderedReplicas *int64
+		expectedReplicas *int64
+	}{
+		{
+			name:             "autoscaled-without-rendered-replicas",
+			autoscalerTarget: "istiod-basic",
+			expectedReplicas: int64Ptr(3),
+		},
+		{
+			name:             "autoscaled-with-rendered-replicas",
+			autoscalerTarget: "istiod-basic",
+			renderedReplicas: int64Ptr(1),
+			expectedReplicas: int64Ptr(3),
+		},
+		{
+			name:             "other-deployment-autoscaled",
+			autoscalerTarget: "istio-ingressgateway",
+			renderedReplicas: int64Ptr(1),
+			expectedReplicas: int64Ptr(1),
+		},
+		{
+			name:             "not-autoscaled",
+			renderedReplicas: int64Ptr(1),
+			expectedReplicas: int64Ptr(1),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			controlPlane := newControlPlane()
+			cl, _, r := newReconcilerTestFixture(controlPlane)
+			if tc.autoscalerTarget != "" {
+				test.PanicOnError(cl.Create(ctx, &autoscalingv2beta1.HorizontalPodAutoscaler{
+					ObjectMeta: metav1.ObjectMeta{Name: tc.autoscalerTarget, Namespace: controlPlaneNamespace},
+					Spec: autoscalingv2beta1.HorizontalPodAutoscalerSpec{
+						ScaleTargetRef: autoscalingv2beta1.CrossVersionObjectReference{
+							APIVersion: "apps/v1",
+							Kind:       "Deployment",
+							Name:       tc.autoscalerTarget,
+						},
+						MaxReplicas: 5,
+					},
+				}))
+			}
+
+			oldObj := newDeploymentObject("istiod-basic", int64Ptr(3))
+			newObj := newDeploymentObject("istiod-basic", tc.renderedReplicas)
+
+			patchObj, err := r.(*controlPlaneInstanceReconciler).preprocessObjectForPatch(ctx, oldObj, newObj)
+			assert.Success(err, "preprocessObjectForPatch", t)
+
+			replicas, found, err := unstructured.NestedInt64(patchObj.UnstructuredContent(), "spec", "replicas")
+			assert.Success(err, "NestedInt64", t)
+			if tc.expectedReplicas == nil {
+				assert.False(found, "Expected no replicas", t)
+			} else {
+				assert.True(found, "Expected replicas", t)
+				assert.Equals(replicas, *tc.expectedReplicas, "Unexpected replicas", t)
+			}
+
+			// the rendered object is left alone, as it's used to recreate the
+			// Deployment if the patch fails
+			_, found, _ = unstructured.NestedInt64(newObj.UnstructuredContent(), "spec", "replicas")
+			assert.Equals(found, tc.renderedReplicas != nil, "Expected the rendered object not to be modified", t)
+		})
+	}
+}
+
+func newDeploymentObject(name string, replicas *int64) *unstructured.Unstructured {
+	object := &unstructured.Unstructured{}
+	object.SetAPIVersion("apps/v1")
+	object.SetKind("Deployment")
+	object.SetNamespace(controlPlaneNamespace)
+	object.SetName(name)
+	if replicas != nil {
+		test.PanicOnError(unstructured.SetNestedField(object.UnstructuredContent(), *replicas, "spec", "replicas"))
+	}
+	return object
+}
+
+func int64Ptr(value int64) *int64 {
+	return &value
+}
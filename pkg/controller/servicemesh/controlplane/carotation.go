@@ -0,0 +1,362 @@
+package controlplane
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+)
+
+const (
+	eventReasonCARotation = "CARotation"
+
+	// caCertsSecretName is the secret containing the CA used by istiod to sign
+	// workload certificates
+	caCertsSecretName = "cacerts"
+	// previousCACertsSecretName is the copy of the cacerts secret taken before
+	// the CA is rotated, which can be used to roll back manually
+	previousCACertsSecretName = "cacerts-previous"
+	// rootCertConfigMapName is the ConfigMap istiod creates in every mesh
+	// namespace with the roots trusted by the workloads
+	rootCertConfigMapName = "istio-ca-root-cert"
+
+	caCertKey    = "ca-cert.pem"
+	caKeyKey     = "ca-key.pem"
+	certChainKey = "cert-chain.pem"
+	rootCertKey  = "root-cert.pem"
+
+	// defaultWorkloadCertRefreshPeriod matches istiod's default workload
+	// certificate TTL
+	defaultWorkloadCertRefreshPeriod = 24 * time.Hour
+)
+
+func caRotationConfig(smcp *v2.ServiceMeshControlPlane) *v2.CARotationConfig {
+	if smcp.Status.AppliedSpec.Security == nil {
+		return nil
+	}
+	return smcp.Status.AppliedSpec.Security.CARotation
+}
+
+// isCARotationInProgress returns true if the rotation of the intermediate CA
+// waits for istiod or the workloads
+func isCARotationInProgress(smcp *v2.ServiceMeshControlPlane) bool {
+	return caRotationConfig(smcp) != nil &&
+		(smcp.Status.CARotation == nil || smcp.Status.CARotation.Phase != v2.CARotationPhaseComplete)
+}
+
+// RotateCA advances the rotation of the intermediate CA requested in
+// spec.security.caRotation and posts its progress to status.caRotation.  It is
+// called when the control plane is fully reconciled, as the rotation updates
+// the cacerts secret and restarts istiod.
+func (r *controlPlaneInstanceReconciler) RotateCA(ctx context.Context) error {
+	if !r.updateCARotationStatus(ctx) {
+		return nil
+	}
+	return r.PostStatus(ctx)
+}
+
+// updateCARotationStatus performs the next stage of the rotation and updates
+// status.caRotation.  Each stage waits for the previous one to take effect, so
+// the rotation progresses as the control plane is rechecked.  It returns true
+// if the status was changed.
+func (r *controlPlaneInstanceReconciler) updateCARotationStatus(ctx context.Context) bool {
+	log := common.LogFromContext(ctx)
+
+	config := caRotationConfig(r.Instance)
+	if config == nil {
+		if r.Status.CARotation != nil {
+			r.Status.CARotation = nil
+			return true
+		}
+		return false
+	}
+
+	rotation := r.Status.CARotation.DeepCopy()
+	if rotation == nil || rotation.SecretName != config.SecretName {
+		rotation = &v2.CARotationStatus{SecretName: config.SecretName, Phase: v2.CARotationPhasePending}
+	}
+	previousPhase := rotation.Phase
+	if err := r.rotateCA(ctx, config, rotation, r.clock.Now()); err != nil {
+		log.Error(err, "error rotating intermediate CA", "secret", config.SecretName)
+		rotation.Message = fmt.Sprintf("Error rotating the CA: %s", err)
+	}
+	if reflect.DeepEqual(rotation, r.Status.CARotation) {
+		return false
+	}
+	if rotation.Phase != previousPhase {
+		r.EventRecorder.Event(r.Instance, corev1.EventTypeNormal, eventReasonCARotation, rotation.Message)
+	}
+	r.Status.CARotation = rotation
+	return true
+}
+
+// rotateCA performs the next stage of the rotation, if the current stage is
+// complete, and updates rotation accordingly.  The workloads must trust the
+// new root before istiod signs certificates with the new CA, and the old root
+// is only retired once all workloads received certificates signed by the new
+// CA:
+//  1. istiod keeps signing with the old CA, but trusts both roots
+//  2. once all mesh namespaces received both roots, istiod signs with the new CA
+//  3. once the workloads refreshed their certificates, the old root is retired
+func (r *controlPlaneInstanceReconciler) rotateCA(ctx context.Context, config *v2.CARotationConfig,
+	rotation *v2.CARotationStatus, now time.Time,
+) error {
+	newCA, err := r.getCASecret(ctx, config.SecretName)
+	if err != nil {
+		return err
+	}
+
+	switch rotation.Phase {
+	case v2.CARotationPhasePending:
+		caCerts, err := r.getCASecret(ctx, caCertsSecretName)
+		if err != nil {
+			return fmt.Errorf("only the CA in the %s secret can be rotated: %s", caCertsSecretName, err)
+		}
+		if err := r.backUpCASecret(ctx, caCerts, config.SecretName); err != nil {
+			return err
+		}
+		data := copyCAData(caCerts)
+		data[rootCertKey] = combineRoots(newCA.Data[rootCertKey], caCerts.Data[rootCertKey])
+		if err := r.updateCASecret(ctx, caCerts, data); err != nil {
+			return err
+		}
+		if err := r.restartIstiod(ctx, now); err != nil {
+			return err
+		}
+		rotation.Phase = v2.CARotationPhaseDistributingRoots
+		rotation.IstiodRestartedAt = &metav1.Time{Time: now}
+		rotation.Message = fmt.Sprintf("Restarting istiod to trust both the old root and the root of the CA in secret %s", config.SecretName)
+		return nil
+
+	case v2.CARotationPhaseDistributingRoots:
+		if ready, err := r.isIstiodRestarted(ctx); err != nil || !ready {
+			return err
+		}
+		// cacerts contains the combined roots staged in the previous stage
+		caCerts, err := r.getCASecret(ctx, caCertsSecretName)
+		if err != nil {
+			return err
+		}
+		combinedRoots := caCerts.Data[rootCertKey]
+		updated, total, err := r.countNamespacesTrustingRoots(ctx, combinedRoots)
+		if err != nil {
+			return err
+		}
+		rotation.UpdatedNamespaces, rotation.TotalNamespaces = updated, total
+		if updated < total {
+			rotation.Message = fmt.Sprintf("Waiting for both roots to be distributed to the mesh namespaces (%d of %d updated)", updated, total)
+			return nil
+		}
+		data := copyCAData(newCA)
+		data[rootCertKey] = combinedRoots
+		if err := r.updateCASecret(ctx, caCerts, data); err != nil {
+			return err
+		}
+		if err := r.restartIstiod(ctx, now); err != nil {
+			return err
+		}
+		rotation.Phase = v2.CARotationPhaseRefreshingCertificates
+		rotation.IstiodRestartedAt = &metav1.Time{Time: now}
+		rotation.Message = fmt.Sprintf("Restarting istiod to sign certificates with the CA in secret %s", config.SecretName)
+		return nil
+
+	case v2.CARotationPhaseRefreshingCertificates:
+		if ready, err := r.isIstiodRestarted(ctx); err != nil || !ready {
+			return err
+		}
+		refreshDeadline := rotation.IstiodRestartedAt.Add(workloadCertRefreshPeriod(config))
+		if now.Before(refreshDeadline) {
+			rotation.Message = fmt.Sprintf("Waiting until %s for the workloads to refresh their certificates",
+				refreshDeadline.UTC().Format(time.RFC3339))
+			return nil
+		}
+		caCerts, err := r.getCASecret(ctx, caCertsSecretName)
+		if err != nil {
+			return err
+		}
+		if err := r.updateCASecret(ctx, caCerts, copyCAData(newCA)); err != nil {
+			return err
+		}
+		if err := r.restartIstiod(ctx, now); err != nil {
+			return err
+		}
+		rotation.Phase = v2.CARotationPhaseRetiringOldRoot
+		rotation.IstiodRestartedAt = &metav1.Time{Time: now}
+		rotation.Message = "Restarting istiod without the old root"
+		return nil
+
+	case v2.CARotationPhaseRetiringOldRoot:
+		if ready, err := r.isIstiodRestarted(ctx); err != nil || !ready {
+			return err
+		}
+		rotation.Phase = v2.CARotationPhaseComplete
+		rotation.Message = fmt.Sprintf("The CA was rotated to the CA in secret %s and the old root was retired", config.SecretName)
+	}
+	return nil
+}
+
+// combineRoots returns a root certificate bundle containing both roots
+func combineRoots(newRoot, oldRoot []byte) []byte {
+	if bytes.Contains(newRoot, bytes.TrimSpace(oldRoot)) {
+		return append([]byte(nil), newRoot...)
+	}
+	return append(append(bytes.TrimRight(newRoot, "\n"), '\n'), oldRoot...)
+}
+
+func workloadCertRefreshPeriod(config *v2.CARotationConfig) time.Duration {
+	if period, err := time.ParseDuration(config.WorkloadCertRefreshPeriod); err == nil && period > 0 {
+		return period
+	}
+	return defaultWorkloadCertRefreshPeriod
+}
+
+func (r *controlPlaneInstanceReconciler) getCASecret(ctx context.Context, name string) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: r.Instance.Namespace, Name: name}, secret); err != nil {
+		return nil, fmt.Errorf("could not get secret %s: %s", name, err)
+	}
+	for _, key := range []string{caCertKey, caKeyKey, certChainKey, rootCertKey} {
+		if len(secret.Data[key]) == 0 {
+			return nil, fmt.Errorf("secret %s does not contain %s", name, key)
+		}
+	}
+	return secret, nil
+}
+
+func copyCAData(secret *corev1.Secret) map[string][]byte {
+	data := make(map[string][]byte, 4)
+	for _, key := range []string{caCertKey, caKeyKey, certChainKey, rootCertKey} {
+		data[key] = append([]byte(nil), secret.Data[key]...)
+	}
+	return data
+}
+
+// backUpCASecret copies the cacerts secret before the CA is rotated to the CA
+// in secret rotationSecretName.  An existing copy is kept if it was taken for
+// the same rotation, e.g. because a previous attempt to stage the new CA
+// failed, and replaced if it was taken for an earlier rotation.
+func (r *controlPlaneInstanceReconciler) backUpCASecret(ctx context.Context, caCerts *corev1.Secret, rotationSecretName string) error {
+	backup := &corev1.Secret{}
+	err := r.Client.Get(ctx, client.ObjectKey{Namespace: caCerts.Namespace, Name: previousCACertsSecretName}, backup)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("could not get secret %s: %s", previousCACertsSecretName, err)
+	}
+	exists := err == nil
+	if exists && backup.Annotations[common.CARotationSecretKey] == rotationSecretName {
+		return nil
+	}
+
+	backup.Name = previousCACertsSecretName
+	backup.Namespace = caCerts.Namespace
+	common.SetLabel(backup, common.OwnerKey, r.Instance.Namespace)
+	common.SetAnnotation(backup, common.CARotationSecretKey, rotationSecretName)
+	backup.Data = caCerts.Data
+	if exists {
+		err = r.Client.Update(ctx, backup)
+	} else {
+		backup.Type = caCerts.Type
+		err = r.Client.Create(ctx, backup)
+	}
+	if err != nil {
+		return fmt.Errorf("could not back up secret %s: %s", caCerts.Name, err)
+	}
+	return nil
+}
+
+func (r *controlPlaneInstanceReconciler) updateCASecret(ctx context.Context, caCerts *corev1.Secret, data map[string][]byte) error {
+	if caCerts.Data == nil {
+		caCerts.Data = map[string][]byte{}
+	}
+	for key, value := range data {
+		caCerts.Data[key] = value
+	}
+	if err := r.Client.Update(ctx, caCerts); err != nil {
+		return fmt.Errorf("could not update secret %s: %s", caCerts.Name, err)
+	}
+	return nil
+}
+
+func (r *controlPlaneInstanceReconciler) listIstiodDeployments(ctx context.Context) ([]appsv1.Deployment, error) {
+	deployments := &appsv1.DeploymentList{}
+	if err := r.Client.List(ctx, deployments, client.InNamespace(r.Instance.Namespace),
+		client.MatchingLabels{"app": "istiod", common.IstioRevisionKey: r.Instance.Name}); err != nil {
+		return nil, fmt.Errorf("could not list istiod deployments: %s", err)
+	}
+	if len(deployments.Items) == 0 {
+		return nil, fmt.Errorf("no istiod deployment found")
+	}
+	return deployments.Items, nil
+}
+
+// restartIstiod rolls out new istiod pods, so they load the updated cacerts
+// secret.  The annotation isn't part of the rendered charts, so it isn't
+// removed when the control plane is reconciled.
+func (r *controlPlaneInstanceReconciler) restartIstiod(ctx context.Context, now time.Time) error {
+	deployments, err := r.listIstiodDeployments(ctx)
+	if err != nil {
+		return err
+	}
+	for index := range deployments {
+		deployment := &deployments[index]
+		patch := client.MergeFrom(deployment.DeepCopy())
+		if deployment.Spec.Template.Annotations == nil {
+			deployment.Spec.Template.Annotations = map[string]string{}
+		}
+		deployment.Spec.Template.Annotations[common.CARotationRestartedAtKey] = now.UTC().Format(time.RFC3339)
+		if err := r.Client.Patch(ctx, deployment, patch); err != nil {
+			return fmt.Errorf("could not restart istiod deployment %s: %s", deployment.Name, err)
+		}
+	}
+	return nil
+}
+
+// isIstiodRestarted returns true if the rollout of the istiod pods started by
+// restartIstiod is complete
+func (r *controlPlaneInstanceReconciler) isIstiodRestarted(ctx context.Context) (bool, error) {
+	deployments, err := r.listIstiodDeployments(ctx)
+	if err != nil {
+		return false, err
+	}
+	for index := range deployments {
+		deployment := &deployments[index]
+		if !isDeploymentReady(deployment) || deployment.Status.UpdatedReplicas < deployment.Status.Replicas {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// countNamespacesTrustingRoots returns the number of mesh namespaces whose root
+// certificate ConfigMap contains roots, and the total number of mesh namespaces
+func (r *controlPlaneInstanceReconciler) countNamespacesTrustingRoots(ctx context.Context, roots []byte) (int32, int32, error) {
+	names, err := r.listMeshNamespaceNames(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	roots = bytes.TrimSpace(roots)
+	var updated int32
+	for _, name := range names {
+		configMap := &corev1.ConfigMap{}
+		if err := r.Client.Get(ctx, client.ObjectKey{Namespace: name, Name: rootCertConfigMapName}, configMap); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return 0, 0, fmt.Errorf("could not get root certificate of namespace %s: %s", name, err)
+		}
+		if bytes.Contains([]byte(configMap.Data[rootCertKey]), roots) {
+			updated++
+		}
+	}
+	return updated, int32(len(names)), nil
+}
@@ -0,0 +1,192 @@
+package controlplane
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	maistrav2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/common/test"
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+)
+
+const (
+	oldRootCert = "-----BEGIN CERTIFICATE-----\nold-root\n-----END CERTIFICATE-----\n"
+	newRootCert = "-----BEGIN CERTIFICATE-----\nnew-root\n-----END CERTIFICATE-----\n"
+)
+
+func newCASecret(name, prefix, root string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: controlPlaneNamespace},
+		Data: map[string][]byte{
+			caCertKey:    []byte(prefix + "-ca-cert"),
+			caKeyKey:     []byte(prefix + "-ca-key"),
+			certChainKey: []byte(prefix + "-cert-chain"),
+			rootCertKey:  []byte(root),
+		},
+	}
+}
+
+func newRootCertConfigMap(namespace, root string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: rootCertConfigMapName, Namespace: namespace},
+		Data:       map[string]string{rootCertKey: root},
+	}
+}
+
+func TestUpdateCARotationStatus(t *testing.T) {
+	smcp := newControlPlane()
+	smcp.Spec.Security = &maistrav2.SecurityConfig{
+		CARotation: &maistrav2.CARotationConfig{SecretName: "cacerts-new", WorkloadCertRefreshPeriod: "1h"},
+	}
+	smcp.Status.AppliedSpec = smcp.Spec
+
+	istiod := newDeployment("istiod-"+controlPlaneName, controlPlaneNamespace, "pilot", true)
+	istiod.Labels["app"] = "istiod"
+	istiod.Labels[common.IstioRevisionKey] = controlPlaneName
+	istiod.Status.UpdatedReplicas = 1
+	member := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "app-namespace",
+		Labels: map[string]string{common.MemberOfKey: controlPlaneNamespace},
+	}}
+
+	cl, _ := test.CreateClient(smcp, istiod, member,
+		newCASecret(caCertsSecretName, "old", oldRootCert),
+		newCASecret("cacerts-new", "new", newRootCert),
+		newRootCertConfigMap(controlPlaneNamespace, oldRootCert),
+		newRootCertConfigMap(member.Name, oldRootCert))
+	fakeClock := clock.NewFakeClock(time.Now())
+	r := newTestInstanceReconciler(cl, smcp)
+	r.clock = fakeClock
+
+	getSecret := func(name string) *corev1.Secret {
+		secret := &corev1.Secret{}
+		test.PanicOnError(cl.Get(ctx, client.ObjectKey{Namespace: controlPlaneNamespace, Name: name}, secret))
+		return secret
+	}
+
+	// istiod keeps signing with the old CA, but trusts both roots
+	assert.True(r.updateCARotationStatus(ctx), "Expected status to be updated", t)
+	assert.Equals(r.Status.CARotation.Phase, maistrav2.CARotationPhaseDistributingRoots, "Unexpected phase", t)
+	caCerts := getSecret(caCertsSecretName)
+	assert.Equals(string(caCerts.Data[caCertKey]), "old-ca-cert", "Expected cacerts to contain the old CA", t)
+	assert.Equals(string(caCerts.Data[rootCertKey]), newRootCert+oldRootCert, "Expected cacerts to trust both roots", t)
+	assert.Equals(string(getSecret(previousCACertsSecretName).Data[caCertKey]), "old-ca-cert", "Expected old CA to be backed up", t)
+	test.PanicOnError(cl.Get(ctx, common.ToNamespacedName(istiod), istiod))
+	restartedAt := istiod.Spec.Template.Annotations[common.CARotationRestartedAtKey]
+	assert.True(restartedAt != "", "Expected istiod to be restarted", t)
+
+	// the roots haven't been distributed yet
+	assert.True(r.updateCARotationStatus(ctx), "Expected status to be updated", t)
+	assert.Equals(r.Status.CARotation.Phase, maistrav2.CARotationPhaseDistributingRoots, "Unexpected phase", t)
+	assert.Equals(r.Status.CARotation.UpdatedNamespaces, int32(0), "Unexpected number of updated namespaces", t)
+	assert.Equals(r.Status.CARotation.TotalNamespaces, int32(2), "Unexpected number of namespaces", t)
+	assert.False(r.updateCARotationStatus(ctx), "Expected status to be unchanged", t)
+
+	// istiod signs with the new CA once all namespaces trust both roots
+	for _, namespace := range []string{controlPlaneNamespace, member.Name} {
+		test.PanicOnError(cl.Update(ctx, newRootCertConfigMap(namespace, newRootCert+oldRootCert)))
+	}
+	fakeClock.Step(time.Minute)
+	assert.True(r.updateCARotationStatus(ctx), "Expected status to be updated", t)
+	assert.Equals(r.Status.CARotation.Phase, maistrav2.CARotationPhaseRefreshingCertificates, "Unexpected phase", t)
+	assert.Equals(r.Status.CARotation.UpdatedNamespaces, int32(2), "Unexpected number of updated namespaces", t)
+	caCerts = getSecret(caCertsSecretName)
+	assert.Equals(string(caCerts.Data[caCertKey]), "new-ca-cert", "Expected cacerts to contain the new CA", t)
+	assert.Equals(string(caCerts.Data[rootCertKey]), newRootCert+oldRootCert, "Expected cacerts to trust both roots", t)
+	test.PanicOnError(cl.Get(ctx, common.ToNamespacedName(istiod), istiod))
+	assert.True(istiod.Spec.Template.Annotations[common.CARotationRestartedAtKey] != restartedAt, "Expected istiod to be restarted", t)
+
+	// the workloads haven't had time to refresh their certificates yet
+	assert.True(r.updateCARotationStatus(ctx), "Expected status to be updated", t)
+	assert.Equals(r.Status.CARotation.Phase, maistrav2.CARotationPhaseRefreshingCertificates, "Unexpected phase", t)
+	assert.True(strings.HasPrefix(r.Status.CARotation.Message, "Waiting until"), "Unexpected message: "+r.Status.CARotation.Message, t)
+	assert.False(r.updateCARotationStatus(ctx), "Expected status to be unchanged", t)
+
+	// the old root is retired once the workloads refreshed their certificates
+	fakeClock.Step(time.Hour - time.Second)
+	assert.False(r.updateCARotationStatus(ctx), "Expected status to be unchanged before the refresh period ends", t)
+	fakeClock.Step(time.Second)
+	assert.True(r.updateCARotationStatus(ctx), "Expected status to be updated", t)
+	assert.Equals(r.Status.CARotation.Phase, maistrav2.CARotationPhaseRetiringOldRoot, "Unexpected phase", t)
+	assert.Equals(string(getSecret(caCertsSecretName).Data[rootCertKey]), newRootCert, "Expected cacerts to trust only the new root", t)
+
+	assert.True(r.updateCARotationStatus(ctx), "Expected status to be updated", t)
+	assert.Equals(r.Status.CARotation.Phase, maistrav2.CARotationPhaseComplete, "Unexpected phase", t)
+	smcp.Status.CARotation = r.Status.CARotation
+	assert.False(isCARotationInProgress(smcp), "Expected rotation to be complete", t)
+}
+
+func TestUpdateCARotationStatusWithoutSecret(t *testing.T) {
+	testCases := []struct {
+		name    string
+		objects []runtime.Object
+		message string
+	}{
+		{
+			name:    "missing-new-ca",
+			objects: []runtime.Object{newCASecret(caCertsSecretName, "old", oldRootCert)},
+			message: "could not get secret cacerts-new",
+		},
+		{
+			name:    "missing-cacerts",
+			objects: []runtime.Object{newCASecret("cacerts-new", "new", newRootCert)},
+			message: "only the CA in the cacerts secret can be rotated",
+		},
+		{
+			name: "incomplete-new-ca",
+			objects: []runtime.Object{
+				newCASecret(caCertsSecretName, "old", oldRootCert),
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "cacerts-new", Namespace: controlPlaneNamespace}},
+			},
+			message: "secret cacerts-new does not contain ca-cert.pem",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			smcp := newControlPlane()
+			smcp.Spec.Security = &maistrav2.SecurityConfig{CARotation: &maistrav2.CARotationConfig{SecretName: "cacerts-new"}}
+			smcp.Status.AppliedSpec = smcp.Spec
+			cl, _ := test.CreateClient(append([]runtime.Object{smcp}, tc.objects...)...)
+			r := newTestInstanceReconciler(cl, smcp)
+
+			assert.True(r.updateCARotationStatus(ctx), "Expected status to be updated", t)
+			assert.Equals(r.Status.CARotation.Phase, maistrav2.CARotationPhasePending, "Unexpected phase", t)
+			assert.True(strings.Contains(r.Status.CARotation.Message, tc.message), "Unexpected message: "+r.Status.CARotation.Message, t)
+			assert.True(isCARotationInProgress(smcp), "Expected rotation to be in progress", t)
+		})
+	}
+}
+
+func TestBackUpCASecretOncePerRotation(t *testing.T) {
+	smcp := newControlPlane()
+	staleBackup := newCASecret(previousCACertsSecretName, "older", oldRootCert)
+	staleBackup.Annotations = map[string]string{common.CARotationSecretKey: "cacerts-earlier"}
+	cl, _ := test.CreateClient(smcp, staleBackup)
+	r := newTestInstanceReconciler(cl, smcp)
+
+	getBackup := func() *corev1.Secret {
+		secret := &corev1.Secret{}
+		test.PanicOnError(cl.Get(ctx, client.ObjectKey{Namespace: controlPlaneNamespace, Name: previousCACertsSecretName}, secret))
+		return secret
+	}
+
+	// the backup of an earlier rotation is replaced
+	assert.Success(r.backUpCASecret(ctx, newCASecret(caCertsSecretName, "old", oldRootCert), "cacerts-new"), "backUpCASecret", t)
+	backup := getBackup()
+	assert.Equals(string(backup.Data[caCertKey]), "old-ca-cert", "Expected backup to contain the current CA", t)
+	assert.Equals(backup.Annotations[common.CARotationSecretKey], "cacerts-new", "Expected backup to be tagged with the rotation", t)
+	assert.Equals(backup.Labels[common.OwnerKey], controlPlaneNamespace, "Expected backup to be owned by the control plane", t)
+
+	// the backup is kept when the same rotation is retried, as cacerts may already contain the staged roots
+	assert.Success(r.backUpCASecret(ctx, newCASecret(caCertsSecretName, "staged", newRootCert+oldRootCert), "cacerts-new"), "backUpCASecret", t)
+	assert.Equals(string(getBackup().Data[caCertKey]), "old-ca-cert", "Expected backup to be kept", t)
+}
@@ -0,0 +1,159 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/maistra/istio-operator/pkg/apis/maistra/status"
+	v2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+)
+
+const (
+	eventReasonCertificateSignerUnavailable = "CertificateSignerUnavailable"
+
+	// certificateSignerTimeout is how long a request may wait for the signer
+	// to issue the certificate, before the signer is reported as unavailable
+	certificateSignerTimeout = time.Minute
+)
+
+// certificateSigningRequestsDependency is the API used by istiod to request
+// workload certificates from the signer.  Only certificates.k8s.io/v1 supports
+// arbitrary signer names.
+var certificateSigningRequestsDependency = optionalDependency{
+	GroupVersionResource: schema.GroupVersionResource{Group: "certificates.k8s.io", Version: "v1", Resource: "certificatesigningrequests"},
+}
+
+var certificateSigningRequestListGVK = schema.GroupVersionKind{
+	Group:   "certificates.k8s.io",
+	Version: "v1",
+	Kind:    "CertificateSigningRequestList",
+}
+
+// certificateSignerName returns the name of the signer of the Kubernetes CSR
+// API that signs the workload certificates of the control plane, or an empty
+// string if istiod signs them itself
+func certificateSignerName(smcp *v2.ServiceMeshControlPlane) string {
+	security := smcp.Status.AppliedSpec.Security
+	if security == nil || security.CertificateAuthority == nil ||
+		security.CertificateAuthority.Type != v2.CertificateAuthorityTypeKubernetes ||
+		security.CertificateAuthority.Kubernetes == nil {
+		return ""
+	}
+	return security.CertificateAuthority.Kubernetes.SignerName
+}
+
+// updateCertificateSignerStatus updates the CertificateSignerAvailable
+// condition, which reports whether the signer to which istiod delegates
+// signing workload certificates issues them.  The signer is unavailable if the
+// CSR API isn't served, or if it denied, failed or didn't issue any of the
+// requests created for it.  It returns true if the status was changed.
+func (r *controlPlaneInstanceReconciler) updateCertificateSignerStatus(ctx context.Context) bool {
+	log := common.LogFromContext(ctx)
+
+	signerName := certificateSignerName(r.Instance)
+	if signerName == "" {
+		if hasCondition(&r.Status.StatusType, status.ConditionTypeCertificateSignerAvailable) {
+			r.Status.RemoveCondition(status.ConditionTypeCertificateSignerAvailable)
+			return true
+		}
+		return false
+	}
+
+	missing, err := findMissingDependencies(r.DiscoveryClient, []optionalDependency{certificateSigningRequestsDependency})
+	if err != nil {
+		log.Error(err, "error checking the Kubernetes CSR API")
+		return r.setCertificateSignerCondition(status.ConditionStatusUnknown, status.ConditionReasonProbeError,
+			fmt.Sprintf("Error checking the Kubernetes CSR API: %s", err))
+	}
+	if len(missing) > 0 {
+		return r.setCertificateSignerUnavailable(fmt.Sprintf("The Kubernetes CSR API %s is not served, so signer %s cannot issue workload certificates",
+			certificateSigningRequestsDependency.GroupVersionResource.GroupVersion(), signerName))
+	}
+
+	requests := &unstructured.UnstructuredList{}
+	requests.SetGroupVersionKind(certificateSigningRequestListGVK)
+	if err := r.Client.List(ctx, requests); err != nil {
+		log.Error(err, "error listing CertificateSigningRequests")
+		return r.setCertificateSignerCondition(status.ConditionStatusUnknown, status.ConditionReasonProbeError,
+			fmt.Sprintf("Error listing CertificateSigningRequests: %s", err))
+	}
+
+	now := r.clock.Now()
+	var problems []string
+	for index := range requests.Items {
+		request := &requests.Items[index]
+		if name, _, _ := unstructured.NestedString(request.Object, "spec", "signerName"); name != signerName {
+			continue
+		}
+		if problem := certificateSigningRequestProblem(request, now); problem != "" {
+			problems = append(problems, fmt.Sprintf("%s: %s", request.GetName(), problem))
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return r.setCertificateSignerUnavailable(fmt.Sprintf("Signer %s did not issue the following certificates: %s",
+			signerName, strings.Join(problems, "; ")))
+	}
+	return r.setCertificateSignerCondition(status.ConditionStatusTrue, status.ConditionReasonSignerAvailable,
+		fmt.Sprintf("Signer %s issues the workload certificates requested by istiod", signerName))
+}
+
+// certificateSigningRequestProblem returns why the certificate requested by the
+// CertificateSigningRequest wasn't issued, or an empty string if it was issued
+// or may still be issued
+func certificateSigningRequestProblem(request *unstructured.Unstructured, now time.Time) string {
+	if certificate, _, _ := unstructured.NestedString(request.Object, "status", "certificate"); certificate != "" {
+		return ""
+	}
+	conditions, _, _ := unstructured.NestedSlice(request.Object, "status", "conditions")
+	for _, rawCondition := range conditions {
+		condition, ok := rawCondition.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditionType, _, _ := unstructured.NestedString(condition, "type")
+		if conditionType != "Denied" && conditionType != "Failed" {
+			continue
+		}
+		if message, _, _ := unstructured.NestedString(condition, "message"); message != "" {
+			return fmt.Sprintf("%s (%s)", strings.ToLower(conditionType), message)
+		}
+		return strings.ToLower(conditionType)
+	}
+	if now.Sub(request.GetCreationTimestamp().Time) > certificateSignerTimeout {
+		return fmt.Sprintf("not issued within %s", certificateSignerTimeout)
+	}
+	return ""
+}
+
+func (r *controlPlaneInstanceReconciler) setCertificateSignerUnavailable(message string) bool {
+	updated := r.setCertificateSignerCondition(status.ConditionStatusFalse, status.ConditionReasonSignerUnavailable, message)
+	if updated {
+		r.EventRecorder.Event(r.Instance, corev1.EventTypeWarning, eventReasonCertificateSignerUnavailable, message)
+	}
+	return updated
+}
+
+func (r *controlPlaneInstanceReconciler) setCertificateSignerCondition(conditionStatus status.ConditionStatus,
+	reason status.ConditionReason, message string,
+) bool {
+	condition := r.Status.GetCondition(status.ConditionTypeCertificateSignerAvailable)
+	if hasCondition(&r.Status.StatusType, status.ConditionTypeCertificateSignerAvailable) && condition.Matches(conditionStatus, reason, message) {
+		return false
+	}
+	r.Status.SetCondition(status.Condition{
+		Type:    status.ConditionTypeCertificateSignerAvailable,
+		Status:  conditionStatus,
+		Reason:  reason,
+		Message: message,
+	})
+	return true
+}
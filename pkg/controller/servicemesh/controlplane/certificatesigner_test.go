@@ -0,0 +1,150 @@
+package controlplane
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/maistra/istio-operator/pkg/apis/maistra/status"
+	maistrav2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common/test"
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+)
+
+const testSignerName = "clusterissuers.cert-manager.io/istio-ca"
+
+var certificatesResources = &metav1.APIResourceList{
+	GroupVersion: "certificates.k8s.io/v1",
+	APIResources: []metav1.APIResource{{Name: "certificatesigningrequests", Kind: "CertificateSigningRequest"}},
+}
+
+func newCertificateSigningRequest(name, signerName string, age time.Duration, certificate string,
+	conditions ...map[string]interface{},
+) *unstructured.Unstructured {
+	request := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"signerName": signerName,
+		},
+	}}
+	request.SetGroupVersionKind(certificateSigningRequestListGVK.GroupVersion().WithKind("CertificateSigningRequest"))
+	request.SetName(name)
+	request.SetCreationTimestamp(metav1.NewTime(time.Now().Add(-age)))
+	if certificate != "" {
+		test.PanicOnError(unstructured.SetNestedField(request.Object, certificate, "status", "certificate"))
+	}
+	if len(conditions) > 0 {
+		rawConditions := make([]interface{}, len(conditions))
+		for index, condition := range conditions {
+			rawConditions[index] = condition
+		}
+		test.PanicOnError(unstructured.SetNestedSlice(request.Object, rawConditions, "status", "conditions"))
+	}
+	return request
+}
+
+func TestUpdateCertificateSignerStatus(t *testing.T) {
+	testCases := []struct {
+		name            string
+		signerName      string
+		resources       []*metav1.APIResourceList
+		requests        []runtime.Object
+		expectCondition bool
+		expectedStatus  status.ConditionStatus
+		expectedReason  status.ConditionReason
+		expectedMessage string
+		expectWarnEvent bool
+	}{
+		{
+			name:            "istiod-signs-certificates",
+			expectCondition: false,
+		},
+		{
+			name:            "csr-api-not-served",
+			signerName:      testSignerName,
+			expectCondition: true,
+			expectedStatus:  status.ConditionStatusFalse,
+			expectedReason:  status.ConditionReasonSignerUnavailable,
+			expectedMessage: "The Kubernetes CSR API certificates.k8s.io/v1 is not served, so signer " + testSignerName +
+				" cannot issue workload certificates",
+			expectWarnEvent: true,
+		},
+		{
+			name:       "certificates-issued",
+			signerName: testSignerName,
+			resources:  []*metav1.APIResourceList{certificatesResources},
+			requests: []runtime.Object{
+				newCertificateSigningRequest("issued", testSignerName, time.Hour, "Y2VydA=="),
+				newCertificateSigningRequest("pending", testSignerName, time.Second, ""),
+				newCertificateSigningRequest("other-signer", "example.com/other", time.Hour, ""),
+			},
+			expectCondition: true,
+			expectedStatus:  status.ConditionStatusTrue,
+			expectedReason:  status.ConditionReasonSignerAvailable,
+			expectedMessage: "Signer " + testSignerName + " issues the workload certificates requested by istiod",
+		},
+		{
+			name:       "certificates-not-issued",
+			signerName: testSignerName,
+			resources:  []*metav1.APIResourceList{certificatesResources},
+			requests: []runtime.Object{
+				newCertificateSigningRequest("stuck", testSignerName, time.Hour, ""),
+				newCertificateSigningRequest("failed", testSignerName, time.Second, "",
+					map[string]interface{}{"type": "Approved", "status": "True"},
+					map[string]interface{}{"type": "Failed", "status": "True", "message": "issuer not ready"}),
+			},
+			expectCondition: true,
+			expectedStatus:  status.ConditionStatusFalse,
+			expectedReason:  status.ConditionReasonSignerUnavailable,
+			expectedMessage: "Signer " + testSignerName + " did not issue the following certificates: " +
+				"failed: failed (issuer not ready); stuck: not issued within 1m0s",
+			expectWarnEvent: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			smcp := newControlPlane()
+			if tc.signerName != "" {
+				smcp.Spec.Security = &maistrav2.SecurityConfig{
+					CertificateAuthority: &maistrav2.CertificateAuthorityConfig{
+						Type:       maistrav2.CertificateAuthorityTypeKubernetes,
+						Kubernetes: &maistrav2.KubernetesCertificateAuthorityConfig{SignerName: tc.signerName},
+					},
+				}
+			}
+			smcp.Status.AppliedSpec = smcp.Spec
+
+			s := test.GetScheme()
+			s.AddKnownTypeWithName(certificateSigningRequestListGVK.GroupVersion().WithKind("CertificateSigningRequest"), &unstructured.Unstructured{})
+			s.AddKnownTypeWithName(certificateSigningRequestListGVK, &unstructured.UnstructuredList{})
+			cl, _ := test.CreateClientWithScheme(s, append([]runtime.Object{smcp}, tc.requests...)...)
+			eventRecorder := record.NewFakeRecorder(10)
+			r := newTestInstanceReconciler(cl, smcp)
+			r.EventRecorder = eventRecorder
+			r.DiscoveryClient = &fake.FakeDiscovery{Fake: &clienttesting.Fake{Resources: tc.resources}}
+
+			assert.Equals(r.updateCertificateSignerStatus(ctx), tc.expectCondition, "Unexpected status update", t)
+			assert.Equals(hasCondition(&r.Status.StatusType, status.ConditionTypeCertificateSignerAvailable), tc.expectCondition,
+				"Unexpected presence of condition", t)
+			if !tc.expectCondition {
+				return
+			}
+			condition := r.Status.GetCondition(status.ConditionTypeCertificateSignerAvailable)
+			assert.Equals(condition.Status, tc.expectedStatus, "Unexpected condition status", t)
+			assert.Equals(condition.Reason, tc.expectedReason, "Unexpected condition reason", t)
+			assert.Equals(condition.Message, tc.expectedMessage, "Unexpected condition message", t)
+			if tc.expectWarnEvent {
+				assert.Equals(len(eventRecorder.Events), 1, "Expected warning event", t)
+			} else {
+				assert.Equals(len(eventRecorder.Events), 0, "Expected no events", t)
+			}
+			assert.False(r.updateCertificateSignerStatus(ctx), "Expected status to be unchanged", t)
+		})
+	}
+}
@@ -134,6 +134,8 @@ func RunSimpleInstallTest(t *testing.T, tc IntegrationTestCase) {
 				Reactors: []clienttesting.Reactor{
 					// make sure deployments come back as ready
 					test.ReactTo("create").On("deployments").In(controlPlaneNamespace).With(SetDeploymentReady),
+					// make sure the sidecar injection webhook comes back as serving
+					test.ReactTo("create").On("services").In(controlPlaneNamespace).With(SetServiceEndpointsReady),
 					// create reasonable default Host value
 					test.ReactTo("create").On("routes").In(controlPlaneNamespace).With(SetRouteHostName(domain)),
 					// create jaeger routes and services
@@ -370,3 +372,34 @@ func SetDeploymentReady(action clienttesting.Action, tracker clienttesting.Objec
 	err = tracker.Create(action.GetResource(), deployment, action.GetNamespace())
 	return
 }
+
+// SetServiceEndpointsReady creates an Endpoints object with a ready address
+// for every Service that is created, so the sidecar injection webhook is seen
+// as serving.
+func SetServiceEndpointsReady(action clienttesting.Action, tracker clienttesting.ObjectTracker) (applied bool, handled bool, obj runtime.Object, err error) {
+	createAction := action.(clienttesting.CreateAction)
+	applied = false
+	handled = true
+	obj = createAction.GetObject()
+
+	if err = tracker.Create(action.GetResource(), obj, action.GetNamespace()); err != nil {
+		return
+	}
+	var metaobj metav1.Object
+	if metaobj, err = meta.Accessor(obj); err != nil {
+		return
+	}
+	endpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      metaobj.GetName(),
+			Namespace: metaobj.GetNamespace(),
+		},
+		Subsets: []corev1.EndpointSubset{
+			{
+				Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}},
+			},
+		},
+	}
+	err = tracker.Create(corev1.SchemeGroupVersion.WithResource("endpoints"), endpoints, action.GetNamespace())
+	return
+}
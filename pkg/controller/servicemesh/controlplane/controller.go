@@ -7,10 +7,11 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
-	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/tools/record"
@@ -29,6 +30,7 @@ import (
 	"github.com/maistra/istio-operator/pkg/controller/common"
 	"github.com/maistra/istio-operator/pkg/controller/common/cni"
 	"github.com/maistra/istio-operator/pkg/controller/hacks"
+	"github.com/maistra/istio-operator/pkg/controller/podlocality"
 )
 
 const (
@@ -53,8 +55,17 @@ func Add(mgr manager.Manager) error {
 		return err
 	}
 
-	reconciler := newReconciler(mgr.GetClient(), mgr.GetScheme(), mgr.GetEventRecorderFor(controllerName), operatorNamespace, cniConfig, dc)
-	return add(mgr, reconciler)
+	eventRecorder, err := newNotifyingEventRecorder(mgr.GetEventRecorderFor(controllerName))
+	if err != nil {
+		return err
+	}
+
+	reconciler := newReconciler(mgr.GetClient(), mgr.GetScheme(), eventRecorder, operatorNamespace, cniConfig, dc)
+	reconciler.apiReader = mgr.GetAPIReader()
+	if err := add(mgr, reconciler); err != nil {
+		return err
+	}
+	return addReadinessController(mgr, reconciler)
 }
 
 // newReconciler returns a new reconcile.Reconciler
@@ -72,8 +83,28 @@ func newReconciler(cl client.Client, scheme *runtime.Scheme, eventRecorder recor
 		cniConfig:                   cniConfig,
 		earliestReconciliationTimes: map[types.NamespacedName]time.Time{},
 		reconcilers:                 map[types.NamespacedName]ControlPlaneInstanceReconciler{},
+		instanceLocks:               map[types.NamespacedName]*sync.Mutex{},
+		readinessCache:              newReadinessCache(),
+		readinessDebouncer:          newReadinessDebouncer(),
+		clock:                       clock.RealClock{},
+		apiReader:                   cl,
+		injectedPodReader:           cl,
+		injectionTemplatesReader:    cl,
+		remoteSecretReader:          cl,
+	}
+	reconciler.instanceReconcilerFactory = func(controllerResources common.ControllerResources,
+		instance *v2.ServiceMeshControlPlane, cniConfig cni.Config,
+	) ControlPlaneInstanceReconciler {
+		instanceReconciler := NewControlPlaneInstanceReconciler(controllerResources, instance, cniConfig).(*controlPlaneInstanceReconciler)
+		instanceReconciler.readinessCache = reconciler.readinessCache
+		instanceReconciler.readinessDebouncer = reconciler.readinessDebouncer
+		instanceReconciler.clock = reconciler.clock
+		instanceReconciler.apiReader = reconciler.apiReader
+		instanceReconciler.injectedPodReader = reconciler.injectedPodReader
+		instanceReconciler.injectionTemplatesReader = reconciler.injectionTemplatesReader
+		instanceReconciler.remoteSecretReader = reconciler.remoteSecretReader
+		return instanceReconciler
 	}
-	reconciler.instanceReconcilerFactory = NewControlPlaneInstanceReconciler
 	return reconciler
 }
 
@@ -99,40 +130,139 @@ func add(mgr manager.Manager, r *ControlPlaneReconciler) error {
 		return err
 	}
 
-	// watch created resources for use in synchronizing ready status
-	if err = c.Watch(&source.Kind{Type: &appsv1.Deployment{}}, enqueueRequestForSMCP, ownedResourcePredicates); err != nil {
+	// the workloads of control planes that are being installed are watched by
+	// this controller, as it waits for them to become ready before installing
+	// the next components
+	if err = addWorkloadWatches(mgr, c, r, false); err != nil {
 		return err
 	}
-	if err = c.Watch(&source.Kind{Type: &appsv1.StatefulSet{}}, enqueueRequestForSMCP, ownedResourcePredicates); err != nil {
+
+	// watch member namespaces and injected pods for use in synchronizing the InUse condition
+	// and the list of mesh namespaces in the status
+	enqueueRequestsForMesh := func(meshNamespace string) []reconcile.Request {
+		if meshNamespace == "" {
+			return nil
+		}
+		smcpList := &v2.ServiceMeshControlPlaneList{}
+		if err := mgr.GetClient().List(ctx, smcpList, client.InNamespace(meshNamespace)); err != nil {
+			log.Error(err, "error listing ServiceMeshControlPlane objects", "namespace", meshNamespace)
+			return nil
+		}
+		requests := make([]reconcile.Request, 0, len(smcpList.Items))
+		for _, smcp := range smcpList.Items {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: common.ToNamespacedName(&smcp),
+			})
+		}
+		return requests
+	}
+	if err = c.Watch(&source.Kind{Type: &corev1.Namespace{}},
+		&handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+				return enqueueRequestsForMesh(obj.Meta.GetLabels()[common.MemberOfKey])
+			}),
+		},
+		predicate.Funcs{
+			CreateFunc: func(evt event.CreateEvent) bool { return evt.Meta.GetLabels()[common.MemberOfKey] != "" },
+			DeleteFunc: func(evt event.DeleteEvent) bool { return evt.Meta.GetLabels()[common.MemberOfKey] != "" },
+			UpdateFunc: func(evt event.UpdateEvent) bool {
+				return isMeshNamespaceChange(evt.MetaOld.GetLabels(), evt.MetaNew.GetLabels())
+			},
+			GenericFunc: func(_ event.GenericEvent) bool { return false },
+		}); err != nil {
+		return err
+	}
+	// watch the namespaces of the control planes, so their deletion is reported right away
+	if err = c.Watch(&source.Kind{Type: &corev1.Namespace{}},
+		&handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+				return enqueueRequestsForMesh(obj.Meta.GetName())
+			}),
+		},
+		predicate.Funcs{
+			CreateFunc: func(_ event.CreateEvent) bool { return false },
+			DeleteFunc: func(_ event.DeleteEvent) bool { return false },
+			UpdateFunc: func(evt event.UpdateEvent) bool {
+				return evt.MetaOld.GetDeletionTimestamp() == nil && evt.MetaNew.GetDeletionTimestamp() != nil
+			},
+			GenericFunc: func(_ event.GenericEvent) bool { return false },
+		}); err != nil {
 		return err
 	}
-	if err = c.Watch(&source.Kind{Type: &appsv1.DaemonSet{}}, enqueueRequestForSMCP, ownedResourcePredicates); err != nil {
+	// watch remote cluster secrets, so new secrets are checked without waiting for the next periodic check.
+	// Only the remote cluster secrets are cached, instead of all secrets in the cluster, and they are
+	// also read when the status of the remote secrets is updated.
+	remoteSecretInformer, err := common.NewLabelSelectedInformer(mgr, corev1.SchemeGroupVersion.WithResource("secrets"),
+		remoteSecretLabel+"=true")
+	if err != nil {
 		return err
 	}
-
-	// add watch for cni daemon set
-	operatorNamespace := common.GetOperatorNamespace()
-	if err = c.Watch(&source.Kind{Type: &appsv1.DaemonSet{}},
+	r.remoteSecretReader = &common.InformerReader{Informer: remoteSecretInformer, Resource: corev1.Resource("secrets")}
+	if err = c.Watch(&source.Informer{Informer: remoteSecretInformer},
 		&handler.EnqueueRequestsFromMapFunc{
 			ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
-				if obj.Meta.GetNamespace() != operatorNamespace {
-					return nil
-				}
-				smcpList := &v2.ServiceMeshControlPlaneList{}
-				if err := mgr.GetClient().List(ctx, smcpList); err != nil {
-					log.Error(err, "error listing ServiceMeshControlPlane objects in CNI DaemonSet watcher")
+				return enqueueRequestsForMesh(obj.Meta.GetNamespace())
+			}),
+		},
+		predicate.Funcs{
+			CreateFunc: func(evt event.CreateEvent) bool { return isRemoteSecret(evt.Meta) },
+			DeleteFunc: func(evt event.DeleteEvent) bool { return isRemoteSecret(evt.Meta) },
+			UpdateFunc: func(evt event.UpdateEvent) bool {
+				return isRemoteSecret(evt.MetaOld) || isRemoteSecret(evt.MetaNew)
+			},
+			GenericFunc: func(_ event.GenericEvent) bool { return false },
+		}); err != nil {
+		return err
+	}
+	// watch the ConfigMaps with custom injection templates, so changes are
+	// applied right away.  Only these ConfigMaps are cached.
+	injectionTemplatesInformer, err := common.NewLabelSelectedInformer(mgr, corev1.SchemeGroupVersion.WithResource("configmaps"),
+		injectionTemplatesLabel+"=true")
+	if err != nil {
+		return err
+	}
+	r.injectionTemplatesReader = &common.InformerReader{Informer: injectionTemplatesInformer, Resource: corev1.Resource("configmaps")}
+	if err = c.Watch(&source.Informer{Informer: injectionTemplatesInformer},
+		&handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+				return enqueueRequestsForMesh(obj.Meta.GetNamespace())
+			}),
+		}); err != nil {
+		return err
+	}
+	// pods come and go frequently, so their events are delayed and coalesced.
+	// Only the pods with injected sidecars are cached, instead of all pods in
+	// the cluster, and they are also used to update the InUse condition.
+	injectedPodInformer, err := common.NewLabelSelectedInformer(mgr, corev1.SchemeGroupVersion.WithResource("pods"), injectedPodLabel)
+	if err != nil {
+		return err
+	}
+	r.injectedPodReader = &common.InformerReader{Informer: injectedPodInformer, Resource: corev1.Resource("pods")}
+	if err = c.Watch(&source.Informer{Informer: injectedPodInformer},
+		&delayedEnqueueRequestsFromMapFunc{
+			Delay: inUseUpdateDelay,
+			ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+				namespace := &corev1.Namespace{}
+				if err := mgr.GetClient().Get(ctx, client.ObjectKey{Name: obj.Meta.GetNamespace()}, namespace); err != nil {
+					if !errors.IsNotFound(err) {
+						log.Error(err, "error retrieving namespace of injected pod", "namespace", obj.Meta.GetNamespace())
+					}
 					return nil
 				}
-				requests := make([]reconcile.Request, 0, len(smcpList.Items))
-				for _, smcp := range smcpList.Items {
-					requests = append(requests, reconcile.Request{
-						NamespacedName: common.ToNamespacedName(&smcp),
-					})
-				}
-				return requests
+				return enqueueRequestsForMesh(namespace.Labels[common.MemberOfKey])
 			}),
 		},
-		ownedResourcePredicates); err != nil {
+		predicate.Funcs{
+			// only the appearance or disappearance of sidecars affects the InUse condition
+			CreateFunc: func(evt event.CreateEvent) bool {
+				return evt.Meta.GetAnnotations()[podlocality.IstioSidecarStatusAnnotation] != ""
+			},
+			DeleteFunc: func(evt event.DeleteEvent) bool {
+				return evt.Meta.GetAnnotations()[podlocality.IstioSidecarStatusAnnotation] != ""
+			},
+			UpdateFunc:  func(_ event.UpdateEvent) bool { return false },
+			GenericFunc: func(_ event.GenericEvent) bool { return false },
+		}); err != nil {
 		return err
 	}
 
@@ -156,6 +286,39 @@ var enqueueRequestForSMCP = &handler.EnqueueRequestsFromMapFunc{
 	}),
 }
 
+// enqueueRequestForSMCPAndMarkDirty returns an event handler that enqueues a
+// request for the owning ServiceMeshControlPlane, like enqueueRequestForSMCP,
+// and marks the object as changed in the readiness cache, so that only the
+// changed objects need to be rechecked when the readiness is updated.  Requests
+// are only enqueued if the owner being fully reconciled matches fullyReconciled.
+func (r *ControlPlaneReconciler) enqueueRequestForSMCPAndMarkDirty(kind string, fullyReconciled bool) handler.EventHandler {
+	return &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+			var requests []reconcile.Request
+			for _, request := range enqueueRequestForSMCP.ToRequests.Map(obj) {
+				r.readinessCache.markDirty(request.NamespacedName, readinessObjectKey{
+					Kind:           kind,
+					NamespacedName: types.NamespacedName{Namespace: obj.Meta.GetNamespace(), Name: obj.Meta.GetName()},
+				})
+				if r.isInstanceFullyReconciled(request.NamespacedName) == fullyReconciled {
+					requests = append(requests, request)
+				}
+			}
+			return requests
+		}),
+	}
+}
+
+// isInstanceFullyReconciled returns true if the ServiceMeshControlPlane with
+// the specified key exists and is fully reconciled
+func (r *ControlPlaneReconciler) isInstanceFullyReconciled(key types.NamespacedName) bool {
+	instance := &v2.ServiceMeshControlPlane{}
+	if err := r.Client.Get(common.NewContext(), key, instance); err != nil {
+		return false
+	}
+	return isFullyReconciled(instance)
+}
+
 var ownedResourcePredicates = predicate.Funcs{
 	CreateFunc: func(_ event.CreateEvent) bool {
 		// we don't need to update status on create events
@@ -180,16 +343,47 @@ type ControlPlaneReconciler struct {
 	earliestReconciliationTimes map[types.NamespacedName]time.Time
 	reconcilers                 map[types.NamespacedName]ControlPlaneInstanceReconciler
 	mu                          sync.Mutex
+	readinessCache              *readinessCache
+	readinessDebouncer          *readinessDebouncer
+
+	// instanceLocks prevent the readiness controller from updating the status
+	// of an instance while it is being reconciled
+	instanceLocks map[types.NamespacedName]*sync.Mutex
 
 	instanceReconcilerFactory func(common.ControllerResources, *v2.ServiceMeshControlPlane, cni.Config) ControlPlaneInstanceReconciler
+
+	// clock is shared with the instance reconcilers, so that tests can
+	// control the passing of time
+	clock clock.Clock
+	// apiReader is shared with the instance reconcilers to read objects
+	// that aren't watched
+	apiReader client.Reader
+	// injectedPodReader is shared with the instance reconcilers to read the
+	// pods with injected sidecars
+	injectedPodReader client.Reader
+	// injectionTemplatesReader is shared with the instance reconcilers to
+	// read the ConfigMaps with custom injection templates
+	injectionTemplatesReader client.Reader
+	// remoteSecretReader is shared with the instance reconcilers to read the
+	// remote cluster secrets
+	remoteSecretReader client.Reader
 }
 
 // ControlPlaneInstanceReconciler reconciles a specific instance of a ServiceMeshControlPlane
 type ControlPlaneInstanceReconciler interface {
 	Reconcile(ctx context.Context) (reconcile.Result, error)
 	UpdateReadiness(ctx context.Context) error
+	RotateCA(ctx context.Context) error
+	ApplyGatewayClassParameters(ctx context.Context) error
+	UpdateWorkloads(ctx context.Context) error
+	VerifyTraffic(ctx context.Context) error
 	PatchAddons(ctx context.Context, spec *v2.ControlPlaneSpec) (reconcile.Result, error)
-	Delete(ctx context.Context) error
+	Delete(ctx context.Context) (reconcile.Result, error)
+	DryRun(ctx context.Context) error
+	Pause(ctx context.Context) error
+	Resume(ctx context.Context) error
+	NamespaceTerminating(ctx context.Context) error
+	DumpConfig(ctx context.Context) error
 	SetInstance(instance *v2.ServiceMeshControlPlane)
 	IsFinished() bool
 }
@@ -197,20 +391,28 @@ type ControlPlaneInstanceReconciler interface {
 // Reconcile reads that state of the cluster for a ServiceMeshControlPlane object and makes changes based on the state read
 // and what is in the ServiceMeshControlPlane.Spec
 func (r *ControlPlaneReconciler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	result, err := r.reconcile(request)
+	recordRequeue(request.NamespacedName, result, err)
+	return result, err
+}
+
+func (r *ControlPlaneReconciler) reconcile(request reconcile.Request) (reconcile.Result, error) {
 	log := createLogger().WithValues("ServiceMeshControlPlane", request)
 	ctx := common.NewReconcileContext(log)
 
 	if earliestReconciliationTime, ok := r.earliestReconciliationTimes[request.NamespacedName]; ok {
-		if earliestReconciliationTime.After(time.Now()) {
+		if now := r.clock.Now(); earliestReconciliationTime.After(now) {
 			log.Info("Skipping reconciliation of ServiceMeshControlPlane because reconciliation is paused")
 			return reconcile.Result{
 				Requeue:      true,
-				RequeueAfter: time.Until(earliestReconciliationTime),
+				RequeueAfter: earliestReconciliationTime.Sub(now),
 			}, nil
 		}
 		delete(r.earliestReconciliationTimes, request.NamespacedName)
 	}
-	ctx = hacks.WrapContext(ctx, r.earliestReconciliationTimes)
+	ctx = hacks.WrapContext(ctx, r.earliestReconciliationTimes, r.clock)
+
+	defer r.lockInstance(request.NamespacedName)()
 
 	log.Info("Processing ServiceMeshControlPlane")
 	defer func() {
@@ -227,6 +429,9 @@ func (r *ControlPlaneReconciler) Reconcile(request reconcile.Request) (reconcile
 			// Return and don't requeue
 			log.Info("ServiceMeshControlPlane deleted")
 			delete(r.earliestReconciliationTimes, request.NamespacedName)
+			r.readinessCache.invalidate(request.NamespacedName)
+			r.readinessDebouncer.reset(request.NamespacedName)
+			forgetRequeues(request.NamespacedName)
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object
@@ -240,12 +445,12 @@ func (r *ControlPlaneReconciler) Reconcile(request reconcile.Request) (reconcile
 	finalizers := sets.NewString(instance.Finalizers...)
 
 	if deleted {
+		r.readinessCache.invalidate(key)
 		if !finalizers.Has(common.FinalizerName) {
 			log.Info("Deletion of ServiceMeshControlPlane complete")
 			return reconcile.Result{}, nil
 		}
-		err := reconciler.Delete(ctx)
-		return reconcile.Result{}, err
+		return reconciler.Delete(ctx)
 	} else if !finalizers.Has(common.FinalizerName) {
 		log.V(1).Info("Adding finalizer", "finalizer", common.FinalizerName)
 		finalizers.Insert(common.FinalizerName)
@@ -254,13 +459,82 @@ func (r *ControlPlaneReconciler) Reconcile(request reconcile.Request) (reconcile
 		return reconcile.Result{}, err
 	}
 
-	if isFullyReconciled(instance) {
+	if terminating, err := isNamespaceTerminating(ctx, r.Client, instance); err != nil {
+		return common.RequeueWithError(err)
+	} else if terminating {
+		// no objects can be created in the namespace anymore, and the control
+		// plane is deleted along with it
+		log.Info("Skipping reconciliation of ServiceMeshControlPlane, as its namespace is being deleted")
+		return reconcile.Result{}, reconciler.NamespaceTerminating(ctx)
+	}
+
+	if isPaused(instance) {
+		log.Info("Skipping reconciliation of ServiceMeshControlPlane, as it is paused")
+		if err := reconciler.Pause(ctx); err != nil {
+			return common.RequeueWithError(err)
+		}
+		// the configuration can still be dumped, e.g. during incident response
+		return reconcile.Result{}, reconciler.DumpConfig(ctx)
+	} else if isPausedConditionSet(instance) {
+		log.Info("Resuming reconciliation of ServiceMeshControlPlane")
+		if err := reconciler.Resume(ctx); err != nil {
+			return common.RequeueWithError(err)
+		}
+	}
+
+	fullyReconciled := isFullyReconciled(instance)
+	if fullyReconciled {
+		appliedChecksum := instance.Status.GetAnnotation(statusAnnotationInjectionTemplatesChecksum)
+		if changed, err := r.injectionTemplatesChanged(ctx, instance.Namespace, appliedChecksum); err != nil {
+			return common.RequeueWithError(err)
+		} else if changed {
+			// the templates are applied by rendering the charts again
+			log.Info("Reconciling ServiceMeshControlPlane, as its custom injection templates changed")
+			fullyReconciled = false
+		}
+	}
+
+	if fullyReconciled {
 		if err := reconciler.UpdateReadiness(ctx); err != nil {
 			return common.RequeueWithError(err)
 		}
-		return reconciler.PatchAddons(ctx, &instance.Spec)
+		if err := reconciler.RotateCA(ctx); err != nil {
+			return common.RequeueWithError(err)
+		}
+		if err := reconciler.ApplyGatewayClassParameters(ctx); err != nil {
+			return common.RequeueWithError(err)
+		}
+		if err := reconciler.UpdateWorkloads(ctx); err != nil {
+			return common.RequeueWithError(err)
+		}
+		if err := reconciler.DumpConfig(ctx); err != nil {
+			return common.RequeueWithError(err)
+		}
+		if err := reconciler.VerifyTraffic(ctx); err != nil {
+			return common.RequeueWithError(err)
+		}
+		result, err := reconciler.PatchAddons(ctx, &instance.Spec)
+		if err == nil && !result.Requeue && result.RequeueAfter == 0 {
+			interval := recheckInterval(instance)
+			if delay := r.readinessDebouncer.remaining(key, r.clock.Now()); delay > 0 && (interval == 0 || delay < interval) {
+				// report the lost readiness once the delay expires
+				interval = delay
+			}
+			if interval > 0 {
+				return common.RequeueAfter(interval)
+			}
+		}
+		return result, err
+	}
+
+	if isDryRun(instance) {
+		log.Info("Skipping reconciliation of ServiceMeshControlPlane, as dry run is enabled")
+		return reconcile.Result{}, reconciler.DryRun(ctx)
 	}
 
+	// the reconciliation may add or remove components, so all objects are
+	// checked when the readiness is next calculated
+	r.readinessCache.invalidate(key)
 	return reconciler.Reconcile(ctx)
 }
 
@@ -269,6 +543,70 @@ func isFullyReconciled(instance *v2.ServiceMeshControlPlane) bool {
 		instance.Status.GetCondition(status.ConditionTypeReconciled).Status == status.ConditionStatusTrue
 }
 
+func hasRemoteSecretCondition(instance *v2.ServiceMeshControlPlane) bool {
+	return hasCondition(&instance.Status.StatusType, status.ConditionTypeRemoteSecretExpiring)
+}
+
+// recheckInterval returns how long to wait before the status of a fully
+// reconciled control plane is checked again, even if none of its objects
+// changed, or zero if it only needs to be checked when they change
+func recheckInterval(instance *v2.ServiceMeshControlPlane) time.Duration {
+	var interval time.Duration
+	if hasRemoteSecretCondition(instance) {
+		// periodically recheck the expiry of the remote cluster secrets
+		interval = common.Config.Controller.RemoteSecretCheckInterval
+	}
+	if usesExternalControlPlane(instance) || usesIstioCSR(instance) {
+		// the operator isn't notified when the external istiod or istio-csr,
+		// which aren't deployed for the control plane, become ready
+		if checkInterval := common.Config.Controller.ExternalControlPlaneCheckInterval; interval == 0 || checkInterval < interval {
+			interval = checkInterval
+		}
+	}
+	if certificateSignerName(instance) != "" {
+		// the operator isn't notified when the signer issues certificates again
+		if checkInterval := common.Config.Controller.CertificateSignerCheckInterval; interval == 0 || checkInterval < interval {
+			interval = checkInterval
+		}
+	}
+	if isCARotationInProgress(instance) {
+		// the operator isn't notified when the new root is distributed or the
+		// workload certificate refresh period ends
+		if checkInterval := common.Config.Controller.CARotationCheckInterval; interval == 0 || checkInterval < interval {
+			interval = checkInterval
+		}
+	}
+	if gatewayClassParameters(instance) != nil {
+		// the operator isn't notified when istiod deploys gateways
+		if checkInterval := common.Config.Controller.GatewayClassParametersCheckInterval; interval == 0 || checkInterval < interval {
+			interval = checkInterval
+		}
+	}
+	if isWorkloadUpdateInProgress(instance) {
+		// the operator isn't notified when the rollout of a restarted
+		// Deployment completes
+		if checkInterval := common.Config.Controller.WorkloadUpdateCheckInterval; interval == 0 || checkInterval < interval {
+			interval = checkInterval
+		}
+	}
+	if isTrafficVerificationInProgress(instance) {
+		// the operator isn't notified when the canary workload becomes
+		// available or the ingress gateway starts routing to it
+		if checkInterval := common.Config.Controller.TrafficVerificationCheckInterval; interval == 0 || checkInterval < interval {
+			interval = checkInterval
+		}
+	}
+	if reason := instance.Status.GetCondition(status.ConditionTypeReady).Reason; reason == status.ConditionReasonWebhookNotReady ||
+		reason == status.ConditionReasonXDSNotReady {
+		// the operator isn't notified when the endpoints of the webhook become
+		// ready or istiod starts serving
+		if checkInterval := common.Config.Controller.InjectionWebhookCheckInterval; interval == 0 || checkInterval < interval {
+			interval = checkInterval
+		}
+	}
+	return interval
+}
+
 func (r *ControlPlaneReconciler) getOrCreateReconciler(newInstance *v2.ServiceMeshControlPlane) (types.NamespacedName, ControlPlaneInstanceReconciler) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -283,6 +621,20 @@ func (r *ControlPlaneReconciler) getOrCreateReconciler(newInstance *v2.ServiceMe
 	return key, newReconciler
 }
 
+// lockInstance locks the instance with the specified key and returns a function
+// unlocking it
+func (r *ControlPlaneReconciler) lockInstance(key types.NamespacedName) func() {
+	r.mu.Lock()
+	lock, ok := r.instanceLocks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		r.instanceLocks[key] = lock
+	}
+	r.mu.Unlock()
+	lock.Lock()
+	return lock.Unlock
+}
+
 func (r *ControlPlaneReconciler) deleteReconcilerIfFinished(key types.NamespacedName, reconciler ControlPlaneInstanceReconciler) {
 	if reconciler == nil {
 		return
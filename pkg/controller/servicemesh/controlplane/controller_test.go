@@ -5,9 +5,11 @@ import (
 	"testing"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/client-go/discovery/fake"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
@@ -21,6 +23,7 @@ import (
 	"github.com/maistra/istio-operator/pkg/controller/common/test"
 	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
 	"github.com/maistra/istio-operator/pkg/controller/versions"
+	"github.com/maistra/istio-operator/pkg/featuregates"
 	"github.com/maistra/istio-operator/pkg/version"
 )
 
@@ -97,6 +100,60 @@ func TestReconcileInvokedWhenInstanceNotFullyReconciled(t *testing.T) {
 	assert.False(instanceReconciler.updateReadinessInvoked, "Expected UpdateReadiness() NOT to be invoked on instance reconciler", t)
 }
 
+func TestDryRunInvokedInsteadOfReconcileWhenDryRunAnnotationSet(t *testing.T) {
+	controlPlane := newControlPlane()
+	controlPlane.Annotations = map[string]string{common.DryRunKey: "true"}
+
+	_, _, r := createClientAndReconciler(controlPlane)
+	assertReconcileSucceeds(r, t)
+
+	assert.True(instanceReconciler.dryRunInvoked, "Expected DryRun() to be invoked on instance reconciler", t)
+	assert.False(instanceReconciler.reconcileInvoked, "Expected Reconcile() to NOT be invoked on instance reconciler", t)
+}
+
+func TestPauseInvokedInsteadOfReconcileWhenPausedAnnotationSet(t *testing.T) {
+	controlPlane := newControlPlane()
+	controlPlane.Annotations = map[string]string{common.PausedKey: "true"}
+
+	_, _, r := createClientAndReconciler(controlPlane)
+	assertReconcileSucceeds(r, t)
+
+	assert.True(instanceReconciler.pauseInvoked, "Expected Pause() to be invoked on instance reconciler", t)
+	assert.False(instanceReconciler.reconcileInvoked, "Expected Reconcile() to NOT be invoked on instance reconciler", t)
+	assert.False(instanceReconciler.updateReadinessInvoked, "Expected UpdateReadiness() to NOT be invoked on instance reconciler", t)
+}
+
+func TestResumeInvokedWhenPausedAnnotationRemoved(t *testing.T) {
+	controlPlane := newControlPlane()
+	controlPlane.Status.Conditions = append(controlPlane.Status.Conditions, status.Condition{
+		Type:   status.ConditionTypePaused,
+		Status: status.ConditionStatusTrue,
+		Reason: status.ConditionReasonPausedByUser,
+	})
+
+	_, _, r := createClientAndReconciler(controlPlane)
+	assertReconcileSucceeds(r, t)
+
+	assert.True(instanceReconciler.resumeInvoked, "Expected Resume() to be invoked on instance reconciler", t)
+	assert.True(instanceReconciler.reconcileInvoked, "Expected Reconcile() to be invoked on instance reconciler", t)
+}
+
+func TestNamespaceTerminatingInvokedInsteadOfReconcileWhenNamespaceDeleted(t *testing.T) {
+	controlPlane := newControlPlane()
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              controlPlane.Namespace,
+			DeletionTimestamp: &metav1.Time{Time: time.Now()},
+		},
+	}
+
+	_, _, r := createClientAndReconciler(controlPlane, namespace)
+	assertReconcileSucceeds(r, t)
+
+	assert.True(instanceReconciler.namespaceTerminatingInvoked, "Expected NamespaceTerminating() to be invoked on instance reconciler", t)
+	assert.False(instanceReconciler.reconcileInvoked, "Expected Reconcile() to NOT be invoked on instance reconciler", t)
+}
+
 func TestUpdateReadinessInvokedWhenInstanceFullyReconciled(t *testing.T) {
 	controlPlane := newControlPlane()
 	controlPlane.Status.OperatorVersion = version.Info.Version
@@ -112,6 +169,48 @@ func TestUpdateReadinessInvokedWhenInstanceFullyReconciled(t *testing.T) {
 	_, _, r := createClientAndReconciler(controlPlane)
 	assertReconcileSucceeds(r, t)
 
+	assert.True(instanceReconciler.updateReadinessInvoked, "Expected UpdateReadiness() to be invoked on instance reconciler", t)
+	assert.True(instanceReconciler.dumpConfigInvoked, "Expected DumpConfig() to be invoked on instance reconciler", t)
+	assert.True(instanceReconciler.verifyTrafficInvoked, "Expected VerifyTraffic() to be invoked on instance reconciler", t)
+	assert.False(instanceReconciler.reconcileInvoked, "Expected Reconcile() to NOT be invoked on instance reconciler", t)
+}
+
+func TestReconcileInvokedWhenInjectionTemplatesChanged(t *testing.T) {
+	controlPlane := newControlPlane()
+	controlPlane.Status.OperatorVersion = version.Info.Version
+	controlPlane.Status.ObservedGeneration = controlPlane.Generation
+	controlPlane.Status.Conditions = append(controlPlane.Status.Conditions, status.Condition{
+		Type:               status.ConditionTypeReconciled,
+		Status:             status.ConditionStatusTrue,
+		LastTransitionTime: oneMinuteAgo,
+	})
+	configMap := newInjectionTemplateConfigMap("custom-templates", map[string]string{"custom": "spec: {}"})
+
+	_, _, r := createClientAndReconciler(controlPlane, &configMap)
+	assertReconcileSucceeds(r, t)
+
+	assert.True(instanceReconciler.reconcileInvoked, "Expected Reconcile() to be invoked on instance reconciler", t)
+	assert.False(instanceReconciler.updateReadinessInvoked, "Expected UpdateReadiness() to NOT be invoked on instance reconciler", t)
+}
+
+func TestInjectionTemplatesIgnoredWhenFeatureDisabled(t *testing.T) {
+	assert.Success(featuregates.Default.Set("CustomInjectionTemplates=false"), "Set", t)
+	defer func() {
+		test.PanicOnError(featuregates.Default.Set("CustomInjectionTemplates=true"))
+	}()
+	controlPlane := newControlPlane()
+	controlPlane.Status.OperatorVersion = version.Info.Version
+	controlPlane.Status.ObservedGeneration = controlPlane.Generation
+	controlPlane.Status.Conditions = append(controlPlane.Status.Conditions, status.Condition{
+		Type:               status.ConditionTypeReconciled,
+		Status:             status.ConditionStatusTrue,
+		LastTransitionTime: oneMinuteAgo,
+	})
+	configMap := newInjectionTemplateConfigMap("custom-templates", map[string]string{"custom": "spec: {}"})
+
+	_, _, r := createClientAndReconciler(controlPlane, &configMap)
+	assertReconcileSucceeds(r, t)
+
 	assert.True(instanceReconciler.updateReadinessInvoked, "Expected UpdateReadiness() to be invoked on instance reconciler", t)
 	assert.False(instanceReconciler.reconcileInvoked, "Expected Reconcile() to NOT be invoked on instance reconciler", t)
 }
@@ -143,10 +242,16 @@ func createClientAndReconciler(clientObjects ...runtime.Object) (client.Client,
 }
 
 type fakeInstanceReconciler struct {
-	reconcileInvoked       bool
-	updateReadinessInvoked bool
-	deleteInvoked          bool
-	finished               bool
+	reconcileInvoked            bool
+	updateReadinessInvoked      bool
+	deleteInvoked               bool
+	dryRunInvoked               bool
+	pauseInvoked                bool
+	resumeInvoked               bool
+	namespaceTerminatingInvoked bool
+	dumpConfigInvoked           bool
+	verifyTrafficInvoked        bool
+	finished                    bool
 }
 
 func NewFakeInstanceReconciler(_ common.ControllerResources, _ *maistrav2.ServiceMeshControlPlane, _ cni.Config) ControlPlaneInstanceReconciler {
@@ -163,13 +268,55 @@ func (r *fakeInstanceReconciler) UpdateReadiness(ctx context.Context) error {
 	return nil
 }
 
+func (r *fakeInstanceReconciler) RotateCA(ctx context.Context) error {
+	return nil
+}
+
+func (r *fakeInstanceReconciler) ApplyGatewayClassParameters(ctx context.Context) error {
+	return nil
+}
+
+func (r *fakeInstanceReconciler) UpdateWorkloads(ctx context.Context) error {
+	return nil
+}
+
+func (r *fakeInstanceReconciler) VerifyTraffic(ctx context.Context) error {
+	r.verifyTrafficInvoked = true
+	return nil
+}
+
 func (r *fakeInstanceReconciler) PatchAddons(ctx context.Context, _ *maistrav2.ControlPlaneSpec) (reconcile.Result, error) {
 	r.updateReadinessInvoked = true
 	return common.Reconciled()
 }
 
-func (r *fakeInstanceReconciler) Delete(ctx context.Context) error {
+func (r *fakeInstanceReconciler) Delete(ctx context.Context) (reconcile.Result, error) {
 	r.deleteInvoked = true
+	return common.Reconciled()
+}
+
+func (r *fakeInstanceReconciler) DryRun(ctx context.Context) error {
+	r.dryRunInvoked = true
+	return nil
+}
+
+func (r *fakeInstanceReconciler) Pause(ctx context.Context) error {
+	r.pauseInvoked = true
+	return nil
+}
+
+func (r *fakeInstanceReconciler) Resume(ctx context.Context) error {
+	r.resumeInvoked = true
+	return nil
+}
+
+func (r *fakeInstanceReconciler) NamespaceTerminating(ctx context.Context) error {
+	r.namespaceTerminatingInvoked = true
+	return nil
+}
+
+func (r *fakeInstanceReconciler) DumpConfig(ctx context.Context) error {
+	r.dumpConfigInvoked = true
 	return nil
 }
 
@@ -212,3 +359,21 @@ func newControlPlane() *maistrav2.ServiceMeshControlPlane {
 		Status: maistrav2.ControlPlaneStatus{},
 	}
 }
+
+func TestReconcileSkippedUntilEarliestReconciliationTime(t *testing.T) {
+	controlPlane := newControlPlane()
+
+	_, _, r := createClientAndReconciler(controlPlane)
+	fakeClock := clock.NewFakeClock(time.Now())
+	r.clock = fakeClock
+	r.earliestReconciliationTimes[request.NamespacedName] = fakeClock.Now().Add(2 * time.Second)
+
+	result, err := r.Reconcile(request)
+	assert.Nil(err, "Unexpected error", t)
+	assert.Equals(result.RequeueAfter, 2*time.Second, "Unexpected requeue interval", t)
+	assert.False(instanceReconciler.reconcileInvoked, "Expected Reconcile() NOT to be invoked on instance reconciler", t)
+
+	fakeClock.Step(2 * time.Second)
+	assertReconcileSucceeds(r, t)
+	assert.True(instanceReconciler.reconcileInvoked, "Expected Reconcile() to be invoked on instance reconciler", t)
+}
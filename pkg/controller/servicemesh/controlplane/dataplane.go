@@ -0,0 +1,98 @@
+package controlplane
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/podlocality"
+)
+
+// proxyImageVersion returns the tag of the image, or an empty string if the
+// image is referenced by digest or has no tag
+func proxyImageVersion(image string) string {
+	if strings.Contains(image, "@") {
+		return ""
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	if index := strings.LastIndex(name, ":"); index >= 0 {
+		return name[index+1:]
+	}
+	return ""
+}
+
+// updateDataPlaneStatus counts the pods with injected sidecars in the mesh
+// namespaces by revision and proxy image in status.dataPlane.  Like the mesh
+// namespaces, it's kept up to date as injected pods come and go.  It returns
+// true if the status was changed.
+func (r *controlPlaneInstanceReconciler) updateDataPlaneStatus(ctx context.Context) bool {
+	log := common.LogFromContext(ctx)
+
+	names, err := r.listMeshNamespaceNames(ctx)
+	if err != nil {
+		// keep the last known counts
+		log.Error(err, "error listing mesh namespaces")
+		return false
+	}
+
+	type proxiesKey struct {
+		revision, image string
+	}
+	expectedImage := expectedProxyImage(r.Instance.Status.AppliedValues.Istio)
+	dataPlane := &v2.DataPlaneStatus{}
+	pods := map[proxiesKey]int32{}
+	for _, name := range names {
+		podList := &corev1.PodList{}
+		if err := r.injectedPodReader.List(ctx, podList, client.InNamespace(name), client.HasLabels{injectedPodLabel}); err != nil {
+			log.Error(err, "error listing injected pods", "namespace", name)
+			return false
+		}
+		for index := range podList.Items {
+			pod := &podList.Items[index]
+			if pod.Annotations[podlocality.IstioSidecarStatusAnnotation] == "" {
+				continue
+			}
+			for _, container := range pod.Spec.Containers {
+				if container.Name != proxyContainerName {
+					continue
+				}
+				revision := pod.Labels[common.IstioRevisionKey]
+				pods[proxiesKey{revision: revision, image: container.Image}]++
+				dataPlane.Total++
+				if expectedImage != "" && container.Image != expectedImage &&
+					(revision == "" || revision == r.Instance.Name) {
+					dataPlane.Outdated++
+				}
+			}
+		}
+	}
+	for key, count := range pods {
+		dataPlane.Proxies = append(dataPlane.Proxies, v2.DataPlaneProxiesStatus{
+			Revision:   key.revision,
+			ProxyImage: key.image,
+			Version:    proxyImageVersion(key.image),
+			Current:    key.image == expectedImage,
+			Pods:       count,
+		})
+	}
+	sort.Slice(dataPlane.Proxies, func(i, j int) bool {
+		if dataPlane.Proxies[i].Revision != dataPlane.Proxies[j].Revision {
+			return dataPlane.Proxies[i].Revision < dataPlane.Proxies[j].Revision
+		}
+		return dataPlane.Proxies[i].ProxyImage < dataPlane.Proxies[j].ProxyImage
+	})
+	if dataPlane.Total == 0 {
+		dataPlane = nil
+	}
+	if reflect.DeepEqual(r.Status.DataPlane, dataPlane) {
+		return false
+	}
+	r.Status.DataPlane = dataPlane
+	return true
+}
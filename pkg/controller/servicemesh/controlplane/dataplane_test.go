@@ -0,0 +1,55 @@
+package controlplane
+
+import (
+	"testing"
+
+	maistrav2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common/test"
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+)
+
+func TestProxyImageVersion(t *testing.T) {
+	cases := map[string]string{
+		"registry.example.com/maistra/proxyv2:2.4.0":      "2.4.0",
+		"registry.example.com:5000/maistra/proxyv2:2.4.0": "2.4.0",
+		"registry.example.com:5000/maistra/proxyv2":       "",
+		"quay.io/maistra/proxyv2-ubi8@sha256:1234":        "",
+		"proxyv2": "",
+	}
+	for image, expected := range cases {
+		assert.Equals(proxyImageVersion(image), expected, "Unexpected version of "+image, t)
+	}
+}
+
+func TestUpdateDataPlaneStatus(t *testing.T) {
+	smcp := newWorkloadUpdateControlPlane(false)
+	member := newMemberNamespace("app-namespace", controlPlaneNamespace)
+	cl, _ := test.CreateClient(smcp, member,
+		newDeploymentPod(member.Name, "details", oldProxyImage, ""),
+		newDeploymentPod(member.Name, "ratings", newProxyImage, ""),
+		newDeploymentPod(member.Name, "reviews", newProxyImage, ""),
+		withRevision(newDeploymentPod(member.Name, "canary", oldProxyImage, ""), "canary"),
+		newTestPod(member.Name, "not-injected", false),
+		newDeploymentPod(controlPlaneNamespace, "sleep", oldProxyImage, ""))
+	r := newTestInstanceReconciler(cl, smcp)
+
+	assert.True(r.updateDataPlaneStatus(ctx), "Expected status to be updated", t)
+	assert.DeepEquals(r.Status.DataPlane, &maistrav2.DataPlaneStatus{
+		Total:    5,
+		Outdated: 2,
+		Proxies: []maistrav2.DataPlaneProxiesStatus{
+			{ProxyImage: oldProxyImage, Version: "2.3.0", Pods: 2},
+			{ProxyImage: newProxyImage, Version: "2.4.0", Current: true, Pods: 2},
+			{Revision: "canary", ProxyImage: oldProxyImage, Version: "2.3.0", Pods: 1},
+		},
+	}, "Unexpected data plane status", t)
+	assert.False(r.updateDataPlaneStatus(ctx), "Expected status to be unchanged", t)
+
+	// the status is removed once no injected pods are left
+	for _, name := range []string{"details", "ratings", "reviews", "canary"} {
+		test.PanicOnError(cl.Delete(ctx, newDeploymentPod(member.Name, name, "", "")))
+	}
+	test.PanicOnError(cl.Delete(ctx, newDeploymentPod(controlPlaneNamespace, "sleep", "", "")))
+	assert.True(r.updateDataPlaneStatus(ctx), "Expected status to be updated", t)
+	assert.True(r.Status.DataPlane == nil, "Expected status.dataPlane to be removed", t)
+}
@@ -0,0 +1,142 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+)
+
+const (
+	// istiodDebugPort is the port on which istiod serves its debug endpoints
+	istiodDebugPort = 15014
+
+	// configDumpPath is the debug endpoint of istiod that returns the Istio
+	// configuration istiod has received
+	configDumpPath = "/debug/configz"
+
+	// configDumpKey is the key of the config dump in the ConfigMap
+	configDumpKey = "configz.json"
+
+	// maxConfigDumpSize keeps the config dump below the size limit of
+	// ConfigMaps; larger dumps are truncated
+	maxConfigDumpSize = 900 * 1024
+
+	// istiodDebugTimeout bounds requests to the debug endpoints of istiod, as
+	// they are performed while the control plane is being reconciled
+	istiodDebugTimeout = 10 * time.Second
+
+	// configDumpTruncatedKey is set to "true" on the ConfigMap if the config
+	// dump was truncated
+	configDumpTruncatedKey = common.MetadataNamespace + "/config-dump-truncated"
+
+	eventReasonConfigDump = "ConfigDump"
+)
+
+// istiodDebugFetcher returns the response of the debug endpoint of istiod at
+// the given URL, limited to maxSize bytes
+type istiodDebugFetcher func(ctx context.Context, url string, maxSize int64) ([]byte, error)
+
+var fetchIstiodDebug istiodDebugFetcher = func(ctx context.Context, url string, maxSize int64) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, istiodDebugTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response from %s: %s", url, resp.Status)
+	}
+	return ioutil.ReadAll(io.LimitReader(resp.Body, maxSize))
+}
+
+// configDumpRequest returns the value of the config-dump annotation, which
+// identifies the requested dump, or an empty string if no dump is requested
+func configDumpRequest(instance *v2.ServiceMeshControlPlane) string {
+	value, _ := common.GetAnnotation(instance, common.ConfigDumpKey)
+	return value
+}
+
+func configDumpConfigMapName(instance *v2.ServiceMeshControlPlane) string {
+	return fmt.Sprintf("istiod-%s-config-dump", instance.GetName())
+}
+
+// DumpConfig stores the configuration istiod has received in a ConfigMap, if
+// requested with the config-dump annotation.  Each value of the annotation
+// is only dumped once, so a new dump is requested by changing the value, e.g.
+// to the current time.  The value of the dump is recorded in the same
+// annotation on the ConfigMap.
+func (r *controlPlaneInstanceReconciler) DumpConfig(ctx context.Context) error {
+	request := configDumpRequest(r.Instance)
+	if request == "" {
+		return nil
+	}
+	log := common.LogFromContext(ctx)
+
+	configMap := &corev1.ConfigMap{}
+	key := common.ToNamespacedName(r.Instance)
+	key.Name = configDumpConfigMapName(r.Instance)
+	err := r.Client.Get(ctx, key, configMap)
+	exists := err == nil
+	if exists {
+		if dumped, _ := common.GetAnnotation(configMap, common.ConfigDumpKey); dumped == request {
+			return nil
+		}
+	} else if !errors.IsNotFound(err) {
+		return err
+	}
+
+	if usesExternalControlPlane(r.Instance) {
+		r.EventRecorder.Event(r.Instance, corev1.EventTypeWarning, eventReasonConfigDump,
+			"Configuration cannot be dumped, as istiod isn't deployed for the control plane")
+		return nil
+	}
+
+	log.Info("Dumping istiod configuration", "ConfigMap", key.Name)
+	url := fmt.Sprintf("http://istiod-%s.%s.svc:%d%s", r.Instance.GetName(), r.Instance.GetNamespace(),
+		istiodDebugPort, configDumpPath)
+	data, err := fetchIstiodDebug(ctx, url, maxConfigDumpSize+1)
+	if err != nil {
+		r.EventRecorder.Event(r.Instance, corev1.EventTypeWarning, eventReasonConfigDump,
+			fmt.Sprintf("Error dumping istiod configuration: %s", err))
+		return fmt.Errorf("error dumping istiod configuration: %s", err)
+	}
+	truncated := len(data) > maxConfigDumpSize
+	if truncated {
+		data = data[:maxConfigDumpSize]
+	}
+
+	configMap.SetName(key.Name)
+	configMap.SetNamespace(key.Namespace)
+	configMap.SetOwnerReferences([]metav1.OwnerReference{
+		*metav1.NewControllerRef(r.Instance, v2.SchemeGroupVersion.WithKind("ServiceMeshControlPlane")),
+	})
+	common.SetAnnotation(configMap, common.ConfigDumpKey, request)
+	common.SetAnnotation(configMap, configDumpTruncatedKey, fmt.Sprint(truncated))
+	configMap.Data = map[string]string{configDumpKey: string(data)}
+	if exists {
+		err = r.Client.Update(ctx, configMap)
+	} else {
+		err = r.Client.Create(ctx, configMap)
+	}
+	if err != nil {
+		return err
+	}
+	r.EventRecorder.Event(r.Instance, corev1.EventTypeNormal, eventReasonConfigDump,
+		fmt.Sprintf("Dumped istiod configuration to ConfigMap %s", key.Name))
+	return nil
+}
@@ -0,0 +1,71 @@
+package controlplane
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/common/test"
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+)
+
+func TestDumpConfig(t *testing.T) {
+	var fetchedURLs []string
+	response := `{"kind": "VirtualService"}`
+	defer func(original istiodDebugFetcher) { fetchIstiodDebug = original }(fetchIstiodDebug)
+	fetchIstiodDebug = func(_ context.Context, url string, maxSize int64) ([]byte, error) {
+		fetchedURLs = append(fetchedURLs, url)
+		data := []byte(response)
+		if int64(len(data)) > maxSize {
+			data = data[:maxSize]
+		}
+		return data, nil
+	}
+
+	controlPlane := newControlPlane()
+	controlPlane.Annotations = map[string]string{common.ConfigDumpKey: "1"}
+	cl, _, r := newReconcilerTestFixture(controlPlane)
+
+	assert.Success(r.DumpConfig(ctx), "DumpConfig", t)
+	assert.DeepEquals(fetchedURLs, []string{"http://istiod-" + controlPlane.Name + "." + controlPlane.Namespace + ".svc:15014/debug/configz"},
+		"unexpected requests to istiod", t)
+	configMap := &corev1.ConfigMap{}
+	key := client.ObjectKey{Namespace: controlPlane.Namespace, Name: configDumpConfigMapName(controlPlane)}
+	test.PanicOnError(cl.Get(ctx, key, configMap))
+	assert.Equals(configMap.Data[configDumpKey], response, "unexpected config dump", t)
+	assert.Equals(configMap.Annotations[common.ConfigDumpKey], "1", "unexpected config dump annotation", t)
+	assert.Equals(configMap.Annotations[configDumpTruncatedKey], "false", "unexpected truncated annotation", t)
+	assert.Equals(len(configMap.OwnerReferences), 1, "expected the control plane to own the ConfigMap", t)
+
+	// the same request is only dumped once
+	assert.Success(r.DumpConfig(ctx), "DumpConfig", t)
+	assert.Equals(len(fetchedURLs), 1, "expected istiod not to be queried again", t)
+
+	// changing the annotation requests a new dump, which is truncated if it
+	// doesn't fit into the ConfigMap
+	response = strings.Repeat("x", maxConfigDumpSize+10)
+	controlPlane.Annotations[common.ConfigDumpKey] = "2"
+	r.SetInstance(controlPlane)
+	assert.Success(r.DumpConfig(ctx), "DumpConfig", t)
+	assert.Equals(len(fetchedURLs), 2, "expected istiod to be queried again", t)
+	test.PanicOnError(cl.Get(ctx, key, configMap))
+	assert.Equals(len(configMap.Data[configDumpKey]), maxConfigDumpSize, "expected the config dump to be truncated", t)
+	assert.Equals(configMap.Annotations[common.ConfigDumpKey], "2", "unexpected config dump annotation", t)
+	assert.Equals(configMap.Annotations[configDumpTruncatedKey], "true", "unexpected truncated annotation", t)
+}
+
+func TestDumpConfigNotRequested(t *testing.T) {
+	defer func(original istiodDebugFetcher) { fetchIstiodDebug = original }(fetchIstiodDebug)
+	fetchIstiodDebug = func(_ context.Context, url string, _ int64) ([]byte, error) {
+		t.Fatalf("unexpected request to %s", url)
+		return nil, nil
+	}
+
+	_, tracker, r := newReconcilerTestFixture(newControlPlane())
+	assert.Success(r.DumpConfig(ctx), "DumpConfig", t)
+	test.AssertNumberOfWriteActions(t, tracker.Actions(), 0)
+}
@@ -3,11 +3,16 @@ package controlplane
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	errors "github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/maistra/istio-operator/pkg/apis/maistra/status"
 	maistrav2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
@@ -16,11 +21,17 @@ import (
 	"github.com/maistra/istio-operator/pkg/controller/versions"
 )
 
-func (r *controlPlaneInstanceReconciler) Delete(ctx context.Context) error {
+func (r *controlPlaneInstanceReconciler) Delete(ctx context.Context) (reconcile.Result, error) {
 	log := common.LogFromContext(ctx)
 
+	// a previous deletion attempt that failed, or that succeeded but couldn't
+	// remove the finalizer, is retried without resetting the status, so the
+	// error stays visible until deletion succeeds
 	reconciledCondition := r.Status.GetCondition(status.ConditionTypeReconciled)
-	if reconciledCondition.Status != status.ConditionStatusFalse || reconciledCondition.Reason != status.ConditionReasonDeleting {
+	deletionStarted := reconciledCondition.Reason == status.ConditionReasonDeleting ||
+		reconciledCondition.Reason == status.ConditionReasonDeletionError ||
+		reconciledCondition.Reason == status.ConditionReasonDeleted
+	if !deletionStarted {
 		r.Status.SetCondition(status.Condition{
 			Type:    status.ConditionTypeReconciled,
 			Status:  status.ConditionStatusFalse,
@@ -33,16 +44,38 @@ func (r *controlPlaneInstanceReconciler) Delete(ctx context.Context) error {
 			Reason:  status.ConditionReasonDeleting,
 			Message: "Deleting service mesh",
 		})
+		r.Status.SetCondition(status.Condition{
+			Type:    status.ConditionTypeTerminating,
+			Status:  status.ConditionStatusTrue,
+			Reason:  status.ConditionReasonDeleting,
+			Message: "Deleting service mesh",
+		})
+		for i := range r.Status.ComponentStatus {
+			r.Status.ComponentStatus[i].SetCondition(status.Condition{
+				Type:    status.ConditionTypeReconciled,
+				Status:  status.ConditionStatusFalse,
+				Reason:  status.ConditionReasonDeleting,
+				Message: "Deleting component",
+			})
+		}
 
 		err := r.PostStatus(ctx)
-		return err // return regardless of error; deletion will continue when update event comes back into the operator
+		return reconcile.Result{}, err // return regardless of error; deletion will continue when update event comes back into the operator
+	}
+
+	// keep istiod running while workloads still use it, so their sidecars
+	// keep working
+	if blockedFor, err := r.checkDeletionBlocked(ctx); err != nil || blockedFor > 0 {
+		return reconcile.Result{RequeueAfter: blockedFor}, err
 	}
 
 	log.Info("Deleting ServiceMeshControlPlane")
 
 	// delete resources owned by the SMCP
 	r.EventRecorder.Event(r.Instance, corev1.EventTypeNormal, eventReasonDeleting, "Deleting service mesh")
-	err := r.prune(ctx, "")
+	deletionErrors := componentDeletionErrors{}
+	err := r.prune(ctx, "", deletionErrors)
+	r.updateComponentDeletionStatus(deletionErrors, err != nil)
 	if err == nil {
 		r.EventRecorder.Event(r.Instance, corev1.EventTypeNormal, eventReasonDeleted,
 			"Successfully deleted service mesh resources")
@@ -51,33 +84,43 @@ func (r *controlPlaneInstanceReconciler) Delete(ctx context.Context) error {
 			fmt.Sprintf("Error deleting service mesh resources: %s", err))
 	}
 
-	// get smcp version for ns label deletion.
-	var version versions.Version
-	version, err = versions.ParseVersion(r.Instance.Spec.Version)
-	if err != nil {
-		log.Error(err, "invalid version specified")
-		return nil
-	}
+	// remove namespace labels; this is done even if pruning failed, as it is
+	// safe to repeat on the next attempt
+	err = utilerrors.NewAggregate([]error{err, r.cleanupNamespaceLabels(ctx)})
 
-	// remove namespace labels
-	if err == nil {
-		err = removeNamespaceLabels(ctx, r.Client, r.Instance.Namespace, version)
+	// stop retrying once the deletion timeout expired, so the finalizer
+	// doesn't keep the control plane from being deleted forever
+	deletedReason, deletedMessage := status.ConditionReasonDeleted, "Service mesh deleted, removing finalizer"
+	if err != nil && r.deletionTimedOut() {
+		deletedReason = status.ConditionReasonDeletionForced
+		deletedMessage = fmt.Sprintf("Deletion forced after %s, removing finalizer; resources may have been left behind: %s",
+			common.Config.Controller.DeletionTimeout, err)
+		log.Info("Deletion timed out, removing finalizer", "error", err.Error())
+		r.EventRecorder.Event(r.Instance, corev1.EventTypeWarning, eventReasonDeletionForced, deletedMessage)
+		err = nil
 	}
 
 	// update SMCP status and stop reconciling if there was an error
 	if err != nil {
+		message := fmt.Sprintf("Error deleting service mesh: %s", err)
 		r.Status.SetCondition(status.Condition{
 			Type:    status.ConditionTypeReconciled,
 			Status:  status.ConditionStatusFalse,
 			Reason:  status.ConditionReasonDeletionError,
-			Message: fmt.Sprintf("Error deleting service mesh: %s", err),
+			Message: message,
+		})
+		r.Status.SetCondition(status.Condition{
+			Type:    status.ConditionTypeTerminating,
+			Status:  status.ConditionStatusTrue,
+			Reason:  status.ConditionReasonDeletionError,
+			Message: message,
 		})
 		statusErr := r.PostStatus(ctx)
 		if statusErr != nil {
 			// we must return the original error, thus we can only log the status update error
 			log.Error(statusErr, "Error updating status")
 		}
-		return err
+		return reconcile.Result{}, err
 	}
 
 	// set reconcile status to true to ensure reconciler is deleted from the cache
@@ -87,30 +130,167 @@ func (r *controlPlaneInstanceReconciler) Delete(ctx context.Context) error {
 		Reason:  status.ConditionReasonDeleted,
 		Message: "Service mesh deleted",
 	})
+	r.Status.SetCondition(status.Condition{
+		Type:    status.ConditionTypeTerminating,
+		Status:  status.ConditionStatusTrue,
+		Reason:  deletedReason,
+		Message: deletedMessage,
+	})
+	// post the per-component results before the finalizer is removed
+	if err := r.PostStatus(ctx); err != nil {
+		return reconcile.Result{}, err
+	}
 
 	// remove finalizer from SMCP
-	// get fresh SMCP from cache to minimize the chance of a conflict during update
-	// (the SMCP might have been updated during the execution of reconciler.Delete())
+	// get fresh SMCP from cache and patch it, so the status update above
+	// doesn't cause a conflict
 	instance := &maistrav2.ServiceMeshControlPlane{}
 	smcpNamespacedName := common.ToNamespacedName(r.Instance)
 	if err := r.Client.Get(ctx, smcpNamespacedName, instance); err == nil {
+		patch := client.MergeFrom(instance.DeepCopy())
 		finalizers := sets.NewString(instance.Finalizers...)
 		finalizers.Delete(common.FinalizerName)
 		instance.SetFinalizers(finalizers.List())
-		if err := r.Client.Update(ctx, instance); err == nil {
+		if err := r.Client.Patch(ctx, instance, patch); err == nil {
 			log.Info("Removed finalizer")
 			hacks.SkipReconciliationUntilCacheSynced(ctx, smcpNamespacedName)
 		} else if !apierrors.IsNotFound(err) {
 			// TODO: this event probably isn't needed at all
 			r.EventRecorder.Event(instance, corev1.EventTypeWarning, eventReasonFailedRemovingFinalizer,
 				fmt.Sprintf("Error occurred removing finalizer from service mesh: %s", err))
-			return errors.Wrap(err, "error removing ServiceMeshControlPlane finalizer")
+			return reconcile.Result{}, errors.Wrap(err, "error removing ServiceMeshControlPlane finalizer")
 		}
 	} else if !apierrors.IsNotFound(err) {
 		r.EventRecorder.Event(instance, corev1.EventTypeWarning, eventReasonFailedRemovingFinalizer,
 			fmt.Sprintf("Error occurred removing finalizer from service mesh: %s", err))
-		return errors.Wrap(err, "error getting ServiceMeshControlPlane prior to removing finalizer")
+		return reconcile.Result{}, errors.Wrap(err, "error getting ServiceMeshControlPlane prior to removing finalizer")
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// checkDeletionBlocked blocks the deletion of the control plane while
+// workloads in its member namespaces still use it, until the
+// DeletionBlockedTimeout has passed since the deletion was requested.  The
+// DeletionBlocked condition reports why deletion is blocked and until when.
+// It returns how long deletion remains blocked, or zero if the control plane
+// can be deleted.  Pods coming and going trigger a reconciliation, so the
+// deletion proceeds as soon as the last workload is gone.
+func (r *controlPlaneInstanceReconciler) checkDeletionBlocked(ctx context.Context) (time.Duration, error) {
+	timeout := common.Config.Controller.DeletionBlockedTimeout
+	if timeout <= 0 {
+		return 0, nil
+	}
+	namespaces, err := r.findNamespacesUsingControlPlane(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(namespaces) == 0 {
+		if hasCondition(&r.Status.StatusType, status.ConditionTypeDeletionBlocked) {
+			r.Status.SetCondition(status.Condition{
+				Type:    status.ConditionTypeDeletionBlocked,
+				Status:  status.ConditionStatusFalse,
+				Reason:  status.ConditionReasonNoWorkloads,
+				Message: "No workloads use the control plane",
+			})
+		}
+		return 0, nil
+	}
+
+	now := r.clock.Now()
+	deadline := now.Add(timeout)
+	if deletionTimestamp := r.Instance.GetDeletionTimestamp(); deletionTimestamp != nil {
+		deadline = deletionTimestamp.Add(timeout)
+	}
+	if !now.Before(deadline) {
+		message := fmt.Sprintf("Deletion forced after %s, although workloads in the following namespaces still use the control plane: %s",
+			timeout, strings.Join(namespaces, ", "))
+		r.Status.SetCondition(status.Condition{
+			Type:    status.ConditionTypeDeletionBlocked,
+			Status:  status.ConditionStatusFalse,
+			Reason:  status.ConditionReasonDeletionForced,
+			Message: message,
+		})
+		r.EventRecorder.Event(r.Instance, corev1.EventTypeWarning, eventReasonDeletionForced, message)
+		return 0, nil
+	}
+
+	message := fmt.Sprintf("Workloads in the following namespaces still use the control plane: %s; deletion will be forced at %s",
+		strings.Join(namespaces, ", "), deadline.UTC().Format(time.RFC3339))
+	condition := r.Status.GetCondition(status.ConditionTypeDeletionBlocked)
+	if !condition.Matches(status.ConditionStatusTrue, status.ConditionReasonWorkloadsPresent, message) {
+		r.Status.SetCondition(status.Condition{
+			Type:    status.ConditionTypeDeletionBlocked,
+			Status:  status.ConditionStatusTrue,
+			Reason:  status.ConditionReasonWorkloadsPresent,
+			Message: message,
+		})
+		r.EventRecorder.Event(r.Instance, corev1.EventTypeWarning, eventReasonDeletionBlocked, message)
+		if err := r.PostStatus(ctx); err != nil {
+			return 0, err
+		}
+	}
+	return deadline.Sub(now), nil
+}
+
+// deletionTimedOut returns true if the DeletionTimeout has passed since the
+// deletion of the control plane was requested
+func (r *controlPlaneInstanceReconciler) deletionTimedOut() bool {
+	timeout := common.Config.Controller.DeletionTimeout
+	deletionTimestamp := r.Instance.GetDeletionTimestamp()
+	if timeout <= 0 || deletionTimestamp == nil {
+		return false
+	}
+	return !r.clock.Now().Before(deletionTimestamp.Add(timeout))
+}
+
+// updateComponentDeletionStatus sets the Reconciled condition of each
+// component according to the outcome of deleting its resources. Components
+// without recorded errors are marked as deleted, unless pruning stopped early,
+// in which case they may still have resources left.
+func (r *controlPlaneInstanceReconciler) updateComponentDeletionStatus(deletionErrors componentDeletionErrors, incomplete bool) {
+	for component := range deletionErrors {
+		if r.Status.FindComponentByName(component) == nil {
+			componentStatus := status.NewComponentStatus()
+			componentStatus.Resource = component
+			r.Status.ComponentStatus = append(r.Status.ComponentStatus, *componentStatus)
+		}
 	}
+	for i := range r.Status.ComponentStatus {
+		componentStatus := &r.Status.ComponentStatus[i]
+		if errs := deletionErrors[componentStatus.Resource]; len(errs) > 0 {
+			componentStatus.SetCondition(status.Condition{
+				Type:    status.ConditionTypeReconciled,
+				Status:  status.ConditionStatusFalse,
+				Reason:  status.ConditionReasonDeletionError,
+				Message: fmt.Sprintf("Error deleting component: %s", utilerrors.NewAggregate(errs)),
+			})
+		} else if !incomplete {
+			componentStatus.SetCondition(status.Condition{
+				Type:    status.ConditionTypeReconciled,
+				Status:  status.ConditionStatusTrue,
+				Reason:  status.ConditionReasonDeleted,
+				Message: "Component deleted",
+			})
+		}
+	}
+}
+
+func (r *controlPlaneInstanceReconciler) cleanupNamespaceLabels(ctx context.Context) error {
+	log := common.LogFromContext(ctx)
 
-	return nil
+	// get smcp version for ns label deletion. An invalid version must not block
+	// deletion of the SMCP, so we only remove the member-of label in that case.
+	version, err := versions.ParseVersion(r.Instance.Spec.Version)
+	if err != nil {
+		log.Error(err, "invalid version specified, removing only the member-of label from the namespace")
+		err = setNamespaceLabels(ctx, r.Client, r.Instance.Namespace, map[string]string{common.MemberOfKey: ""})
+	} else {
+		err = removeNamespaceLabels(ctx, r.Client, r.Instance.Namespace, version)
+	}
+	if apierrors.IsNotFound(err) {
+		// namespace is already being deleted
+		return nil
+	}
+	return err
 }
@@ -0,0 +1,191 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apiextensions-apiserver/pkg/apihelpers"
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/maistra/istio-operator/pkg/apis/maistra/status"
+	v2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/common/cni"
+)
+
+const eventReasonDependenciesMissing = "DependenciesMissing"
+
+// optionalDependency is a resource defined by a CustomResourceDefinition that
+// is not installed in every cluster.  If it is missing, the features of the
+// control plane that rely on it are skipped.
+type optionalDependency struct {
+	GroupVersionResource schema.GroupVersionResource
+	// required returns true if the control plane relies on the resource
+	required func(smcp *v2.ServiceMeshControlPlane, cniConfig cni.Config) bool
+}
+
+func (d optionalDependency) String() string {
+	return d.GroupVersionResource.GroupResource().String()
+}
+
+var optionalDependencies = []optionalDependency{
+	{
+		// used to enable Istio CNI in member namespaces through Multus
+		GroupVersionResource: schema.GroupVersionResource{Group: "k8s.cni.cncf.io", Version: "v1", Resource: "network-attachment-definitions"},
+		required: func(_ *v2.ServiceMeshControlPlane, cniConfig cni.Config) bool {
+			return cniConfig.Enabled && cniConfig.UseMultus
+		},
+	},
+	{
+		GroupVersionResource: schema.GroupVersionResource{Group: "kiali.io", Version: "v1alpha1", Resource: "kialis"},
+		required: func(smcp *v2.ServiceMeshControlPlane, _ cni.Config) bool {
+			return smcp.Spec.IsKialiEnabled()
+		},
+	},
+	{
+		GroupVersionResource: schema.GroupVersionResource{Group: "jaegertracing.io", Version: "v1", Resource: "jaegers"},
+		required: func(smcp *v2.ServiceMeshControlPlane, _ cni.Config) bool {
+			return smcp.Spec.IsJaegerEnabled()
+		},
+	},
+}
+
+// updateDependenciesStatus updates the DependenciesHealthy condition, which
+// lists the optional CustomResourceDefinitions that the control plane relies
+// on, but that are not installed.  It returns true if the status was changed.
+func (r *controlPlaneInstanceReconciler) updateDependenciesStatus(ctx context.Context) bool {
+	log := common.LogFromContext(ctx)
+
+	var required []optionalDependency
+	for _, dependency := range optionalDependencies {
+		if dependency.required(r.Instance, r.cniConfig) {
+			required = append(required, dependency)
+		}
+	}
+	if len(required) == 0 {
+		return r.setDependenciesCondition(status.ConditionStatusTrue, status.ConditionReasonDependenciesAvailable,
+			"The control plane doesn't use any optional CustomResourceDefinitions")
+	}
+
+	missing, err := findMissingDependencies(r.DiscoveryClient, required)
+	if err != nil {
+		log.Error(err, "error checking optional CustomResourceDefinitions")
+		return r.setDependenciesCondition(status.ConditionStatusUnknown, status.ConditionReasonProbeError,
+			fmt.Sprintf("Error checking optional CustomResourceDefinitions: %s", err))
+	}
+	if len(missing) > 0 {
+		message := fmt.Sprintf("The following CustomResourceDefinitions used by the control plane are not installed: %s",
+			strings.Join(missing, ", "))
+		updated := r.setDependenciesCondition(status.ConditionStatusFalse, status.ConditionReasonDependenciesMissing, message)
+		if updated {
+			r.EventRecorder.Event(r.Instance, corev1.EventTypeWarning, eventReasonDependenciesMissing, message)
+		}
+		return updated
+	}
+	return r.setDependenciesCondition(status.ConditionStatusTrue, status.ConditionReasonDependenciesAvailable,
+		"All optional CustomResourceDefinitions used by the control plane are installed")
+}
+
+// findMissingDependencies returns the names of the dependencies whose resources
+// are not served by the API server, sorted alphabetically
+func findMissingDependencies(dc discovery.DiscoveryInterface, dependencies []optionalDependency) ([]string, error) {
+	groups, err := dc.ServerGroups()
+	if err != nil {
+		return nil, err
+	}
+	servedGroupVersions := map[string]bool{}
+	if groups == nil {
+		// the cached discovery client doesn't return an error if there are no groups
+		groups = &metav1.APIGroupList{}
+	}
+	for _, group := range groups.Groups {
+		for _, version := range group.Versions {
+			servedGroupVersions[version.GroupVersion] = true
+		}
+	}
+
+	var missing []string
+	for _, dependency := range dependencies {
+		groupVersion := dependency.GroupVersionResource.GroupVersion().String()
+		if !servedGroupVersions[groupVersion] {
+			missing = append(missing, dependency.String())
+			continue
+		}
+		resources, err := dc.ServerResourcesForGroupVersion(groupVersion)
+		if err != nil {
+			return nil, err
+		}
+		served := false
+		for _, resource := range resources.APIResources {
+			if resource.Name == dependency.GroupVersionResource.Resource {
+				served = true
+				break
+			}
+		}
+		if !served {
+			missing = append(missing, dependency.String())
+		}
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
+
+func (r *controlPlaneInstanceReconciler) setDependenciesCondition(conditionStatus status.ConditionStatus,
+	reason status.ConditionReason, message string,
+) bool {
+	condition := r.Status.GetCondition(status.ConditionTypeDependenciesHealthy)
+	if hasCondition(&r.Status.StatusType, status.ConditionTypeDependenciesHealthy) && condition.Matches(conditionStatus, reason, message) {
+		return false
+	}
+	r.Status.SetCondition(status.Condition{
+		Type:    status.ConditionTypeDependenciesHealthy,
+		Status:  conditionStatus,
+		Reason:  reason,
+		Message: message,
+	})
+	return true
+}
+
+// isOptionalDependency returns true if the CustomResourceDefinition defines
+// the resource of one of the optional dependencies
+func isOptionalDependency(crd *apixv1.CustomResourceDefinition) bool {
+	for _, dependency := range optionalDependencies {
+		if crd.Spec.Group == dependency.GroupVersionResource.Group && crd.Spec.Names.Plural == dependency.GroupVersionResource.Resource {
+			return true
+		}
+	}
+	return false
+}
+
+// servedResourcesPredicates filter the events of CustomResourceDefinitions, so
+// that only changes that can add or remove resources served by the API server
+// are handled
+var servedResourcesPredicates = predicate.Funcs{
+	CreateFunc: func(_ event.CreateEvent) bool {
+		return true
+	},
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		if e.MetaOld.GetGeneration() != e.MetaNew.GetGeneration() {
+			return true
+		}
+		// the resources are only served once the CustomResourceDefinition is established
+		oldCRD, oldOK := e.ObjectOld.(*apixv1.CustomResourceDefinition)
+		newCRD, newOK := e.ObjectNew.(*apixv1.CustomResourceDefinition)
+		return oldOK && newOK &&
+			apihelpers.IsCRDConditionTrue(oldCRD, apixv1.Established) != apihelpers.IsCRDConditionTrue(newCRD, apixv1.Established)
+	},
+	DeleteFunc: func(_ event.DeleteEvent) bool {
+		return true
+	},
+	GenericFunc: func(_ event.GenericEvent) bool {
+		return false
+	},
+}
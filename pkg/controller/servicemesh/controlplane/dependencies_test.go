@@ -0,0 +1,168 @@
+package controlplane
+
+import (
+	"testing"
+
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/maistra/istio-operator/pkg/apis/maistra/status"
+	maistrav2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/common/test"
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+)
+
+var kialiResources = &metav1.APIResourceList{
+	GroupVersion: "kiali.io/v1alpha1",
+	APIResources: []metav1.APIResource{{Name: "kialis", Kind: "Kiali", Namespaced: true}},
+}
+
+func TestUpdateDependenciesStatus(t *testing.T) {
+	testCases := []struct {
+		name            string
+		kialiEnabled    bool
+		jaegerEnabled   bool
+		resources       []*metav1.APIResourceList
+		expectedStatus  status.ConditionStatus
+		expectedReason  status.ConditionReason
+		expectedMessage string
+		expectWarnEvent bool
+	}{
+		{
+			name:            "no-optional-dependencies",
+			expectedStatus:  status.ConditionStatusTrue,
+			expectedReason:  status.ConditionReasonDependenciesAvailable,
+			expectedMessage: "The control plane doesn't use any optional CustomResourceDefinitions",
+		},
+		{
+			name:            "kiali-installed",
+			kialiEnabled:    true,
+			resources:       []*metav1.APIResourceList{kialiResources},
+			expectedStatus:  status.ConditionStatusTrue,
+			expectedReason:  status.ConditionReasonDependenciesAvailable,
+			expectedMessage: "All optional CustomResourceDefinitions used by the control plane are installed",
+		},
+		{
+			name:           "jaeger-missing",
+			kialiEnabled:   true,
+			jaegerEnabled:  true,
+			resources:      []*metav1.APIResourceList{kialiResources},
+			expectedStatus: status.ConditionStatusFalse,
+			expectedReason: status.ConditionReasonDependenciesMissing,
+			expectedMessage: "The following CustomResourceDefinitions used by the control plane are not installed: " +
+				"jaegers.jaegertracing.io",
+			expectWarnEvent: true,
+		},
+		{
+			name:           "all-missing",
+			kialiEnabled:   true,
+			jaegerEnabled:  true,
+			expectedStatus: status.ConditionStatusFalse,
+			expectedReason: status.ConditionReasonDependenciesMissing,
+			expectedMessage: "The following CustomResourceDefinitions used by the control plane are not installed: " +
+				"jaegers.jaegertracing.io, kialis.kiali.io",
+			expectWarnEvent: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			smcp := newControlPlane()
+			smcp.Spec.Addons = &maistrav2.AddonsConfig{
+				Kiali: &maistrav2.KialiAddonConfig{Enablement: maistrav2.Enablement{Enabled: &tc.kialiEnabled}},
+			}
+			smcp.Spec.Tracing = &maistrav2.TracingConfig{Type: maistrav2.TracerTypeNone}
+			if tc.jaegerEnabled {
+				smcp.Spec.Tracing.Type = maistrav2.TracerTypeJaeger
+			}
+			smcp.Status.AppliedSpec = smcp.Spec
+
+			cl, _ := test.CreateClient(smcp)
+			eventRecorder := record.NewFakeRecorder(10)
+			r := newTestInstanceReconciler(cl, smcp)
+			r.EventRecorder = eventRecorder
+			r.DiscoveryClient = &fake.FakeDiscovery{Fake: &clienttesting.Fake{Resources: tc.resources}}
+
+			assert.True(r.updateDependenciesStatus(ctx), "Expected status to be updated", t)
+			condition := r.Status.GetCondition(status.ConditionTypeDependenciesHealthy)
+			assert.Equals(condition.Status, tc.expectedStatus, "Unexpected condition status", t)
+			assert.Equals(condition.Reason, tc.expectedReason, "Unexpected condition reason", t)
+			assert.Equals(condition.Message, tc.expectedMessage, "Unexpected condition message", t)
+			if tc.expectWarnEvent {
+				assert.Equals(len(eventRecorder.Events), 1, "Expected warning event", t)
+			} else {
+				assert.Equals(len(eventRecorder.Events), 0, "Expected no events", t)
+			}
+
+			recordedEvents := len(eventRecorder.Events)
+			assert.False(r.updateDependenciesStatus(ctx), "Expected status to be unchanged", t)
+			assert.Equals(len(eventRecorder.Events), recordedEvents, "Expected no additional events", t)
+		})
+	}
+}
+
+func TestMissingDependenciesAreCachedUntilInvalidated(t *testing.T) {
+	fakeDiscovery := &clienttesting.Fake{Resources: []*metav1.APIResourceList{kialiResources}}
+	dc := memory.NewMemCacheClient(&fake.FakeDiscovery{Fake: fakeDiscovery})
+	// the Kiali and Jaeger dependencies
+	dependencies := optionalDependencies[1:]
+
+	missing, err := findMissingDependencies(dc, dependencies)
+	assert.Success(err, "findMissingDependencies", t)
+	assert.DeepEquals(missing, []string{"jaegers.jaegertracing.io"}, "Unexpected missing dependencies", t)
+
+	fakeDiscovery.Resources = append(fakeDiscovery.Resources, &metav1.APIResourceList{
+		GroupVersion: "jaegertracing.io/v1",
+		APIResources: []metav1.APIResource{{Name: "jaegers", Kind: "Jaeger", Namespaced: true}},
+	})
+	missing, err = findMissingDependencies(dc, dependencies)
+	assert.Success(err, "findMissingDependencies", t)
+	assert.DeepEquals(missing, []string{"jaegers.jaegertracing.io"}, "Expected cached API resources to be used", t)
+
+	common.InvalidateDiscoveryCache(dc)
+	missing, err = findMissingDependencies(dc, dependencies)
+	assert.Success(err, "findMissingDependencies", t)
+	assert.Equals(len(missing), 0, "Expected no missing dependencies after invalidating the cache", t)
+}
+
+func TestServedResourcesPredicates(t *testing.T) {
+	crd := &apixv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "kialis.kiali.io", Generation: 1},
+	}
+	establishedCRD := crd.DeepCopy()
+	establishedCRD.Status.Conditions = []apixv1.CustomResourceDefinitionCondition{
+		{Type: apixv1.Established, Status: apixv1.ConditionTrue},
+	}
+	updatedCRD := establishedCRD.DeepCopy()
+	updatedCRD.Generation = 2
+	relabeledCRD := establishedCRD.DeepCopy()
+	relabeledCRD.Labels = map[string]string{"app": "kiali"}
+
+	assert.True(servedResourcesPredicates.Create(event.CreateEvent{Meta: crd, Object: crd}),
+		"Expected creation of CRD to be handled", t)
+	assert.True(servedResourcesPredicates.Delete(event.DeleteEvent{Meta: crd, Object: crd}),
+		"Expected deletion of CRD to be handled", t)
+	assert.True(servedResourcesPredicates.Update(event.UpdateEvent{MetaOld: crd, ObjectOld: crd, MetaNew: establishedCRD, ObjectNew: establishedCRD}),
+		"Expected CRD becoming established to be handled", t)
+	assert.True(servedResourcesPredicates.Update(event.UpdateEvent{MetaOld: establishedCRD, ObjectOld: establishedCRD, MetaNew: updatedCRD, ObjectNew: updatedCRD}),
+		"Expected change of CRD spec to be handled", t)
+	assert.False(servedResourcesPredicates.Update(event.UpdateEvent{MetaOld: establishedCRD, ObjectOld: establishedCRD, MetaNew: relabeledCRD, ObjectNew: relabeledCRD}),
+		"Expected change of CRD labels to be ignored", t)
+}
+
+func TestIsOptionalDependency(t *testing.T) {
+	kialiCRD := &apixv1.CustomResourceDefinition{
+		Spec: apixv1.CustomResourceDefinitionSpec{Group: "kiali.io", Names: apixv1.CustomResourceDefinitionNames{Plural: "kialis"}},
+	}
+	otherCRD := &apixv1.CustomResourceDefinition{
+		Spec: apixv1.CustomResourceDefinitionSpec{Group: "example.com", Names: apixv1.CustomResourceDefinitionNames{Plural: "kialis"}},
+	}
+	assert.True(isOptionalDependency(kialiCRD), "Expected Kiali CRD to be an optional dependency", t)
+	assert.False(isOptionalDependency(otherCRD), "Expected other CRD not to be an optional dependency", t)
+}
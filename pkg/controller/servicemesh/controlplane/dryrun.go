@@ -0,0 +1,162 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/maistra/istio-operator/pkg/apis/maistra/conversion"
+	"github.com/maistra/istio-operator/pkg/apis/maistra/status"
+	v2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/common/helm"
+	"github.com/maistra/istio-operator/pkg/controller/versions"
+)
+
+const (
+	// statusAnnotationDryRunGeneration is the generation of the spec the
+	// DryRun condition refers to
+	statusAnnotationDryRunGeneration = "dryRunGeneration"
+
+	// maxDryRunObjects limits the number of created and updated objects listed
+	// in the message of the DryRun condition
+	maxDryRunObjects = 10
+)
+
+func isDryRun(instance *v2.ServiceMeshControlPlane) bool {
+	value, _ := common.GetAnnotation(instance, common.DryRunKey)
+	return value == "true"
+}
+
+// DryRun renders and validates the charts for the current spec and compares
+// the resulting objects with those in the cluster. The result is reported in
+// the DryRun condition; no objects are created or updated. Each generation of
+// the spec is only checked once.
+func (r *controlPlaneInstanceReconciler) DryRun(ctx context.Context) error {
+	generation := strconv.FormatInt(r.Instance.GetGeneration(), 10)
+	if hasCondition(&r.Status.StatusType, status.ConditionTypeDryRun) &&
+		r.Status.GetAnnotation(statusAnnotationDryRunGeneration) == generation {
+		return nil
+	}
+
+	log := common.LogFromContext(ctx)
+	log.Info("Performing dry run of ServiceMeshControlPlane")
+
+	condition := status.Condition{
+		Type:   status.ConditionTypeDryRun,
+		Status: status.ConditionStatusTrue,
+	}
+	var err error
+	condition.Reason, condition.Message, err = r.performDryRun(ctx)
+	if err != nil {
+		log.Error(err, condition.Message)
+		condition.Status = status.ConditionStatusFalse
+		condition.Message = fmt.Sprintf("%s: error: %s", condition.Message, err)
+	}
+	r.Status.SetCondition(condition)
+	r.Status.SetAnnotation(statusAnnotationDryRunGeneration, generation)
+	return r.PostStatus(ctx)
+}
+
+func (r *controlPlaneInstanceReconciler) performDryRun(ctx context.Context) (status.ConditionReason, string, error) {
+	version, err := versions.ParseVersion(r.Instance.Spec.Version)
+	if err != nil {
+		return status.ConditionReasonValidationError, "Spec is invalid", err
+	}
+	if conversionError, exists, _ := r.Instance.Spec.TechPreview.GetString(conversion.TechPreviewErroredMessage); exists {
+		return status.ConditionReasonValidationError, "Spec is invalid", fmt.Errorf("conversion error: %s", conversionError)
+	}
+
+	// the dry run uses its own reconciler, as rendering updates the status of
+	// the instance and the reconciler may be in the middle of applying the
+	// previous generation. Objects are processed using the mesh generation
+	// they were last applied with, so only changes to their content are
+	// reported as updates.
+	dryRunReconciler := &controlPlaneInstanceReconciler{
+		ControllerResources: r.ControllerResources,
+		Instance:            r.Instance.DeepCopy(),
+		Status:              r.Status.DeepCopy(),
+		cniConfig:           r.cniConfig,
+		meshGeneration:      r.Status.GetReconciledVersion(),
+		dryRun:              true,
+	}
+	owner := metav1.NewControllerRef(r.Instance, v2.SchemeGroupVersion.WithKind("ServiceMeshControlPlane"))
+	dryRunReconciler.ownerRefs = []metav1.OwnerReference{*owner}
+
+	dryRunReconciler.renderings, err = version.Strategy().Render(ctx, &r.ControllerResources, r.cniConfig, dryRunReconciler.Instance)
+	if err != nil {
+		if versions.IsValidationError(err) {
+			return status.ConditionReasonValidationError, "Spec is invalid", err
+		} else if versions.IsDependencyMissingError(err) {
+			return status.ConditionReasonDependencyMissingError,
+				fmt.Sprintf("Dependency %q is missing", versions.GetMissingDependency(err)), err
+		}
+		return status.ConditionReasonReconcileError, "Error rendering helm charts", helm.SummarizeRenderErrors(err)
+	}
+	dryRunReconciler.Instance.Status.AppliedValues.DeepCopyInto(&dryRunReconciler.Status.AppliedValues)
+	dryRunReconciler.Instance.Status.AppliedSpec.DeepCopyInto(&dryRunReconciler.Status.AppliedSpec)
+	if err = dryRunReconciler.validateManifests(ctx, dryRunReconciler.renderings); err != nil {
+		return status.ConditionReasonReconcileError, "Error validating generated manifests", err
+	}
+
+	changes := helm.ManifestChanges{}
+	for _, charts := range dryRunReconciler.getChartsInInstallationOrder(version.Strategy().GetChartInstallOrder()) {
+		for _, chart := range charts {
+			component := componentFromChartName(chart)
+			mp := helm.NewManifestProcessor(r.ControllerResources, helm.NewPatchFactory(r.Client), r.Instance.GetNamespace(),
+				dryRunReconciler.meshGeneration, common.ToNamespacedName(r.Instance), dryRunReconciler.preprocessObject,
+				dryRunReconciler.processNewObject, dryRunReconciler.preprocessObjectForPatch)
+			mp.DryRun = true
+			mp.Adopt = isAdoptionEnabled(r.Instance)
+			if _, err = mp.ProcessManifests(ctx, dryRunReconciler.renderings[chart], component); err != nil {
+				return status.ConditionReasonReconcileError, fmt.Sprintf("Error processing component %s", component), err
+			}
+			changes.Created = append(changes.Created, mp.Changes.Created...)
+			changes.Updated = append(changes.Updated, mp.Changes.Updated...)
+			changes.Unchanged += mp.Changes.Unchanged
+		}
+	}
+	return status.ConditionReasonDryRunSuccessful, summarizeChanges(r.Instance.GetGeneration(), changes), nil
+}
+
+// summarizeChanges returns a message listing the objects that would be created
+// or updated when the specified generation is applied
+func summarizeChanges(generation int64, changes helm.ManifestChanges) string {
+	if len(changes.Created) == 0 && len(changes.Updated) == 0 {
+		return fmt.Sprintf("Applying generation %d would not change any of the %d objects", generation, changes.Unchanged)
+	}
+	message := fmt.Sprintf("Applying generation %d would create %d, update %d and leave %d objects unchanged",
+		generation, len(changes.Created), len(changes.Updated), changes.Unchanged)
+	if len(changes.Created) > 0 {
+		message += fmt.Sprintf("; created: %s", describeObjects(changes.Created))
+	}
+	if len(changes.Updated) > 0 {
+		message += fmt.Sprintf("; updated: %s", describeObjects(changes.Updated))
+	}
+	return message
+}
+
+func describeObjects(keys []status.ResourceKey) string {
+	sorted := append([]status.ResourceKey(nil), keys...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	descriptions := make([]string, 0, maxDryRunObjects+1)
+	for index, key := range sorted {
+		if index == maxDryRunObjects {
+			descriptions = append(descriptions, fmt.Sprintf("and %d more", len(sorted)-index))
+			break
+		}
+		obj := key.ToUnstructured()
+		name := obj.GetName()
+		if obj.GetNamespace() != "" {
+			name = obj.GetNamespace() + "/" + name
+		}
+		descriptions = append(descriptions, fmt.Sprintf("%s %s", obj.GetKind(), name))
+	}
+	return strings.Join(descriptions, ", ")
+}
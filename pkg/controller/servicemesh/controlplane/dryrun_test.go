@@ -0,0 +1,103 @@
+package controlplane
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/maistra/istio-operator/pkg/apis/maistra/status"
+	maistrav2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/common/helm"
+	"github.com/maistra/istio-operator/pkg/controller/common/test"
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+)
+
+func TestDryRunReportsChangesWithoutApplyingThem(t *testing.T) {
+	controlPlane := newControlPlane()
+	controlPlane.Annotations = map[string]string{common.DryRunKey: "true"}
+	controlPlane.Spec.Profiles = []string{"maistra"}
+
+	cl, tracker, r := newReconcilerTestFixture(controlPlane)
+
+	assert.Success(r.DryRun(ctx), "DryRun", t)
+
+	// only the status of the control plane is updated
+	test.AssertNumberOfWriteActions(t, tracker.Actions(), 1)
+	updatedControlPlane := &maistrav2.ServiceMeshControlPlane{}
+	test.PanicOnError(cl.Get(ctx, common.ToNamespacedName(controlPlane), updatedControlPlane))
+	condition := updatedControlPlane.Status.GetCondition(status.ConditionTypeDryRun)
+	assert.Equals(condition.Status, status.ConditionStatusTrue, "unexpected condition status: "+condition.Message, t)
+	assert.Equals(condition.Reason, status.ConditionReasonDryRunSuccessful, "unexpected condition reason", t)
+	assert.True(strings.HasPrefix(condition.Message, "Applying generation 1 would create ") &&
+		strings.Contains(condition.Message, "update 0 and leave 0 objects unchanged"),
+		"expected all objects to be reported as created: "+condition.Message, t)
+	assert.True(updatedControlPlane.Status.AppliedSpec.Version == "", "expected applied spec to be unchanged", t)
+
+	// the same generation is only checked once
+	tracker.ClearActions()
+	assert.Success(r.DryRun(ctx), "DryRun", t)
+	test.AssertNumberOfWriteActions(t, tracker.Actions(), 0)
+}
+
+func TestDryRunReportsValidationErrors(t *testing.T) {
+	controlPlane := newControlPlane()
+	controlPlane.Annotations = map[string]string{common.DryRunKey: "true"}
+	controlPlane.Spec.Version = "v1.0"
+
+	cl, _, r := newReconcilerTestFixture(controlPlane)
+
+	assert.Success(r.DryRun(ctx), "DryRun", t)
+
+	updatedControlPlane := &maistrav2.ServiceMeshControlPlane{}
+	test.PanicOnError(cl.Get(ctx, common.ToNamespacedName(controlPlane), updatedControlPlane))
+	condition := updatedControlPlane.Status.GetCondition(status.ConditionTypeDryRun)
+	assert.Equals(condition.Status, status.ConditionStatusFalse, "unexpected condition status", t)
+	assert.Equals(condition.Reason, status.ConditionReasonValidationError, "unexpected condition reason", t)
+}
+
+func TestSummarizeChanges(t *testing.T) {
+	var created []status.ResourceKey
+	for i := 0; i < 12; i++ {
+		created = append(created, status.ResourceKey(fmt.Sprintf("istio-system/config-%02d=v1,Kind=ConfigMap", i)))
+	}
+	testCases := []struct {
+		name     string
+		changes  helm.ManifestChanges
+		expected string
+	}{
+		{
+			name:     "no-changes",
+			changes:  helm.ManifestChanges{Unchanged: 3},
+			expected: "Applying generation 2 would not change any of the 3 objects",
+		},
+		{
+			name: "changes",
+			changes: helm.ManifestChanges{
+				Created: []status.ResourceKey{
+					"istio-system/istiod-basic=apps/v1,Kind=Deployment",
+					"/istiod-basic=admissionregistration.k8s.io/v1,Kind=MutatingWebhookConfiguration",
+				},
+				Updated:   []status.ResourceKey{"istio-system/istio-basic=v1,Kind=ConfigMap"},
+				Unchanged: 3,
+			},
+			expected: "Applying generation 2 would create 2, update 1 and leave 3 objects unchanged; " +
+				"created: MutatingWebhookConfiguration istiod-basic, Deployment istio-system/istiod-basic; " +
+				"updated: ConfigMap istio-system/istio-basic",
+		},
+		{
+			name:    "many-changes",
+			changes: helm.ManifestChanges{Created: created},
+			expected: "Applying generation 2 would create 12, update 0 and leave 0 objects unchanged; created: " +
+				"ConfigMap istio-system/config-00, ConfigMap istio-system/config-01, ConfigMap istio-system/config-02, " +
+				"ConfigMap istio-system/config-03, ConfigMap istio-system/config-04, ConfigMap istio-system/config-05, " +
+				"ConfigMap istio-system/config-06, ConfigMap istio-system/config-07, ConfigMap istio-system/config-08, " +
+				"ConfigMap istio-system/config-09, and 2 more",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equals(summarizeChanges(2, tc.changes), tc.expected, "unexpected summary", t)
+		})
+	}
+}
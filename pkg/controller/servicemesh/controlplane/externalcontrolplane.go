@@ -0,0 +1,64 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	v2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+)
+
+const (
+	// externalControlPlaneComponent is the name under which the readiness of
+	// the external istiod is reported
+	externalControlPlaneComponent = "external-istiod"
+
+	// externalControlPlanePort is the port on which proxies connect to the
+	// external istiod
+	externalControlPlanePort = "15012"
+
+	// externalControlPlaneProbeTimeout bounds the connection attempt, as the
+	// external istiod is probed while the readiness is being updated
+	externalControlPlaneProbeTimeout = 5 * time.Second
+)
+
+// externalControlPlaneProber checks whether the external istiod accepts
+// connections on the given address
+type externalControlPlaneProber func(ctx context.Context, address string) error
+
+var probeExternalControlPlane externalControlPlaneProber = func(ctx context.Context, address string) error {
+	ctx, cancel := context.WithTimeout(ctx, externalControlPlaneProbeTimeout)
+	defer cancel()
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(address, externalControlPlanePort))
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// usesExternalControlPlane returns true if istiod isn't deployed for the
+// control plane, because its data plane uses an external istiod
+func usesExternalControlPlane(smcp *v2.ServiceMeshControlPlane) bool {
+	return smcp.Status.AppliedSpec.IsExternalControlPlaneEnabled()
+}
+
+// externalControlPlaneReadiness returns whether the external istiod used by the
+// control plane is reachable.  As istiod isn't deployed, there is no local
+// Deployment whose readiness could be checked instead.
+func (r *controlPlaneInstanceReconciler) externalControlPlaneReadiness(ctx context.Context) bool {
+	log := common.LogFromContext(ctx)
+
+	address := r.Instance.Status.AppliedSpec.Cluster.ExternalControlPlane.Address
+	if address == "" {
+		log.Info("No address configured for the external control plane")
+		return false
+	}
+	if err := probeExternalControlPlane(ctx, address); err != nil {
+		log.Info(fmt.Sprintf("External control plane is not reachable: %s", err), "address", address)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/maistra/istio-operator/pkg/apis/maistra/status"
+	maistrav2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/common/test"
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+)
+
+func TestUpdateReadinessStatusProbesExternalControlPlane(t *testing.T) {
+	testCases := []struct {
+		name             string
+		address          string
+		probeErr         error
+		expectedStatus   status.ConditionStatus
+		expectedReady    []string
+		expectedUnready  []string
+		expectedCount    string
+		expectProbeCalls int
+	}{
+		{
+			name:             "reachable",
+			address:          "istiod.example.com",
+			expectedStatus:   status.ConditionStatusTrue,
+			expectedReady:    []string{externalControlPlaneComponent, "istio-ingressgateway"},
+			expectedUnready:  []string{},
+			expectedCount:    "2/2",
+			expectProbeCalls: 1,
+		},
+		{
+			name:             "unreachable",
+			address:          "istiod.example.com",
+			probeErr:         fmt.Errorf("connection refused"),
+			expectedStatus:   status.ConditionStatusFalse,
+			expectedReady:    []string{"istio-ingressgateway"},
+			expectedUnready:  []string{externalControlPlaneComponent},
+			expectedCount:    "1/2",
+			expectProbeCalls: 1,
+		},
+		{
+			name:            "no-address",
+			expectedStatus:  status.ConditionStatusFalse,
+			expectedReady:   []string{"istio-ingressgateway"},
+			expectedUnready: []string{externalControlPlaneComponent},
+			expectedCount:   "1/2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var probedAddresses []string
+			defer func(original externalControlPlaneProber) { probeExternalControlPlane = original }(probeExternalControlPlane)
+			probeExternalControlPlane = func(_ context.Context, address string) error {
+				probedAddresses = append(probedAddresses, address)
+				return tc.probeErr
+			}
+
+			enabled := true
+			smcp := newControlPlane()
+			smcp.Spec.Cluster = &maistrav2.ControlPlaneClusterConfig{
+				ExternalControlPlane: &maistrav2.ExternalControlPlaneConfig{
+					Enablement: maistrav2.Enablement{Enabled: &enabled},
+					Address:    tc.address,
+				},
+			}
+			smcp.Status.AppliedSpec = smcp.Spec
+			smcp.Status.SetCondition(status.Condition{Type: status.ConditionTypeReconciled, Status: status.ConditionStatusTrue})
+			smcp.Status.ComponentStatus = []status.ComponentStatus{{Resource: "istio-ingressgateway"}}
+
+			cl, _ := test.CreateClient(smcp, newDeployment("istio-ingressgateway", controlPlaneNamespace, "istio-ingressgateway", true))
+			r := newTestInstanceReconciler(cl, smcp)
+
+			assert.True(r.updateReadinessStatus(ctx), "Expected status to be updated", t)
+			assert.Equals(r.Status.GetCondition(status.ConditionTypeReady).Status, tc.expectedStatus, "Unexpected Ready condition status", t)
+			assert.DeepEquals(r.Status.Readiness.Components["ready"], tc.expectedReady, "Unexpected ready components", t)
+			assert.DeepEquals(r.Status.Readiness.Components["unready"], tc.expectedUnready, "Unexpected unready components", t)
+			assert.Equals(r.Status.GetAnnotation(statusAnnotationReadyComponentCount), tc.expectedCount, "Unexpected ready component count", t)
+			assert.Equals(len(probedAddresses), tc.expectProbeCalls, "Unexpected number of probes", t)
+		})
+	}
+}
+
+func TestRecheckInterval(t *testing.T) {
+	defer func(remoteSecretCheckInterval, externalControlPlaneCheckInterval, certificateSignerCheckInterval time.Duration) {
+		common.Config.Controller.RemoteSecretCheckInterval = remoteSecretCheckInterval
+		common.Config.Controller.ExternalControlPlaneCheckInterval = externalControlPlaneCheckInterval
+		common.Config.Controller.CertificateSignerCheckInterval = certificateSignerCheckInterval
+	}(common.Config.Controller.RemoteSecretCheckInterval, common.Config.Controller.ExternalControlPlaneCheckInterval,
+		common.Config.Controller.CertificateSignerCheckInterval)
+	common.Config.Controller.RemoteSecretCheckInterval = time.Hour
+	common.Config.Controller.ExternalControlPlaneCheckInterval = time.Minute
+	common.Config.Controller.CertificateSignerCheckInterval = 30 * time.Second
+
+	enabled := true
+	smcp := newControlPlane()
+	assert.Equals(recheckInterval(smcp), time.Duration(0), "Expected no recheck", t)
+
+	smcp.Status.SetCondition(status.Condition{Type: status.ConditionTypeRemoteSecretExpiring, Status: status.ConditionStatusFalse})
+	assert.Equals(recheckInterval(smcp), time.Hour, "Expected remote secrets to be rechecked", t)
+
+	smcp.Status.AppliedSpec.Cluster = &maistrav2.ControlPlaneClusterConfig{
+		ExternalControlPlane: &maistrav2.ExternalControlPlaneConfig{Enablement: maistrav2.Enablement{Enabled: &enabled}},
+	}
+	assert.Equals(recheckInterval(smcp), time.Minute, "Expected external control plane to be rechecked", t)
+
+	smcp.Status.AppliedSpec.Security = &maistrav2.SecurityConfig{
+		CertificateAuthority: &maistrav2.CertificateAuthorityConfig{
+			Type:       maistrav2.CertificateAuthorityTypeKubernetes,
+			Kubernetes: &maistrav2.KubernetesCertificateAuthorityConfig{SignerName: "example.com/istio-ca"},
+		},
+	}
+	assert.Equals(recheckInterval(smcp), 30*time.Second, "Expected certificate signer to be rechecked", t)
+}
@@ -0,0 +1,206 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta1 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+)
+
+const (
+	eventReasonGatewayClassParameters = "GatewayClassParameters"
+
+	// gatewayManagedKey is the label istiod adds to the Deployments and
+	// Services it deploys for Gateway API Gateway resources
+	gatewayManagedKey = "gateway.istio.io/managed"
+	// the values of the gatewayManagedKey label, depending on the gateway
+	// class istiod is the controller of
+	gatewayManagedIstio     = "istio.io-gateway-controller"
+	gatewayManagedOpenShift = "openshift.io-gateway-controller"
+)
+
+func gatewayClassParameters(smcp *v2.ServiceMeshControlPlane) *v2.GatewayClassParametersConfig {
+	gateways := smcp.Status.AppliedSpec.Gateways
+	if gateways == nil || gateways.GatewayAPI == nil || gateways.GatewayAPI.Enabled == nil || !*gateways.GatewayAPI.Enabled {
+		return nil
+	}
+	return gateways.GatewayAPI.GatewayClassParameters
+}
+
+// gatewayManagedValue returns the value of the gatewayManagedKey label of the
+// gateways deployed by the istiod of the control plane
+func gatewayManagedValue(smcp *v2.ServiceMeshControlPlane) string {
+	if controllerMode := smcp.Status.AppliedSpec.Gateways.GatewayAPI.ControllerMode; controllerMode != nil && *controllerMode {
+		return gatewayManagedOpenShift
+	}
+	return gatewayManagedIstio
+}
+
+// ApplyGatewayClassParameters applies the parameters in
+// spec.gateways.gatewayAPI.gatewayClassParameters that the charts can't apply
+// to the gateways istiod deploys in the mesh namespaces.  The pod settings are
+// applied by the gateway injection template when the gateway pods are created,
+// but istiod doesn't create autoscalers and only copies the annotations of the
+// Gateway resources to the Services.  istiod creates the gateways when Gateway
+// resources are created, so the operator isn't notified and applies the
+// parameters when the control plane is rechecked.  Annotations missing from
+// the Services are added, so that annotations istiod derives from the Gateway
+// resources take precedence, and annotations are not removed when they are
+// removed from the parameters.
+func (r *controlPlaneInstanceReconciler) ApplyGatewayClassParameters(ctx context.Context) error {
+	parameters := gatewayClassParameters(r.Instance)
+	if parameters == nil {
+		return nil
+	}
+	namespaces, err := r.listMeshNamespaceNames(ctx)
+	if err == nil {
+		var allErrors []error
+		for _, namespace := range namespaces {
+			if err := r.applyGatewayClassParametersInNamespace(ctx, parameters, namespace); err != nil {
+				allErrors = append(allErrors, err)
+			}
+		}
+		err = utilerrors.NewAggregate(allErrors)
+	}
+	if err != nil {
+		r.EventRecorder.Event(r.Instance, corev1.EventTypeWarning, eventReasonGatewayClassParameters,
+			fmt.Sprintf("Error applying gateway class parameters: %s", err))
+		return fmt.Errorf("error applying gateway class parameters: %s", err)
+	}
+	return nil
+}
+
+func (r *controlPlaneInstanceReconciler) applyGatewayClassParametersInNamespace(ctx context.Context,
+	parameters *v2.GatewayClassParametersConfig, namespace string,
+) error {
+	selector := client.MatchingLabels{gatewayManagedKey: gatewayManagedValue(r.Instance)}
+
+	if len(parameters.ServiceAnnotations) > 0 {
+		services := &corev1.ServiceList{}
+		if err := r.Client.List(ctx, services, client.InNamespace(namespace), selector); err != nil {
+			return fmt.Errorf("could not list gateway services in namespace %s: %s", namespace, err)
+		}
+		for index := range services.Items {
+			service := &services.Items[index]
+			patch := client.MergeFrom(service.DeepCopy())
+			if addMissing(&service.Annotations, parameters.ServiceAnnotations) {
+				if err := r.Client.Patch(ctx, service, patch); err != nil {
+					return fmt.Errorf("could not update gateway service %s/%s: %s", namespace, service.Name, err)
+				}
+			}
+		}
+	}
+
+	deployments := &appsv1.DeploymentList{}
+	if err := r.Client.List(ctx, deployments, client.InNamespace(namespace), selector); err != nil {
+		return fmt.Errorf("could not list gateway deployments in namespace %s: %s", namespace, err)
+	}
+	for index := range deployments.Items {
+		if err := r.reconcileGatewayAutoscaler(ctx, &deployments.Items[index], parameters.Autoscaling); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addMissing adds the entries of values missing from target and returns true
+// if target was changed
+func addMissing(target *map[string]string, values map[string]string) bool {
+	updated := false
+	for key, value := range values {
+		if _, ok := (*target)[key]; ok {
+			continue
+		}
+		if *target == nil {
+			*target = map[string]string{}
+		}
+		(*target)[key] = value
+		updated = true
+	}
+	return updated
+}
+
+// reconcileGatewayAutoscaler creates or updates the HorizontalPodAutoscaler of
+// the gateway deployment if autoscaling is enabled, and deletes the one
+// created by the operator otherwise.  The autoscaler is owned by the
+// deployment, so it is deleted together with the gateway.
+func (r *controlPlaneInstanceReconciler) reconcileGatewayAutoscaler(ctx context.Context, deployment *appsv1.Deployment,
+	autoscaling *v2.AutoScalerConfig,
+) error {
+	existing := &autoscalingv2beta1.HorizontalPodAutoscaler{}
+	err := r.Client.Get(ctx, client.ObjectKey{Namespace: deployment.Namespace, Name: deployment.Name}, existing)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("could not get autoscaler of gateway %s/%s: %s", deployment.Namespace, deployment.Name, err)
+	}
+	exists := err == nil
+	if exists && existing.Labels[common.OwnerKey] != r.Instance.Namespace {
+		// the autoscaler wasn't created by the operator, so we shouldn't touch it
+		return nil
+	}
+
+	if autoscaling == nil || autoscaling.Enabled == nil || !*autoscaling.Enabled || autoscaling.MaxReplicas == nil {
+		if exists {
+			if err := r.Client.Delete(ctx, existing); err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("could not delete autoscaler of gateway %s/%s: %s", deployment.Namespace, deployment.Name, err)
+			}
+		}
+		return nil
+	}
+
+	spec := autoscalingv2beta1.HorizontalPodAutoscalerSpec{
+		ScaleTargetRef: autoscalingv2beta1.CrossVersionObjectReference{
+			APIVersion: appsv1.SchemeGroupVersion.String(),
+			Kind:       "Deployment",
+			Name:       deployment.Name,
+		},
+		MinReplicas: autoscaling.MinReplicas,
+		MaxReplicas: *autoscaling.MaxReplicas,
+	}
+	if autoscaling.TargetCPUUtilizationPercentage != nil {
+		spec.Metrics = []autoscalingv2beta1.MetricSpec{
+			{
+				Type: autoscalingv2beta1.ResourceMetricSourceType,
+				Resource: &autoscalingv2beta1.ResourceMetricSource{
+					Name:                     corev1.ResourceCPU,
+					TargetAverageUtilization: autoscaling.TargetCPUUtilizationPercentage,
+				},
+			},
+		}
+	}
+
+	if !exists {
+		autoscaler := &autoscalingv2beta1.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      deployment.Name,
+				Namespace: deployment.Namespace,
+				Labels:    map[string]string{common.OwnerKey: r.Instance.Namespace},
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(deployment, appsv1.SchemeGroupVersion.WithKind("Deployment")),
+				},
+			},
+			Spec: spec,
+		}
+		if err := r.Client.Create(ctx, autoscaler); err != nil {
+			return fmt.Errorf("could not create autoscaler of gateway %s/%s: %s", deployment.Namespace, deployment.Name, err)
+		}
+		return nil
+	}
+	if reflect.DeepEqual(existing.Spec, spec) {
+		return nil
+	}
+	existing.Spec = spec
+	if err := r.Client.Update(ctx, existing); err != nil {
+		return fmt.Errorf("could not update autoscaler of gateway %s/%s: %s", deployment.Namespace, deployment.Name, err)
+	}
+	return nil
+}
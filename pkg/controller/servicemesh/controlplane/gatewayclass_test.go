@@ -0,0 +1,93 @@
+package controlplane
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta1 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	maistrav2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/common/test"
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+)
+
+func newGateway(name, namespace string) (*appsv1.Deployment, *corev1.Service) {
+	labels := map[string]string{gatewayManagedKey: gatewayManagedIstio}
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels}}
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels}}
+	return deployment, service
+}
+
+func TestApplyGatewayClassParameters(t *testing.T) {
+	enabled := true
+	minReplicas, maxReplicas, cpu := int32(2), int32(5), int32(80)
+	smcp := newControlPlane()
+	smcp.Spec.Gateways = &maistrav2.GatewaysConfig{
+		GatewayAPI: &maistrav2.GatewayAPIConfig{
+			Enablement: maistrav2.Enablement{Enabled: &enabled},
+			GatewayClassParameters: &maistrav2.GatewayClassParametersConfig{
+				Pod: &maistrav2.CommonPodRuntimeConfig{
+					NodeSelector:      map[string]string{"node-role.kubernetes.io/infra": ""},
+					PriorityClassName: "gateway-priority",
+				},
+				PodAnnotations: map[string]string{
+					"sidecar.istio.io/proxyCPU":    "200m",
+					"sidecar.istio.io/proxyMemory": "256Mi",
+				},
+				ServiceAnnotations: map[string]string{"service.beta.kubernetes.io/aws-load-balancer-type": "nlb"},
+				Autoscaling: &maistrav2.AutoScalerConfig{
+					Enablement:                     maistrav2.Enablement{Enabled: &enabled},
+					MinReplicas:                    &minReplicas,
+					MaxReplicas:                    &maxReplicas,
+					TargetCPUUtilizationPercentage: &cpu,
+				},
+			},
+		},
+	}
+	smcp.Status.AppliedSpec = smcp.Spec
+
+	member := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "app-namespace",
+		Labels: map[string]string{common.MemberOfKey: controlPlaneNamespace},
+	}}
+	deployment, service := newGateway("bookinfo-gateway-istio", member.Name)
+	// annotations derived from the Gateway resource take precedence
+	service.Annotations = map[string]string{"service.beta.kubernetes.io/aws-load-balancer-internal": "true"}
+	otherDeployment, otherService := newGateway("other-gateway", "other-namespace")
+
+	cl, _ := test.CreateClient(smcp, member, deployment, service, otherDeployment, otherService)
+	r := newTestInstanceReconciler(cl, smcp)
+
+	assert.Nil(r.ApplyGatewayClassParameters(ctx), "Unexpected error applying gateway class parameters", t)
+
+	test.PanicOnError(cl.Get(ctx, common.ToNamespacedName(service), service))
+	assert.DeepEquals(service.Annotations, map[string]string{
+		"service.beta.kubernetes.io/aws-load-balancer-internal": "true",
+		"service.beta.kubernetes.io/aws-load-balancer-type":     "nlb",
+	}, "Unexpected service annotations", t)
+
+	// the pod settings are applied by the gateway injection template
+	test.PanicOnError(cl.Get(ctx, common.ToNamespacedName(deployment), deployment))
+	assert.DeepEquals(deployment.Spec.Template, corev1.PodTemplateSpec{}, "Expected gateway deployment to be unchanged", t)
+
+	autoscaler := &autoscalingv2beta1.HorizontalPodAutoscaler{}
+	test.PanicOnError(cl.Get(ctx, common.ToNamespacedName(deployment), autoscaler))
+	assert.Equals(*autoscaler.Spec.MinReplicas, minReplicas, "Unexpected minReplicas", t)
+	assert.Equals(autoscaler.Spec.MaxReplicas, maxReplicas, "Unexpected maxReplicas", t)
+	assert.Equals(autoscaler.Spec.ScaleTargetRef.Name, deployment.Name, "Unexpected scale target", t)
+	assert.True(metav1.IsControlledBy(autoscaler, deployment), "Expected autoscaler to be owned by the gateway", t)
+
+	// gateways outside the mesh are left alone
+	test.PanicOnError(cl.Get(ctx, common.ToNamespacedName(otherService), otherService))
+	assert.Equals(len(otherService.Annotations), 0, "Expected service outside the mesh to be unchanged", t)
+
+	// the autoscaler is deleted when autoscaling is disabled
+	smcp.Status.AppliedSpec.Gateways.GatewayAPI.GatewayClassParameters.Autoscaling = nil
+	assert.Nil(r.ApplyGatewayClassParameters(ctx), "Unexpected error applying gateway class parameters", t)
+	err := cl.Get(ctx, common.ToNamespacedName(deployment), autoscaler)
+	assert.True(apierrors.IsNotFound(err), "Expected autoscaler to be deleted", t)
+}
@@ -24,6 +24,17 @@ func (r *controlPlaneInstanceReconciler) processDeletedComponent(name string, st
 }
 
 func (r *controlPlaneInstanceReconciler) preprocessObject(ctx context.Context, object *unstructured.Unstructured) (bool, error) {
+	// the charts don't support topology spread constraints for istiod, so the
+	// defaults are applied to the rendered object, before the user overlays
+	if err := applyDefaultTopologySpreadConstraints(object); err != nil {
+		return false, err
+	}
+
+	// apply user overlays first, so they can't override the metadata added below
+	if err := r.applyOverlays(ctx, object); err != nil {
+		return false, err
+	}
+
 	// Add owner ref
 	if object.GetNamespace() == r.Instance.GetNamespace() {
 		object.SetOwnerReferences(r.ownerRefs)
@@ -45,6 +56,12 @@ func (r *controlPlaneInstanceReconciler) preprocessObject(ctx context.Context, o
 	case "ConfigMap":
 		if object.GetName() == "istio-grafana" {
 			return true, r.patchGrafanaConfig(ctx, object)
+		} else if isInjectorConfigMap(object) {
+			return true, r.patchInjectorConfig(object)
+		}
+	case "Deployment":
+		if isIstiodDeployment(object) {
+			return true, r.patchIstiodInjectionTemplatesChecksum(object)
 		}
 	case "Secret":
 		switch object.GetName() {
@@ -67,7 +84,10 @@ func (r *controlPlaneInstanceReconciler) preprocessObject(ctx context.Context, o
 func (r *controlPlaneInstanceReconciler) preprocessObjectForPatch(ctx context.Context,
 	oldObj, newObj *unstructured.Unstructured,
 ) (*unstructured.Unstructured, error) {
-	if newObj.GetKind() == "Kiali" {
+	switch newObj.GetKind() {
+	case "Deployment":
+		return r.preserveAutoscaledReplicas(ctx, oldObj, newObj)
+	case "Kiali":
 		accessibleNamespaces, found, err := unstructured.NestedStringSlice(oldObj.UnstructuredContent(), "spec", "deployment", "accessible_namespaces")
 		if err != nil {
 			return nil, err
@@ -204,3 +224,17 @@ func (r *controlPlaneInstanceReconciler) waitForWebhookCABundleInitialization(ct
 		log.Error(nil, "webhook CABundle failed to become initialized in a timely manner", kind, name)
 	}
 }
+
+// applyDefaultTopologySpreadConstraints sets the topology spread constraints
+// configured for all control planes on the istiod Deployment, unless it
+// already specifies any
+func applyDefaultTopologySpreadConstraints(object *unstructured.Unstructured) error {
+	constraints := common.Config.ControlPlaneDefaults.TopologySpreadConstraintsValues
+	if len(constraints) == 0 || object.GetKind() != "Deployment" || object.GetLabels()["app"] != "istiod" {
+		return nil
+	}
+	if _, found, _ := unstructured.NestedSlice(object.UnstructuredContent(), "spec", "template", "spec", "topologySpreadConstraints"); found {
+		return nil
+	}
+	return unstructured.SetNestedSlice(object.UnstructuredContent(), constraints, "spec", "template", "spec", "topologySpreadConstraints")
+}
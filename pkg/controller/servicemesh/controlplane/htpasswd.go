@@ -10,6 +10,7 @@ import (
 
 	"golang.org/x/crypto/bcrypt"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -94,6 +95,10 @@ func (r *controlPlaneInstanceReconciler) getRawHtPasswd(ctx context.Context) (st
 	htSecret := &corev1.Secret{}
 	err := r.Client.Get(ctx, client.ObjectKey{Namespace: r.Instance.GetNamespace(), Name: "htpasswd"}, htSecret)
 	if err != nil {
+		if r.dryRun && apierrors.IsNotFound(err) {
+			// the secret is only created when the spec is applied
+			return "", nil
+		}
 		log.Error(err, "error retrieving htpasswd Secret")
 		return "", err
 	}
@@ -0,0 +1,205 @@
+package controlplane
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/maistra/istio-operator/pkg/controller/versions"
+	"github.com/maistra/istio-operator/pkg/featuregates"
+)
+
+const (
+	// injectionTemplatesLabel identifies the ConfigMaps in the control plane
+	// namespace whose entries are custom sidecar injection templates
+	injectionTemplatesLabel = "maistra.io/injection-templates"
+
+	// injectionTemplatesChecksumKey is the pod annotation of istiod that
+	// restarts istiod when the custom injection templates change
+	injectionTemplatesChecksumKey = "maistra.io/injection-templates-checksum"
+
+	// statusAnnotationInjectionTemplatesChecksum records the checksum of the
+	// custom injection templates that were last applied
+	statusAnnotationInjectionTemplatesChecksum = "injectionTemplatesChecksum"
+
+	injectorConfigMapPrefix = "istio-sidecar-injector"
+)
+
+// injectionTemplateFuncs are the functions available to injection templates.
+// Only their names matter, as templates are parsed, but not executed.
+var injectionTemplateFuncs = func() template.FuncMap {
+	funcs := sprig.TxtFuncMap()
+	stub := func(...interface{}) interface{} { return nil }
+	for _, name := range []string{
+		"formatDuration", "isset", "excludeInboundPort", "includeInboundPorts", "kubevirtInterfaces",
+		"excludeInterfaces", "applicationPorts", "annotation", "valueOrDefault", "toJSON", "fromJSON",
+		"structToJSON", "protoToJSON", "toYaml", "indent", "directory", "contains", "toLower",
+		"appendMultusNetwork", "env", "omit", "strdict", "toJsonMap", "mergeMaps",
+	} {
+		funcs[name] = stub
+	}
+	return funcs
+}()
+
+// listInjectionTemplateConfigMaps returns the ConfigMaps with custom injection
+// templates in the namespace, sorted by name
+func listInjectionTemplateConfigMaps(ctx context.Context, reader client.Reader, namespace string) ([]corev1.ConfigMap, error) {
+	list := &corev1.ConfigMapList{}
+	if err := reader.List(ctx, list, client.InNamespace(namespace), client.MatchingLabels{injectionTemplatesLabel: "true"}); err != nil {
+		return nil, err
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].Name < list.Items[j].Name
+	})
+	return list.Items, nil
+}
+
+// injectionTemplatesChecksum returns a checksum of the entries of the
+// ConfigMaps, or an empty string if there are none
+func injectionTemplatesChecksum(configMaps []corev1.ConfigMap) string {
+	hash := sha256.New()
+	empty := true
+	for _, configMap := range configMaps {
+		keys := make([]string, 0, len(configMap.Data))
+		for key := range configMap.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(hash, "%s/%s=%q\n", configMap.Name, key, configMap.Data[key])
+			empty = false
+		}
+	}
+	if empty {
+		return ""
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// injectionTemplatesFromConfigMaps returns the templates in the ConfigMaps by
+// name.  Each template must be a valid Go template, and its name must be
+// unique across the ConfigMaps.
+func injectionTemplatesFromConfigMaps(configMaps []corev1.ConfigMap) (map[string]string, error) {
+	var allErrors []error
+	templates := map[string]string{}
+	sources := map[string]string{}
+	for _, configMap := range configMaps {
+		for name, content := range configMap.Data {
+			if source, ok := sources[name]; ok {
+				allErrors = append(allErrors, fmt.Errorf("injection template %q is defined in both ConfigMap %s and %s",
+					name, source, configMap.Name))
+			} else if strings.TrimSpace(content) == "" {
+				allErrors = append(allErrors, fmt.Errorf("injection template %q in ConfigMap %s is empty", name, configMap.Name))
+			} else if _, err := template.New(name).Funcs(injectionTemplateFuncs).Parse(content); err != nil {
+				allErrors = append(allErrors, fmt.Errorf("injection template %q in ConfigMap %s is invalid: %v", name, configMap.Name, err))
+			}
+			templates[name] = content
+			sources[name] = configMap.Name
+		}
+	}
+	if len(allErrors) > 0 {
+		return nil, versions.NewValidationError(allErrors...)
+	}
+	return templates, nil
+}
+
+// injectionTemplatesChanged returns true if the custom injection templates of
+// the control plane changed since they were last applied
+func (r *ControlPlaneReconciler) injectionTemplatesChanged(ctx context.Context, namespace, appliedChecksum string) (bool, error) {
+	if !featuregates.Default.Enabled(featuregates.CustomInjectionTemplates) {
+		return false, nil
+	}
+	configMaps, err := listInjectionTemplateConfigMaps(ctx, r.injectionTemplatesReader, namespace)
+	if err != nil {
+		return false, err
+	}
+	return injectionTemplatesChecksum(configMaps) != appliedChecksum, nil
+}
+
+// loadInjectionTemplates reads and validates the custom injection templates,
+// which are added to the injector ConfigMap when it's applied.  No templates
+// are loaded if the CustomInjectionTemplates feature is disabled.
+func (r *controlPlaneInstanceReconciler) loadInjectionTemplates(ctx context.Context) error {
+	if !featuregates.Default.Enabled(featuregates.CustomInjectionTemplates) {
+		r.injectionTemplates = nil
+		r.injectionTemplatesChecksum = ""
+		r.Status.RemoveAnnotation(statusAnnotationInjectionTemplatesChecksum)
+		return nil
+	}
+	configMaps, err := listInjectionTemplateConfigMaps(ctx, r.injectionTemplatesReader, r.Instance.Namespace)
+	if err != nil {
+		return err
+	}
+	templates, err := injectionTemplatesFromConfigMaps(configMaps)
+	if err != nil {
+		return err
+	}
+	r.injectionTemplates = templates
+	r.injectionTemplatesChecksum = injectionTemplatesChecksum(configMaps)
+	r.Status.SetAnnotation(statusAnnotationInjectionTemplatesChecksum, r.injectionTemplatesChecksum)
+	return nil
+}
+
+// patchInjectorConfig adds the custom injection templates to the templates in
+// the config of the injector ConfigMap, replacing templates with the same name
+func (r *controlPlaneInstanceReconciler) patchInjectorConfig(object *unstructured.Unstructured) error {
+	if len(r.injectionTemplates) == 0 {
+		return nil
+	}
+	rawConfig, _, err := unstructured.NestedString(object.UnstructuredContent(), "data", "config")
+	if err != nil {
+		return err
+	}
+	config := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(rawConfig), &config); err != nil {
+		return fmt.Errorf("error parsing the config of ConfigMap %s: %v", object.GetName(), err)
+	}
+	templates, ok := config["templates"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("custom injection templates are not supported in version %s", r.Instance.Spec.Version)
+	}
+	for name, content := range r.injectionTemplates {
+		templates[name] = content
+	}
+	patchedConfig, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	return unstructured.SetNestedField(object.UnstructuredContent(), string(patchedConfig), "data", "config")
+}
+
+// patchIstiodInjectionTemplatesChecksum annotates the istiod pods with the
+// checksum of the custom injection templates, so istiod is restarted and
+// applies the templates when they change
+func (r *controlPlaneInstanceReconciler) patchIstiodInjectionTemplatesChecksum(object *unstructured.Unstructured) error {
+	if r.injectionTemplatesChecksum == "" {
+		return nil
+	}
+	annotations, _, err := unstructured.NestedStringMap(object.UnstructuredContent(), "spec", "template", "metadata", "annotations")
+	if err != nil {
+		return err
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[injectionTemplatesChecksumKey] = r.injectionTemplatesChecksum
+	return unstructured.SetNestedStringMap(object.UnstructuredContent(), annotations, "spec", "template", "metadata", "annotations")
+}
+
+func isInjectorConfigMap(object *unstructured.Unstructured) bool {
+	return object.GetKind() == "ConfigMap" && strings.HasPrefix(object.GetName(), injectorConfigMapPrefix)
+}
+
+func isIstiodDeployment(object *unstructured.Unstructured) bool {
+	return object.GetKind() == "Deployment" && object.GetLabels()["app"] == "istiod"
+}
@@ -0,0 +1,184 @@
+package controlplane
+
+import (
+	"io/ioutil"
+	"path"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	maistrav2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+	"github.com/maistra/istio-operator/pkg/controller/versions"
+)
+
+func TestInjectionTemplatesFromConfigMaps(t *testing.T) {
+	testCases := []struct {
+		name       string
+		configMaps []corev1.ConfigMap
+		expected   map[string]string
+		expectErr  bool
+	}{
+		{
+			name:       "none",
+			configMaps: nil,
+			expected:   map[string]string{},
+		},
+		{
+			name: "valid",
+			configMaps: []corev1.ConfigMap{
+				newInjectionTemplateConfigMap("a", map[string]string{"custom": "metadata:\n  labels: {{ toJSON .ObjectMeta.Labels }}"}),
+				newInjectionTemplateConfigMap("b", map[string]string{"other": "spec: {}"}),
+			},
+			expected: map[string]string{
+				"custom": "metadata:\n  labels: {{ toJSON .ObjectMeta.Labels }}",
+				"other":  "spec: {}",
+			},
+		},
+		{
+			name: "duplicate",
+			configMaps: []corev1.ConfigMap{
+				newInjectionTemplateConfigMap("a", map[string]string{"custom": "spec: {}"}),
+				newInjectionTemplateConfigMap("b", map[string]string{"custom": "spec: {}"}),
+			},
+			expectErr: true,
+		},
+		{
+			name: "empty",
+			configMaps: []corev1.ConfigMap{
+				newInjectionTemplateConfigMap("a", map[string]string{"custom": " \n"}),
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid",
+			configMaps: []corev1.ConfigMap{
+				newInjectionTemplateConfigMap("a", map[string]string{"custom": "spec: {{ .Spec"}),
+			},
+			expectErr: true,
+		},
+		{
+			name: "unknown-function",
+			configMaps: []corev1.ConfigMap{
+				newInjectionTemplateConfigMap("a", map[string]string{"custom": "spec: {{ unknown .Spec }}"}),
+			},
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			templates, err := injectionTemplatesFromConfigMaps(tc.configMaps)
+			if tc.expectErr {
+				assert.Failure(err, "injectionTemplatesFromConfigMaps", t)
+				assert.True(versions.IsValidationError(err), "Expected a validation error", t)
+			} else {
+				assert.Success(err, "injectionTemplatesFromConfigMaps", t)
+				assert.DeepEquals(templates, tc.expected, "Unexpected templates", t)
+			}
+		})
+	}
+}
+
+func TestBundledInjectionTemplatesAreValid(t *testing.T) {
+	InitializeGlobals("istio-operator")()
+	dir := path.Join(common.Config.Rendering.ChartsDir, versions.V2_4.String(), "istio-control/istio-discovery/files")
+	data := map[string]string{}
+	for _, file := range []string{"injection-template.yaml", "gateway-injection-template.yaml", "grpc-simple.yaml", "grpc-agent.yaml"} {
+		content, err := ioutil.ReadFile(path.Join(dir, file))
+		assert.Success(err, "ReadFile", t)
+		data[file] = string(content)
+	}
+	_, err := injectionTemplatesFromConfigMaps([]corev1.ConfigMap{newInjectionTemplateConfigMap("bundled", data)})
+	assert.Success(err, "injectionTemplatesFromConfigMaps", t)
+}
+
+func TestInjectionTemplatesChecksum(t *testing.T) {
+	assert.Equals(injectionTemplatesChecksum(nil), "", "Expected no checksum without templates", t)
+	assert.Equals(injectionTemplatesChecksum([]corev1.ConfigMap{newInjectionTemplateConfigMap("a", nil)}), "",
+		"Expected no checksum for an empty ConfigMap", t)
+
+	configMaps := []corev1.ConfigMap{newInjectionTemplateConfigMap("a", map[string]string{"x": "spec: {}", "y": "metadata: {}"})}
+	checksum := injectionTemplatesChecksum(configMaps)
+	assert.True(checksum != "", "Expected a checksum", t)
+	assert.Equals(injectionTemplatesChecksum(configMaps), checksum, "Expected the checksum to be stable", t)
+
+	configMaps[0].Data["y"] = "metadata: {labels: {}}"
+	assert.True(injectionTemplatesChecksum(configMaps) != checksum, "Expected the checksum to change with the templates", t)
+}
+
+func TestPatchInjectorConfig(t *testing.T) {
+	config, err := yaml.Marshal(map[string]interface{}{
+		"policy":    "enabled",
+		"templates": map[string]interface{}{"sidecar": "original", "gateway": "original"},
+	})
+	assert.Success(err, "Marshal", t)
+	object := newInjectorConfigMap(string(config))
+	r := &controlPlaneInstanceReconciler{
+		Instance:           &maistrav2.ServiceMeshControlPlane{Spec: maistrav2.ControlPlaneSpec{Version: versions.V2_4.String()}},
+		injectionTemplates: map[string]string{"gateway": "custom", "custom": "custom"},
+	}
+
+	assert.Success(r.patchInjectorConfig(object), "patchInjectorConfig", t)
+
+	patchedConfig := map[string]interface{}{}
+	rawConfig, _, _ := unstructured.NestedString(object.UnstructuredContent(), "data", "config")
+	assert.Success(yaml.Unmarshal([]byte(rawConfig), &patchedConfig), "Unmarshal", t)
+	assert.Equals(patchedConfig["policy"], "enabled", "Expected other settings to be preserved", t)
+	assert.DeepEquals(patchedConfig["templates"],
+		map[string]interface{}{"sidecar": "original", "gateway": "custom", "custom": "custom"},
+		"Unexpected templates", t)
+}
+
+func TestPatchInjectorConfigFailsWithoutTemplates(t *testing.T) {
+	object := newInjectorConfigMap("policy: enabled\ntemplate: original\n")
+	r := &controlPlaneInstanceReconciler{
+		Instance:           &maistrav2.ServiceMeshControlPlane{Spec: maistrav2.ControlPlaneSpec{Version: versions.V2_0.String()}},
+		injectionTemplates: map[string]string{"custom": "custom"},
+	}
+	assert.Failure(r.patchInjectorConfig(object), "patchInjectorConfig", t)
+
+	// nothing is patched if there are no custom templates
+	r.injectionTemplates = nil
+	assert.Success(r.patchInjectorConfig(object), "patchInjectorConfig", t)
+}
+
+func TestPatchIstiodInjectionTemplatesChecksum(t *testing.T) {
+	object := &unstructured.Unstructured{}
+	object.SetKind("Deployment")
+	object.SetName("istiod-basic")
+	object.SetLabels(map[string]string{"app": "istiod"})
+	assert.True(isIstiodDeployment(object), "Expected istiod Deployment to be recognized", t)
+
+	r := &controlPlaneInstanceReconciler{}
+	assert.Success(r.patchIstiodInjectionTemplatesChecksum(object), "patchIstiodInjectionTemplatesChecksum", t)
+	_, found, _ := unstructured.NestedMap(object.UnstructuredContent(), "spec")
+	assert.False(found, "Expected the Deployment not to be patched without templates", t)
+
+	r.injectionTemplatesChecksum = "checksum"
+	assert.Success(r.patchIstiodInjectionTemplatesChecksum(object), "patchIstiodInjectionTemplatesChecksum", t)
+	annotations, _, _ := unstructured.NestedStringMap(object.UnstructuredContent(), "spec", "template", "metadata", "annotations")
+	assert.Equals(annotations[injectionTemplatesChecksumKey], "checksum", "Unexpected checksum annotation", t)
+}
+
+func newInjectionTemplateConfigMap(name string, data map[string]string) corev1.ConfigMap {
+	return corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: controlPlaneNamespace,
+			Labels:    map[string]string{injectionTemplatesLabel: "true"},
+		},
+		Data: data,
+	}
+}
+
+func newInjectorConfigMap(config string) *unstructured.Unstructured {
+	object := &unstructured.Unstructured{}
+	object.SetKind("ConfigMap")
+	object.SetName("istio-sidecar-injector-basic")
+	_ = unstructured.SetNestedField(object.UnstructuredContent(), config, "data", "config")
+	return object
+}
@@ -0,0 +1,99 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/versions"
+)
+
+// injectionWebhookSuffix is the suffix of the names of the webhooks that inject
+// sidecars.  Newer charts prefix the name to make it unique.
+const injectionWebhookSuffix = "sidecar-injector.istio.io"
+
+// injectionWebhookComponents are the components that render the
+// MutatingWebhookConfiguration of the sidecar injector
+var injectionWebhookComponents = sets.NewString(
+	componentFromChartName(versions.DiscoveryChart),
+	componentFromChartName("istio/charts/sidecarInjectorWebhook"),
+)
+
+// checkInjectionWebhookOfComponents checks the sidecar injection webhook if
+// it is rendered by one of the components of the control plane.  Control
+// planes using an external istiod don't serve the webhook themselves.
+func (r *controlPlaneInstanceReconciler) checkInjectionWebhookOfComponents(ctx context.Context, components sets.String) error {
+	if usesExternalControlPlane(r.Instance) || !components.HasAny(injectionWebhookComponents.UnsortedList()...) {
+		return nil
+	}
+	return r.checkInjectionWebhook(ctx)
+}
+
+// checkInjectionWebhook returns an error describing why the sidecar injection
+// webhook of the control plane isn't serving, or nil if it is.  The pods
+// serving the webhook being ready doesn't guarantee that injection works,
+// e.g. if the MutatingWebhookConfiguration was deleted or the Service it
+// calls doesn't select any ready pods.  Webhooks calling a URL instead of a
+// Service aren't checked.
+func (r *controlPlaneInstanceReconciler) checkInjectionWebhook(ctx context.Context) error {
+	webhookConfigs := &admissionv1.MutatingWebhookConfigurationList{}
+	if err := r.Client.List(ctx, webhookConfigs, client.MatchingLabels{
+		common.OwnerKey:     r.Instance.Namespace,
+		common.OwnerNameKey: r.Instance.Name,
+	}); err != nil {
+		return fmt.Errorf("could not list MutatingWebhookConfigurations: %s", err)
+	}
+
+	found := false
+	checkedServices := sets.NewString()
+	for _, webhookConfig := range webhookConfigs.Items {
+		for _, webhook := range webhookConfig.Webhooks {
+			if !strings.HasSuffix(webhook.Name, injectionWebhookSuffix) {
+				continue
+			}
+			found = true
+			service := webhook.ClientConfig.Service
+			if service == nil {
+				continue
+			}
+			key := fmt.Sprintf("%s/%s", service.Namespace, service.Name)
+			if checkedServices.Has(key) {
+				continue
+			}
+			checkedServices.Insert(key)
+			if err := r.checkServiceEndpoints(ctx, service.Namespace, service.Name); err != nil {
+				return fmt.Errorf("MutatingWebhookConfiguration %s: %s", webhookConfig.Name, err)
+			}
+		}
+	}
+	if !found {
+		return fmt.Errorf("no MutatingWebhookConfiguration for sidecar injection found")
+	}
+	return nil
+}
+
+// checkServiceEndpoints returns an error if the Service has no ready endpoints.
+// Endpoints change constantly, so they are read from the API server instead
+// of being watched and cached.
+func (r *controlPlaneInstanceReconciler) checkServiceEndpoints(ctx context.Context, namespace, name string) error {
+	endpoints := &corev1.Endpoints{}
+	if err := r.apiReader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, endpoints); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("service %s/%s has no endpoints", namespace, name)
+		}
+		return fmt.Errorf("could not get endpoints of service %s/%s: %s", namespace, name, err)
+	}
+	for _, subset := range endpoints.Subsets {
+		if len(subset.Addresses) > 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("service %s/%s has no ready endpoints", namespace, name)
+}
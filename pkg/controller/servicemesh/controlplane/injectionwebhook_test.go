@@ -0,0 +1,130 @@
+package controlplane
+
+import (
+	"testing"
+	"time"
+
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/maistra/istio-operator/pkg/apis/maistra/status"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/common/test"
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+)
+
+func newInjectionWebhookConfig(clientConfig admissionv1.WebhookClientConfig) *admissionv1.MutatingWebhookConfiguration {
+	return &admissionv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "istiod-" + controlPlaneName + "-" + controlPlaneNamespace,
+			Labels: map[string]string{
+				common.OwnerKey:     controlPlaneNamespace,
+				common.OwnerNameKey: controlPlaneName,
+			},
+		},
+		Webhooks: []admissionv1.MutatingWebhook{
+			{Name: "rev.namespace.sidecar-injector.istio.io", ClientConfig: clientConfig},
+		},
+	}
+}
+
+func newIstiodEndpoints(ready bool) *corev1.Endpoints {
+	address := []corev1.EndpointAddress{{IP: "10.0.0.1"}}
+	subset := corev1.EndpointSubset{}
+	if ready {
+		subset.Addresses = address
+	} else {
+		subset.NotReadyAddresses = address
+	}
+	return &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "istiod-" + controlPlaneName, Namespace: controlPlaneNamespace},
+		Subsets:    []corev1.EndpointSubset{subset},
+	}
+}
+
+func TestUpdateReadinessStatusChecksInjectionWebhook(t *testing.T) {
+	serviceConfig := admissionv1.WebhookClientConfig{
+		Service: &admissionv1.ServiceReference{Name: "istiod-" + controlPlaneName, Namespace: controlPlaneNamespace},
+	}
+	url := "https://istiod.example.com:15017/inject"
+	urlConfig := admissionv1.WebhookClientConfig{URL: &url}
+
+	testCases := []struct {
+		name           string
+		objects        []runtime.Object
+		expectedStatus status.ConditionStatus
+		expectedReason status.ConditionReason
+	}{
+		{
+			name:           "serving",
+			objects:        []runtime.Object{newInjectionWebhookConfig(serviceConfig), newIstiodEndpoints(true)},
+			expectedStatus: status.ConditionStatusTrue,
+			expectedReason: status.ConditionReasonComponentsReady,
+		},
+		{
+			name:           "missing-webhook-config",
+			objects:        []runtime.Object{newIstiodEndpoints(true)},
+			expectedStatus: status.ConditionStatusFalse,
+			expectedReason: status.ConditionReasonWebhookNotReady,
+		},
+		{
+			name:           "missing-endpoints",
+			objects:        []runtime.Object{newInjectionWebhookConfig(serviceConfig)},
+			expectedStatus: status.ConditionStatusFalse,
+			expectedReason: status.ConditionReasonWebhookNotReady,
+		},
+		{
+			name:           "no-ready-endpoints",
+			objects:        []runtime.Object{newInjectionWebhookConfig(serviceConfig), newIstiodEndpoints(false)},
+			expectedStatus: status.ConditionStatusFalse,
+			expectedReason: status.ConditionReasonWebhookNotReady,
+		},
+		{
+			name:           "url",
+			objects:        []runtime.Object{newInjectionWebhookConfig(urlConfig)},
+			expectedStatus: status.ConditionStatusTrue,
+			expectedReason: status.ConditionReasonComponentsReady,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			smcp := newControlPlane()
+			smcp.Status.SetCondition(status.Condition{Type: status.ConditionTypeReconciled, Status: status.ConditionStatusTrue})
+			smcp.Status.ComponentStatus = []status.ComponentStatus{{Resource: "istio-discovery"}}
+
+			objects := append([]runtime.Object{smcp, newDeployment("istiod-"+controlPlaneName, controlPlaneNamespace, "istio-discovery", true)},
+				tc.objects...)
+			cl, _ := test.CreateClient(objects...)
+			r := newTestInstanceReconciler(cl, smcp)
+
+			assert.True(r.updateReadinessStatus(ctx), "Expected status to be updated", t)
+			readyCondition := r.Status.GetCondition(status.ConditionTypeReady)
+			assert.Equals(readyCondition.Status, tc.expectedStatus, "Unexpected Ready condition status", t)
+			assert.Equals(readyCondition.Reason, tc.expectedReason, "Unexpected Ready condition reason", t)
+		})
+	}
+}
+
+func TestRecheckIntervalWhileWebhookNotReady(t *testing.T) {
+	smcp := newControlPlane()
+	assert.Equals(recheckInterval(smcp), time.Duration(0), "Expected no recheck", t)
+
+	smcp.Status.SetCondition(status.Condition{
+		Type:   status.ConditionTypeReady,
+		Status: status.ConditionStatusFalse,
+		Reason: status.ConditionReasonWebhookNotReady,
+	})
+	assert.Equals(recheckInterval(smcp), common.Config.Controller.InjectionWebhookCheckInterval,
+		"Expected webhook to be rechecked", t)
+
+	smcp.Status.SetCondition(status.Condition{
+		Type:   status.ConditionTypeReady,
+		Status: status.ConditionStatusFalse,
+		Reason: status.ConditionReasonXDSNotReady,
+	})
+	assert.Equals(recheckInterval(smcp), common.Config.Controller.InjectionWebhookCheckInterval,
+		"Expected istiod to be rechecked", t)
+}
@@ -0,0 +1,152 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	"github.com/maistra/istio-operator/pkg/apis/maistra/status"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/podlocality"
+)
+
+const (
+	// inUseUpdateDelay is how long pod events are collected before the control
+	// plane is reconciled to update its InUse condition
+	inUseUpdateDelay = 10 * time.Second
+
+	// injectedPodLabel selects the pods with injected sidecars, as the sidecar
+	// injector adds the security.istio.io/tlsMode label to every pod it injects
+	injectedPodLabel = "security.istio.io/tlsMode"
+)
+
+// delayedEnqueueRequestsFromMapFunc enqueues the requests returned by
+// ToRequests after a delay. The work queue coalesces requests for the same
+// control plane that are still waiting, so a burst of events, e.g. a
+// deployment being scaled, results in a single reconciliation.
+type delayedEnqueueRequestsFromMapFunc struct {
+	ToRequests handler.ToRequestsFunc
+	Delay      time.Duration
+}
+
+var _ handler.EventHandler = &delayedEnqueueRequestsFromMapFunc{}
+
+func (e *delayedEnqueueRequestsFromMapFunc) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	e.enqueue(handler.MapObject{Meta: evt.Meta, Object: evt.Object}, q)
+}
+
+func (e *delayedEnqueueRequestsFromMapFunc) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	e.enqueue(handler.MapObject{Meta: evt.MetaOld, Object: evt.ObjectOld}, q)
+	e.enqueue(handler.MapObject{Meta: evt.MetaNew, Object: evt.ObjectNew}, q)
+}
+
+func (e *delayedEnqueueRequestsFromMapFunc) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	e.enqueue(handler.MapObject{Meta: evt.Meta, Object: evt.Object}, q)
+}
+
+func (e *delayedEnqueueRequestsFromMapFunc) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	e.enqueue(handler.MapObject{Meta: evt.Meta, Object: evt.Object}, q)
+}
+
+func (e *delayedEnqueueRequestsFromMapFunc) enqueue(obj handler.MapObject, q workqueue.RateLimitingInterface) {
+	for _, req := range e.ToRequests.Map(obj) {
+		q.AddAfter(req, e.Delay)
+	}
+}
+
+// updateInUseCondition updates the InUse condition from the member namespaces
+// containing workloads that use the control plane, as found by
+// updateMeshNamespacesStatus.  inUseNamespaces and mismatchedNamespaces may
+// be capped, in which case the message mentions how many namespaces were
+// omitted.  It returns true if the status was changed.
+func (r *controlPlaneInstanceReconciler) updateInUseCondition(inUseCount int, inUseNamespaces, mismatchedNamespaces []string) bool {
+	if inUseCount == 0 {
+		return r.setInUseCondition(status.ConditionStatusFalse, status.ConditionReasonNoWorkloads,
+			"No workloads use the control plane")
+	}
+	message := fmt.Sprintf("Workloads in the following namespaces use the control plane: %s",
+		joinCapped(inUseNamespaces, inUseCount))
+	if len(mismatchedNamespaces) > 0 {
+		message += fmt.Sprintf("; the following namespaces contain workloads injected by another revision: %s",
+			strings.Join(mismatchedNamespaces, ", "))
+	}
+	return r.setInUseCondition(status.ConditionStatusTrue, status.ConditionReasonWorkloadsPresent, message)
+}
+
+// joinCapped joins the names, noting how many of the total were omitted
+func joinCapped(names []string, total int) string {
+	joined := strings.Join(names, ", ")
+	if omitted := total - len(names); omitted > 0 {
+		joined += fmt.Sprintf(" and %d more", omitted)
+	}
+	return joined
+}
+
+// findInjectedRevisions returns whether the namespace contains at least one
+// pod with an injected sidecar, together with the revisions found in the
+// istio.io/rev label of those pods
+func (r *controlPlaneInstanceReconciler) findInjectedRevisions(ctx context.Context, namespace string) (bool, sets.String, error) {
+	podList := &corev1.PodList{}
+	if err := r.injectedPodReader.List(ctx, podList, client.InNamespace(namespace), client.HasLabels{injectedPodLabel}); err != nil {
+		return false, nil, err
+	}
+	injected := false
+	revisions := sets.NewString()
+	for _, pod := range podList.Items {
+		if pod.Annotations[podlocality.IstioSidecarStatusAnnotation] == "" {
+			continue
+		}
+		injected = true
+		if revision := pod.Labels[common.IstioRevisionKey]; revision != "" {
+			revisions.Insert(revision)
+		}
+	}
+	return injected, revisions, nil
+}
+
+// findNamespacesUsingControlPlane returns the member namespaces containing
+// pods whose sidecars were injected by the control plane.  Pods injected by
+// other revisions don't use it.
+func (r *controlPlaneInstanceReconciler) findNamespacesUsingControlPlane(ctx context.Context) ([]string, error) {
+	namespaces, err := r.listMeshNamespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, namespace := range namespaces {
+		// workloads in the control plane namespace are deleted with it
+		if namespace.Name == r.Instance.Namespace {
+			continue
+		}
+		injected, revisions, err := r.findInjectedRevisions(ctx, namespace.Name)
+		if err != nil {
+			return nil, err
+		}
+		if injected && (revisions.Len() == 0 || revisions.Has(r.Instance.Name)) {
+			names = append(names, namespace.Name)
+		}
+	}
+	return names, nil
+}
+
+func (r *controlPlaneInstanceReconciler) setInUseCondition(conditionStatus status.ConditionStatus, reason status.ConditionReason, message string) bool {
+	condition := r.Status.GetCondition(status.ConditionTypeInUse)
+	if hasCondition(&r.Status.StatusType, status.ConditionTypeInUse) && condition.Matches(conditionStatus, reason, message) {
+		return false
+	}
+	r.Status.SetCondition(status.Condition{
+		Type:    status.ConditionTypeInUse,
+		Status:  conditionStatus,
+		Reason:  reason,
+		Message: message,
+	})
+	return true
+}
@@ -0,0 +1,196 @@
+package controlplane
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/maistra/istio-operator/pkg/apis/maistra/status"
+	maistrav2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/common/test"
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+	"github.com/maistra/istio-operator/pkg/controller/podlocality"
+)
+
+func TestUpdateInUseCondition(t *testing.T) {
+	testCases := []struct {
+		name               string
+		objects            []runtime.Object
+		expectedStatus     status.ConditionStatus
+		expectedNamespaces []string
+		expectedRevisions  []maistrav2.MeshNamespaceStatus
+	}{
+		{
+			name: "no-members",
+			objects: []runtime.Object{
+				newMemberNamespace(controlPlaneNamespace, controlPlaneNamespace),
+			},
+			expectedStatus: status.ConditionStatusFalse,
+		},
+		{
+			name: "members-without-sidecars",
+			objects: []runtime.Object{
+				newMemberNamespace("app", controlPlaneNamespace),
+				newTestPod("app", "app-1", false),
+			},
+			expectedStatus: status.ConditionStatusFalse,
+		},
+		{
+			name: "members-with-sidecars",
+			objects: []runtime.Object{
+				newMemberNamespace("app-a", controlPlaneNamespace),
+				newMemberNamespace("app-b", controlPlaneNamespace),
+				newMemberNamespace("app-c", controlPlaneNamespace),
+				newTestPod("app-b", "app-1", true),
+				newTestPod("app-b", "app-2", true),
+				newTestPod("app-a", "app-1", true),
+				newTestPod("app-c", "app-1", false),
+			},
+			expectedStatus:     status.ConditionStatusTrue,
+			expectedNamespaces: []string{"app-a", "app-b"},
+		},
+		{
+			name: "sidecars-from-this-revision",
+			objects: []runtime.Object{
+				newMemberNamespace("app", controlPlaneNamespace),
+				withRevision(newTestPod("app", "app-1", true), "test"),
+			},
+			expectedStatus:     status.ConditionStatusTrue,
+			expectedNamespaces: []string{"app"},
+			expectedRevisions: []maistrav2.MeshNamespaceStatus{
+				{Name: "app", InjectedRevisions: []string{"test"}},
+			},
+		},
+		{
+			name: "sidecars-from-other-revision",
+			objects: []runtime.Object{
+				newMemberNamespace("app-a", controlPlaneNamespace),
+				newMemberNamespace("app-b", controlPlaneNamespace),
+				withRevision(newTestPod("app-a", "app-1", true), "test"),
+				withRevision(newTestPod("app-b", "app-1", true), "test"),
+				withRevision(newTestPod("app-b", "app-2", true), "removed"),
+			},
+			expectedStatus:     status.ConditionStatusTrue,
+			expectedNamespaces: []string{"app-a", "app-b"},
+			expectedRevisions: []maistrav2.MeshNamespaceStatus{
+				{Name: "app-a", InjectedRevisions: []string{"test"}},
+				{Name: "app-b", InjectedRevisions: []string{"removed", "test"}, RevisionMismatch: true},
+			},
+		},
+		{
+			name: "sidecars-in-other-mesh",
+			objects: []runtime.Object{
+				newMemberNamespace("app", "other-mesh"),
+				newTestPod("app", "app-1", true),
+			},
+			expectedStatus: status.ConditionStatusFalse,
+		},
+		{
+			name: "sidecars-in-control-plane-namespace",
+			objects: []runtime.Object{
+				newMemberNamespace(controlPlaneNamespace, controlPlaneNamespace),
+				newTestPod(controlPlaneNamespace, "app-1", true),
+			},
+			expectedStatus: status.ConditionStatusFalse,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			smcp := &maistrav2.ServiceMeshControlPlane{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: controlPlaneNamespace,
+				},
+			}
+			cl, _ := test.CreateClient(tc.objects...)
+			instanceReconciler := newTestInstanceReconciler(cl, smcp)
+
+			assert.True(instanceReconciler.updateMeshNamespacesStatus(ctx), "expected status to be updated", t)
+			condition := instanceReconciler.Status.GetCondition(status.ConditionTypeInUse)
+			assert.Equals(condition.Status, tc.expectedStatus, "unexpected condition status: "+condition.Message, t)
+			var namespaces []string
+			var revisions []maistrav2.MeshNamespaceStatus
+			if meshNamespaces := instanceReconciler.Status.MeshNamespaces; meshNamespaces != nil {
+				for _, namespace := range meshNamespaces.Namespaces {
+					if namespace.InUse {
+						namespaces = append(namespaces, namespace.Name)
+						revisions = append(revisions, maistrav2.MeshNamespaceStatus{
+							Name:              namespace.Name,
+							InjectedRevisions: namespace.InjectedRevisions,
+							RevisionMismatch:  namespace.RevisionMismatch,
+						})
+					}
+				}
+				assert.Equals(int(meshNamespaces.InUse), len(namespaces), "unexpected number of namespaces in use", t)
+			}
+			assert.DeepEquals(namespaces, tc.expectedNamespaces, "unexpected namespaces", t)
+			if tc.expectedRevisions != nil {
+				assert.DeepEquals(revisions, tc.expectedRevisions, "unexpected namespace revisions", t)
+			}
+
+			assert.False(instanceReconciler.updateMeshNamespacesStatus(ctx), "expected no status update on second check", t)
+		})
+	}
+}
+
+func TestDelayedEnqueueCoalescesEvents(t *testing.T) {
+	eventHandler := &delayedEnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: controlPlaneNamespace, Name: "test"}}}
+		}),
+		Delay: 100 * time.Millisecond,
+	}
+	q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer q.ShutDown()
+
+	for i := 0; i < 5; i++ {
+		pod := newTestPod("app", fmt.Sprintf("app-%d", i), true)
+		eventHandler.Create(event.CreateEvent{Meta: pod, Object: pod}, q)
+	}
+	assert.Equals(q.Len(), 0, "expected requests to be delayed", t)
+
+	time.Sleep(500 * time.Millisecond)
+	assert.Equals(q.Len(), 1, "expected requests to be coalesced", t)
+}
+
+func newMemberNamespace(name, meshNamespace string) *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{common.MemberOfKey: meshNamespace},
+		},
+	}
+}
+
+func newTestPod(namespace, name string, injected bool) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+	if injected {
+		pod.Labels = map[string]string{injectedPodLabel: "istio"}
+		pod.Annotations = map[string]string{podlocality.IstioSidecarStatusAnnotation: `{"containers":["istio-proxy"]}`}
+	}
+	return pod
+}
+
+func withRevision(pod *corev1.Pod, revision string) *corev1.Pod {
+	if pod.Labels == nil {
+		pod.Labels = map[string]string{}
+	}
+	pod.Labels[common.IstioRevisionKey] = revision
+	return pod
+}
@@ -0,0 +1,86 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+)
+
+// istioCSRComponent is the name under which the readiness of the cert-manager
+// istio-csr Deployment is reported
+const istioCSRComponent = "istio-csr"
+
+// usesIstioCSR returns true if istiod doesn't sign workload certificates
+// itself, but relies on cert-manager istio-csr at the configured address.
+// Control planes older than v2.4 may omit the address, in which case
+// istio-csr can't be checked.
+func usesIstioCSR(smcp *v2.ServiceMeshControlPlane) bool {
+	security := smcp.Status.AppliedSpec.Security
+	return security != nil && security.CertificateAuthority != nil &&
+		security.CertificateAuthority.Type == v2.CertificateAuthorityTypeCertManager &&
+		security.CertificateAuthority.CertManager != nil &&
+		security.CertificateAuthority.CertManager.Address != ""
+}
+
+// istioCSRService returns the namespaced name of the Service in the address of
+// istio-csr, e.g. cert-manager-istio-csr.cert-manager.svc:443
+func istioCSRService(address string) (client.ObjectKey, error) {
+	host := address
+	if h, _, err := net.SplitHostPort(address); err == nil {
+		host = h
+	}
+	parts := strings.Split(host, ".")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return client.ObjectKey{}, fmt.Errorf("address %q does not refer to a Service in the cluster, "+
+			"expected <service>.<namespace>.svc[:<port>]", address)
+	}
+	return client.ObjectKey{Namespace: parts[1], Name: parts[0]}, nil
+}
+
+// istioCSRReadiness returns whether a Deployment backing the istio-csr Service
+// is available.  istio-csr isn't deployed for the control plane, so it isn't
+// included in the readiness of the control plane's own workloads.
+func (r *controlPlaneInstanceReconciler) istioCSRReadiness(ctx context.Context) bool {
+	log := common.LogFromContext(ctx)
+
+	address := r.Instance.Status.AppliedSpec.Security.CertificateAuthority.CertManager.Address
+	key, err := istioCSRService(address)
+	if err != nil {
+		log.Info(err.Error())
+		return false
+	}
+
+	service := &corev1.Service{}
+	if err := r.Client.Get(ctx, key, service); err != nil {
+		log.Info(fmt.Sprintf("Could not get istio-csr Service: %s", err), "service", key)
+		return false
+	}
+	if len(service.Spec.Selector) == 0 {
+		log.Info("istio-csr Service does not select any pods", "service", key)
+		return false
+	}
+
+	deployments := &appsv1.DeploymentList{}
+	if err := r.Client.List(ctx, deployments, client.InNamespace(key.Namespace)); err != nil {
+		log.Info(fmt.Sprintf("Could not list istio-csr Deployments: %s", err), "namespace", key.Namespace)
+		return false
+	}
+	selector := labels.SelectorFromSet(service.Spec.Selector)
+	for index := range deployments.Items {
+		deployment := &deployments.Items[index]
+		if selector.Matches(labels.Set(deployment.Spec.Template.Labels)) && isDeploymentReady(deployment) {
+			return true
+		}
+	}
+	log.Info("No available Deployment backs the istio-csr Service", "service", key)
+	return false
+}
@@ -0,0 +1,94 @@
+package controlplane
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/maistra/istio-operator/pkg/apis/maistra/status"
+	maistrav2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common/test"
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+)
+
+func TestUpdateReadinessStatusChecksIstioCSR(t *testing.T) {
+	istioCSRLabels := map[string]string{"app": "cert-manager-istio-csr"}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "cert-manager-istio-csr", Namespace: "cert-manager"},
+		Spec:       corev1.ServiceSpec{Selector: istioCSRLabels},
+	}
+	newIstioCSRDeployment := func(ready bool) runtime.Object {
+		deployment := newDeployment("cert-manager-istio-csr", "cert-manager", "", ready)
+		deployment.Spec.Template.Labels = istioCSRLabels
+		return deployment
+	}
+
+	testCases := []struct {
+		name            string
+		address         string
+		objects         []runtime.Object
+		expectedStatus  status.ConditionStatus
+		expectedReady   []string
+		expectedUnready []string
+	}{
+		{
+			name:            "available",
+			address:         "cert-manager-istio-csr.cert-manager.svc:443",
+			objects:         []runtime.Object{service, newIstioCSRDeployment(true)},
+			expectedStatus:  status.ConditionStatusTrue,
+			expectedReady:   []string{istioCSRComponent, "istio-ingressgateway"},
+			expectedUnready: []string{},
+		},
+		{
+			name:            "unavailable",
+			address:         "cert-manager-istio-csr.cert-manager.svc:443",
+			objects:         []runtime.Object{service, newIstioCSRDeployment(false)},
+			expectedStatus:  status.ConditionStatusFalse,
+			expectedReady:   []string{"istio-ingressgateway"},
+			expectedUnready: []string{istioCSRComponent},
+		},
+		{
+			name:            "missing-service",
+			address:         "cert-manager-istio-csr.cert-manager.svc:443",
+			objects:         []runtime.Object{newIstioCSRDeployment(true)},
+			expectedStatus:  status.ConditionStatusFalse,
+			expectedReady:   []string{"istio-ingressgateway"},
+			expectedUnready: []string{istioCSRComponent},
+		},
+		{
+			name:            "address-outside-cluster",
+			address:         "istio-csr:443",
+			objects:         []runtime.Object{service, newIstioCSRDeployment(true)},
+			expectedStatus:  status.ConditionStatusFalse,
+			expectedReady:   []string{"istio-ingressgateway"},
+			expectedUnready: []string{istioCSRComponent},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			smcp := newControlPlane()
+			smcp.Spec.Security = &maistrav2.SecurityConfig{
+				CertificateAuthority: &maistrav2.CertificateAuthorityConfig{
+					Type:        maistrav2.CertificateAuthorityTypeCertManager,
+					CertManager: &maistrav2.CertManagerCertificateAuthorityConfig{Address: tc.address},
+				},
+			}
+			smcp.Status.AppliedSpec = smcp.Spec
+			smcp.Status.SetCondition(status.Condition{Type: status.ConditionTypeReconciled, Status: status.ConditionStatusTrue})
+			smcp.Status.ComponentStatus = []status.ComponentStatus{{Resource: "istio-ingressgateway"}}
+
+			objects := append([]runtime.Object{smcp, newDeployment("istio-ingressgateway", controlPlaneNamespace, "istio-ingressgateway", true)},
+				tc.objects...)
+			cl, _ := test.CreateClient(objects...)
+			r := newTestInstanceReconciler(cl, smcp)
+
+			assert.True(r.updateReadinessStatus(ctx), "Expected status to be updated", t)
+			assert.Equals(r.Status.GetCondition(status.ConditionTypeReady).Status, tc.expectedStatus, "Unexpected Ready condition status", t)
+			assert.DeepEquals(r.Status.Readiness.Components["ready"], tc.expectedReady, "Unexpected ready components", t)
+			assert.DeepEquals(r.Status.Readiness.Components["unready"], tc.expectedUnready, "Unexpected unready components", t)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/versions"
+)
+
+// syncStatusPath is the debug endpoint of istiod that returns the XDS sync
+// status of the connected proxies.  It's only served once istiod has
+// initialized its XDS server.
+const syncStatusPath = "/debug/syncz"
+
+// checkIstiodXDSOfComponents checks that istiod is serving, if enabled in the
+// operator configuration and istiod is one of the components of the control
+// plane
+func (r *controlPlaneInstanceReconciler) checkIstiodXDSOfComponents(ctx context.Context, components sets.String) error {
+	if !common.Config.Controller.IstiodXDSReadinessCheck || usesExternalControlPlane(r.Instance) ||
+		!components.Has(componentFromChartName(versions.DiscoveryChart)) {
+		return nil
+	}
+	return r.checkIstiodXDS(ctx)
+}
+
+// checkIstiodXDS returns an error if istiod doesn't respond to requests for
+// the sync status of its proxies through its Service.  The pods of istiod
+// being ready doesn't guarantee that this succeeds, e.g. if the Service
+// doesn't select them or istiod is still initializing its XDS server.
+func (r *controlPlaneInstanceReconciler) checkIstiodXDS(ctx context.Context) error {
+	url := fmt.Sprintf("http://istiod-%s.%s.svc:%d%s", r.Instance.GetName(), r.Instance.GetNamespace(),
+		istiodDebugPort, syncStatusPath)
+	// only the response status is of interest
+	_, err := fetchIstiodDebug(ctx, url, 0)
+	return err
+}
@@ -0,0 +1,82 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+
+	"github.com/maistra/istio-operator/pkg/apis/maistra/status"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/common/test"
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+)
+
+func TestUpdateReadinessStatusChecksIstiodXDS(t *testing.T) {
+	testCases := []struct {
+		name           string
+		checkEnabled   bool
+		fetchErr       error
+		expectedStatus status.ConditionStatus
+		expectedReason status.ConditionReason
+		expectFetch    bool
+	}{
+		{
+			name:           "disabled",
+			checkEnabled:   false,
+			fetchErr:       fmt.Errorf("connection refused"),
+			expectedStatus: status.ConditionStatusTrue,
+			expectedReason: status.ConditionReasonComponentsReady,
+		},
+		{
+			name:           "serving",
+			checkEnabled:   true,
+			expectedStatus: status.ConditionStatusTrue,
+			expectedReason: status.ConditionReasonComponentsReady,
+			expectFetch:    true,
+		},
+		{
+			name:           "not-serving",
+			checkEnabled:   true,
+			fetchErr:       fmt.Errorf("connection refused"),
+			expectedStatus: status.ConditionStatusFalse,
+			expectedReason: status.ConditionReasonXDSNotReady,
+			expectFetch:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func(enabled bool) { common.Config.Controller.IstiodXDSReadinessCheck = enabled }(common.Config.Controller.IstiodXDSReadinessCheck)
+			common.Config.Controller.IstiodXDSReadinessCheck = tc.checkEnabled
+			var fetchedURLs []string
+			defer func(original istiodDebugFetcher) { fetchIstiodDebug = original }(fetchIstiodDebug)
+			fetchIstiodDebug = func(_ context.Context, url string, _ int64) ([]byte, error) {
+				fetchedURLs = append(fetchedURLs, url)
+				return nil, tc.fetchErr
+			}
+
+			smcp := newControlPlane()
+			smcp.Status.SetCondition(status.Condition{Type: status.ConditionTypeReconciled, Status: status.ConditionStatusTrue})
+			smcp.Status.ComponentStatus = []status.ComponentStatus{{Resource: "istio-discovery"}}
+			serviceConfig := admissionv1.WebhookClientConfig{
+				Service: &admissionv1.ServiceReference{Name: "istiod-" + controlPlaneName, Namespace: controlPlaneNamespace},
+			}
+			cl, _ := test.CreateClient(smcp, newDeployment("istiod-"+controlPlaneName, controlPlaneNamespace, "istio-discovery", true),
+				newInjectionWebhookConfig(serviceConfig), newIstiodEndpoints(true))
+			r := newTestInstanceReconciler(cl, smcp)
+
+			assert.True(r.updateReadinessStatus(ctx), "Expected status to be updated", t)
+			readyCondition := r.Status.GetCondition(status.ConditionTypeReady)
+			assert.Equals(readyCondition.Status, tc.expectedStatus, "Unexpected Ready condition status", t)
+			assert.Equals(readyCondition.Reason, tc.expectedReason, "Unexpected Ready condition reason", t)
+			if tc.expectFetch {
+				assert.DeepEquals(fetchedURLs, []string{"http://istiod-" + controlPlaneName + "." + controlPlaneNamespace + ".svc:15014/debug/syncz"},
+					"unexpected requests to istiod", t)
+			} else {
+				assert.Equals(len(fetchedURLs), 0, "expected istiod not to be queried", t)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package controlplane
 import (
 	"context"
 
+	v2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
 	"github.com/maistra/istio-operator/pkg/controller/common"
 	"github.com/maistra/istio-operator/pkg/controller/common/helm"
 )
@@ -27,6 +28,7 @@ func (r *controlPlaneInstanceReconciler) processComponentManifests(ctx context.C
 
 	mp := helm.NewManifestProcessor(r.ControllerResources, helm.NewPatchFactory(r.Client), r.Instance.GetNamespace(),
 		r.meshGeneration, common.ToNamespacedName(r.Instance), r.preprocessObject, r.processNewObject, r.preprocessObjectForPatch)
+	mp.Adopt = isAdoptionEnabled(r.Instance)
 	if madeChanges, err = mp.ProcessManifests(ctx, renderings, status.Resource); err != nil {
 		return madeChanges, err
 	}
@@ -38,6 +40,13 @@ func (r *controlPlaneInstanceReconciler) processComponentManifests(ctx context.C
 	return madeChanges, nil
 }
 
+// isAdoptionEnabled returns true if the control plane takes over the objects
+// of an existing Istio installation, see common.AdoptKey
+func isAdoptionEnabled(instance *v2.ServiceMeshControlPlane) bool {
+	value, _ := common.GetAnnotation(instance, common.AdoptKey)
+	return value == "true"
+}
+
 func (r *controlPlaneInstanceReconciler) anyComponentHasReadiness(chartName string) bool {
 	for _, rendering := range r.renderings[chartName] {
 		if r.hasReadiness(rendering.Head.Kind) {
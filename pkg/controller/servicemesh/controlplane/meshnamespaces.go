@@ -0,0 +1,157 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/maistra/istio-operator/pkg/apis/maistra/status"
+	v2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+)
+
+// updateMeshNamespacesStatus updates the list of mesh namespaces, their
+// injection settings and whether their workloads use the control plane in
+// status.meshNamespaces, as well as the InUse condition.  The control plane is
+// reconciled whenever a namespace joins or leaves the mesh, its injection
+// labels change or injected pods come and go, so the list is kept up to date
+// without periodic rechecks.  It returns true if the status was changed.
+func (r *controlPlaneInstanceReconciler) updateMeshNamespacesStatus(ctx context.Context) bool {
+	log := common.LogFromContext(ctx)
+
+	namespaces, err := r.listMeshNamespaces(ctx)
+	if err != nil {
+		// keep the last known list, as the namespaces are unlikely to have changed
+		log.Error(err, "error listing mesh namespaces")
+		return r.setInUseCondition(status.ConditionStatusUnknown, status.ConditionReasonProbeError,
+			fmt.Sprintf("Error checking whether the control plane is in use: %s", err))
+	}
+
+	meshNamespaces := &v2.MeshNamespacesStatus{
+		Total: int32(len(namespaces)),
+	}
+	limit := common.Config.Controller.MeshNamespacesStatusLimit
+	var inUseNamespaces, mismatchedNamespaces []string
+	for _, namespace := range namespaces {
+		namespaceStatus := v2.MeshNamespaceStatus{
+			Name:           namespace.Name,
+			InjectionLabel: namespace.Labels[common.IstioInjectionKey],
+			Revision:       namespace.Labels[common.IstioRevisionKey],
+			Injection:      namespaceInjection(namespace.Labels, r.Instance.Name),
+		}
+		// workloads in the control plane namespace don't keep it in use
+		if namespace.Name != r.Instance.Namespace {
+			injected, revisions, err := r.findInjectedRevisions(ctx, namespace.Name)
+			if err != nil {
+				log.Error(err, "error checking whether the control plane is in use")
+				return r.setInUseCondition(status.ConditionStatusUnknown, status.ConditionReasonProbeError,
+					fmt.Sprintf("Error checking whether the control plane is in use: %s", err))
+			}
+			if injected {
+				namespaceStatus.InUse = true
+				namespaceStatus.RevisionMismatch = revisions.Len() > 0 && !revisions.Equal(sets.NewString(r.Instance.Name))
+				if revisions.Len() > 0 {
+					namespaceStatus.InjectedRevisions = revisions.List()
+				}
+				meshNamespaces.InUse++
+				if len(inUseNamespaces) < limit {
+					inUseNamespaces = append(inUseNamespaces, namespace.Name)
+				}
+				if namespaceStatus.RevisionMismatch && len(mismatchedNamespaces) < limit {
+					mismatchedNamespaces = append(mismatchedNamespaces, namespace.Name)
+				}
+			}
+		}
+		if len(meshNamespaces.Namespaces) < limit {
+			meshNamespaces.Namespaces = append(meshNamespaces.Namespaces, namespaceStatus)
+		} else {
+			meshNamespaces.Truncated = true
+		}
+	}
+	updated := r.updateInUseCondition(int(meshNamespaces.InUse), inUseNamespaces, mismatchedNamespaces)
+	if meshNamespaces.Total == 0 {
+		meshNamespaces = nil
+	}
+	if reflect.DeepEqual(r.Status.MeshNamespaces, meshNamespaces) {
+		return updated
+	}
+	r.Status.MeshNamespaces = meshNamespaces
+	return true
+}
+
+// listMeshNamespaces returns the namespaces labeled as members of the mesh,
+// sorted by name.  It is the single place that lists the mesh namespaces, for
+// the status as well as the CA rotation and the gateway class parameters.
+func (r *controlPlaneInstanceReconciler) listMeshNamespaces(ctx context.Context) ([]corev1.Namespace, error) {
+	namespaceList := &corev1.NamespaceList{}
+	if err := r.Client.List(ctx, namespaceList, client.MatchingLabels{common.MemberOfKey: r.Instance.Namespace}); err != nil {
+		return nil, fmt.Errorf("could not list mesh namespaces: %s", err)
+	}
+	sort.Slice(namespaceList.Items, func(i, j int) bool {
+		return namespaceList.Items[i].Name < namespaceList.Items[j].Name
+	})
+	return namespaceList.Items, nil
+}
+
+// listMeshNamespaceNames returns the names of the control plane namespace and
+// the member namespaces of the mesh
+func (r *controlPlaneInstanceReconciler) listMeshNamespaceNames(ctx context.Context) ([]string, error) {
+	namespaces, err := r.listMeshNamespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{r.Instance.Namespace}
+	for _, namespace := range namespaces {
+		if namespace.Name != r.Instance.Namespace {
+			names = append(names, namespace.Name)
+		}
+	}
+	return names, nil
+}
+
+// namespaceInjection derives whether sidecars are injected into the pods of a
+// namespace from its labels.  Like in istiod, the istio-injection label takes
+// precedence over the istio.io/rev label.
+func namespaceInjection(labels map[string]string, revision string) v2.MeshNamespaceInjection {
+	if _, ignored := labels[common.IgnoreNamespaceKey]; ignored {
+		return v2.MeshNamespaceInjectionDisabled
+	}
+	switch labels[common.IstioInjectionKey] {
+	case "enabled":
+		return v2.MeshNamespaceInjectionEnabled
+	case "disabled":
+		return v2.MeshNamespaceInjectionDisabled
+	}
+	switch labels[common.IstioRevisionKey] {
+	case "":
+		return v2.MeshNamespaceInjectionPerPod
+	case revision:
+		return v2.MeshNamespaceInjectionEnabled
+	default:
+		return v2.MeshNamespaceInjectionOtherRevision
+	}
+}
+
+// isMeshNamespaceChange returns true if a namespace joined or left a mesh, or
+// if the injection labels of a mesh namespace changed
+func isMeshNamespaceChange(oldLabels, newLabels map[string]string) bool {
+	if oldLabels[common.MemberOfKey] != newLabels[common.MemberOfKey] {
+		return true
+	}
+	if newLabels[common.MemberOfKey] == "" {
+		return false
+	}
+	for _, key := range []string{common.IstioInjectionKey, common.IstioRevisionKey, common.IgnoreNamespaceKey} {
+		oldValue, oldOk := oldLabels[key]
+		newValue, newOk := newLabels[key]
+		if oldValue != newValue || oldOk != newOk {
+			return true
+		}
+	}
+	return false
+}
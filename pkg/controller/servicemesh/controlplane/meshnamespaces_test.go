@@ -0,0 +1,141 @@
+package controlplane
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/maistra/istio-operator/pkg/apis/maistra/status"
+	maistrav2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/common/test"
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+)
+
+func newMeshNamespace(name string, labels map[string]string) *corev1.Namespace {
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   name,
+		Labels: map[string]string{common.MemberOfKey: controlPlaneNamespace},
+	}}
+	for key, value := range labels {
+		namespace.Labels[key] = value
+	}
+	return namespace
+}
+
+func TestUpdateMeshNamespacesStatus(t *testing.T) {
+	defer func(limit int) {
+		common.Config.Controller.MeshNamespacesStatusLimit = limit
+	}(common.Config.Controller.MeshNamespacesStatusLimit)
+	common.Config.Controller.MeshNamespacesStatusLimit = 3
+
+	smcp := newControlPlane()
+	cl, _ := test.CreateClient(smcp,
+		newMeshNamespace(controlPlaneNamespace, nil),
+		newMeshNamespace("app-a", map[string]string{common.IstioInjectionKey: "enabled"}),
+		newMeshNamespace("app-b", map[string]string{common.IstioRevisionKey: "other-revision"}),
+		newMeshNamespace("app-c", map[string]string{common.IstioInjectionKey: "disabled"}),
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "not-a-member"}})
+	r := newTestInstanceReconciler(cl, smcp)
+
+	assert.True(r.updateMeshNamespacesStatus(ctx), "Expected status to be updated", t)
+	assert.DeepEquals(r.Status.MeshNamespaces, &maistrav2.MeshNamespacesStatus{
+		Total:     4,
+		Truncated: true,
+		Namespaces: []maistrav2.MeshNamespaceStatus{
+			{Name: "app-a", InjectionLabel: "enabled", Injection: maistrav2.MeshNamespaceInjectionEnabled},
+			{Name: "app-b", Revision: "other-revision", Injection: maistrav2.MeshNamespaceInjectionOtherRevision},
+			{Name: "app-c", InjectionLabel: "disabled", Injection: maistrav2.MeshNamespaceInjectionDisabled},
+		},
+	}, "Unexpected mesh namespaces", t)
+	assert.False(r.updateMeshNamespacesStatus(ctx), "Expected status to be unchanged", t)
+
+	common.Config.Controller.MeshNamespacesStatusLimit = 10
+	assert.True(r.updateMeshNamespacesStatus(ctx), "Expected status to be updated", t)
+	assert.False(r.Status.MeshNamespaces.Truncated, "Expected list not to be truncated", t)
+	assert.Equals(len(r.Status.MeshNamespaces.Namespaces), 4, "Unexpected number of namespaces", t)
+	assert.Equals(r.Status.MeshNamespaces.Namespaces[3].Injection, maistrav2.MeshNamespaceInjectionPerPod, "Unexpected injection", t)
+}
+
+func TestUpdateMeshNamespacesStatusCapsNamespacesInUse(t *testing.T) {
+	defer func(limit int) {
+		common.Config.Controller.MeshNamespacesStatusLimit = limit
+	}(common.Config.Controller.MeshNamespacesStatusLimit)
+	common.Config.Controller.MeshNamespacesStatusLimit = 2
+
+	smcp := newControlPlane()
+	cl, _ := test.CreateClient(smcp,
+		newMeshNamespace("app-a", nil),
+		newMeshNamespace("app-b", nil),
+		newMeshNamespace("app-c", nil),
+		newTestPod("app-a", "app-1", true),
+		newTestPod("app-b", "app-1", true),
+		newTestPod("app-c", "app-1", true))
+	r := newTestInstanceReconciler(cl, smcp)
+
+	assert.True(r.updateMeshNamespacesStatus(ctx), "Expected status to be updated", t)
+	assert.Equals(r.Status.MeshNamespaces.InUse, int32(3), "Unexpected number of namespaces in use", t)
+	assert.True(r.Status.MeshNamespaces.Truncated, "Expected list to be truncated", t)
+	assert.Equals(len(r.Status.MeshNamespaces.Namespaces), 2, "Unexpected number of namespaces", t)
+	condition := r.Status.GetCondition(status.ConditionTypeInUse)
+	assert.Equals(condition.Message, "Workloads in the following namespaces use the control plane: app-a, app-b and 1 more",
+		"Unexpected InUse message", t)
+}
+
+func TestNamespaceInjection(t *testing.T) {
+	testCases := []struct {
+		name     string
+		labels   map[string]string
+		expected maistrav2.MeshNamespaceInjection
+	}{
+		{
+			name:     "no-labels",
+			expected: maistrav2.MeshNamespaceInjectionPerPod,
+		},
+		{
+			name:     "injection-enabled",
+			labels:   map[string]string{common.IstioInjectionKey: "enabled"},
+			expected: maistrav2.MeshNamespaceInjectionEnabled,
+		},
+		{
+			name:     "injection-disabled",
+			labels:   map[string]string{common.IstioInjectionKey: "disabled", common.IstioRevisionKey: controlPlaneName},
+			expected: maistrav2.MeshNamespaceInjectionDisabled,
+		},
+		{
+			name:     "own-revision",
+			labels:   map[string]string{common.IstioRevisionKey: controlPlaneName},
+			expected: maistrav2.MeshNamespaceInjectionEnabled,
+		},
+		{
+			name:     "other-revision",
+			labels:   map[string]string{common.IstioRevisionKey: "other-revision"},
+			expected: maistrav2.MeshNamespaceInjectionOtherRevision,
+		},
+		{
+			name:     "ignored",
+			labels:   map[string]string{common.IgnoreNamespaceKey: "ignore", common.IstioInjectionKey: "enabled"},
+			expected: maistrav2.MeshNamespaceInjectionDisabled,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equals(namespaceInjection(tc.labels, controlPlaneName), tc.expected, "Unexpected injection", t)
+		})
+	}
+}
+
+func TestIsMeshNamespaceChange(t *testing.T) {
+	member := map[string]string{common.MemberOfKey: controlPlaneNamespace}
+	injected := map[string]string{common.MemberOfKey: controlPlaneNamespace, common.IstioInjectionKey: "enabled"}
+
+	assert.True(isMeshNamespaceChange(nil, member), "Expected joining the mesh to be a change", t)
+	assert.True(isMeshNamespaceChange(member, nil), "Expected leaving the mesh to be a change", t)
+	assert.True(isMeshNamespaceChange(member, injected), "Expected injection label change to be a change", t)
+	assert.False(isMeshNamespaceChange(member, map[string]string{common.MemberOfKey: controlPlaneNamespace, "app": "foo"}),
+		"Expected unrelated label change not to be a change", t)
+	assert.False(isMeshNamespaceChange(nil, map[string]string{common.IstioInjectionKey: "enabled"}),
+		"Expected changes of namespaces outside the mesh to be ignored", t)
+}
@@ -0,0 +1,47 @@
+package controlplane
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	requeueReasonError   = "error"
+	requeueReasonRequeue = "requeue"
+)
+
+// requeuesTotal counts the requeues of each control plane, so that control
+// planes that keep the reconcilers busy can be identified
+var requeuesTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "servicemesh_control_plane_requeues_total",
+		Help: "Number of times the reconciliation of a ServiceMeshControlPlane was requeued, by reason",
+	},
+	[]string{"namespace", "name", "reason"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(requeuesTotal)
+}
+
+// recordRequeue counts the requeue of the control plane, if its reconciliation
+// failed or explicitly requested a requeue.  A result with only RequeueAfter
+// set isn't counted, as it schedules a periodic recheck or waits for a backoff
+// to pass, which doesn't keep the reconcilers busy.
+func recordRequeue(key types.NamespacedName, result reconcile.Result, err error) {
+	switch {
+	case err != nil:
+		requeuesTotal.WithLabelValues(key.Namespace, key.Name, requeueReasonError).Inc()
+	case result.Requeue:
+		requeuesTotal.WithLabelValues(key.Namespace, key.Name, requeueReasonRequeue).Inc()
+	}
+}
+
+// forgetRequeues removes the requeue counts of a deleted control plane
+func forgetRequeues(key types.NamespacedName) {
+	for _, reason := range []string{requeueReasonError, requeueReasonRequeue} {
+		requeuesTotal.DeleteLabelValues(key.Namespace, key.Name, reason)
+	}
+}
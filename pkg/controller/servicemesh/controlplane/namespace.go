@@ -0,0 +1,55 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/maistra/istio-operator/pkg/apis/maistra/status"
+	v2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+)
+
+// isNamespaceTerminating returns true if the namespace of the control plane
+// is being deleted
+func isNamespaceTerminating(ctx context.Context, cl client.Client, instance *v2.ServiceMeshControlPlane) (bool, error) {
+	namespace := &corev1.Namespace{}
+	if err := cl.Get(ctx, client.ObjectKey{Name: instance.Namespace}, namespace); err != nil {
+		if errors.IsNotFound(err) {
+			// the namespace isn't in the cache yet; the reconciliation fails
+			// on its own if it's really gone
+			return false, nil
+		}
+		return false, err
+	}
+	return namespace.DeletionTimestamp != nil || namespace.Status.Phase == corev1.NamespaceTerminating, nil
+}
+
+// NamespaceTerminating is invoked instead of Reconcile once the namespace of
+// the control plane is being deleted.  Nothing can be installed in the
+// namespace anymore, so the charts aren't rendered and the Reconciled and
+// Ready conditions are set to False until the control plane is deleted with
+// the namespace.
+func (r *controlPlaneInstanceReconciler) NamespaceTerminating(ctx context.Context) error {
+	message := fmt.Sprintf("The namespace %s is being deleted", r.Instance.Namespace)
+	update := false
+	for _, conditionType := range []status.ConditionType{status.ConditionTypeReconciled, status.ConditionTypeReady} {
+		condition := r.Status.GetCondition(conditionType)
+		if !condition.Matches(status.ConditionStatusFalse, status.ConditionReasonNamespaceTerminating, message) {
+			r.Status.SetCondition(status.Condition{
+				Type:    conditionType,
+				Status:  status.ConditionStatusFalse,
+				Reason:  status.ConditionReasonNamespaceTerminating,
+				Message: message,
+			})
+			update = true
+		}
+	}
+	if update {
+		r.EventRecorder.Event(r.Instance, corev1.EventTypeWarning, eventReasonNamespaceTerminating, message)
+		return r.PostStatus(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package controlplane
+
+import (
+	"testing"
+
+	"github.com/maistra/istio-operator/pkg/apis/maistra/status"
+	maistrav2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/common/test"
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+)
+
+func TestNamespaceTerminatingOnlyUpdatesStatus(t *testing.T) {
+	controlPlane := newControlPlane()
+
+	cl, tracker, r := newReconcilerTestFixture(controlPlane)
+
+	assert.Success(r.NamespaceTerminating(ctx), "NamespaceTerminating", t)
+
+	// only the status of the control plane is updated
+	test.AssertNumberOfWriteActions(t, tracker.Actions(), 1)
+	updatedControlPlane := &maistrav2.ServiceMeshControlPlane{}
+	test.PanicOnError(cl.Get(ctx, common.ToNamespacedName(controlPlane), updatedControlPlane))
+	for _, conditionType := range []status.ConditionType{status.ConditionTypeReconciled, status.ConditionTypeReady} {
+		condition := updatedControlPlane.Status.GetCondition(conditionType)
+		assert.Equals(condition.Status, status.ConditionStatusFalse, "unexpected condition status", t)
+		assert.Equals(condition.Reason, status.ConditionReasonNamespaceTerminating, "unexpected condition reason", t)
+	}
+
+	// the status isn't updated again if nothing changed
+	tracker.ClearActions()
+	r.SetInstance(updatedControlPlane)
+	assert.Success(r.NamespaceTerminating(ctx), "NamespaceTerminating", t)
+	test.AssertNumberOfWriteActions(t, tracker.Actions(), 0)
+}
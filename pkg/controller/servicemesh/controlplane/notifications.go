@@ -0,0 +1,205 @@
+package controlplane
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"text/template"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	v2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+)
+
+// defaultNotificationTemplate renders notifications as a JSON object
+const defaultNotificationTemplate = `{"namespace":{{ json .Namespace }},"name":{{ json .Name }},"version":{{ json .Version }},` +
+	`"type":{{ json .Type }},"reason":{{ json .Reason }},"message":{{ json .Message }}}`
+
+// defaultNotificationContentType is the content type of the default template
+const defaultNotificationContentType = "application/json"
+
+// Notification describes a state change of a ServiceMeshControlPlane.  It is
+// the data passed to the notification template.
+type Notification struct {
+	Namespace string
+	Name      string
+	Version   string
+	// Type is the type of the corresponding event, i.e. Normal or Warning
+	Type    string
+	Reason  string
+	Message string
+	Time    time.Time
+}
+
+// isNotifiedEvent returns true if the event with the specified type and reason
+// signals a state change that external systems are notified about: the control
+// plane became ready or not ready, or an installation or update succeeded or
+// failed.
+func isNotifiedEvent(eventType, reason string) bool {
+	switch reason {
+	case eventReasonReady, eventReasonNotReady, eventReasonInstalled, eventReasonUpdated:
+		return true
+	case eventReasonInstalling, eventReasonUpdating:
+		// these are only warnings if the installation or update failed
+		return eventType == corev1.EventTypeWarning
+	}
+	return false
+}
+
+// notifyingEventRecorder posts a notification to an external endpoint for
+// every event that signals a state change of a ServiceMeshControlPlane, in
+// addition to recording the event.
+type notifyingEventRecorder struct {
+	record.EventRecorder
+	url         string
+	contentType string
+	template    *template.Template
+	client      *http.Client
+	now         func() time.Time
+
+	// readiness holds the reason of the last readiness notification sent for
+	// each control plane, i.e. Ready or NotReady.  The Ready condition is
+	// updated whenever its message changes or probing fails, but the endpoint
+	// is only notified when the control plane becomes ready or not ready.
+	readinessLock sync.Mutex
+	readiness     map[types.UID]string
+}
+
+var _ record.EventRecorder = (*notifyingEventRecorder)(nil)
+
+// newNotifyingEventRecorder wraps eventRecorder, so state changes are posted to
+// the endpoint configured in common.Config.Notification.  eventRecorder is
+// returned unchanged if no endpoint is configured.
+func newNotifyingEventRecorder(eventRecorder record.EventRecorder) (record.EventRecorder, error) {
+	config := common.Config.Notification
+	if config.URL == "" {
+		return eventRecorder, nil
+	}
+	return newNotifyingEventRecorderForURL(eventRecorder, config.URL, config.ContentType, config.Template, config.Timeout)
+}
+
+func newNotifyingEventRecorderForURL(eventRecorder record.EventRecorder, url, contentType, tmpl string, timeout time.Duration,
+) (*notifyingEventRecorder, error) {
+	if tmpl == "" {
+		tmpl = defaultNotificationTemplate
+	}
+	t, err := template.New("notification").Funcs(template.FuncMap{
+		"json": func(value interface{}) (string, error) {
+			data, err := json.Marshal(value)
+			return string(data), err
+		},
+	}).Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid notification template: %s", err)
+	}
+	if contentType == "" {
+		contentType = defaultNotificationContentType
+	}
+	return &notifyingEventRecorder{
+		EventRecorder: eventRecorder,
+		url:           url,
+		contentType:   contentType,
+		template:      t,
+		client:        &http.Client{Timeout: timeout},
+		now:           time.Now,
+		readiness:     map[types.UID]string{},
+	}, nil
+}
+
+func (r *notifyingEventRecorder) Event(object runtime.Object, eventType, reason, message string) {
+	r.EventRecorder.Event(object, eventType, reason, message)
+	r.notify(object, eventType, reason, message)
+}
+
+func (r *notifyingEventRecorder) Eventf(object runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	r.EventRecorder.Eventf(object, eventType, reason, messageFmt, args...)
+	r.notify(object, eventType, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *notifyingEventRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string,
+	eventType, reason, messageFmt string, args ...interface{},
+) {
+	r.EventRecorder.AnnotatedEventf(object, annotations, eventType, reason, messageFmt, args...)
+	r.notify(object, eventType, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *notifyingEventRecorder) notify(object runtime.Object, eventType, reason, message string) {
+	if !isNotifiedEvent(eventType, reason) {
+		return
+	}
+	log := createLogger()
+	objectMeta, err := meta.Accessor(object)
+	if err != nil {
+		log.Error(err, "cannot send notification for object without metadata")
+		return
+	}
+	if !r.readinessChanged(objectMeta.GetUID(), reason) {
+		return
+	}
+	notification := Notification{
+		Namespace: objectMeta.GetNamespace(),
+		Name:      objectMeta.GetName(),
+		Type:      eventType,
+		Reason:    reason,
+		Message:   message,
+		Time:      r.now(),
+	}
+	if smcp, ok := object.(*v2.ServiceMeshControlPlane); ok {
+		notification.Version = smcp.Spec.Version
+	}
+	body := &bytes.Buffer{}
+	if err := r.template.Execute(body, notification); err != nil {
+		log.Error(err, "error rendering notification", "ServiceMeshControlPlane", common.ToNamespacedName(objectMeta))
+		return
+	}
+	// notifications are sent asynchronously, so an unresponsive endpoint
+	// doesn't slow down reconciliation
+	go func() {
+		if err := r.send(body.Bytes()); err != nil {
+			log.Error(err, "error sending notification", "ServiceMeshControlPlane", common.ToNamespacedName(objectMeta),
+				"Reason", reason)
+		}
+	}()
+}
+
+func (r *notifyingEventRecorder) send(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, r.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", r.contentType)
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status from notification endpoint: %s", resp.Status)
+	}
+	return nil
+}
+
+// readinessChanged returns false if reason is Ready or NotReady and the last
+// readiness notification sent for the control plane had the same reason
+func (r *notifyingEventRecorder) readinessChanged(uid types.UID, reason string) bool {
+	r.readinessLock.Lock()
+	defer r.readinessLock.Unlock()
+	switch reason {
+	case eventReasonReady, eventReasonNotReady:
+		if r.readiness[uid] == reason {
+			return false
+		}
+		r.readiness[uid] = reason
+	case eventReasonDeleted:
+		delete(r.readiness, uid)
+	}
+	return true
+}
@@ -0,0 +1,111 @@
+package controlplane
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+	"github.com/maistra/istio-operator/pkg/controller/versions"
+)
+
+func TestNotifyingEventRecorder(t *testing.T) {
+	bodies := make(chan []byte, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if contentType := r.Header.Get("Content-Type"); contentType != defaultNotificationContentType {
+			t.Errorf("unexpected Content-Type: %s", contentType)
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies <- body
+	}))
+	defer server.Close()
+
+	fakeRecorder := record.NewFakeRecorder(10)
+	recorder, err := newNotifyingEventRecorderForURL(fakeRecorder, server.URL, "", "", time.Second)
+	assert.Success(err, "newNotifyingEventRecorderForURL", t)
+
+	smcp := newControlPlane()
+	smcp.Spec.Version = versions.V2_4.String()
+	recorder.Event(smcp, corev1.EventTypeNormal, eventReasonPruning, "Pruning obsolete resources")
+	recorder.Event(smcp, corev1.EventTypeNormal, eventReasonInstalling, "Installing mesh generation 1")
+	recorder.Event(smcp, corev1.EventTypeWarning, eventReasonNotReady, `component "istiod" is "not" ready`)
+	assert.Equals(len(fakeRecorder.Events), 3, "expected all events to be recorded", t)
+
+	select {
+	case body := <-bodies:
+		notification := map[string]string{}
+		assert.Success(json.Unmarshal(body, &notification), "json.Unmarshal", t)
+		assert.DeepEquals(notification, map[string]string{
+			"namespace": smcp.Namespace,
+			"name":      smcp.Name,
+			"version":   versions.V2_4.String(),
+			"type":      corev1.EventTypeWarning,
+			"reason":    eventReasonNotReady,
+			"message":   `component "istiod" is "not" ready`,
+		}, "unexpected notification", t)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for notification")
+	}
+	select {
+	case body := <-bodies:
+		t.Fatalf("unexpected notification: %s", body)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestNotificationTemplate(t *testing.T) {
+	recorder, err := newNotifyingEventRecorderForURL(record.NewFakeRecorder(1), "http://localhost", "",
+		`{"text": {{ json (printf "%s/%s: %s" .Namespace .Name .Message) }}}`, time.Second)
+	assert.Success(err, "newNotifyingEventRecorderForURL", t)
+	body := &bytes.Buffer{}
+	assert.Success(recorder.template.Execute(body, Notification{Namespace: "istio-system", Name: "basic", Message: "Ready"}),
+		"Execute", t)
+	assert.Equals(body.String(), `{"text": "istio-system/basic: Ready"}`, "unexpected notification body", t)
+
+	_, err = newNotifyingEventRecorderForURL(record.NewFakeRecorder(1), "http://localhost", "", "{{ .Missing", time.Second)
+	assert.Failure(err, "newNotifyingEventRecorderForURL", t)
+}
+
+func TestNotificationContentType(t *testing.T) {
+	contentTypes := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentTypes <- r.Header.Get("Content-Type")
+	}))
+	defer server.Close()
+
+	recorder, err := newNotifyingEventRecorderForURL(record.NewFakeRecorder(1), server.URL, "text/plain",
+		"{{ .Namespace }}/{{ .Name }}: {{ .Message }}", time.Second)
+	assert.Success(err, "newNotifyingEventRecorderForURL", t)
+	assert.Success(recorder.send([]byte("istio-system/basic: Ready")), "send", t)
+	assert.Equals(<-contentTypes, "text/plain", "unexpected Content-Type", t)
+}
+
+func TestReadinessNotifiedOnlyOnChange(t *testing.T) {
+	recorder, err := newNotifyingEventRecorderForURL(record.NewFakeRecorder(1), "http://localhost", "", "", time.Second)
+	assert.Success(err, "newNotifyingEventRecorderForURL", t)
+
+	smcp := newControlPlane()
+	assert.True(recorder.readinessChanged(smcp.UID, eventReasonNotReady), "expected first NotReady to be notified", t)
+	assert.False(recorder.readinessChanged(smcp.UID, eventReasonNotReady), "expected repeated NotReady not to be notified", t)
+	assert.True(recorder.readinessChanged(smcp.UID, eventReasonInstalled), "expected Installed to be notified", t)
+	assert.True(recorder.readinessChanged(smcp.UID, eventReasonReady), "expected Ready to be notified", t)
+	assert.False(recorder.readinessChanged(smcp.UID, eventReasonReady), "expected repeated Ready not to be notified", t)
+	assert.True(recorder.readinessChanged(smcp.UID, eventReasonNotReady), "expected NotReady to be notified", t)
+	recorder.readinessChanged(smcp.UID, eventReasonDeleted)
+	assert.True(recorder.readinessChanged(smcp.UID, eventReasonNotReady), "expected NotReady to be notified after deletion", t)
+}
+
+func TestIsNotifiedEvent(t *testing.T) {
+	assert.True(isNotifiedEvent(corev1.EventTypeNormal, eventReasonReady), "expected Ready to be notified", t)
+	assert.True(isNotifiedEvent(corev1.EventTypeNormal, eventReasonUpdated), "expected Updated to be notified", t)
+	assert.True(isNotifiedEvent(corev1.EventTypeWarning, eventReasonUpdating), "expected failed update to be notified", t)
+	assert.False(isNotifiedEvent(corev1.EventTypeNormal, eventReasonUpdating), "expected update in progress not to be notified", t)
+	assert.False(isNotifiedEvent(corev1.EventTypeNormal, eventReasonPruning), "expected Pruning not to be notified", t)
+}
@@ -0,0 +1,78 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/yaml"
+
+	v2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+)
+
+// applyOverlays applies the overlays from the applied spec that target the object
+func (r *controlPlaneInstanceReconciler) applyOverlays(ctx context.Context, object *unstructured.Unstructured) error {
+	log := common.LogFromContext(ctx)
+	for _, overlay := range r.Status.AppliedSpec.Overlays {
+		if !overlayMatches(overlay, object) {
+			continue
+		}
+		log.Info("applying overlay to resource", "type", overlay.Type)
+		if err := applyOverlay(object, overlay, r.Scheme); err != nil {
+			return fmt.Errorf("could not apply overlay for %s %s: %s", overlay.Kind, overlay.Name, err)
+		}
+	}
+	return nil
+}
+
+func overlayMatches(overlay v2.OverlayConfig, object *unstructured.Unstructured) bool {
+	return overlay.Kind == object.GetKind() && overlay.Name == object.GetName() &&
+		(overlay.Namespace == "" || overlay.Namespace == object.GetNamespace())
+}
+
+func applyOverlay(object *unstructured.Unstructured, overlay v2.OverlayConfig, scheme *runtime.Scheme) error {
+	patch, err := yaml.YAMLToJSON([]byte(overlay.Patch))
+	if err != nil {
+		return err
+	}
+	original, err := object.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	var patched []byte
+	switch overlay.Type {
+	case v2.OverlayPatchTypeStrategicMerge, "":
+		var dataStruct runtime.Object
+		if scheme != nil {
+			dataStruct, _ = scheme.New(object.GroupVersionKind())
+		}
+		if dataStruct == nil {
+			// no schema available, e.g. for custom resources
+			patched, err = jsonpatch.MergePatch(original, patch)
+		} else {
+			patched, err = strategicpatch.StrategicMergePatch(original, patch, dataStruct)
+		}
+	case v2.OverlayPatchTypeJSON6902:
+		var operations jsonpatch.Patch
+		if operations, err = jsonpatch.DecodePatch(patch); err == nil {
+			patched, err = operations.Apply(original)
+		}
+	default:
+		err = fmt.Errorf("unknown overlay type %q", overlay.Type)
+	}
+	if err != nil {
+		return err
+	}
+
+	patchedObject := &unstructured.Unstructured{}
+	if err := patchedObject.UnmarshalJSON(patched); err != nil {
+		return err
+	}
+	object.Object = patchedObject.Object
+	return nil
+}
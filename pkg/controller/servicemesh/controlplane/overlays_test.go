@@ -0,0 +1,154 @@
+package controlplane
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	v2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common/test"
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+)
+
+func TestApplyOverlay(t *testing.T) {
+	cases := []struct {
+		name     string
+		object   *unstructured.Unstructured
+		overlay  v2.OverlayConfig
+		path     []string
+		expected interface{}
+	}{
+		{
+			name:   "strategic-merge",
+			object: newOverlayTestDeployment(),
+			overlay: v2.OverlayConfig{
+				Kind: "Deployment",
+				Name: "istiod-basic",
+				Patch: `
+spec:
+  template:
+    spec:
+      containers:
+      - name: discovery
+        image: custom-image
+`,
+			},
+			path: []string{"spec", "template", "spec", "containers"},
+			expected: []interface{}{
+				map[string]interface{}{
+					"name":  "discovery",
+					"image": "custom-image",
+					"args":  []interface{}{"discovery"},
+				},
+			},
+		},
+		{
+			name:   "json6902",
+			object: newOverlayTestDeployment(),
+			overlay: v2.OverlayConfig{
+				Kind: "Deployment",
+				Name: "istiod-basic",
+				Type: v2.OverlayPatchTypeJSON6902,
+				Patch: `
+- op: replace
+  path: /spec/replicas
+  value: 3
+`,
+			},
+			path:     []string{"spec", "replicas"},
+			expected: int64(3),
+		},
+		{
+			name: "merge-for-unknown-kind",
+			object: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "example.com/v1",
+				"kind":       "Custom",
+				"metadata":   map[string]interface{}{"name": "custom", "namespace": "istio-system"},
+				"spec":       map[string]interface{}{"list": []interface{}{"a", "b"}},
+			}},
+			overlay: v2.OverlayConfig{
+				Kind:  "Custom",
+				Name:  "custom",
+				Patch: `{"spec": {"list": ["c"]}}`,
+			},
+			path:     []string{"spec", "list"},
+			expected: []interface{}{"c"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if !overlayMatches(tc.overlay, tc.object) {
+				t.Fatalf("expected overlay to match object")
+			}
+			if err := applyOverlay(tc.object, tc.overlay, test.GetScheme()); err != nil {
+				t.Fatalf("unexpected error applying overlay: %v", err)
+			}
+			actual, _, _ := unstructured.NestedFieldNoCopy(tc.object.Object, tc.path...)
+			assert.DeepEquals(actual, tc.expected, "unexpected value after applying overlay", t)
+		})
+	}
+}
+
+func TestOverlayMatches(t *testing.T) {
+	object := newOverlayTestDeployment()
+	cases := []struct {
+		name     string
+		overlay  v2.OverlayConfig
+		expected bool
+	}{
+		{
+			name:     "kind-and-name",
+			overlay:  v2.OverlayConfig{Kind: "Deployment", Name: "istiod-basic"},
+			expected: true,
+		},
+		{
+			name:     "namespace",
+			overlay:  v2.OverlayConfig{Kind: "Deployment", Name: "istiod-basic", Namespace: "istio-system"},
+			expected: true,
+		},
+		{
+			name:     "other-namespace",
+			overlay:  v2.OverlayConfig{Kind: "Deployment", Name: "istiod-basic", Namespace: "other"},
+			expected: false,
+		},
+		{
+			name:     "other-kind",
+			overlay:  v2.OverlayConfig{Kind: "Service", Name: "istiod-basic"},
+			expected: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := overlayMatches(tc.overlay, object); actual != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func newOverlayTestDeployment() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":      "istiod-basic",
+			"namespace": "istio-system",
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(1),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{
+							"name":  "discovery",
+							"image": "istiod",
+							"args":  []interface{}{"discovery"},
+						},
+					},
+				},
+			},
+		},
+	}}
+}
@@ -0,0 +1,60 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/maistra/istio-operator/pkg/apis/maistra/status"
+	v2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+)
+
+func isPaused(instance *v2.ServiceMeshControlPlane) bool {
+	value, _ := common.GetAnnotation(instance, common.PausedKey)
+	return value == "true"
+}
+
+func isPausedConditionSet(instance *v2.ServiceMeshControlPlane) bool {
+	return instance.Status.GetCondition(status.ConditionTypePaused).Status == status.ConditionStatusTrue
+}
+
+// Pause is invoked instead of Reconcile while the control plane is paused.
+// No charts are installed or updated and no other changes are made to the
+// mesh, but the readiness and the other observed parts of the status are
+// still updated, and the Paused condition is set.
+func (r *controlPlaneInstanceReconciler) Pause(ctx context.Context) error {
+	update := r.updateObservedStatus(ctx)
+	message := fmt.Sprintf("Reconciliation is paused by the %s annotation", common.PausedKey)
+	condition := r.Status.GetCondition(status.ConditionTypePaused)
+	if !condition.Matches(status.ConditionStatusTrue, status.ConditionReasonPausedByUser, message) {
+		r.Status.SetCondition(status.Condition{
+			Type:    status.ConditionTypePaused,
+			Status:  status.ConditionStatusTrue,
+			Reason:  status.ConditionReasonPausedByUser,
+			Message: message,
+		})
+		r.EventRecorder.Event(r.Instance, corev1.EventTypeNormal, eventReasonPaused, message)
+		update = true
+	}
+	if update {
+		return r.PostStatus(ctx)
+	}
+	return nil
+}
+
+// Resume clears the Paused condition after the paused annotation has been
+// removed.  Any changes made to the spec while the control plane was paused
+// are applied by the following reconciliation.
+func (r *controlPlaneInstanceReconciler) Resume(ctx context.Context) error {
+	message := "Reconciliation has resumed"
+	r.Status.SetCondition(status.Condition{
+		Type:    status.ConditionTypePaused,
+		Status:  status.ConditionStatusFalse,
+		Reason:  status.ConditionReasonResumed,
+		Message: message,
+	})
+	r.EventRecorder.Event(r.Instance, corev1.EventTypeNormal, eventReasonResumed, message)
+	return r.PostStatus(ctx)
+}
@@ -0,0 +1,53 @@
+package controlplane
+
+import (
+	"testing"
+
+	"github.com/maistra/istio-operator/pkg/apis/maistra/status"
+	maistrav2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/common/test"
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+)
+
+func TestPauseOnlyUpdatesStatus(t *testing.T) {
+	controlPlane := newControlPlane()
+	controlPlane.Annotations = map[string]string{common.PausedKey: "true"}
+
+	cl, tracker, r := newReconcilerTestFixture(controlPlane)
+
+	assert.Success(r.Pause(ctx), "Pause", t)
+
+	// only the status of the control plane is updated
+	test.AssertNumberOfWriteActions(t, tracker.Actions(), 1)
+	updatedControlPlane := &maistrav2.ServiceMeshControlPlane{}
+	test.PanicOnError(cl.Get(ctx, common.ToNamespacedName(controlPlane), updatedControlPlane))
+	condition := updatedControlPlane.Status.GetCondition(status.ConditionTypePaused)
+	assert.Equals(condition.Status, status.ConditionStatusTrue, "unexpected condition status", t)
+	assert.Equals(condition.Reason, status.ConditionReasonPausedByUser, "unexpected condition reason", t)
+
+	// the status isn't updated again if nothing changed
+	tracker.ClearActions()
+	r.SetInstance(updatedControlPlane)
+	assert.Success(r.Pause(ctx), "Pause", t)
+	test.AssertNumberOfWriteActions(t, tracker.Actions(), 0)
+}
+
+func TestResumeClearsPausedCondition(t *testing.T) {
+	controlPlane := newControlPlane()
+	controlPlane.Status.SetCondition(status.Condition{
+		Type:   status.ConditionTypePaused,
+		Status: status.ConditionStatusTrue,
+		Reason: status.ConditionReasonPausedByUser,
+	})
+
+	cl, _, r := newReconcilerTestFixture(controlPlane)
+
+	assert.Success(r.Resume(ctx), "Resume", t)
+
+	updatedControlPlane := &maistrav2.ServiceMeshControlPlane{}
+	test.PanicOnError(cl.Get(ctx, common.ToNamespacedName(controlPlane), updatedControlPlane))
+	condition := updatedControlPlane.Status.GetCondition(status.ConditionTypePaused)
+	assert.Equals(condition.Status, status.ConditionStatusFalse, "unexpected condition status", t)
+	assert.Equals(condition.Reason, status.ConditionReasonResumed, "unexpected condition reason", t)
+}
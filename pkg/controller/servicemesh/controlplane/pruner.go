@@ -73,16 +73,40 @@ var (
 		gk("security.istio.io", "AuthorizationPolicy"):       {},
 		gk("security.istio.io", "PeerAuthentication"):        {},
 		gk("security.istio.io", "RequestAuthentication"):     {},
+		gk("telemetry.istio.io", "Telemetry"):                {},
 		gk("certmanager.k8s.io", "ClusterIssuer"):            {},
 	}
 )
 
-func (r *controlPlaneInstanceReconciler) prune(ctx context.Context, generation string) error {
+// componentDeletionErrors records the errors encountered while deleting the
+// resources of each component, keyed by component name. A component without
+// errors maps to an empty slice.
+type componentDeletionErrors map[string][]error
+
+func (e componentDeletionErrors) record(object *unstructured.Unstructured, err error) {
+	if e == nil {
+		return
+	}
+	component := object.GetLabels()[common.KubernetesAppComponentKey]
+	if component == "" {
+		return
+	}
+	if err != nil {
+		e[component] = append(e[component], err)
+	} else if _, ok := e[component]; !ok {
+		e[component] = []error{}
+	}
+}
+
+// prune deletes all resources owned by the control plane that don't belong to
+// the specified generation. If deletionErrors is not nil, the outcome of each
+// deletion is recorded in it.
+func (r *controlPlaneInstanceReconciler) prune(ctx context.Context, generation string, deletionErrors componentDeletionErrors) error {
 	resourcesToPrune, err := r.findResourcesToPrune(ctx)
 	if err != nil {
 		return err
 	}
-	return r.pruneResources(ctx, resourcesToPrune, generation)
+	return r.pruneResources(ctx, resourcesToPrune, generation, deletionErrors)
 }
 
 func (r *controlPlaneInstanceReconciler) findResourcesToPrune(ctx context.Context) ([]pruneConfig, error) {
@@ -127,7 +151,9 @@ func getVersion(crd v1.CustomResourceDefinition) string {
 	return ""
 }
 
-func (r *controlPlaneInstanceReconciler) pruneResources(ctx context.Context, pruneConfigs []pruneConfig, instanceGeneration string) error {
+func (r *controlPlaneInstanceReconciler) pruneResources(ctx context.Context, pruneConfigs []pruneConfig, instanceGeneration string,
+	deletionErrors componentDeletionErrors,
+) error {
 	log := common.LogFromContext(ctx)
 
 	allErrors := []error{}
@@ -136,9 +162,9 @@ func (r *controlPlaneInstanceReconciler) pruneResources(ctx context.Context, pru
 		log.Info("pruning resources", "type", gvk.String(), "instanceGeneration", instanceGeneration, "deleteCollection", pruneConfig.supportsDeleteCollection)
 		var err error
 		if pruneConfig.supportsDeleteCollection {
-			err = r.pruneAll(ctx, gvk, instanceGeneration)
+			err = r.pruneAll(ctx, gvk, instanceGeneration, deletionErrors)
 		} else {
-			err = r.pruneIndividually(common.NewContextWithLog(ctx, log.WithValues("type", gvk.String())), gvk, instanceGeneration)
+			err = r.pruneIndividually(common.NewContextWithLog(ctx, log.WithValues("type", gvk.String())), gvk, instanceGeneration, deletionErrors)
 		}
 		if err != nil {
 			log.Error(err, "Error pruning resources", "type", gvk.String())
@@ -148,7 +174,9 @@ func (r *controlPlaneInstanceReconciler) pruneResources(ctx context.Context, pru
 	return utilerrors.NewAggregate(allErrors)
 }
 
-func (r *controlPlaneInstanceReconciler) pruneIndividually(ctx context.Context, gvk schema.GroupVersionKind, instanceGeneration string) error {
+func (r *controlPlaneInstanceReconciler) pruneIndividually(ctx context.Context, gvk schema.GroupVersionKind, instanceGeneration string,
+	deletionErrors componentDeletionErrors,
+) error {
 	log := common.LogFromContext(ctx)
 	labelSelector, err := createLabelSelector(r.Instance.Name, r.Instance.Namespace, instanceGeneration)
 	if err != nil {
@@ -167,13 +195,21 @@ func (r *controlPlaneInstanceReconciler) pruneIndividually(ctx context.Context,
 		log.Info("deleting resource", "resource", types.NamespacedName{Namespace: object.GetNamespace(), Name: object.GetName()})
 		err = r.Client.Delete(ctx, &object, client.PropagationPolicy(metav1.DeletePropagationBackground))
 		if err != nil && !errors.IsNotFound(err) {
-			return fmt.Errorf("error deleting resource: %v", err)
+			err = fmt.Errorf("error deleting resource: %v", err)
+			deletionErrors.record(&object, err)
+			return err
 		}
+		deletionErrors.record(&object, nil)
 	}
 	return nil
 }
 
-func (r *controlPlaneInstanceReconciler) pruneAll(ctx context.Context, gvk schema.GroupVersionKind, instanceGeneration string) error {
+// pruneAll deletes the resources of the type with a single DeleteCollection
+// call.  If the call fails, the error is recorded in deletionErrors for the
+// components of the resources that remain.
+func (r *controlPlaneInstanceReconciler) pruneAll(ctx context.Context, gvk schema.GroupVersionKind, instanceGeneration string,
+	deletionErrors componentDeletionErrors,
+) error {
 	log := common.LogFromContext(ctx)
 
 	labelSelector, err := createLabelSelector(r.Instance.Name, r.Instance.Namespace, instanceGeneration)
@@ -192,10 +228,31 @@ func (r *controlPlaneInstanceReconciler) pruneAll(ctx context.Context, gvk schem
 		if meta.IsNoMatchError(err) || errors.IsNotFound(err) {
 			return nil
 		}
+		r.recordRemainingObjects(ctx, gvk, labelSelector, fmt.Errorf("error deleting resources: %v", err), deletionErrors)
 	}
 	return err
 }
 
+// recordRemainingObjects records err for the component of each resource of
+// the type that matches the selector
+func (r *controlPlaneInstanceReconciler) recordRemainingObjects(ctx context.Context, gvk schema.GroupVersionKind,
+	labelSelector labels.Selector, err error, deletionErrors componentDeletionErrors,
+) {
+	if deletionErrors == nil {
+		return
+	}
+	log := common.LogFromContext(ctx)
+	objects := &unstructured.UnstructuredList{}
+	objects.SetGroupVersionKind(gvk)
+	if listErr := r.Client.List(ctx, objects, client.MatchingLabelsSelector{Selector: labelSelector}); listErr != nil {
+		log.Error(listErr, "error retrieving resources that could not be pruned", "type", gvk.String())
+		return
+	}
+	for i := range objects.Items {
+		deletionErrors.record(&objects.Items[i], err)
+	}
+}
+
 func gvk(group, version, kind string) schema.GroupVersionKind {
 	return schema.GroupVersionKind{
 		Group:   group,
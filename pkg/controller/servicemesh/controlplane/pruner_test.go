@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -13,6 +14,7 @@ import (
 	"github.com/maistra/istio-operator/pkg/controller/common"
 	"github.com/maistra/istio-operator/pkg/controller/common/cni"
 	"github.com/maistra/istio-operator/pkg/controller/common/test"
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
 )
 
 func TestPrune(t *testing.T) {
@@ -151,9 +153,9 @@ func TestPrune(t *testing.T) {
 
 					var err error
 					if tc.pruneIndividually {
-						err = r.pruneIndividually(ctx, tc.gvk, currentMeshGeneration)
+						err = r.pruneIndividually(ctx, tc.gvk, currentMeshGeneration, nil)
 					} else {
-						err = r.pruneAll(ctx, tc.gvk, currentMeshGeneration)
+						err = r.pruneAll(ctx, tc.gvk, currentMeshGeneration, nil)
 					}
 					if err != nil {
 						t.Fatalf("Unexpected error: %v", err)
@@ -170,3 +172,27 @@ func TestPrune(t *testing.T) {
 		}
 	}
 }
+
+func TestPruneAllRecordsComponentErrors(t *testing.T) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "grafana",
+			Namespace: controlPlaneNamespace,
+			Labels: map[string]string{
+				common.OwnerKey:                  controlPlaneNamespace,
+				common.OwnerNameKey:              controlPlaneName,
+				common.KubernetesAppManagedByKey: common.KubernetesAppManagedByValue,
+				common.KubernetesAppComponentKey: "grafana",
+			},
+		},
+	}
+	smcp := newControlPlane()
+	cl, tracker := test.CreateClient(smcp, configMap)
+	tracker.AddReaction(test.On("delete", "configmaps", test.ClientFails()))
+	r := newTestInstanceReconciler(cl, smcp)
+
+	deletionErrors := componentDeletionErrors{}
+	err := r.pruneAll(ctx, gvk("", "v1", "ConfigMap"), "", deletionErrors)
+	assert.Failure(err, "pruneAll", t)
+	assert.Equals(len(deletionErrors["grafana"]), 1, "Expected error to be recorded for component grafana", t)
+}
@@ -4,13 +4,17 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
+	"sync"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -26,8 +30,7 @@ const (
 )
 
 func (r *controlPlaneInstanceReconciler) UpdateReadiness(ctx context.Context) error {
-	update := r.updateReadinessStatus(ctx)
-	if update {
+	if r.updateObservedStatus(ctx) {
 		err := r.PostStatus(ctx)
 		if err != nil {
 			return err
@@ -36,10 +39,23 @@ func (r *controlPlaneInstanceReconciler) UpdateReadiness(ctx context.Context) er
 	return nil
 }
 
+// updateObservedStatus updates the parts of the status that reflect the state
+// of the cluster, rather than the progress of the installation.  It returns
+// true if the status changed.
+func (r *controlPlaneInstanceReconciler) updateObservedStatus(ctx context.Context) bool {
+	update := r.updateReadinessStatus(ctx)
+	update = r.updateRemoteSecretStatus(ctx) || update
+	update = r.updateMeshNamespacesStatus(ctx) || update
+	update = r.updateDataPlaneStatus(ctx) || update
+	update = r.updateDependenciesStatus(ctx) || update
+	update = r.updateCertificateSignerStatus(ctx) || update
+	return update
+}
+
 func (r *controlPlaneInstanceReconciler) updateReadinessStatus(ctx context.Context) bool {
 	log := common.LogFromContext(ctx)
 	log.Info("Updating ServiceMeshControlPlane readiness state")
-	readyComponents, unreadyComponents, err := r.calculateComponentReadiness(ctx)
+	objects, err := r.calculateWorkloadReadiness(ctx)
 	if err != nil {
 		condition := status.Condition{
 			Type:    status.ConditionTypeReady,
@@ -51,6 +67,20 @@ func (r *controlPlaneInstanceReconciler) updateReadinessStatus(ctx context.Conte
 		r.EventRecorder.Event(r.Instance, corev1.EventTypeWarning, eventReasonNotReady, condition.Message)
 		return true
 	}
+	componentReady := r.componentReadinessMap(ctx, objects)
+	allComponents := sets.NewString()
+	for _, comp := range r.Status.ComponentStatus {
+		allComponents.Insert(comp.Resource)
+	}
+	if usesExternalControlPlane(r.Instance) {
+		componentReady[externalControlPlaneComponent] = r.externalControlPlaneReadiness(ctx)
+		allComponents.Insert(externalControlPlaneComponent)
+	}
+	if usesIstioCSR(r.Instance) {
+		componentReady[istioCSRComponent] = r.istioCSRReadiness(ctx)
+		allComponents.Insert(istioCSRComponent)
+	}
+	readyComponents, unreadyComponents := splitComponentReadiness(componentReady)
 
 	readyCondition := r.Status.GetCondition(status.ConditionTypeReady)
 	updateStatus := false
@@ -66,44 +96,28 @@ func (r *controlPlaneInstanceReconciler) updateReadinessStatus(ctx context.Conte
 			updateStatus = true
 		}
 	} else {
-		if len(unreadyComponents) > 0 {
-			message := fmt.Sprintf("The following components are not fully available: %s", unreadyComponents.List())
-			if !readyCondition.Matches(status.ConditionStatusFalse, status.ConditionReasonComponentsNotReady, message) {
-				r.Status.SetCondition(status.Condition{
-					Type:    status.ConditionTypeReady,
-					Status:  status.ConditionStatusFalse,
-					Reason:  status.ConditionReasonComponentsNotReady,
-					Message: message,
-				})
-				r.EventRecorder.Event(r.Instance, corev1.EventTypeWarning, eventReasonNotReady, message)
-				updateStatus = true
-			}
-		} else {
-			message := "All component deployments are Available"
-			if !readyCondition.Matches(status.ConditionStatusTrue, status.ConditionReasonComponentsReady, message) {
-				r.Status.SetCondition(status.Condition{
-					Type:    status.ConditionTypeReady,
-					Status:  status.ConditionStatusTrue,
-					Reason:  status.ConditionReasonComponentsReady,
-					Message: message,
-				})
-				r.EventRecorder.Event(r.Instance, corev1.EventTypeNormal, eventReasonReady, message)
-				updateStatus = true
-			}
+		condition, eventType, eventReason := r.reconciledReadyCondition(ctx, objects, unreadyComponents, allComponents)
+		key := common.ToNamespacedName(r.Instance)
+		if readyCondition.Status != status.ConditionStatusTrue || condition.Status == status.ConditionStatusTrue {
+			r.readinessDebouncer.reset(key)
+		} else if delay := r.readinessDebouncer.delay(key, r.clock.Now()); delay > 0 {
+			// the readiness is reported as last posted until it settles
+			log.Info("Delaying the report of the lost readiness", "reason", condition.Reason, "delay", delay)
+			return false
+		}
+		if !readyCondition.Matches(condition.Status, condition.Reason, condition.Message) {
+			r.Status.SetCondition(condition)
+			r.EventRecorder.Event(r.Instance, eventType, eventReason, condition.Message)
+			updateStatus = true
 		}
 	}
 
-	readyComponentCount := fmt.Sprintf("%d/%d", len(readyComponents), len(r.Status.ComponentStatus))
+	readyComponentCount := fmt.Sprintf("%d/%d", len(readyComponents), allComponents.Len())
 	if r.Status.GetAnnotation(statusAnnotationReadyComponentCount) != readyComponentCount {
 		r.Status.SetAnnotation(statusAnnotationReadyComponentCount, readyComponentCount)
 		updateStatus = true
 	}
 
-	allComponents := sets.NewString()
-	for _, comp := range r.Status.ComponentStatus {
-		allComponents.Insert(comp.Resource)
-	}
-
 	readinessMap := maistrav2.ReadinessMap{
 		"ready":   readyComponents.List(),
 		"unready": unreadyComponents.List(),
@@ -113,76 +127,208 @@ func (r *controlPlaneInstanceReconciler) updateReadinessStatus(ctx context.Conte
 		r.Status.Readiness.Components = readinessMap
 		updateStatus = true
 	}
+
+	workloads := componentWorkloads(objects, componentReady)
+	if !reflect.DeepEqual(r.Status.Readiness.Workloads, workloads) {
+		r.Status.Readiness.Workloads = workloads
+		updateStatus = true
+	}
 	return updateStatus
 }
 
+// reconciledReadyCondition returns the Ready condition of a fully reconciled
+// control plane, together with the type and reason of the event that is
+// recorded when the condition changes
+func (r *controlPlaneInstanceReconciler) reconciledReadyCondition(ctx context.Context, objects map[readinessObjectKey]componentReadiness,
+	unreadyComponents, allComponents sets.String,
+) (status.Condition, string, string) {
+	if len(unreadyComponents) > 0 {
+		message := fmt.Sprintf("The following components are not fully available: %s", unreadyComponents.List())
+		if hints := r.componentFailureHints(ctx, objects); len(hints) > 0 {
+			message = fmt.Sprintf("%s; %s", message, strings.Join(hints, "; "))
+		}
+		return status.Condition{
+			Type:    status.ConditionTypeReady,
+			Status:  status.ConditionStatusFalse,
+			Reason:  status.ConditionReasonComponentsNotReady,
+			Message: message,
+		}, corev1.EventTypeWarning, eventReasonNotReady
+	} else if err := r.checkInjectionWebhookOfComponents(ctx, allComponents); err != nil {
+		return status.Condition{
+			Type:    status.ConditionTypeReady,
+			Status:  status.ConditionStatusFalse,
+			Reason:  status.ConditionReasonWebhookNotReady,
+			Message: fmt.Sprintf("The sidecar injection webhook is not serving: %s", err),
+		}, corev1.EventTypeWarning, eventReasonNotReady
+	} else if err := r.checkIstiodXDSOfComponents(ctx, allComponents); err != nil {
+		return status.Condition{
+			Type:    status.ConditionTypeReady,
+			Status:  status.ConditionStatusFalse,
+			Reason:  status.ConditionReasonXDSNotReady,
+			Message: fmt.Sprintf("istiod is not serving: %s", err),
+		}, corev1.EventTypeWarning, eventReasonNotReady
+	}
+	return status.Condition{
+		Type:    status.ConditionTypeReady,
+		Status:  status.ConditionStatusTrue,
+		Reason:  status.ConditionReasonComponentsReady,
+		Message: "All component deployments are Available",
+	}, corev1.EventTypeNormal, eventReasonReady
+}
+
+// componentWorkloads returns the images used by the workloads of each
+// component, sorted by component
+func componentWorkloads(objects map[readinessObjectKey]componentReadiness, componentReady map[string]bool) []maistrav2.ComponentWorkloadStatus {
+	images := map[string]sets.String{}
+	for _, object := range objects {
+		if _, ok := images[object.component]; !ok {
+			images[object.component] = sets.NewString()
+		}
+		images[object.component].Insert(object.images...)
+	}
+	if len(images) == 0 {
+		return nil
+	}
+	workloads := make([]maistrav2.ComponentWorkloadStatus, 0, len(images))
+	for _, component := range sets.StringKeySet(images).List() {
+		workloads = append(workloads, maistrav2.ComponentWorkloadStatus{
+			Component: component,
+			Images:    images[component].List(),
+			Ready:     componentReady[component],
+		})
+	}
+	return workloads
+}
+
 type isReadyFunc func(runtime.Object) bool
 
-// keep this in sync with kinds in calculateComponentReadiness()
+// readinessCheck describes how the readiness of objects of a specific kind is
+// determined
+type readinessCheck struct {
+	kind      string
+	newList   func() runtime.Object
+	newObject func() runtime.Object
+	ready     isReadyFunc
+	podSpec   func(runtime.Object) *corev1.PodSpec
+	selector  func(runtime.Object) *metav1.LabelSelector
+	// failures returns the messages reported by the object when it fails to
+	// create its pods, if the kind reports them
+	failures func(runtime.Object) []string
+}
+
+// keep this in sync with kinds in readinessChecks()
 var kindsWithReadiness = sets.NewString("Deployment", "StatefulSet", "DaemonSet")
 
 func (r *controlPlaneInstanceReconciler) hasReadiness(kind string) bool {
 	return kindsWithReadiness.Has(kind)
 }
 
-func (r *controlPlaneInstanceReconciler) calculateComponentReadiness(ctx context.Context) (readyComponents, unreadyComponents sets.String, err error) {
-	readyComponents = sets.NewString()
-	unreadyComponents = sets.NewString()
-
-	var readinessMap map[string]bool
-	readinessMap, err = r.calculateComponentReadinessMap(ctx)
-	if err != nil {
-		return
+func isDeploymentReady(deployment *appsv1.Deployment) bool {
+	if deployment.Status.ReadyReplicas < deployment.Status.Replicas || deployment.Status.ObservedGeneration < deployment.Generation {
+		return false
 	}
-	for component, ready := range readinessMap {
-		if ready {
-			readyComponents.Insert(component)
-		} else {
-			unreadyComponents.Insert(component)
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentAvailable {
+			return condition.Status == corev1.ConditionTrue
 		}
 	}
-	return
+	return false
 }
 
-func (r *controlPlaneInstanceReconciler) calculateComponentReadinessMap(ctx context.Context) (map[string]bool, error) {
-	log := common.LogFromContext(ctx)
-
-	readinessMap := map[string]bool{}
-	typesToCheck := []struct {
-		list  runtime.Object
-		ready isReadyFunc
-	}{
+func (r *controlPlaneInstanceReconciler) readinessChecks() []readinessCheck {
+	return []readinessCheck{
 		// keep this in sync with kindsWithReadiness
 		{
-			list: &appsv1.DeploymentList{},
+			kind:      "Deployment",
+			newList:   func() runtime.Object { return &appsv1.DeploymentList{} },
+			newObject: func() runtime.Object { return &appsv1.Deployment{} },
 			ready: func(obj runtime.Object) bool {
-				deployment := obj.(*appsv1.Deployment)
-				if deployment.Status.ReadyReplicas < deployment.Status.Replicas || deployment.Status.ObservedGeneration < deployment.Generation {
-					return false
-				}
-				for _, condition := range deployment.Status.Conditions {
-					if condition.Type == appsv1.DeploymentAvailable {
-						return condition.Status == corev1.ConditionTrue
+				return isDeploymentReady(obj.(*appsv1.Deployment))
+			},
+			podSpec: func(obj runtime.Object) *corev1.PodSpec {
+				return &obj.(*appsv1.Deployment).Spec.Template.Spec
+			},
+			selector: func(obj runtime.Object) *metav1.LabelSelector {
+				return obj.(*appsv1.Deployment).Spec.Selector
+			},
+			failures: func(obj runtime.Object) []string {
+				var messages []string
+				for _, condition := range obj.(*appsv1.Deployment).Status.Conditions {
+					if condition.Type == appsv1.DeploymentReplicaFailure && condition.Status == corev1.ConditionTrue {
+						messages = append(messages, condition.Message)
 					}
 				}
-				return false
+				return messages
 			},
 		},
 		{
-			list: &appsv1.StatefulSetList{},
+			kind:      "StatefulSet",
+			newList:   func() runtime.Object { return &appsv1.StatefulSetList{} },
+			newObject: func() runtime.Object { return &appsv1.StatefulSet{} },
 			ready: func(obj runtime.Object) bool {
 				statefulSet := obj.(*appsv1.StatefulSet)
 				return statefulSet.Status.ReadyReplicas >= statefulSet.Status.Replicas
 			},
+			podSpec: func(obj runtime.Object) *corev1.PodSpec {
+				return &obj.(*appsv1.StatefulSet).Spec.Template.Spec
+			},
+			selector: func(obj runtime.Object) *metav1.LabelSelector {
+				return obj.(*appsv1.StatefulSet).Spec.Selector
+			},
 		},
 		{
-			list: &appsv1.DaemonSetList{},
+			kind:      "DaemonSet",
+			newList:   func() runtime.Object { return &appsv1.DaemonSetList{} },
+			newObject: func() runtime.Object { return &appsv1.DaemonSet{} },
 			ready: func(obj runtime.Object) bool {
 				daemonSet := obj.(*appsv1.DaemonSet)
 				return r.daemonSetReady(daemonSet)
 			},
+			podSpec: func(obj runtime.Object) *corev1.PodSpec {
+				return &obj.(*appsv1.DaemonSet).Spec.Template.Spec
+			},
+			selector: func(obj runtime.Object) *metav1.LabelSelector {
+				return obj.(*appsv1.DaemonSet).Spec.Selector
+			},
 		},
 	}
+}
+
+func (r *controlPlaneInstanceReconciler) calculateComponentReadiness(ctx context.Context) (readyComponents, unreadyComponents sets.String, err error) {
+	var readinessMap map[string]bool
+	readinessMap, err = r.calculateComponentReadinessMap(ctx)
+	if err != nil {
+		return sets.NewString(), sets.NewString(), err
+	}
+	readyComponents, unreadyComponents = splitComponentReadiness(readinessMap)
+	return
+}
+
+func splitComponentReadiness(readinessMap map[string]bool) (readyComponents, unreadyComponents sets.String) {
+	readyComponents = sets.NewString()
+	unreadyComponents = sets.NewString()
+	for component, ready := range readinessMap {
+		if ready {
+			readyComponents.Insert(component)
+		} else {
+			unreadyComponents.Insert(component)
+		}
+	}
+	return
+}
+
+func (r *controlPlaneInstanceReconciler) calculateComponentReadinessMap(ctx context.Context) (map[string]bool, error) {
+	objects, err := r.calculateWorkloadReadiness(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.componentReadinessMap(ctx, objects), nil
+}
+
+// calculateWorkloadReadiness returns the readiness of each of the control
+// plane's workloads
+func (r *controlPlaneInstanceReconciler) calculateWorkloadReadiness(ctx context.Context) (map[readinessObjectKey]componentReadiness, error) {
+	log := common.LogFromContext(ctx)
 
 	namespaces, err := r.getNamespacesToCheck()
 	if err != nil {
@@ -191,12 +337,40 @@ func (r *controlPlaneInstanceReconciler) calculateComponentReadinessMap(ctx cont
 
 	log.V(2).Info("Calculating readiness", "namespaces", namespaces)
 
-	for _, check := range typesToCheck {
-		err := r.calculateReadinessForType(ctx, namespaces, check.list, check.ready, readinessMap)
-		if err != nil {
-			return readinessMap, err
+	key := common.ToNamespacedName(r.Instance)
+	objects, dirty, cached := r.readinessCache.take(key, r.Instance.UID, namespaces)
+	if cached {
+		log.V(2).Info("Rechecking readiness of changed objects", "objects", len(dirty))
+		err = r.recheckReadiness(ctx, namespaces, dirty, objects)
+	} else {
+		objects = map[readinessObjectKey]componentReadiness{}
+		for _, check := range r.readinessChecks() {
+			err = r.calculateReadinessForType(ctx, namespaces, check, objects)
+			if err != nil {
+				break
+			}
 		}
 	}
+	if err != nil {
+		r.readinessCache.invalidate(key)
+		return nil, err
+	}
+	r.readinessCache.store(key, r.Instance.UID, namespaces, objects)
+	return objects, nil
+}
+
+// componentReadinessMap returns the readiness of each component, which is only
+// ready if all of its workloads are ready
+func (r *controlPlaneInstanceReconciler) componentReadinessMap(ctx context.Context,
+	objects map[readinessObjectKey]componentReadiness,
+) map[string]bool {
+	log := common.LogFromContext(ctx)
+
+	readinessMap := map[string]bool{}
+	for _, object := range objects {
+		ready, exists := readinessMap[object.component]
+		readinessMap[object.component] = (ready || !exists) && object.ready
+	}
 
 	alwaysReadyComponents := r.Status.GetAnnotation(statusAnnotationAlwaysReadyComponents)
 	if alwaysReadyComponents != "" {
@@ -206,7 +380,98 @@ func (r *controlPlaneInstanceReconciler) calculateComponentReadinessMap(ctx cont
 	}
 	log.V(2).Info("Readiness calculated", "readinessMap", readinessMap)
 
-	return readinessMap, nil
+	return readinessMap
+}
+
+// componentFailureHints returns the code and remediation hint of a known failure
+// that keeps each unready component from becoming ready, sorted by component.
+// The failures are looked up in the conditions of the component's workloads and
+// in the states of their pods' containers.
+func (r *controlPlaneInstanceReconciler) componentFailureHints(ctx context.Context,
+	objects map[readinessObjectKey]componentReadiness,
+) []string {
+	log := common.LogFromContext(ctx)
+
+	checks := map[string]readinessCheck{}
+	for _, check := range r.readinessChecks() {
+		checks[check.kind] = check
+	}
+	// check the objects in a fixed order, so the hints don't change between
+	// updates when a component has multiple failing workloads
+	objectKeys := make([]readinessObjectKey, 0, len(objects))
+	for objectKey := range objects {
+		objectKeys = append(objectKeys, objectKey)
+	}
+	sort.Slice(objectKeys, func(i, j int) bool {
+		return objectKeys[i].Kind+"/"+objectKeys[i].String() < objectKeys[j].Kind+"/"+objectKeys[j].String()
+	})
+
+	hints := map[string]string{}
+	for _, objectKey := range objectKeys {
+		object := objects[objectKey]
+		if _, found := hints[object.component]; found || object.ready {
+			continue
+		}
+		check, ok := checks[objectKey.Kind]
+		if !ok {
+			continue
+		}
+		messages, err := r.workloadFailureMessages(ctx, check, objectKey)
+		if err != nil {
+			log.Error(err, "error looking up failures of workload", check.kind, objectKey.NamespacedName)
+			continue
+		}
+		for _, message := range messages {
+			if code, hint, ok := common.LookupError(message); ok {
+				hints[object.component] = fmt.Sprintf("%s: [%s] %s", object.component, code, hint)
+				break
+			}
+		}
+	}
+
+	sortedHints := make([]string, 0, len(hints))
+	for _, component := range sets.StringKeySet(hints).List() {
+		sortedHints = append(sortedHints, hints[component])
+	}
+	return sortedHints
+}
+
+// workloadFailureMessages returns the failures reported by the workload and
+// the messages of its pods' waiting containers
+func (r *controlPlaneInstanceReconciler) workloadFailureMessages(ctx context.Context, check readinessCheck,
+	objectKey readinessObjectKey,
+) ([]string, error) {
+	obj := check.newObject()
+	if err := r.Client.Get(ctx, objectKey.NamespacedName, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var messages []string
+	if check.failures != nil {
+		messages = append(messages, check.failures(obj)...)
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(check.selector(obj))
+	if err != nil {
+		return nil, err
+	}
+	pods := &corev1.PodList{}
+	if err := r.Client.List(ctx, pods, client.InNamespace(objectKey.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+	for _, pod := range pods.Items {
+		containerStatuses := make([]corev1.ContainerStatus, 0, len(pod.Status.InitContainerStatuses)+len(pod.Status.ContainerStatuses))
+		containerStatuses = append(containerStatuses, pod.Status.InitContainerStatuses...)
+		containerStatuses = append(containerStatuses, pod.Status.ContainerStatuses...)
+		for _, containerStatus := range containerStatuses {
+			if waiting := containerStatus.State.Waiting; waiting != nil && waiting.Message != "" {
+				messages = append(messages, waiting.Message)
+			}
+		}
+	}
+	return messages, nil
 }
 
 func (r *controlPlaneInstanceReconciler) isCNIReady(ctx context.Context) (bool, error) {
@@ -228,11 +493,10 @@ func (r *controlPlaneInstanceReconciler) isCNIReady(ctx context.Context) (bool,
 }
 
 func (r *controlPlaneInstanceReconciler) calculateReadinessForType(ctx context.Context, namespaces []string,
-	list runtime.Object, isReady isReadyFunc, readinessMap map[string]bool,
+	check readinessCheck, objects map[readinessObjectKey]componentReadiness,
 ) error {
-	log := common.LogFromContext(ctx)
-
 	selector := map[string]string{common.OwnerKey: r.Instance.GetNamespace()}
+	list := check.newList()
 	for _, ns := range namespaces {
 		err := r.Client.List(ctx, list, client.InNamespace(ns), client.MatchingLabels(selector))
 		if err != nil {
@@ -244,23 +508,70 @@ func (r *controlPlaneInstanceReconciler) calculateReadinessForType(ctx context.C
 		}
 
 		for _, obj := range items {
-			log.V(3).Info("Readiness check found object", "object", obj)
-			metaObject, err := meta.Accessor(obj)
-			if err != nil {
+			if err := r.checkObjectReadiness(ctx, check, obj, objects); err != nil {
 				return err
 			}
-			if component, ok := metaObject.GetLabels()[common.KubernetesAppComponentKey]; ok {
-				ready, exists := readinessMap[component]
-				readinessMap[component] = (ready || !exists) && isReady(obj)
-			} else {
-				// resource was most likely created by user, not by the operator; we can safely ignore it
-				log.V(3).Info("skipping resource for readiness check: resource has no component label", obj.GetObjectKind(), metaObject.GetName())
+		}
+	}
+	return nil
+}
+
+// recheckReadiness retrieves the objects that changed since the readiness was
+// last calculated and updates their entries in objects
+func (r *controlPlaneInstanceReconciler) recheckReadiness(ctx context.Context, namespaces []string,
+	dirty []readinessObjectKey, objects map[readinessObjectKey]componentReadiness,
+) error {
+	checks := map[string]readinessCheck{}
+	for _, check := range r.readinessChecks() {
+		checks[check.kind] = check
+	}
+	namespaceSet := sets.NewString(namespaces...)
+	for _, objectKey := range dirty {
+		delete(objects, objectKey)
+		check, ok := checks[objectKey.Kind]
+		if !ok || !namespaceSet.Has(objectKey.Namespace) {
+			continue
+		}
+		obj := check.newObject()
+		if err := r.Client.Get(ctx, objectKey.NamespacedName, obj); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
 			}
+			return err
+		}
+		if err := r.checkObjectReadiness(ctx, check, obj, objects); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
+func (r *controlPlaneInstanceReconciler) checkObjectReadiness(ctx context.Context, check readinessCheck,
+	obj runtime.Object, objects map[readinessObjectKey]componentReadiness,
+) error {
+	log := common.LogFromContext(ctx)
+
+	log.V(3).Info("Readiness check found object", "object", obj)
+	metaObject, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	if metaObject.GetLabels()[common.OwnerKey] != r.Instance.GetNamespace() {
+		return nil
+	}
+	if component, ok := metaObject.GetLabels()[common.KubernetesAppComponentKey]; ok {
+		objectKey := readinessObjectKey{
+			Kind:           check.kind,
+			NamespacedName: types.NamespacedName{Namespace: metaObject.GetNamespace(), Name: metaObject.GetName()},
+		}
+		objects[objectKey] = componentReadiness{component: component, ready: check.ready(obj), images: podImages(check.podSpec(obj))}
+	} else {
+		// resource was most likely created by user, not by the operator; we can safely ignore it
+		log.V(3).Info("skipping resource for readiness check: resource has no component label", check.kind, metaObject.GetName())
+	}
+	return nil
+}
+
 func (r *controlPlaneInstanceReconciler) getNamespacesToCheck() ([]string, error) {
 	// gather namespaces in SMCP
 	namespaces := sets.NewString(r.Instance.Namespace)
@@ -302,3 +613,129 @@ func (r *controlPlaneInstanceReconciler) getNamespacesToCheck() ([]string, error
 func (r *controlPlaneInstanceReconciler) daemonSetReady(ds *appsv1.DaemonSet) bool {
 	return ds.Status.NumberUnavailable == 0
 }
+
+// readinessObjectKey identifies an object whose readiness is checked
+type readinessObjectKey struct {
+	Kind string
+	types.NamespacedName
+}
+
+// componentReadiness is the readiness of a single object belonging to a
+// component, together with the images used by its containers
+type componentReadiness struct {
+	component string
+	ready     bool
+	images    []string
+}
+
+func podImages(podSpec *corev1.PodSpec) []string {
+	images := make([]string, 0, len(podSpec.InitContainers)+len(podSpec.Containers))
+	for _, container := range podSpec.InitContainers {
+		images = append(images, container.Image)
+	}
+	for _, container := range podSpec.Containers {
+		images = append(images, container.Image)
+	}
+	return images
+}
+
+// readinessCache remembers the readiness of the objects found during the last
+// readiness calculation of each control plane, together with the objects that
+// changed since. This allows the readiness to be updated by retrieving only the
+// changed objects instead of listing all objects in all mesh namespaces on
+// every event. The methods are safe to call on a nil cache, in which case the
+// readiness is always calculated from scratch.
+type readinessCache struct {
+	mu      sync.Mutex
+	entries map[types.NamespacedName]*readinessCacheEntry
+}
+
+type readinessCacheEntry struct {
+	uid        types.UID
+	namespaces []string
+	objects    map[readinessObjectKey]componentReadiness
+	dirty      map[readinessObjectKey]struct{}
+	valid      bool
+}
+
+func newReadinessCache() *readinessCache {
+	return &readinessCache{
+		entries: map[types.NamespacedName]*readinessCacheEntry{},
+	}
+}
+
+// markDirty records that the object has changed and must be rechecked during
+// the next readiness calculation of the control plane
+func (c *readinessCache) markDirty(smcpKey types.NamespacedName, objectKey readinessObjectKey) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[smcpKey]
+	if !ok {
+		return
+	}
+	entry.dirty[objectKey] = struct{}{}
+}
+
+// take returns a copy of the cached readiness of the control plane's objects
+// and the objects that changed since it was stored. The returned bool is false
+// if there is no usable entry, e.g. because the namespaces to check changed.
+func (c *readinessCache) take(smcpKey types.NamespacedName, uid types.UID, namespaces []string,
+) (map[readinessObjectKey]componentReadiness, []readinessObjectKey, bool) {
+	if c == nil {
+		return nil, nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[smcpKey]
+	if !ok || !entry.valid || entry.uid != uid || !reflect.DeepEqual(entry.namespaces, namespaces) {
+		// changes observed from now on are included in the calculation that follows
+		c.entries[smcpKey] = &readinessCacheEntry{
+			uid:   uid,
+			dirty: map[readinessObjectKey]struct{}{},
+		}
+		return nil, nil, false
+	}
+	objects := make(map[readinessObjectKey]componentReadiness, len(entry.objects))
+	for objectKey, readiness := range entry.objects {
+		objects[objectKey] = readiness
+	}
+	dirty := make([]readinessObjectKey, 0, len(entry.dirty))
+	for objectKey := range entry.dirty {
+		dirty = append(dirty, objectKey)
+	}
+	entry.dirty = map[readinessObjectKey]struct{}{}
+	return objects, dirty, true
+}
+
+// store records the readiness of the control plane's objects. Objects marked
+// dirty while the readiness was being calculated remain dirty.
+func (c *readinessCache) store(smcpKey types.NamespacedName, uid types.UID, namespaces []string,
+	objects map[readinessObjectKey]componentReadiness,
+) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[smcpKey]
+	if !ok || entry.uid != uid {
+		return
+	}
+	entry.namespaces = namespaces
+	entry.objects = objects
+	entry.valid = true
+}
+
+// invalidate drops the cached readiness of the control plane, so the next
+// calculation checks all objects
+func (c *readinessCache) invalidate(smcpKey types.NamespacedName) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, smcpKey)
+}
@@ -7,9 +7,11 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
 
+	"github.com/maistra/istio-operator/pkg/apis/maistra/status"
 	maistrav1 "github.com/maistra/istio-operator/pkg/apis/maistra/v1"
 	maistrav2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
 	"github.com/maistra/istio-operator/pkg/controller/common"
@@ -286,6 +288,73 @@ func TestCalculateComponentReadinessMap(t *testing.T) {
 	}
 }
 
+func TestCalculateComponentReadinessMapRechecksOnlyChangedObjects(t *testing.T) {
+	smcp := newControlPlane()
+	cl, _ := test.CreateClient(
+		newDeployment("foo", controlPlaneNamespace, "component1", true),
+		newDeployment("bar", controlPlaneNamespace, "component2", true))
+	instanceReconciler := newTestInstanceReconciler(cl, smcp)
+	instanceReconciler.readinessCache = newReadinessCache()
+
+	smcpKey := common.ToNamespacedName(smcp)
+	fooKey := readinessObjectKey{Kind: "Deployment", NamespacedName: types.NamespacedName{Namespace: controlPlaneNamespace, Name: "foo"}}
+
+	readinessMap, err := instanceReconciler.calculateComponentReadinessMap(ctx)
+	assert.Success(err, "calculateComponentReadinessMap", t)
+	assert.DeepEquals(readinessMap, map[string]bool{"component1": true, "component2": true}, "Unexpected readiness map", t)
+
+	// changes to objects that aren't marked dirty aren't observed
+	test.PanicOnError(cl.Update(ctx, newDeployment("foo", controlPlaneNamespace, "component1", false)))
+	test.PanicOnError(cl.Update(ctx, newDeployment("bar", controlPlaneNamespace, "component2", false)))
+	readinessMap, err = instanceReconciler.calculateComponentReadinessMap(ctx)
+	assert.Success(err, "calculateComponentReadinessMap", t)
+	assert.DeepEquals(readinessMap, map[string]bool{"component1": true, "component2": true}, "Unexpected readiness map", t)
+
+	instanceReconciler.readinessCache.markDirty(smcpKey, fooKey)
+	readinessMap, err = instanceReconciler.calculateComponentReadinessMap(ctx)
+	assert.Success(err, "calculateComponentReadinessMap", t)
+	assert.DeepEquals(readinessMap, map[string]bool{"component1": false, "component2": true}, "Unexpected readiness map", t)
+
+	test.PanicOnError(cl.Delete(ctx, newDeployment("foo", controlPlaneNamespace, "component1", false)))
+	instanceReconciler.readinessCache.markDirty(smcpKey, fooKey)
+	readinessMap, err = instanceReconciler.calculateComponentReadinessMap(ctx)
+	assert.Success(err, "calculateComponentReadinessMap", t)
+	assert.DeepEquals(readinessMap, map[string]bool{"component2": true}, "Unexpected readiness map", t)
+
+	// invalidating the cache causes all objects to be checked
+	instanceReconciler.readinessCache.invalidate(smcpKey)
+	readinessMap, err = instanceReconciler.calculateComponentReadinessMap(ctx)
+	assert.Success(err, "calculateComponentReadinessMap", t)
+	assert.DeepEquals(readinessMap, map[string]bool{"component2": false}, "Unexpected readiness map", t)
+}
+
+func TestUpdateReadinessStatusReportsWorkloadImages(t *testing.T) {
+	smcp := newControlPlane()
+	istiod := newDeployment("istiod", controlPlaneNamespace, "istiod", true)
+	istiod.Spec.Template.Spec.Containers = []corev1.Container{{Name: "discovery", Image: "quay.io/maistra/pilot:2.4"}}
+	gatewayFoo := newDeployment("gateway-foo", controlPlaneNamespace, "istio-ingressgateway", true)
+	gatewayFoo.Spec.Template.Spec.Containers = []corev1.Container{{Name: "istio-proxy", Image: "quay.io/maistra/proxyv2:2.4"}}
+	gatewayBar := newDeployment("gateway-bar", controlPlaneNamespace, "istio-ingressgateway", false)
+	gatewayBar.Spec.Template.Spec.InitContainers = []corev1.Container{{Name: "init", Image: "quay.io/maistra/init:2.4"}}
+	gatewayBar.Spec.Template.Spec.Containers = []corev1.Container{{Name: "istio-proxy", Image: "quay.io/maistra/proxyv2:2.4"}}
+	cl, _ := test.CreateClient(istiod, gatewayFoo, gatewayBar)
+	instanceReconciler := newTestInstanceReconciler(cl, smcp)
+
+	assert.True(instanceReconciler.updateReadinessStatus(ctx), "expected status to be updated", t)
+	assert.DeepEquals(instanceReconciler.Status.Readiness.Workloads, []maistrav2.ComponentWorkloadStatus{
+		{
+			Component: "istio-ingressgateway",
+			Images:    []string{"quay.io/maistra/init:2.4", "quay.io/maistra/proxyv2:2.4"},
+			Ready:     false,
+		},
+		{
+			Component: "istiod",
+			Images:    []string{"quay.io/maistra/pilot:2.4"},
+			Ready:     true,
+		},
+	}, "Unexpected workloads", t)
+}
+
 func newDeployment(name, namespace, component string, ready bool) *appsv1.Deployment {
 	var readyReplicas int32
 	if ready {
@@ -360,3 +429,47 @@ func newDaemonSet(name, namespace, owner, component string, ready bool) *appsv1.
 		},
 	}
 }
+
+func TestUpdateReadinessStatusReportsFailureHints(t *testing.T) {
+	smcp := newControlPlane()
+	smcp.Status.SetCondition(status.Condition{Type: status.ConditionTypeReconciled, Status: status.ConditionStatusTrue})
+	smcp.Status.ComponentStatus = []status.ComponentStatus{
+		{Resource: "istiod"}, {Resource: "istio-ingressgateway"}, {Resource: "istio-egressgateway"},
+	}
+
+	istiod := newDeployment("istiod", controlPlaneNamespace, "istiod", false)
+	istiod.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "istiod"}}
+	istiodPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "istiod-abcde", Namespace: controlPlaneNamespace, Labels: map[string]string{"app": "istiod"}},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "discovery",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+					Reason:  "ImagePullBackOff",
+					Message: `Back-off pulling image "registry.example.com/pilot": 401 Unauthorized`,
+				}},
+			}},
+		},
+	}
+	gateway := newDeployment("istio-ingressgateway", controlPlaneNamespace, "istio-ingressgateway", false)
+	gateway.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "istio-ingressgateway"}}
+	gateway.Status.Conditions = append(gateway.Status.Conditions, appsv1.DeploymentCondition{
+		Type:    appsv1.DeploymentReplicaFailure,
+		Status:  corev1.ConditionTrue,
+		Message: `pods "istio-ingressgateway-7d9f-" is forbidden: unable to validate against any security context constraint`,
+	})
+	egressGateway := newDeployment("istio-egressgateway", controlPlaneNamespace, "istio-egressgateway", false)
+	egressGateway.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "istio-egressgateway"}}
+
+	cl, _ := test.CreateClient(smcp, istiod, istiodPod, gateway, egressGateway)
+	instanceReconciler := newTestInstanceReconciler(cl, smcp)
+
+	assert.True(instanceReconciler.updateReadinessStatus(ctx), "expected status to be updated", t)
+	readyCondition := instanceReconciler.Status.GetCondition(status.ConditionTypeReady)
+	assert.Equals(readyCondition.Status, status.ConditionStatusFalse, "Unexpected Ready condition status", t)
+	assert.Equals(readyCondition.Message,
+		"The following components are not fully available: [istio-egressgateway istio-ingressgateway istiod]; "+
+			"istio-ingressgateway: [SCCMissing] grant the service account of the pods the use of a SecurityContextConstraints that allows them, e.g. anyuid; "+
+			"istiod: [ImagePullUnauthorized] check that the image pull secrets of the service account contain valid credentials for the registry",
+		"Unexpected Ready condition message", t)
+}
@@ -0,0 +1,163 @@
+package controlplane
+
+import (
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	apixv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	v2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+)
+
+const readinessControllerName = "servicemeshcontrolplane-readiness-controller"
+
+// readinessReconciler updates the readiness of a ServiceMeshControlPlane when
+// the state of one of its workloads changes.  It has its own work queue, so
+// readiness updates aren't delayed by the installation of other control planes.
+// Changes to the workloads of a control plane that is still being installed are
+// handled by the ServiceMeshControlPlane controller, as it waits for the
+// components to become ready before installing the next ones.
+type readinessReconciler struct {
+	*ControlPlaneReconciler
+}
+
+var _ reconcile.Reconciler = &readinessReconciler{}
+
+// addReadinessController adds a controller watching the workloads created for
+// the control planes, and the CustomResourceDefinitions, to mgr
+func addReadinessController(mgr manager.Manager, r *ControlPlaneReconciler) error {
+	c, err := controller.New(readinessControllerName, mgr,
+		controller.Options{
+			MaxConcurrentReconciles: common.Config.Controller.ControlPlaneReconcilers,
+			Reconciler:              common.NewConflictHandlingReconciler(&readinessReconciler{ControlPlaneReconciler: r}),
+		})
+	if err != nil {
+		return err
+	}
+
+	if err = addWorkloadWatches(mgr, c, r, true); err != nil {
+		return err
+	}
+
+	// the cached API groups and resources are outdated when CRDs are
+	// installed or removed, which also changes the DependenciesHealthy
+	// condition if the CRD is an optional dependency
+	enqueueForDependencies := enqueueControlPlanes(mgr, true)
+	return c.Watch(&source.Kind{Type: &apixv1.CustomResourceDefinition{}},
+		&handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+				common.InvalidateDiscoveryCache(r.DiscoveryClient)
+				if crd, ok := obj.Object.(*apixv1.CustomResourceDefinition); ok && isOptionalDependency(crd) {
+					return enqueueForDependencies(obj)
+				}
+				return nil
+			}),
+		},
+		servedResourcesPredicates)
+}
+
+// addWorkloadWatches adds watches for the workloads created for the control
+// planes to c.  Changes are only enqueued for control planes whose full
+// reconciliation matches fullyReconciled, so that each change is handled either
+// by the readiness controller or by the ServiceMeshControlPlane controller.
+func addWorkloadWatches(mgr manager.Manager, c controller.Controller, r *ControlPlaneReconciler, fullyReconciled bool) error {
+	// watch created resources for use in synchronizing ready status
+	if err := c.Watch(&source.Kind{Type: &appsv1.Deployment{}}, r.enqueueRequestForSMCPAndMarkDirty("Deployment", fullyReconciled), ownedResourcePredicates); err != nil {
+		return err
+	}
+	if err := c.Watch(&source.Kind{Type: &appsv1.StatefulSet{}}, r.enqueueRequestForSMCPAndMarkDirty("StatefulSet", fullyReconciled), ownedResourcePredicates); err != nil {
+		return err
+	}
+	if err := c.Watch(&source.Kind{Type: &appsv1.DaemonSet{}}, r.enqueueRequestForSMCPAndMarkDirty("DaemonSet", fullyReconciled), ownedResourcePredicates); err != nil {
+		return err
+	}
+
+	// add watch for cni daemon set
+	operatorNamespace := common.GetOperatorNamespace()
+	enqueueForCNI := enqueueControlPlanes(mgr, fullyReconciled)
+	return c.Watch(&source.Kind{Type: &appsv1.DaemonSet{}},
+		&handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+				if obj.Meta.GetNamespace() != operatorNamespace {
+					return nil
+				}
+				return enqueueForCNI(obj)
+			}),
+		},
+		ownedResourcePredicates)
+}
+
+// enqueueControlPlanes returns a function that maps any object to requests for
+// all control planes whose full reconciliation matches fullyReconciled
+func enqueueControlPlanes(mgr manager.Manager, fullyReconciled bool) handler.ToRequestsFunc {
+	log := createReadinessLogger()
+	ctx := common.NewContextWithLog(common.NewContext(), log)
+	return func(_ handler.MapObject) []reconcile.Request {
+		smcpList := &v2.ServiceMeshControlPlaneList{}
+		if err := mgr.GetClient().List(ctx, smcpList); err != nil {
+			log.Error(err, "error listing ServiceMeshControlPlane objects")
+			return nil
+		}
+		requests := make([]reconcile.Request, 0, len(smcpList.Items))
+		for _, smcp := range smcpList.Items {
+			if isFullyReconciled(&smcp) == fullyReconciled {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: common.ToNamespacedName(&smcp),
+				})
+			}
+		}
+		return requests
+	}
+}
+
+// Reconcile updates the readiness of a fully reconciled ServiceMeshControlPlane
+// and requeues it while a loss of readiness is delayed by the readinessDebouncer
+func (r *readinessReconciler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	log := createReadinessLogger().WithValues("ServiceMeshControlPlane", request)
+	ctx := common.NewReconcileContext(log)
+
+	defer r.lockInstance(request.NamespacedName)()
+
+	instance := &v2.ServiceMeshControlPlane{}
+	if err := r.Client.Get(ctx, request.NamespacedName, instance); err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	if instance.GetDeletionTimestamp() != nil {
+		return reconcile.Result{}, nil
+	}
+
+	if !isFullyReconciled(instance) {
+		// the ServiceMeshControlPlane controller updates the readiness once the installation is complete
+		log.V(2).Info("Skipping readiness update, as the installation is in progress")
+		return reconcile.Result{}, nil
+	}
+
+	key, reconciler := r.getOrCreateReconciler(instance)
+	defer r.deleteReconcilerIfFinished(key, reconciler)
+	if err := reconciler.UpdateReadiness(ctx); err != nil {
+		return reconcile.Result{}, err
+	}
+	if delay := r.readinessDebouncer.remaining(key, r.clock.Now()); delay > 0 {
+		// report the lost readiness once the delay expires, even if the
+		// workloads don't change in the meantime
+		return common.RequeueAfter(delay)
+	}
+	return reconcile.Result{}, nil
+}
+
+// Don't use this function to obtain a logger. Get it by invoking
+// common.LogFromContext(ctx) to ensure that the logger has the
+// correct context info and logs it.
+func createReadinessLogger() logr.Logger {
+	return logf.Log.WithName(readinessControllerName)
+}
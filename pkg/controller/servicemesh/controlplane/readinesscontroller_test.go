@@ -0,0 +1,156 @@
+package controlplane
+
+import (
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/maistra/istio-operator/pkg/apis/maistra/status"
+	maistrav2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/common/cni"
+	"github.com/maistra/istio-operator/pkg/controller/common/test"
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+	"github.com/maistra/istio-operator/pkg/version"
+)
+
+func TestReadinessControllerUpdatesReadinessWhenInstanceFullyReconciled(t *testing.T) {
+	controlPlane := newFullyReconciledControlPlane()
+
+	_, _, r := createClientAndReconciler(controlPlane)
+	_, err := (&readinessReconciler{ControlPlaneReconciler: r}).Reconcile(request)
+	assert.Success(err, "Reconcile", t)
+
+	assert.True(instanceReconciler.updateReadinessInvoked, "Expected UpdateReadiness() to be invoked on instance reconciler", t)
+	assert.False(instanceReconciler.reconcileInvoked, "Expected Reconcile() to NOT be invoked on instance reconciler", t)
+}
+
+func TestReadinessControllerSkipsInstanceNotFullyReconciled(t *testing.T) {
+	controlPlane := newControlPlane()
+
+	_, tracker, r := createClientAndReconciler(controlPlane)
+	_, err := (&readinessReconciler{ControlPlaneReconciler: r}).Reconcile(request)
+	assert.Success(err, "Reconcile", t)
+
+	assert.False(instanceReconciler.updateReadinessInvoked, "Expected UpdateReadiness() to NOT be invoked on instance reconciler", t)
+	assert.False(instanceReconciler.reconcileInvoked, "Expected Reconcile() to NOT be invoked on instance reconciler", t)
+	test.AssertNumberOfWriteActions(t, tracker.Actions(), 0)
+}
+
+func TestReadinessControllerDoesNothingWhenResourceIsNotFound(t *testing.T) {
+	_, tracker, r := createClientAndReconciler()
+	_, err := (&readinessReconciler{ControlPlaneReconciler: r}).Reconcile(request)
+	assert.Success(err, "Reconcile", t)
+
+	test.AssertNumberOfWriteActions(t, tracker.Actions(), 0)
+}
+
+func TestReadinessControllerRequeuesWhileLostReadinessIsDebounced(t *testing.T) {
+	defer func(debounce time.Duration) {
+		common.Config.Controller.ReadinessDebounce = debounce
+	}(common.Config.Controller.ReadinessDebounce)
+	common.Config.Controller.ReadinessDebounce = 30 * time.Second
+
+	controlPlane := newFullyReconciledControlPlane()
+	cl, tracker := test.CreateClient(controlPlane, newDeployment("istiod", controlPlaneNamespace, "istiod", true))
+	r := newReconciler(cl, scheme.Scheme, &record.FakeRecorder{}, "istio-operator", cni.Config{Enabled: true},
+		&fake.FakeDiscovery{Fake: &tracker.Fake, FakedServerVersion: test.DefaultKubeVersion})
+	fakeClock := clock.NewFakeClock(time.Now())
+	r.clock = fakeClock
+	readinessReconciler := &readinessReconciler{ControlPlaneReconciler: r}
+
+	reconcileReadiness := func(expectedRequeueAfter time.Duration) {
+		t.Helper()
+		result, err := readinessReconciler.Reconcile(request)
+		assert.Success(err, "Reconcile", t)
+		assert.Equals(result.RequeueAfter, expectedRequeueAfter, "Unexpected RequeueAfter", t)
+	}
+	assertReady := func(expected status.ConditionStatus) {
+		t.Helper()
+		test.PanicOnError(cl.Get(ctx, common.ToNamespacedName(controlPlane), controlPlane))
+		assert.Equals(controlPlane.Status.GetCondition(status.ConditionTypeReady).Status, expected, "Unexpected Ready condition", t)
+	}
+
+	reconcileReadiness(0)
+	assertReady(status.ConditionStatusTrue)
+
+	// the control plane is rechecked when the delay of the lost readiness expires
+	test.PanicOnError(cl.Update(ctx, newDeployment("istiod", controlPlaneNamespace, "istiod", false)))
+	r.readinessCache.markDirty(request.NamespacedName, readinessObjectKey{Kind: "Deployment",
+		NamespacedName: types.NamespacedName{Namespace: controlPlaneNamespace, Name: "istiod"}})
+	reconcileReadiness(30 * time.Second)
+	assertReady(status.ConditionStatusTrue)
+	fakeClock.Step(10 * time.Second)
+	reconcileReadiness(20 * time.Second)
+	assertReady(status.ConditionStatusTrue)
+
+	fakeClock.Step(20 * time.Second)
+	reconcileReadiness(0)
+	assertReady(status.ConditionStatusFalse)
+}
+
+func TestWorkloadChangesAreRoutedByReconciliationState(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "istiod",
+			Namespace: controlPlaneNamespace,
+			Labels: map[string]string{
+				common.KubernetesAppManagedByKey: common.KubernetesAppManagedByValue,
+				common.OwnerKey:                  controlPlaneNamespace,
+				common.OwnerNameKey:              controlPlaneName,
+			},
+		},
+	}
+	mapObject := handler.MapObject{Meta: deployment, Object: deployment}
+
+	testCases := []struct {
+		name                    string
+		controlPlane            *maistrav2.ServiceMeshControlPlane
+		expectedFullyReconciled bool
+	}{
+		{
+			name:                    "installing",
+			controlPlane:            newControlPlane(),
+			expectedFullyReconciled: false,
+		},
+		{
+			name:                    "fully-reconciled",
+			controlPlane:            newFullyReconciledControlPlane(),
+			expectedFullyReconciled: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, r := createClientAndReconciler(tc.controlPlane)
+			for _, fullyReconciled := range []bool{true, false} {
+				eventHandler := r.enqueueRequestForSMCPAndMarkDirty("Deployment", fullyReconciled).(*handler.EnqueueRequestsFromMapFunc)
+				requests := eventHandler.ToRequests.Map(mapObject)
+				if fullyReconciled == tc.expectedFullyReconciled {
+					assert.DeepEquals(requests, []reconcile.Request{request}, "Expected request for owning control plane", t)
+				} else {
+					assert.Equals(len(requests), 0, "Expected no requests", t)
+				}
+			}
+		})
+	}
+}
+
+func newFullyReconciledControlPlane() *maistrav2.ServiceMeshControlPlane {
+	controlPlane := newControlPlane()
+	controlPlane.Status.OperatorVersion = version.Info.Version
+	controlPlane.Status.ObservedGeneration = controlPlane.Generation
+	controlPlane.Status.Conditions = append(controlPlane.Status.Conditions, status.Condition{
+		Type:   status.ConditionTypeReconciled,
+		Status: status.ConditionStatusTrue,
+	})
+	return controlPlane
+}
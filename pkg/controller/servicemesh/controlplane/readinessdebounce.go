@@ -0,0 +1,77 @@
+package controlplane
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/maistra/istio-operator/pkg/controller/common"
+)
+
+// readinessDebouncer delays reporting that a ready control plane is no longer
+// ready until it has been observed not to be ready for ReadinessDebounce.
+// Until then, the readiness of the control plane is reported as it was last
+// posted, so components flapping during a rollout don't cause a status patch
+// on every change.  The methods are safe to call on a nil debouncer, in which
+// case changes are never delayed.
+type readinessDebouncer struct {
+	mu           sync.Mutex
+	unreadySince map[types.NamespacedName]time.Time
+}
+
+func newReadinessDebouncer() *readinessDebouncer {
+	return &readinessDebouncer{
+		unreadySince: map[types.NamespacedName]time.Time{},
+	}
+}
+
+// delay records that the ready control plane was observed not to be ready at
+// now and returns how much longer it must remain unready before the loss of
+// readiness is reported, or zero if it should be reported now
+func (d *readinessDebouncer) delay(key types.NamespacedName, now time.Time) time.Duration {
+	period := common.Config.Controller.ReadinessDebounce
+	if d == nil || period <= 0 {
+		return 0
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	since, ok := d.unreadySince[key]
+	if !ok {
+		since = now
+		d.unreadySince[key] = now
+	}
+	if remaining := since.Add(period).Sub(now); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// remaining returns how long it takes until a delayed loss of readiness of
+// the control plane is reported, or zero if none is pending
+func (d *readinessDebouncer) remaining(key types.NamespacedName, now time.Time) time.Duration {
+	if d == nil {
+		return 0
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	since, ok := d.unreadySince[key]
+	if !ok {
+		return 0
+	}
+	if remaining := since.Add(common.Config.Controller.ReadinessDebounce).Sub(now); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// reset forgets that the control plane was observed not to be ready, e.g.
+// because it's ready again or isn't reported as ready anymore
+func (d *readinessDebouncer) reset(key types.NamespacedName) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.unreadySince, key)
+}
@@ -0,0 +1,65 @@
+package controlplane
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/maistra/istio-operator/pkg/apis/maistra/status"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/common/test"
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+)
+
+func TestUpdateReadinessStatusDebouncesLostReadiness(t *testing.T) {
+	defer func(debounce time.Duration) {
+		common.Config.Controller.ReadinessDebounce = debounce
+	}(common.Config.Controller.ReadinessDebounce)
+	common.Config.Controller.ReadinessDebounce = 30 * time.Second
+
+	smcp := newControlPlane()
+	istiod := newDeployment("istiod", controlPlaneNamespace, "istiod", true)
+	cl, _ := test.CreateClient(istiod)
+	r := newTestInstanceReconciler(cl, smcp)
+	fakeClock := clock.NewFakeClock(time.Now())
+	r.clock = fakeClock
+	r.readinessDebouncer = newReadinessDebouncer()
+	r.Status.SetCondition(status.Condition{Type: status.ConditionTypeReconciled, Status: status.ConditionStatusTrue})
+	key := common.ToNamespacedName(smcp)
+
+	setIstiodReady := func(ready bool) {
+		test.PanicOnError(cl.Update(ctx, newDeployment("istiod", controlPlaneNamespace, "istiod", ready)))
+	}
+	assertReady := func(expected status.ConditionStatus) {
+		t.Helper()
+		assert.Equals(r.Status.GetCondition(status.ConditionTypeReady).Status, expected, "Unexpected Ready condition", t)
+	}
+
+	assert.True(r.updateReadinessStatus(ctx), "Expected status to be updated", t)
+	assertReady(status.ConditionStatusTrue)
+
+	// the lost readiness isn't reported while it's shorter than the debounce
+	setIstiodReady(false)
+	assert.False(r.updateReadinessStatus(ctx), "Expected status to be unchanged", t)
+	assertReady(status.ConditionStatusTrue)
+	assert.Equals(r.readinessDebouncer.remaining(key, fakeClock.Now()), 30*time.Second, "Unexpected remaining delay", t)
+
+	fakeClock.Step(10 * time.Second)
+	setIstiodReady(true)
+	assert.False(r.updateReadinessStatus(ctx), "Expected status to be unchanged", t)
+	assertReady(status.ConditionStatusTrue)
+	assert.Equals(r.readinessDebouncer.remaining(key, fakeClock.Now()), time.Duration(0), "Expected no pending delay", t)
+
+	// it's reported once it lasts longer than the debounce
+	setIstiodReady(false)
+	assert.False(r.updateReadinessStatus(ctx), "Expected status to be unchanged", t)
+	fakeClock.Step(30 * time.Second)
+	assert.True(r.updateReadinessStatus(ctx), "Expected status to be updated", t)
+	assertReady(status.ConditionStatusFalse)
+
+	// regaining readiness is reported right away
+	setIstiodReady(true)
+	assert.True(r.updateReadinessStatus(ctx), "Expected status to be updated", t)
+	assertReady(status.ConditionStatusTrue)
+}
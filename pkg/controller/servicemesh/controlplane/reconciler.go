@@ -13,6 +13,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/clock"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/helm/pkg/manifest"
@@ -28,6 +29,7 @@ import (
 	"github.com/maistra/istio-operator/pkg/bootstrap"
 	"github.com/maistra/istio-operator/pkg/controller/common"
 	"github.com/maistra/istio-operator/pkg/controller/common/cni"
+	"github.com/maistra/istio-operator/pkg/controller/common/helm"
 	"github.com/maistra/istio-operator/pkg/controller/hacks"
 	"github.com/maistra/istio-operator/pkg/controller/versions"
 	buildinfo "github.com/maistra/istio-operator/pkg/version"
@@ -43,6 +45,30 @@ type controlPlaneInstanceReconciler struct {
 	renderings        map[string][]manifest.Manifest
 	waitForComponents sets.String
 	cniConfig         cni.Config
+	readinessCache    *readinessCache
+	timings           *reconcileTimings
+	retryBudget       retryBudget
+	dryRun            bool
+	// readinessDebouncer delays reporting the loss of readiness, see
+	// Config.Controller.ReadinessDebounce
+	readinessDebouncer *readinessDebouncer
+	// clock is used for all timing decisions, e.g. backoffs and rechecks, so
+	// that tests can control the passing of time
+	clock clock.Clock
+	// apiReader reads objects that aren't watched directly from the API
+	// server, so that they aren't cached by the manager
+	apiReader client.Reader
+	// injectedPodReader reads the pods with injected sidecars, see injectedPodLabel
+	injectedPodReader client.Reader
+	// injectionTemplatesReader reads the ConfigMaps with custom injection
+	// templates, see injectionTemplatesLabel
+	injectionTemplatesReader client.Reader
+	// remoteSecretReader reads the remote cluster secrets, see remoteSecretLabel
+	remoteSecretReader client.Reader
+	// injectionTemplates are the custom injection templates applied by the
+	// current reconciliation, by name
+	injectionTemplates         map[string]string
+	injectionTemplatesChecksum string
 }
 
 // ensure controlPlaneInstanceReconciler implements ControlPlaneInstanceReconciler
@@ -58,6 +84,11 @@ const (
 	eventReasonUpdated                 = "Updated"
 	eventReasonDeleting                = "Deleting"
 	eventReasonDeleted                 = "Deleted"
+	eventReasonDeletionBlocked         = "DeletionBlocked"
+	eventReasonDeletionForced          = "DeletionForced"
+	eventReasonPaused                  = "Paused"
+	eventReasonResumed                 = "Resumed"
+	eventReasonNamespaceTerminating    = "NamespaceTerminating"
 	eventReasonPruning                 = "Pruning"
 	eventReasonFailedRemovingFinalizer = "FailedRemovingFinalizer"
 	eventReasonFailedDeletingResources = "FailedDeletingResources"
@@ -71,10 +102,15 @@ func NewControlPlaneInstanceReconciler(controllerResources common.ControllerReso
 	newInstance *v2.ServiceMeshControlPlane, cniConfig cni.Config,
 ) ControlPlaneInstanceReconciler {
 	return &controlPlaneInstanceReconciler{
-		ControllerResources: controllerResources,
-		Instance:            newInstance,
-		Status:              newInstance.Status.DeepCopy(),
-		cniConfig:           cniConfig,
+		ControllerResources:      controllerResources,
+		Instance:                 newInstance,
+		Status:                   newInstance.Status.DeepCopy(),
+		cniConfig:                cniConfig,
+		clock:                    clock.RealClock{},
+		apiReader:                controllerResources.Client,
+		injectedPodReader:        controllerResources.Client,
+		injectionTemplatesReader: controllerResources.Client,
+		remoteSecretReader:       controllerResources.Client,
 	}
 }
 
@@ -92,6 +128,15 @@ func (r *controlPlaneInstanceReconciler) Reconcile(ctx context.Context) (result
 			fmt.Errorf("multiple ServiceMeshControlPlane resources exist in the namespace"))
 	}
 
+	if backoff := r.retryBudget.remainingBackoff(r.clock.Now()); backoff > 0 {
+		log.Info("Skipping reconciliation of ServiceMeshControlPlane, as its retry budget is exhausted", "backoff", backoff)
+		return common.RequeueAfter(backoff)
+	}
+	defer func() {
+		// this runs after the reconciliation status is posted
+		result, err = r.updateRetryBudget(ctx, result, err)
+	}()
+
 	if r.Status.GetCondition(status.ConditionTypeReconciled).Status != status.ConditionStatusFalse {
 		r.initializeReconcileStatus()
 		err := r.PostStatus(ctx)
@@ -132,6 +177,7 @@ func (r *controlPlaneInstanceReconciler) Reconcile(ctx context.Context) (result
 		}()
 
 		r.Status.SetAnnotation(statusAnnotationAlwaysReadyComponents, "")
+		r.timings = newReconcileTimings()
 
 		conversionError, exists, err2 := r.Instance.Spec.TechPreview.GetString(conversion.TechPreviewErroredMessage)
 		if err2 != nil {
@@ -147,7 +193,9 @@ func (r *controlPlaneInstanceReconciler) Reconcile(ctx context.Context) (result
 		}
 
 		// Render the templates
+		renderStart := time.Now()
 		r.renderings, err = version.Strategy().Render(ctx, &r.ControllerResources, r.cniConfig, r.Instance)
+		r.timings.track("render", renderStart)
 		// always set these, especially if rendering failed, as these are useful for debugging
 		r.Instance.Status.AppliedValues.DeepCopyInto(&r.Status.AppliedValues)
 		r.Instance.Status.AppliedSpec.DeepCopyInto(&r.Status.AppliedSpec)
@@ -162,6 +210,21 @@ func (r *controlPlaneInstanceReconciler) Reconcile(ctx context.Context) (result
 			} else {
 				reconciliationReason = status.ConditionReasonReconcileError
 				reconciliationMessage = "Error rendering helm charts"
+				err = helm.SummarizeRenderErrors(err)
+			}
+			err = errors.Wrap(err, reconciliationMessage)
+			return
+		}
+
+		// the custom injection templates are added to the rendered injector ConfigMap
+		if err = r.loadInjectionTemplates(ctx); err != nil {
+			r.renderings = nil
+			if versions.IsValidationError(err) {
+				reconciliationReason = status.ConditionReasonValidationError
+				reconciliationMessage = "Custom injection templates are invalid"
+			} else {
+				reconciliationReason = status.ConditionReasonReconcileError
+				reconciliationMessage = "Error reading custom injection templates"
 			}
 			err = errors.Wrap(err, reconciliationMessage)
 			return
@@ -209,13 +272,16 @@ func (r *controlPlaneInstanceReconciler) Reconcile(ctx context.Context) (result
 		r.ownerRefs = []metav1.OwnerReference{*owner}
 		r.meshGeneration = status.CurrentReconciledVersion(r.Instance.GetGeneration())
 
-		// Ensure CRDs are installed
-		chartsDir := version.GetChartsDir()
-		if err = bootstrap.InstallCRDs(common.NewContextWithLog(ctx, log.WithValues("version", r.Instance.Spec.Version)), r.Client, chartsDir); err != nil {
-			reconciliationReason = status.ConditionReasonReconcileError
-			reconciliationMessage = "Failed to install/update Istio CRDs"
-			log.Error(err, reconciliationMessage)
-			return
+		// Ensure CRDs are installed, unless they are managed externally
+		bootstrapStart := time.Now()
+		if common.Config.Controller.CRDManagementEnabled {
+			chartsDir := version.GetChartsDir()
+			if err = bootstrap.InstallCRDs(common.NewContextWithLog(ctx, log.WithValues("version", r.Instance.Spec.Version)), r.Client, chartsDir); err != nil {
+				reconciliationReason = status.ConditionReasonReconcileError
+				reconciliationMessage = "Failed to install/update Istio CRDs"
+				log.Error(err, reconciliationMessage)
+				return
+			}
 		}
 
 		// Ensure Istio CNI is installed
@@ -234,11 +300,14 @@ func (r *controlPlaneInstanceReconciler) Reconcile(ctx context.Context) (result
 			log.Error(err, reconciliationMessage)
 			return
 		}
+		r.timings.track("bootstrap", bootstrapStart)
 
 	} else if r.waitForComponents.Len() > 0 {
 		// if we've already begun reconciling, make sure we weren't waiting for
 		// the last component to become ready
+		readinessStart := time.Now()
 		readyComponents, _, readinessErr := r.calculateComponentReadiness(ctx)
+		r.timings.track("readiness", readinessStart)
 		if readinessErr != nil {
 			// error calculating readiness
 			reconciliationReason = status.ConditionReasonProbeError
@@ -257,7 +326,9 @@ func (r *controlPlaneInstanceReconciler) Reconcile(ctx context.Context) (result
 
 	// validate generated manifests
 	// this has to be done always before applying because the memberroll might have changed
-	err = r.validateManifests(ctx)
+	validateStart := time.Now()
+	err = r.validateManifests(ctx, r.renderings)
+	r.timings.track("validate", validateStart)
 	if err != nil {
 		reconciliationReason = status.ConditionReasonReconcileError
 		reconciliationMessage = "Error validating generated manifests"
@@ -272,7 +343,9 @@ func (r *controlPlaneInstanceReconciler) Reconcile(ctx context.Context) (result
 		for _, chart := range charts {
 			component := componentFromChartName(chart)
 			var changes bool
+			applyStart := time.Now()
 			changes, err = r.processComponentManifests(ctx, chart)
+			r.timings.track("apply/"+component, applyStart)
 			madeChanges = madeChanges || changes
 			if err != nil {
 				reconciliationReason = status.ConditionReasonReconcileError
@@ -305,7 +378,9 @@ func (r *controlPlaneInstanceReconciler) Reconcile(ctx context.Context) (result
 				return
 			}
 
+			readinessStart := time.Now()
 			readyComponents, _, readyErr := r.calculateComponentReadiness(ctx)
+			r.timings.track("readiness", readinessStart)
 			if readyErr != nil {
 				reconciliationReason, reconciliationMessage = r.pauseReconciliation(ctx)
 				return
@@ -325,13 +400,15 @@ func (r *controlPlaneInstanceReconciler) Reconcile(ctx context.Context) (result
 	reconciliationMessage = "Pruning obsolete resources"
 	r.EventRecorder.Event(r.Instance, corev1.EventTypeNormal, eventReasonPruning, reconciliationMessage)
 	log.Info(reconciliationMessage)
-	err = r.prune(ctx, r.meshGeneration)
+	pruneStart := time.Now()
+	err = r.prune(ctx, r.meshGeneration, nil)
 	if err != nil {
 		reconciliationReason = status.ConditionReasonReconcileError
 		reconciliationMessage = "Error pruning obsolete resources"
 		err = errors.Wrap(err, reconciliationMessage)
 		return
 	}
+	r.timings.track("prune", pruneStart)
 
 	if r.isUpdating() {
 		reconciliationReason = status.ConditionReasonUpdateSuccessful
@@ -345,6 +422,9 @@ func (r *controlPlaneInstanceReconciler) Reconcile(ctx context.Context) (result
 	r.Status.ObservedGeneration = r.Instance.GetGeneration()
 	r.Status.OperatorVersion = buildinfo.Info.Version
 	r.Status.ChartVersion = r.chartVersion
+	if r.timings != nil {
+		r.Status.SetAnnotation(statusAnnotationReconcileTimings, r.timings.String())
+	}
 	updateControlPlaneConditions(r.Status, nil)
 
 	hacks.SkipReconciliationUntilCacheSynced(ctx, common.ToNamespacedName(r.Instance))
@@ -407,7 +487,7 @@ func (r *controlPlaneInstanceReconciler) validateSMCPSpec(spec v1.ControlPlaneSp
 	return nil
 }
 
-func (r *controlPlaneInstanceReconciler) validateManifests(ctx context.Context) error {
+func (r *controlPlaneInstanceReconciler) validateManifests(ctx context.Context, renderings map[string][]manifest.Manifest) error {
 	log := common.LogFromContext(ctx)
 	allErrors := []error{}
 	// validate resource namespaces
@@ -422,7 +502,7 @@ func (r *controlPlaneInstanceReconciler) validateManifests(ctx context.Context)
 		}
 	}
 	meshNamespaces := common.GetMeshNamespaces(r.Instance.GetNamespace(), smmr)
-	for _, manifestList := range r.renderings {
+	for _, manifestList := range renderings {
 		for _, manifestBundle := range manifestList {
 			manifests := releaseutil.SplitManifests(manifestBundle.Content)
 			for _, manifest := range manifests {
@@ -489,7 +569,8 @@ func (r *controlPlaneInstanceReconciler) postReconciliationStatus(ctx context.Co
 		reconciledCondition.Message = reconciliationMessage
 	} else {
 		// grab the cause, as it's likely the error includes the reconciliation message
-		reconciledCondition.Message = fmt.Sprintf("%s: error: %s", reconciliationMessage, errors.Cause(processingErr))
+		reconciledCondition.Message = common.WithRemediationHint(
+			fmt.Sprintf("%s: error: %s", reconciliationMessage, errors.Cause(processingErr)))
 		r.EventRecorder.Event(r.Instance, corev1.EventTypeWarning, reason, reconciledCondition.Message)
 	}
 	r.Status.SetCondition(reconciledCondition)
@@ -525,6 +606,9 @@ func (r *controlPlaneInstanceReconciler) initializeReconcileStatus() {
 		})
 	}
 	r.EventRecorder.Event(r.Instance, corev1.EventTypeNormal, eventReason, readyMessage)
+	// the result of a previous dry run no longer applies once the spec is applied
+	r.Status.RemoveCondition(status.ConditionTypeDryRun)
+	r.Status.RemoveAnnotation(statusAnnotationDryRunGeneration)
 	r.Status.SetCondition(status.Condition{
 		Type:    status.ConditionTypeReconciled,
 		Status:  status.ConditionStatusFalse,
@@ -546,6 +630,9 @@ func (r *controlPlaneInstanceReconciler) SetInstance(newInstance *v2.ServiceMesh
 		r.waitForComponents = sets.NewString()
 		// reset reconcile status
 		r.Status.SetCondition(status.Condition{Type: status.ConditionTypeReconciled, Status: status.ConditionStatusUnknown})
+		// the new spec may fix the failures, so it gets a new retry budget
+		r.retryBudget.reset()
+		r.Status.RemoveCondition(status.ConditionTypeBackoff)
 	}
 	r.Instance = newInstance
 }
@@ -14,7 +14,9 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/scheme"
 	clienttesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -306,7 +308,7 @@ func TestManifestValidation(t *testing.T) {
 					tc.setupFn(cl, tracker)
 				}
 				// run initial reconcile to update the SMCP status
-				_, err := r.Reconcile(hacks.WrapContext(ctx, map[types.NamespacedName]time.Time{}))
+				_, err := r.Reconcile(hacks.WrapContext(ctx, map[types.NamespacedName]time.Time{}, clock.RealClock{}))
 
 				expectedErrorMessage := tc.errorMessages[version]
 				if expectedErrorMessage == "" {
@@ -334,7 +336,7 @@ func TestManifestValidation(t *testing.T) {
 }
 
 func assertInstanceReconcilerFails(r ControlPlaneInstanceReconciler, t *testing.T) {
-	_, err := r.Reconcile(hacks.WrapContext(ctx, map[types.NamespacedName]time.Time{}))
+	_, err := r.Reconcile(hacks.WrapContext(ctx, map[types.NamespacedName]time.Time{}, clock.RealClock{}))
 	if err == nil {
 		t.Fatal("Expected reconcile to fail, but it didn't")
 	}
@@ -717,6 +719,216 @@ func TestNewNamespaceLabels(t *testing.T) {
 	assert.DeepEquals(ns.Labels, map[string]string(nil), "Namespace labels weren't removed", t)
 }
 
+// tests that an SMCP with an invalid version, or whose namespace is already
+// gone, can still be deleted
+func TestDeleteRemovesFinalizer(t *testing.T) {
+	testCases := []struct {
+		name            string
+		version         string
+		deleteNamespace bool
+	}{
+		{
+			name:    "invalid-version",
+			version: "v0.9",
+		},
+		{
+			name:            "namespace-deleted",
+			version:         versions.V2_4.String(),
+			deleteNamespace: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			smcp := newControlPlane()
+			smcp.Spec.Version = tc.version
+			smcp.DeletionTimestamp = &oneMinuteAgo
+
+			cl, _, r := newReconcilerTestFixture(smcp)
+			if tc.deleteNamespace {
+				test.PanicOnError(cl.Delete(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: controlPlaneNamespace}}))
+			}
+
+			assertDeleteSucceeds(r, t) // this only initializes the SMCP status
+			assertDeleteSucceeds(r, t) // this does the actual work
+
+			updatedSmcp := &maistrav2.ServiceMeshControlPlane{}
+			err := cl.Get(ctx, common.ToNamespacedName(smcp), updatedSmcp)
+			if err == nil {
+				assert.Equals(len(updatedSmcp.Finalizers), 0, "Expected finalizer to be removed", t)
+			} else if !errors.IsNotFound(err) {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestDeleteReportsComponentStatus(t *testing.T) {
+	smcp := newControlPlane()
+	smcp.DeletionTimestamp = &oneMinuteAgo
+	for _, component := range []string{"istiod", "grafana"} {
+		componentStatus := status.NewComponentStatus()
+		componentStatus.Resource = component
+		smcp.Status.ComponentStatus = append(smcp.Status.ComponentStatus, *componentStatus)
+	}
+
+	cl, tracker, r := newReconcilerTestFixture(smcp)
+	newComponentObject := func(obj runtime.Object, component string) runtime.Object {
+		o := obj.(metav1.Object)
+		o.SetName(component)
+		o.SetNamespace(controlPlaneNamespace)
+		o.SetLabels(map[string]string{
+			common.OwnerKey:                  controlPlaneNamespace,
+			common.OwnerNameKey:              controlPlaneName,
+			common.KubernetesAppManagedByKey: common.KubernetesAppManagedByValue,
+			common.KubernetesAppComponentKey: component,
+		})
+		return obj
+	}
+	test.PanicOnError(cl.Create(ctx, newComponentObject(&appsv1.Deployment{}, "istiod")))
+	test.PanicOnError(cl.Create(ctx, newComponentObject(&corev1.ConfigMap{}, "grafana")))
+	deleteDeploymentsFails := test.On("delete", "deployments", test.ClientFails())
+	tracker.AddReaction(deleteDeploymentsFails)
+
+	assertDeleteSucceeds(r, t) // this only initializes the SMCP status
+	assertComponentReconciledCondition(r, "istiod", status.ConditionReasonDeleting, t)
+	assertComponentReconciledCondition(r, "grafana", status.ConditionReasonDeleting, t)
+
+	_, err := r.Delete(hacks.WrapContext(ctx, map[types.NamespacedName]time.Time{}, clock.RealClock{}))
+	assert.Failure(err, "Delete", t)
+	assertComponentReconciledCondition(r, "istiod", status.ConditionReasonDeletionError, t)
+	assertComponentReconciledCondition(r, "grafana", status.ConditionReasonDeleting, t)
+
+	updatedSmcp := &maistrav2.ServiceMeshControlPlane{}
+	test.PanicOnError(cl.Get(ctx, common.ToNamespacedName(smcp), updatedSmcp))
+	assert.Equals(len(updatedSmcp.Finalizers), 1, "Expected finalizer to be kept after failed deletion", t)
+
+	tracker.RemoveReaction(deleteDeploymentsFails)
+	assertDeleteSucceeds(r, t)
+	assertComponentReconciledCondition(r, "istiod", status.ConditionReasonDeleted, t)
+	assertComponentReconciledCondition(r, "grafana", status.ConditionReasonDeleted, t)
+	assertTerminatingCondition(r, status.ConditionReasonDeleted, t)
+}
+
+func TestDeleteForcedAfterDeletionTimeout(t *testing.T) {
+	smcp := newControlPlane()
+	smcp.DeletionTimestamp = &now
+
+	cl, tracker, r := newReconcilerTestFixture(smcp)
+	fakeClock := clock.NewFakeClock(now.Time)
+	r.(*controlPlaneInstanceReconciler).clock = fakeClock
+	test.PanicOnError(cl.Create(ctx, &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "istiod",
+			Namespace: controlPlaneNamespace,
+			Labels: map[string]string{
+				common.OwnerKey:                  controlPlaneNamespace,
+				common.OwnerNameKey:              controlPlaneName,
+				common.KubernetesAppManagedByKey: common.KubernetesAppManagedByValue,
+				common.KubernetesAppComponentKey: "istiod",
+			},
+		},
+	}))
+	tracker.AddReactor("delete", "deployments", test.ClientFails())
+
+	assertDeleteSucceeds(r, t) // this only initializes the SMCP status
+
+	ctx := hacks.WrapContext(ctx, map[types.NamespacedName]time.Time{}, fakeClock)
+	_, err := r.Delete(ctx)
+	assert.Failure(err, "Delete", t)
+	assertTerminatingCondition(r, status.ConditionReasonDeletionError, t)
+
+	fakeClock.Step(common.Config.Controller.DeletionTimeout)
+	assertDeleteSucceeds(r, t)
+	assertTerminatingCondition(r, status.ConditionReasonDeletionForced, t)
+	assertComponentReconciledCondition(r, "istiod", status.ConditionReasonDeletionError, t)
+	updatedSmcp := &maistrav2.ServiceMeshControlPlane{}
+	test.PanicOnError(cl.Get(ctx, common.ToNamespacedName(smcp), updatedSmcp))
+	assert.Equals(len(updatedSmcp.Finalizers), 0, "Expected finalizer to be removed after the deletion timeout", t)
+}
+
+func TestDeleteBlockedWhileWorkloadsUseControlPlane(t *testing.T) {
+	smcp := newControlPlane()
+	smcp.DeletionTimestamp = &now
+
+	cl, _, r := newReconcilerTestFixture(smcp)
+	fakeClock := clock.NewFakeClock(now.Time)
+	r.(*controlPlaneInstanceReconciler).clock = fakeClock
+	test.PanicOnError(cl.Create(ctx, newMemberNamespace("app", controlPlaneNamespace)))
+	test.PanicOnError(cl.Create(ctx, withRevision(newTestPod("app", "other-revision", true), "other")))
+	pod := withRevision(newTestPod("app", "injected", true), controlPlaneName)
+	test.PanicOnError(cl.Create(ctx, pod))
+
+	assertDeleteSucceeds(r, t) // this only initializes the SMCP status
+
+	ctx := hacks.WrapContext(ctx, map[types.NamespacedName]time.Time{}, fakeClock)
+	result, err := r.Delete(ctx)
+	assert.Success(err, "Delete", t)
+	assert.Equals(result.RequeueAfter, common.Config.Controller.DeletionBlockedTimeout, "Unexpected requeue delay", t)
+	assertDeletionBlockedCondition(r, status.ConditionStatusTrue, status.ConditionReasonWorkloadsPresent, t)
+	updatedSmcp := &maistrav2.ServiceMeshControlPlane{}
+	test.PanicOnError(cl.Get(ctx, common.ToNamespacedName(smcp), updatedSmcp))
+	assert.Equals(len(updatedSmcp.Finalizers), 1, "Expected finalizer to be kept while deletion is blocked", t)
+
+	// the deletion proceeds once the workloads are gone
+	test.PanicOnError(cl.Delete(ctx, pod))
+	result, err = r.Delete(ctx)
+	assert.Success(err, "Delete", t)
+	assert.Equals(result.RequeueAfter, time.Duration(0), "Unexpected requeue delay", t)
+	assertDeletionBlockedCondition(r, status.ConditionStatusFalse, status.ConditionReasonNoWorkloads, t)
+	assertTerminatingCondition(r, status.ConditionReasonDeleted, t)
+}
+
+func TestDeleteForcedAfterTimeout(t *testing.T) {
+	smcp := newControlPlane()
+	smcp.DeletionTimestamp = &now
+
+	cl, _, r := newReconcilerTestFixture(smcp)
+	fakeClock := clock.NewFakeClock(now.Time)
+	r.(*controlPlaneInstanceReconciler).clock = fakeClock
+	test.PanicOnError(cl.Create(ctx, newMemberNamespace("app", controlPlaneNamespace)))
+	test.PanicOnError(cl.Create(ctx, newTestPod("app", "injected", true)))
+
+	assertDeleteSucceeds(r, t) // this only initializes the SMCP status
+
+	ctx := hacks.WrapContext(ctx, map[types.NamespacedName]time.Time{}, fakeClock)
+	fakeClock.Step(time.Minute)
+	result, err := r.Delete(ctx)
+	assert.Success(err, "Delete", t)
+	assert.Equals(result.RequeueAfter, common.Config.Controller.DeletionBlockedTimeout-time.Minute, "Unexpected requeue delay", t)
+	assertDeletionBlockedCondition(r, status.ConditionStatusTrue, status.ConditionReasonWorkloadsPresent, t)
+
+	fakeClock.Step(common.Config.Controller.DeletionBlockedTimeout)
+	result, err = r.Delete(ctx)
+	assert.Success(err, "Delete", t)
+	assert.Equals(result.RequeueAfter, time.Duration(0), "Unexpected requeue delay", t)
+	assertDeletionBlockedCondition(r, status.ConditionStatusFalse, status.ConditionReasonDeletionForced, t)
+	assertTerminatingCondition(r, status.ConditionReasonDeleted, t)
+}
+
+func assertDeletionBlockedCondition(r ControlPlaneInstanceReconciler, conditionStatus status.ConditionStatus, reason status.ConditionReason, t *testing.T) {
+	t.Helper()
+	condition := r.(*controlPlaneInstanceReconciler).Status.GetCondition(status.ConditionTypeDeletionBlocked)
+	assert.Equals(condition.Status, conditionStatus, "Unexpected DeletionBlocked condition status", t)
+	assert.Equals(condition.Reason, reason, "Unexpected DeletionBlocked condition reason", t)
+}
+
+func assertTerminatingCondition(r ControlPlaneInstanceReconciler, reason status.ConditionReason, t *testing.T) {
+	t.Helper()
+	condition := r.(*controlPlaneInstanceReconciler).Status.GetCondition(status.ConditionTypeTerminating)
+	assert.Equals(condition.Status, status.ConditionStatusTrue, "Unexpected Terminating condition status", t)
+	assert.Equals(condition.Reason, reason, "Unexpected Terminating condition reason", t)
+}
+
+func assertComponentReconciledCondition(r ControlPlaneInstanceReconciler, component string, reason status.ConditionReason, t *testing.T) {
+	t.Helper()
+	componentStatus := r.(*controlPlaneInstanceReconciler).Status.FindComponentByName(component)
+	if componentStatus == nil {
+		t.Fatalf("Expected status for component %s", component)
+	}
+	condition := componentStatus.GetCondition(status.ConditionTypeReconciled)
+	assert.Equals(condition.Reason, reason, "Unexpected Reconciled condition reason for component "+component, t)
+}
+
 func TestGetEarliestSMCP(t *testing.T) {
 	// smcp1 is earliest by creationTimestamp, but last by name
 	smcp1 := newControlPlane()
@@ -782,7 +994,7 @@ func TestGetEarliestSMCP(t *testing.T) {
 }
 
 func assertDeleteSucceeds(r ControlPlaneInstanceReconciler, t *testing.T) {
-	err := r.Delete(hacks.WrapContext(ctx, map[types.NamespacedName]time.Time{}))
+	_, err := r.Delete(hacks.WrapContext(ctx, map[types.NamespacedName]time.Time{}, clock.RealClock{}))
 	assert.Success(err, "Delete", t)
 }
 
@@ -825,9 +1037,22 @@ func newReconcilerTestFixture(smcp *maistrav2.ServiceMeshControlPlane) (client.C
 	return cl, tracker, r
 }
 
+func newTestInstanceReconciler(cl client.Client, smcp *maistrav2.ServiceMeshControlPlane) *controlPlaneInstanceReconciler {
+	return NewControlPlaneInstanceReconciler(
+		common.ControllerResources{
+			Client:            cl,
+			Scheme:            scheme.Scheme,
+			EventRecorder:     &record.FakeRecorder{},
+			OperatorNamespace: "istio-operator",
+			DiscoveryClient:   &fake.FakeDiscovery{Fake: &clienttesting.Fake{}, FakedServerVersion: test.DefaultKubeVersion},
+		},
+		smcp,
+		cni.Config{Enabled: true}).(*controlPlaneInstanceReconciler)
+}
+
 func assertInstanceReconcilerSucceeds(r ControlPlaneInstanceReconciler, t *testing.T) {
 	t.Helper()
-	_, err := r.Reconcile(hacks.WrapContext(ctx, map[types.NamespacedName]time.Time{}))
+	_, err := r.Reconcile(hacks.WrapContext(ctx, map[types.NamespacedName]time.Time{}, clock.RealClock{}))
 	assert.Success(err, "Reconcile", t)
 }
 
@@ -0,0 +1,453 @@
+package controlplane
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapiv1 "k8s.io/client-go/tools/clientcmd/api/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/maistra/istio-operator/pkg/apis/maistra/status"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+)
+
+const (
+	// remoteSecretLabel identifies the secrets istiod uses to access remote clusters
+	remoteSecretLabel = "istio/multiCluster"
+
+	eventReasonRemoteSecretExpiring = "RemoteSecretExpiring"
+	eventReasonRemoteSecretRotated  = "RemoteSecretRotated"
+
+	serviceAccountSubjectPrefix = "system:serviceaccount:"
+
+	// rotatedTokenExpiration is the lifetime requested for tokens that replace
+	// expiring tokens in remote secrets
+	rotatedTokenExpiration = 90 * 24 * time.Hour
+
+	// remoteClusterRequestTimeout bounds requests to remote clusters, as the
+	// secrets are checked while the control plane is being reconciled
+	remoteClusterRequestTimeout = 10 * time.Second
+
+	// statusAnnotationRemoteSecretsCheckTime and statusAnnotationRemoteSecretsChecksum
+	// record when the remote cluster secrets were last checked and which
+	// versions of the secrets were checked
+	statusAnnotationRemoteSecretsCheckTime = "remoteSecretsCheckTime"
+	statusAnnotationRemoteSecretsChecksum  = "remoteSecretsChecksum"
+)
+
+func isRemoteSecret(obj metav1.Object) bool {
+	return obj != nil && obj.GetLabels()[remoteSecretLabel] == "true"
+}
+
+// tokenRequester obtains a new token for the service account using the TokenRequest API
+type tokenRequester func(ctx context.Context, config *rest.Config, namespace, name string, expiration time.Duration) (string, error)
+
+var requestServiceAccountToken tokenRequester = func(ctx context.Context, config *rest.Config, namespace, name string,
+	expiration time.Duration,
+) (string, error) {
+	cs, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return "", err
+	}
+	expirationSeconds := int64(expiration.Seconds())
+	tokenRequest, err := cs.CoreV1().ServiceAccounts(namespace).CreateToken(ctx, name, &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			ExpirationSeconds: &expirationSeconds,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return "", err
+	}
+	return tokenRequest.Status.Token, nil
+}
+
+// remoteCredential is a credential embedded in a kubeconfig stored in a remote secret
+type remoteCredential struct {
+	secretKey string
+	authInfo  string
+	expiry    time.Time
+	// isToken is true if the credential is a service account token
+	isToken bool
+}
+
+// updateRemoteSecretStatus checks the expiry of the credentials in the remote
+// cluster secrets in the control plane namespace and updates the
+// RemoteSecretExpiring condition accordingly.  If auto rotation is enabled,
+// expiring service account tokens are replaced with new ones.  The secrets are
+// only checked again once RemoteSecretCheckInterval has passed since the last
+// check, unless they changed.  Returns true if the status was updated.
+func (r *controlPlaneInstanceReconciler) updateRemoteSecretStatus(ctx context.Context) bool {
+	log := common.LogFromContext(ctx)
+
+	secrets, err := r.listRemoteSecrets(ctx)
+	if err != nil {
+		log.Error(err, "error listing remote cluster secrets")
+		return r.setRemoteSecretCondition(status.ConditionStatusUnknown, status.ConditionReasonRemoteSecretError,
+			fmt.Sprintf("Error listing remote cluster secrets: %s", err))
+	}
+	if len(secrets) == 0 {
+		if hasCondition(&r.Status.StatusType, status.ConditionTypeRemoteSecretExpiring) {
+			r.Status.RemoveCondition(status.ConditionTypeRemoteSecretExpiring)
+			r.Status.RemoveAnnotation(statusAnnotationRemoteSecretsCheckTime)
+			r.Status.RemoveAnnotation(statusAnnotationRemoteSecretsChecksum)
+			return true
+		}
+		return false
+	}
+
+	now := r.clock.Now()
+	checksum := remoteSecretsChecksum(secrets)
+	if !r.isRemoteSecretCheckDue(now, checksum) {
+		return false
+	}
+	r.Status.SetAnnotation(statusAnnotationRemoteSecretsCheckTime, now.UTC().Format(time.RFC3339))
+	r.Status.SetAnnotation(statusAnnotationRemoteSecretsChecksum, checksum)
+	r.checkRemoteSecrets(ctx, secrets, now)
+	return true
+}
+
+// isRemoteSecretCheckDue returns true if the remote cluster secrets changed or
+// haven't been checked within RemoteSecretCheckInterval
+func (r *controlPlaneInstanceReconciler) isRemoteSecretCheckDue(now time.Time, checksum string) bool {
+	if !hasCondition(&r.Status.StatusType, status.ConditionTypeRemoteSecretExpiring) ||
+		r.Status.GetAnnotation(statusAnnotationRemoteSecretsChecksum) != checksum {
+		return true
+	}
+	lastCheck, err := time.Parse(time.RFC3339, r.Status.GetAnnotation(statusAnnotationRemoteSecretsCheckTime))
+	if err != nil {
+		return true
+	}
+	return !now.Before(lastCheck.Add(common.Config.Controller.RemoteSecretCheckInterval))
+}
+
+// remoteSecretsChecksum returns a checksum of the names and versions of the secrets
+func remoteSecretsChecksum(secrets []*corev1.Secret) string {
+	versions := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		versions = append(versions, secret.Name+"/"+secret.ResourceVersion)
+	}
+	sort.Strings(versions)
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(versions, ","))))
+}
+
+// checkRemoteSecrets checks the credentials in the secrets and sets the
+// RemoteSecretExpiring condition
+func (r *controlPlaneInstanceReconciler) checkRemoteSecrets(ctx context.Context, secrets []*corev1.Secret, now time.Time) {
+	log := common.LogFromContext(ctx)
+	threshold := common.Config.Controller.RemoteSecretExpiryThreshold
+	var expiring, invalid, rotationErrors []string
+	for _, secret := range secrets {
+		credentials, err := getRemoteCredentials(secret)
+		if err != nil {
+			log.Error(err, "error parsing remote cluster secret", "secret", secret.Name)
+			invalid = append(invalid, secret.Name)
+			continue
+		}
+		if common.Config.Controller.RemoteSecretAutoRotation {
+			if credentials, err = r.rotateRemoteSecretTokens(ctx, secret, credentials, now, now.Add(threshold)); err != nil {
+				log.Error(err, "error rotating token in remote cluster secret", "secret", secret.Name)
+				rotationErrors = append(rotationErrors, fmt.Sprintf("%s: %s", secret.Name, err))
+			}
+		}
+		for _, credential := range credentials {
+			if credential.expiry.Before(now.Add(threshold)) {
+				expiring = append(expiring, fmt.Sprintf("%s (%s)", secret.Name, credential.expiry.UTC().Format(time.RFC3339)))
+			}
+		}
+	}
+
+	if len(invalid) > 0 {
+		r.setRemoteSecretCondition(status.ConditionStatusUnknown, status.ConditionReasonRemoteSecretError,
+			fmt.Sprintf("The following remote cluster secrets could not be parsed: %s", strings.Join(invalid, ", ")))
+	} else if len(expiring) > 0 {
+		sort.Strings(expiring)
+		message := fmt.Sprintf("The credentials in the following remote cluster secrets expire within %s: %s",
+			threshold, strings.Join(expiring, ", "))
+		if len(rotationErrors) > 0 {
+			sort.Strings(rotationErrors)
+			message = fmt.Sprintf("%s. The tokens could not be rotated: %s", message, strings.Join(rotationErrors, "; "))
+		}
+		if r.setRemoteSecretCondition(status.ConditionStatusTrue, status.ConditionReasonRemoteSecretExpiring, message) {
+			r.EventRecorder.Event(r.Instance, corev1.EventTypeWarning, eventReasonRemoteSecretExpiring, message)
+		}
+	} else {
+		r.setRemoteSecretCondition(status.ConditionStatusFalse, status.ConditionReasonRemoteSecretsValid,
+			"The credentials in all remote cluster secrets are valid")
+	}
+}
+
+// listRemoteSecrets returns the remote cluster secrets in the control plane
+// namespace.  They are read from the informer that caches only the remote
+// cluster secrets, as the manager's cache would hold all secrets in the
+// cluster.  An unstructured list is used, as SecretList is registered for
+// multiple group versions in the scheme (openshift image api), which prevents
+// listing typed secrets.
+func (r *controlPlaneInstanceReconciler) listRemoteSecrets(ctx context.Context) ([]*corev1.Secret, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("SecretList"))
+	if err := r.remoteSecretReader.List(ctx, list, client.InNamespace(r.Instance.Namespace), client.MatchingLabels{remoteSecretLabel: "true"}); err != nil {
+		return nil, err
+	}
+	secrets := make([]*corev1.Secret, 0, len(list.Items))
+	for index := range list.Items {
+		secret := &corev1.Secret{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(list.Items[index].Object, secret); err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, secret)
+	}
+	return secrets, nil
+}
+
+func (r *controlPlaneInstanceReconciler) setRemoteSecretCondition(conditionStatus status.ConditionStatus,
+	reason status.ConditionReason, message string,
+) bool {
+	condition := r.Status.GetCondition(status.ConditionTypeRemoteSecretExpiring)
+	if hasCondition(&r.Status.StatusType, status.ConditionTypeRemoteSecretExpiring) && condition.Matches(conditionStatus, reason, message) {
+		return false
+	}
+	r.Status.SetCondition(status.Condition{
+		Type:    status.ConditionTypeRemoteSecretExpiring,
+		Status:  conditionStatus,
+		Reason:  reason,
+		Message: message,
+	})
+	return true
+}
+
+// rotateRemoteSecretTokens replaces the service account tokens in the secret
+// that expire before the deadline and returns the updated credentials.  The
+// new token is requested with the expiring token, so the service account must
+// be allowed to create tokens for itself (serviceaccounts/token) in the remote
+// cluster, and tokens that have already expired cannot be rotated.
+func (r *controlPlaneInstanceReconciler) rotateRemoteSecretTokens(ctx context.Context, secret *corev1.Secret,
+	credentials []remoteCredential, now, deadline time.Time,
+) ([]remoteCredential, error) {
+	log := common.LogFromContext(ctx)
+	rotatedKeys := map[string]*clientcmdapiv1.Config{}
+	for index, credential := range credentials {
+		if !credential.isToken || credential.expiry.After(deadline) {
+			continue
+		}
+		if !credential.expiry.After(now) {
+			return credentials, fmt.Errorf("token of user %s has already expired and must be replaced manually", credential.authInfo)
+		}
+		kubeconfig, ok := rotatedKeys[credential.secretKey]
+		if !ok {
+			var err error
+			if kubeconfig, err = parseKubeconfig(secret.Data[credential.secretKey]); err != nil {
+				return credentials, err
+			}
+		}
+		authInfo := findAuthInfo(kubeconfig, credential.authInfo)
+		namespace, name, err := serviceAccountFromToken(authInfo.Token)
+		if err != nil {
+			return credentials, err
+		}
+		restConfig, err := restConfigForAuthInfo(secret.Data[credential.secretKey], credential.authInfo)
+		if err != nil {
+			return credentials, err
+		}
+		token, err := requestServiceAccountToken(ctx, restConfig, namespace, name, rotatedTokenExpiration)
+		if err != nil {
+			if apierrors.IsForbidden(err) {
+				return credentials, fmt.Errorf("service account %s/%s is not allowed to create tokens for itself: %s", namespace, name, err)
+			}
+			return credentials, err
+		}
+		expiry, err := tokenExpiry(token)
+		if err != nil {
+			return credentials, err
+		}
+		authInfo.Token = token
+		rotatedKeys[credential.secretKey] = kubeconfig
+		credentials[index].expiry = expiry
+	}
+	if len(rotatedKeys) == 0 {
+		return credentials, nil
+	}
+
+	updated := secret.DeepCopy()
+	for key, kubeconfig := range rotatedKeys {
+		data, err := yaml.Marshal(kubeconfig)
+		if err != nil {
+			return credentials, err
+		}
+		updated.Data[key] = data
+	}
+	if err := r.Client.Update(ctx, updated); err != nil {
+		return credentials, err
+	}
+	log.Info("rotated service account token in remote cluster secret", "secret", secret.Name)
+	r.EventRecorder.Event(r.Instance, corev1.EventTypeNormal, eventReasonRemoteSecretRotated,
+		fmt.Sprintf("Rotated service account token in remote cluster secret %s", secret.Name))
+	return credentials, nil
+}
+
+// getRemoteCredentials returns the credentials that expire from all kubeconfigs in the secret
+func getRemoteCredentials(secret *corev1.Secret) ([]remoteCredential, error) {
+	var credentials []remoteCredential
+	for key, data := range secret.Data {
+		kubeconfig, err := parseKubeconfig(data)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse kubeconfig in key %s: %s", key, err)
+		}
+		for _, namedAuthInfo := range kubeconfig.AuthInfos {
+			name, authInfo := namedAuthInfo.Name, namedAuthInfo.AuthInfo
+			if authInfo.Token != "" {
+				if expiry, err := tokenExpiry(authInfo.Token); err != nil {
+					return nil, fmt.Errorf("could not parse token of user %s in key %s: %s", name, key, err)
+				} else if !expiry.IsZero() {
+					credentials = append(credentials, remoteCredential{secretKey: key, authInfo: name, expiry: expiry, isToken: true})
+				}
+			}
+			if len(authInfo.ClientCertificateData) > 0 {
+				expiry, err := certificateExpiry(authInfo.ClientCertificateData)
+				if err != nil {
+					return nil, fmt.Errorf("could not parse client certificate of user %s in key %s: %s", name, key, err)
+				}
+				credentials = append(credentials, remoteCredential{secretKey: key, authInfo: name, expiry: expiry})
+			}
+		}
+	}
+	return credentials, nil
+}
+
+func parseKubeconfig(data []byte) (*clientcmdapiv1.Config, error) {
+	kubeconfig := &clientcmdapiv1.Config{}
+	if err := yaml.Unmarshal(data, kubeconfig); err != nil {
+		return nil, err
+	}
+	return kubeconfig, nil
+}
+
+func findAuthInfo(kubeconfig *clientcmdapiv1.Config, name string) *clientcmdapiv1.AuthInfo {
+	for index := range kubeconfig.AuthInfos {
+		if kubeconfig.AuthInfos[index].Name == name {
+			return &kubeconfig.AuthInfos[index].AuthInfo
+		}
+	}
+	return nil
+}
+
+type tokenClaims struct {
+	Expiry  int64  `json:"exp,omitempty"`
+	Subject string `json:"sub,omitempty"`
+}
+
+func parseTokenClaims(token string) (*tokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("token is not a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, err
+	}
+	claims := &tokenClaims{}
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// tokenExpiry returns the expiry of the token or the zero time if the token
+// does not expire (e.g. legacy service account tokens)
+func tokenExpiry(token string) (time.Time, error) {
+	claims, err := parseTokenClaims(token)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if claims.Expiry == 0 {
+		return time.Time{}, nil
+	}
+	return time.Unix(claims.Expiry, 0), nil
+}
+
+func serviceAccountFromToken(token string) (namespace, name string, err error) {
+	claims, err := parseTokenClaims(token)
+	if err != nil {
+		return "", "", err
+	}
+	parts := strings.Split(strings.TrimPrefix(claims.Subject, serviceAccountSubjectPrefix), ":")
+	if !strings.HasPrefix(claims.Subject, serviceAccountSubjectPrefix) || len(parts) != 2 {
+		return "", "", fmt.Errorf("token subject %q is not a service account", claims.Subject)
+	}
+	return parts[0], parts[1], nil
+}
+
+func certificateExpiry(data []byte) (time.Time, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return time.Time{}, fmt.Errorf("no PEM data found")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return cert.NotAfter, nil
+}
+
+// restConfigForAuthInfo returns a rest.Config for the first context in the
+// kubeconfig that uses the specified user
+func restConfigForAuthInfo(data []byte, authInfo string) (*rest.Config, error) {
+	kubeconfig, err := clientcmd.Load(data)
+	if err != nil {
+		return nil, err
+	}
+	contextName := ""
+	if current, ok := kubeconfig.Contexts[kubeconfig.CurrentContext]; ok && current.AuthInfo == authInfo {
+		contextName = kubeconfig.CurrentContext
+	} else {
+		names := make([]string, 0, len(kubeconfig.Contexts))
+		for name := range kubeconfig.Contexts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if kubeconfig.Contexts[name].AuthInfo == authInfo {
+				contextName = name
+				break
+			}
+		}
+	}
+	if contextName == "" {
+		return nil, fmt.Errorf("no context found for user %s", authInfo)
+	}
+	restConfig, err := clientcmd.NewNonInteractiveClientConfig(*kubeconfig, contextName, &clientcmd.ConfigOverrides{}, nil).ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	restConfig.Timeout = remoteClusterRequestTimeout
+	return restConfig, nil
+}
+
+func hasCondition(s *status.StatusType, conditionType status.ConditionType) bool {
+	if s == nil {
+		return false
+	}
+	for _, condition := range s.Conditions {
+		if condition.Type == conditionType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,297 @@
+package controlplane
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/rest"
+	clientcmdapiv1 "k8s.io/client-go/tools/clientcmd/api/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/maistra/istio-operator/pkg/apis/maistra/status"
+	maistrav2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/common/test"
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+)
+
+func TestUpdateRemoteSecretStatus(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		name              string
+		objects           []runtime.Object
+		autoRotation      bool
+		rotationErr       error
+		expectedCondition *status.Condition
+		expectedMessage   string
+	}{
+		{
+			name:              "no-remote-secrets",
+			expectedCondition: nil,
+		},
+		{
+			name: "valid-token",
+			objects: []runtime.Object{
+				newRemoteSecret("istio-remote-secret-cluster2", newTestToken(now.Add(30*24*time.Hour))),
+			},
+			expectedCondition: &status.Condition{
+				Status: status.ConditionStatusFalse,
+				Reason: status.ConditionReasonRemoteSecretsValid,
+			},
+		},
+		{
+			name: "legacy-token-without-expiry",
+			objects: []runtime.Object{
+				newRemoteSecret("istio-remote-secret-cluster2", newTestToken(time.Time{})),
+			},
+			expectedCondition: &status.Condition{
+				Status: status.ConditionStatusFalse,
+				Reason: status.ConditionReasonRemoteSecretsValid,
+			},
+		},
+		{
+			name: "expiring-token",
+			objects: []runtime.Object{
+				newRemoteSecret("istio-remote-secret-cluster2", newTestToken(now.Add(time.Hour))),
+			},
+			expectedCondition: &status.Condition{
+				Status: status.ConditionStatusTrue,
+				Reason: status.ConditionReasonRemoteSecretExpiring,
+			},
+		},
+		{
+			name: "expired-token",
+			objects: []runtime.Object{
+				newRemoteSecret("istio-remote-secret-cluster2", newTestToken(now.Add(-time.Hour))),
+			},
+			expectedCondition: &status.Condition{
+				Status: status.ConditionStatusTrue,
+				Reason: status.ConditionReasonRemoteSecretExpiring,
+			},
+		},
+		{
+			name: "expiring-token-rotated",
+			objects: []runtime.Object{
+				newRemoteSecret("istio-remote-secret-cluster2", newTestToken(now.Add(time.Hour))),
+			},
+			autoRotation: true,
+			expectedCondition: &status.Condition{
+				Status: status.ConditionStatusFalse,
+				Reason: status.ConditionReasonRemoteSecretsValid,
+			},
+		},
+		{
+			name: "expired-token-not-rotated",
+			objects: []runtime.Object{
+				newRemoteSecret("istio-remote-secret-cluster2", newTestToken(now.Add(-time.Hour))),
+			},
+			autoRotation: true,
+			expectedCondition: &status.Condition{
+				Status: status.ConditionStatusTrue,
+				Reason: status.ConditionReasonRemoteSecretExpiring,
+			},
+			expectedMessage: "has already expired",
+		},
+		{
+			name: "rotation-forbidden",
+			objects: []runtime.Object{
+				newRemoteSecret("istio-remote-secret-cluster2", newTestToken(now.Add(time.Hour))),
+			},
+			autoRotation: true,
+			rotationErr: apierrors.NewForbidden(schema.GroupResource{Resource: "serviceaccounts/token"},
+				"istio-reader-service-account", fmt.Errorf("not allowed")),
+			expectedCondition: &status.Condition{
+				Status: status.ConditionStatusTrue,
+				Reason: status.ConditionReasonRemoteSecretExpiring,
+			},
+			expectedMessage: "is not allowed to create tokens for itself",
+		},
+		{
+			name: "invalid-kubeconfig",
+			objects: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "istio-remote-secret-cluster2",
+						Namespace: controlPlaneNamespace,
+						Labels:    map[string]string{remoteSecretLabel: "true"},
+					},
+					Data: map[string][]byte{"cluster2": []byte("not a kubeconfig")},
+				},
+			},
+			expectedCondition: &status.Condition{
+				Status: status.ConditionStatusUnknown,
+				Reason: status.ConditionReasonRemoteSecretError,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func(autoRotation bool) {
+				common.Config.Controller.RemoteSecretAutoRotation = autoRotation
+			}(common.Config.Controller.RemoteSecretAutoRotation)
+			common.Config.Controller.RemoteSecretAutoRotation = tc.autoRotation
+
+			defer func(requester tokenRequester) {
+				requestServiceAccountToken = requester
+			}(requestServiceAccountToken)
+			var requestedServiceAccount string
+			requestServiceAccountToken = func(_ context.Context, _ *rest.Config, namespace, name string, expiration time.Duration) (string, error) {
+				requestedServiceAccount = namespace + "/" + name
+				if tc.rotationErr != nil {
+					return "", tc.rotationErr
+				}
+				return newTestToken(time.Now().Add(expiration)), nil
+			}
+
+			smcp := &maistrav2.ServiceMeshControlPlane{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: controlPlaneNamespace,
+				},
+			}
+			cl, _ := test.CreateClient(tc.objects...)
+			instanceReconciler := newTestInstanceReconciler(cl, smcp)
+
+			updated := instanceReconciler.updateRemoteSecretStatus(ctx)
+			assert.Equals(updated, tc.expectedCondition != nil, "unexpected return value from updateRemoteSecretStatus", t)
+
+			if tc.expectedCondition == nil {
+				if hasCondition(&instanceReconciler.Status.StatusType, status.ConditionTypeRemoteSecretExpiring) {
+					t.Fatalf("expected no %s condition", status.ConditionTypeRemoteSecretExpiring)
+				}
+				return
+			}
+			condition := instanceReconciler.Status.GetCondition(status.ConditionTypeRemoteSecretExpiring)
+			assert.Equals(condition.Status, tc.expectedCondition.Status, "unexpected condition status: "+condition.Message, t)
+			assert.Equals(condition.Reason, tc.expectedCondition.Reason, "unexpected condition reason", t)
+			if !strings.Contains(condition.Message, tc.expectedMessage) {
+				t.Errorf("expected condition message to contain %q, but got %q", tc.expectedMessage, condition.Message)
+			}
+
+			assert.False(instanceReconciler.updateRemoteSecretStatus(ctx), "expected no status update on second check", t)
+
+			if tc.autoRotation && tc.expectedCondition.Status == status.ConditionStatusFalse {
+				assert.Equals(requestedServiceAccount, "istio-system/istio-reader-service-account", "unexpected service account", t)
+				secret := &corev1.Secret{}
+				test.PanicOnError(cl.Get(ctx, client.ObjectKey{Name: "istio-remote-secret-cluster2", Namespace: controlPlaneNamespace}, secret))
+				credentials, err := getRemoteCredentials(secret)
+				if err != nil {
+					t.Fatalf("unexpected error parsing rotated secret: %v", err)
+				}
+				for _, credential := range credentials {
+					if credential.expiry.Before(now.Add(common.Config.Controller.RemoteSecretExpiryThreshold)) {
+						t.Errorf("expected token in secret to be rotated, but it expires at %s", credential.expiry)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRemoteSecretsAreOnlyCheckedWhenDue(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	secret := newRemoteSecret("istio-remote-secret-cluster2", newTestToken(fakeClock.Now().Add(30*24*time.Hour)))
+	cl, _ := test.CreateClient(secret)
+	smcp := &maistrav2.ServiceMeshControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: controlPlaneNamespace,
+		},
+	}
+	instanceReconciler := newTestInstanceReconciler(cl, smcp)
+	instanceReconciler.clock = fakeClock
+
+	assert.True(instanceReconciler.updateRemoteSecretStatus(ctx), "expected secrets to be checked initially", t)
+	assert.False(instanceReconciler.updateRemoteSecretStatus(ctx), "expected no check before the check interval passed", t)
+
+	fakeClock.Step(common.Config.Controller.RemoteSecretCheckInterval)
+	assert.True(instanceReconciler.updateRemoteSecretStatus(ctx), "expected secrets to be checked after the check interval", t)
+	assert.False(instanceReconciler.updateRemoteSecretStatus(ctx), "expected no check before the check interval passed", t)
+
+	test.PanicOnError(cl.Get(ctx, client.ObjectKey{Name: secret.Name, Namespace: secret.Namespace}, secret))
+	secret.Data["cluster2"] = newRemoteSecret(secret.Name, newTestToken(fakeClock.Now().Add(time.Hour))).Data["cluster2"]
+	// the fake client doesn't update the resource version
+	secret.ResourceVersion = "2"
+	test.PanicOnError(cl.Update(ctx, secret))
+	assert.True(instanceReconciler.updateRemoteSecretStatus(ctx), "expected changed secrets to be checked immediately", t)
+	condition := instanceReconciler.Status.GetCondition(status.ConditionTypeRemoteSecretExpiring)
+	assert.Equals(condition.Status, status.ConditionStatusTrue, "expected the changed secret to be reported as expiring", t)
+}
+
+func TestRemoteSecretsAreReadFromRemoteSecretReader(t *testing.T) {
+	cl, _ := test.CreateClient()
+	cachedClient, _ := test.CreateClient(newRemoteSecret("istio-remote-secret-cluster2", newTestToken(time.Now().Add(time.Hour))))
+	smcp := &maistrav2.ServiceMeshControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: controlPlaneNamespace,
+		},
+	}
+	instanceReconciler := newTestInstanceReconciler(cl, smcp)
+	instanceReconciler.remoteSecretReader = cachedClient
+
+	assert.True(instanceReconciler.updateRemoteSecretStatus(ctx), "expected the cached secret to be checked", t)
+	condition := instanceReconciler.Status.GetCondition(status.ConditionTypeRemoteSecretExpiring)
+	assert.Equals(condition.Status, status.ConditionStatusTrue, "expected the cached secret to be reported as expiring", t)
+}
+
+func TestRestConfigForAuthInfoHasTimeout(t *testing.T) {
+	secret := newRemoteSecret("istio-remote-secret-cluster2", newTestToken(time.Now().Add(time.Hour)))
+	restConfig, err := restConfigForAuthInfo(secret.Data["cluster2"], "cluster2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.Equals(restConfig.Host, "https://cluster2.example.com:6443", "unexpected host", t)
+	assert.Equals(restConfig.Timeout, remoteClusterRequestTimeout, "expected requests to remote cluster to time out", t)
+}
+
+func newRemoteSecret(name, token string) *corev1.Secret {
+	kubeconfig := &clientcmdapiv1.Config{
+		Clusters: []clientcmdapiv1.NamedCluster{
+			{Name: "cluster2", Cluster: clientcmdapiv1.Cluster{Server: "https://cluster2.example.com:6443"}},
+		},
+		AuthInfos: []clientcmdapiv1.NamedAuthInfo{
+			{Name: "cluster2", AuthInfo: clientcmdapiv1.AuthInfo{Token: token}},
+		},
+		Contexts: []clientcmdapiv1.NamedContext{
+			{Name: "cluster2", Context: clientcmdapiv1.Context{Cluster: "cluster2", AuthInfo: "cluster2"}},
+		},
+		CurrentContext: "cluster2",
+	}
+	data, err := yaml.Marshal(kubeconfig)
+	test.PanicOnError(err)
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: controlPlaneNamespace,
+			Labels:    map[string]string{remoteSecretLabel: "true"},
+		},
+		Data: map[string][]byte{"cluster2": data},
+	}
+}
+
+func newTestToken(expiry time.Time) string {
+	claims := map[string]interface{}{
+		"sub": "system:serviceaccount:istio-system:istio-reader-service-account",
+	}
+	if !expiry.IsZero() {
+		claims["exp"] = expiry.Unix()
+	}
+	payload, err := json.Marshal(claims)
+	test.PanicOnError(err)
+	encode := base64.RawURLEncoding.EncodeToString
+	return fmt.Sprintf("%s.%s.%s", encode([]byte(`{"alg":"RS256"}`)), encode(payload), encode([]byte("signature")))
+}
@@ -0,0 +1,98 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/maistra/istio-operator/pkg/apis/maistra/status"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+)
+
+const eventReasonBackoff = "Backoff"
+
+// retryBudget limits how often the reconciliation of a single control plane
+// may fail, so that a control plane that fails repeatedly doesn't monopolize
+// the reconcilers.  Once the budget is exhausted, reconciliation of the control
+// plane is suspended until the backoff expires or its spec changes.
+type retryBudget struct {
+	failures     int
+	windowStart  time.Time
+	backoffUntil time.Time
+}
+
+// recordFailure counts a failed reconciliation and returns true if this
+// exhausts the budget, in which case the backoff starts
+func (b *retryBudget) recordFailure(now time.Time) bool {
+	budget := common.Config.Controller.RetryBudget
+	if budget <= 0 {
+		return false
+	}
+	if b.windowStart.IsZero() || now.Sub(b.windowStart) > common.Config.Controller.RetryBudgetWindow {
+		b.windowStart = now
+		b.failures = 0
+	}
+	b.failures++
+	if b.failures < budget {
+		return false
+	}
+	b.failures = 0
+	b.windowStart = time.Time{}
+	b.backoffUntil = now.Add(common.Config.Controller.RetryBackoff)
+	return true
+}
+
+// remainingBackoff returns how long reconciliation remains suspended
+func (b *retryBudget) remainingBackoff(now time.Time) time.Duration {
+	if now.Before(b.backoffUntil) {
+		return b.backoffUntil.Sub(now)
+	}
+	return 0
+}
+
+func (b *retryBudget) reset() {
+	*b = retryBudget{}
+}
+
+// updateRetryBudget records the outcome of a reconciliation in the retry budget
+// of the control plane.  If the failure exhausted the budget, the Backoff
+// condition is set and the error is replaced by a requeue after the backoff, so
+// the control plane is no longer retried at the rate of the work queue.
+func (r *controlPlaneInstanceReconciler) updateRetryBudget(ctx context.Context, result reconcile.Result, err error) (reconcile.Result, error) {
+	log := common.LogFromContext(ctx)
+
+	if err == nil {
+		r.retryBudget.reset()
+		if hasCondition(&r.Status.StatusType, status.ConditionTypeBackoff) {
+			r.Status.RemoveCondition(status.ConditionTypeBackoff)
+			if statusErr := r.PostStatus(ctx); statusErr != nil {
+				return result, statusErr
+			}
+		}
+		return result, nil
+	}
+
+	now := r.clock.Now()
+	if !r.retryBudget.recordFailure(now) {
+		return result, err
+	}
+
+	backoff := r.retryBudget.remainingBackoff(now)
+	message := fmt.Sprintf("Reconciliation failed %d times within %s, retrying in %s: %s", common.Config.Controller.RetryBudget,
+		common.Config.Controller.RetryBudgetWindow, backoff, err)
+	log.Info("Retry budget exhausted, backing off", "backoff", backoff)
+	r.Status.SetCondition(status.Condition{
+		Type:    status.ConditionTypeBackoff,
+		Status:  status.ConditionStatusTrue,
+		Reason:  status.ConditionReasonRetryBudgetExhausted,
+		Message: message,
+	})
+	r.EventRecorder.Event(r.Instance, corev1.EventTypeWarning, eventReasonBackoff, message)
+	if statusErr := r.PostStatus(ctx); statusErr != nil {
+		log.Error(statusErr, "Error posting Backoff condition")
+	}
+	return common.RequeueAfter(backoff)
+}
@@ -0,0 +1,108 @@
+package controlplane
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/maistra/istio-operator/pkg/apis/maistra/status"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/common/test"
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+	"github.com/maistra/istio-operator/pkg/controller/hacks"
+)
+
+func TestRetryBudget(t *testing.T) {
+	defer func(budget int, window, backoff time.Duration) {
+		common.Config.Controller.RetryBudget = budget
+		common.Config.Controller.RetryBudgetWindow = window
+		common.Config.Controller.RetryBackoff = backoff
+	}(common.Config.Controller.RetryBudget, common.Config.Controller.RetryBudgetWindow, common.Config.Controller.RetryBackoff)
+	common.Config.Controller.RetryBudget = 3
+	common.Config.Controller.RetryBudgetWindow = 10 * time.Minute
+	common.Config.Controller.RetryBackoff = 5 * time.Minute
+
+	now := time.Now()
+	budget := &retryBudget{}
+	assert.False(budget.recordFailure(now), "Expected budget not to be exhausted after first failure", t)
+	assert.False(budget.recordFailure(now.Add(time.Minute)), "Expected budget not to be exhausted after second failure", t)
+	// the window expired, so this is the first failure of a new window
+	assert.False(budget.recordFailure(now.Add(15*time.Minute)), "Expected failures outside the window to be forgotten", t)
+	assert.False(budget.recordFailure(now.Add(16*time.Minute)), "Expected budget not to be exhausted after second failure", t)
+	assert.Equals(budget.remainingBackoff(now.Add(16*time.Minute)), time.Duration(0), "Unexpected backoff", t)
+	assert.True(budget.recordFailure(now.Add(17*time.Minute)), "Expected budget to be exhausted after third failure", t)
+	assert.Equals(budget.remainingBackoff(now.Add(18*time.Minute)), 4*time.Minute, "Unexpected backoff", t)
+	assert.Equals(budget.remainingBackoff(now.Add(22*time.Minute)), time.Duration(0), "Expected backoff to expire", t)
+
+	budget.reset()
+	common.Config.Controller.RetryBudget = 0
+	for i := 0; i < 10; i++ {
+		assert.False(budget.recordFailure(now), "Expected disabled budget never to be exhausted", t)
+	}
+}
+
+func TestUpdateRetryBudgetSetsBackoffCondition(t *testing.T) {
+	defer func(budget int, backoff time.Duration) {
+		common.Config.Controller.RetryBudget = budget
+		common.Config.Controller.RetryBackoff = backoff
+	}(common.Config.Controller.RetryBudget, common.Config.Controller.RetryBackoff)
+	common.Config.Controller.RetryBudget = 2
+	common.Config.Controller.RetryBackoff = 5 * time.Minute
+
+	smcp := newControlPlane()
+	cl, _ := test.CreateClient(smcp)
+	eventRecorder := record.NewFakeRecorder(10)
+	fakeClock := clock.NewFakeClock(time.Now())
+	r := newTestInstanceReconciler(cl, smcp)
+	r.EventRecorder = eventRecorder
+	r.clock = fakeClock
+
+	reconcileErr := fmt.Errorf("install failed")
+	_, err := r.updateRetryBudget(ctx, reconcile.Result{}, reconcileErr)
+	assert.Equals(err, reconcileErr, "Expected error to be returned while the budget isn't exhausted", t)
+	assert.False(hasCondition(&r.Status.StatusType, status.ConditionTypeBackoff), "Unexpected Backoff condition", t)
+
+	result, err := r.updateRetryBudget(ctx, reconcile.Result{}, reconcileErr)
+	assert.Nil(err, "Expected error to be replaced by backoff", t)
+	assert.Equals(result.RequeueAfter, 5*time.Minute, "Unexpected requeue interval", t)
+	condition := r.Status.GetCondition(status.ConditionTypeBackoff)
+	assert.Equals(condition.Status, status.ConditionStatusTrue, "Unexpected Backoff condition status", t)
+	assert.Equals(condition.Reason, status.ConditionReasonRetryBudgetExhausted, "Unexpected Backoff condition reason", t)
+	assert.Equals(len(eventRecorder.Events), 1, "Expected warning event", t)
+
+	// reconciliation is skipped while backing off
+	fakeClock.Step(2 * time.Minute)
+	result, err = r.Reconcile(hacks.WrapContext(ctx, map[types.NamespacedName]time.Time{}, fakeClock))
+	assert.Nil(err, "Unexpected error while backing off", t)
+	assert.Equals(result.RequeueAfter, 3*time.Minute, "Expected requeue after the remaining backoff", t)
+
+	_, err = r.updateRetryBudget(ctx, reconcile.Result{}, nil)
+	assert.Nil(err, "Unexpected error", t)
+	assert.False(hasCondition(&r.Status.StatusType, status.ConditionTypeBackoff), "Expected Backoff condition to be removed", t)
+}
+
+func TestRecordRequeue(t *testing.T) {
+	key := types.NamespacedName{Namespace: "requeue-ns", Name: "requeue-smcp"}
+	defer forgetRequeues(key)
+
+	recordRequeue(key, reconcile.Result{}, nil)
+	recordRequeue(key, reconcile.Result{RequeueAfter: time.Minute}, nil)
+	recordRequeue(key, reconcile.Result{Requeue: true, RequeueAfter: time.Second}, nil)
+	recordRequeue(key, reconcile.Result{}, fmt.Errorf("error"))
+	recordRequeue(key, reconcile.Result{}, fmt.Errorf("error"))
+
+	assert.Equals(testutil.ToFloat64(requeuesTotal.WithLabelValues(key.Namespace, key.Name, requeueReasonRequeue)), 1.0,
+		"Unexpected requeue count", t)
+	assert.Equals(testutil.ToFloat64(requeuesTotal.WithLabelValues(key.Namespace, key.Name, requeueReasonError)), 2.0,
+		"Unexpected error count", t)
+
+	forgetRequeues(key)
+	assert.Equals(testutil.ToFloat64(requeuesTotal.WithLabelValues(key.Namespace, key.Name, requeueReasonError)), 0.0,
+		"Expected counts to be removed", t)
+}
@@ -0,0 +1,205 @@
+package controlplane
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clienttesting "k8s.io/client-go/testing"
+
+	v2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	. "github.com/maistra/istio-operator/pkg/controller/common/test"
+	"github.com/maistra/istio-operator/pkg/controller/versions"
+)
+
+func TestPilotScheduling(t *testing.T) {
+	nodeSelector := map[string]string{"node-role.kubernetes.io/infra": ""}
+	tolerations := []corev1.Toleration{
+		{
+			Key:      "node-role.kubernetes.io/infra",
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoSchedule,
+		},
+	}
+	nodeAffinity := &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{
+				{
+					MatchExpressions: []corev1.NodeSelectorRequirement{
+						{
+							Key:      "topology.kubernetes.io/zone",
+							Operator: corev1.NodeSelectorOpIn,
+							Values:   []string{"zone-a"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	testCases := []IntegrationTestCase{
+		{
+			name: "pilot." + versions.V2_4.String(),
+			smcp: NewV2xSMCPResource(controlPlaneName, controlPlaneNamespace, &v2.ControlPlaneSpec{
+				Runtime: &v2.ControlPlaneRuntimeConfig{
+					Components: map[v2.ControlPlaneComponentName]*v2.ComponentRuntimeConfig{
+						v2.ControlPlaneComponentNamePilot: {
+							Pod: &v2.PodRuntimeConfig{
+								CommonPodRuntimeConfig: v2.CommonPodRuntimeConfig{
+									NodeSelector: nodeSelector,
+									Tolerations:  tolerations,
+								},
+								Affinity: &v2.Affinity{
+									NodeAffinity: nodeAffinity,
+								},
+							},
+						},
+					},
+				},
+			}, versions.V2_4.String()),
+			create: IntegrationTestValidation{
+				Verifier: Verify("create").On("deployments").Named("istiod-" + controlPlaneName).In(controlPlaneNamespace).Passes(
+					ExpectedPodScheduling(nodeSelector, tolerations, nodeAffinity),
+				),
+			},
+		},
+	}
+	RunSimpleInstallTests(t, testCases)
+}
+
+func ExpectedPodScheduling(nodeSelector map[string]string, tolerations []corev1.Toleration,
+	nodeAffinity *corev1.NodeAffinity,
+) func(action clienttesting.Action) error {
+	return func(action clienttesting.Action) error {
+		createAction := action.(clienttesting.CreateAction)
+		deployment := &appsv1.Deployment{}
+		obj := createAction.GetObject().(*unstructured.Unstructured)
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), deployment); err != nil {
+			return err
+		}
+		podSpec := deployment.Spec.Template.Spec
+		if !reflect.DeepEqual(podSpec.NodeSelector, nodeSelector) {
+			return fmt.Errorf("expected nodeSelector %v, got %v", nodeSelector, podSpec.NodeSelector)
+		}
+		if !reflect.DeepEqual(podSpec.Tolerations, tolerations) {
+			return fmt.Errorf("expected tolerations %v, got %v", tolerations, podSpec.Tolerations)
+		}
+		if podSpec.Affinity == nil || !reflect.DeepEqual(podSpec.Affinity.NodeAffinity, nodeAffinity) {
+			return fmt.Errorf("expected nodeAffinity %v, got %v", nodeAffinity, podSpec.Affinity)
+		}
+		return nil
+	}
+}
+
+func TestControlPlaneDefaults(t *testing.T) {
+	defaults := common.Config.ControlPlaneDefaults
+	defer func() {
+		common.Config.ControlPlaneDefaults = defaults
+	}()
+	common.Config.ControlPlaneDefaults.PilotResources = `{"requests": {"cpu": "500m", "memory": "2Gi"}}`
+	common.Config.ControlPlaneDefaults.PriorityClassName = "system-cluster-critical"
+	common.Config.ControlPlaneDefaults.TopologySpreadConstraints = `
+- maxSkew: 1
+  topologyKey: topology.kubernetes.io/zone
+  whenUnsatisfiable: ScheduleAnyway
+  labelSelector:
+    matchLabels:
+      app: istiod
+`
+	if err := common.Config.ControlPlaneDefaults.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	topologySpreadConstraints := []corev1.TopologySpreadConstraint{
+		{
+			MaxSkew:           1,
+			TopologyKey:       "topology.kubernetes.io/zone",
+			WhenUnsatisfiable: corev1.ScheduleAnyway,
+			LabelSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "istiod"}},
+		},
+	}
+
+	testCases := []IntegrationTestCase{
+		{
+			name: "defaults." + versions.V2_4.String(),
+			smcp: NewV2xSMCPResource(controlPlaneName, controlPlaneNamespace, &v2.ControlPlaneSpec{}, versions.V2_4.String()),
+			create: IntegrationTestValidation{
+				Verifier: Verify("create").On("deployments").Named("istiod-" + controlPlaneName).In(controlPlaneNamespace).Passes(
+					ExpectedPodDefaults(corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("500m"),
+						corev1.ResourceMemory: resource.MustParse("2Gi"),
+					}, "system-cluster-critical", topologySpreadConstraints),
+				),
+			},
+		},
+		{
+			name: "user-values." + versions.V2_4.String(),
+			smcp: NewV2xSMCPResource(controlPlaneName, controlPlaneNamespace, &v2.ControlPlaneSpec{
+				Runtime: &v2.ControlPlaneRuntimeConfig{
+					Components: map[v2.ControlPlaneComponentName]*v2.ComponentRuntimeConfig{
+						v2.ControlPlaneComponentNamePilot: {
+							Container: &v2.ContainerConfig{
+								CommonContainerConfig: v2.CommonContainerConfig{
+									Resources: &corev1.ResourceRequirements{
+										Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+									},
+								},
+							},
+						},
+					},
+					Defaults: &v2.DefaultRuntimeConfig{
+						Pod: &v2.CommonPodRuntimeConfig{
+							PriorityClassName: "tenant-critical",
+						},
+					},
+				},
+			}, versions.V2_4.String()),
+			create: IntegrationTestValidation{
+				Verifier: Verify("create").On("deployments").Named("istiod-" + controlPlaneName).In(controlPlaneNamespace).Passes(
+					ExpectedPodDefaults(corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("1"),
+						corev1.ResourceMemory: resource.MustParse("2Gi"),
+					}, "tenant-critical", topologySpreadConstraints),
+				),
+			},
+		},
+	}
+	RunSimpleInstallTests(t, testCases)
+}
+
+func ExpectedPodDefaults(requests corev1.ResourceList, priorityClassName string,
+	topologySpreadConstraints []corev1.TopologySpreadConstraint,
+) func(action clienttesting.Action) error {
+	return func(action clienttesting.Action) error {
+		createAction := action.(clienttesting.CreateAction)
+		deployment := &appsv1.Deployment{}
+		obj := createAction.GetObject().(*unstructured.Unstructured)
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), deployment); err != nil {
+			return err
+		}
+		podSpec := deployment.Spec.Template.Spec
+		for _, container := range podSpec.Containers {
+			if container.Name != "discovery" {
+				continue
+			}
+			for name, quantity := range requests {
+				if actual := container.Resources.Requests[name]; actual.Cmp(quantity) != 0 {
+					return fmt.Errorf("expected %s request %s, got %s", name, quantity.String(), actual.String())
+				}
+			}
+		}
+		if podSpec.PriorityClassName != priorityClassName {
+			return fmt.Errorf("expected priorityClassName %s, got %s", priorityClassName, podSpec.PriorityClassName)
+		}
+		if !reflect.DeepEqual(podSpec.TopologySpreadConstraints, topologySpreadConstraints) {
+			return fmt.Errorf("expected topologySpreadConstraints %v, got %v", topologySpreadConstraints, podSpec.TopologySpreadConstraints)
+		}
+		return nil
+	}
+}
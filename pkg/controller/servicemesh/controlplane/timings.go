@@ -0,0 +1,59 @@
+package controlplane
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// statusAnnotationReconcileTimings records how long each phase of the last
+// completed reconciliation took
+const statusAnnotationReconcileTimings = "reconcileTimings"
+
+// reconcileTimings accumulates the time spent in each phase of a
+// reconciliation. A reconciliation may span several invocations of Reconcile
+// while the operator waits for components to become ready, so the total also
+// includes the time spent waiting.
+type reconcileTimings struct {
+	start     time.Time
+	phases    []string
+	durations map[string]time.Duration
+}
+
+func newReconcileTimings() *reconcileTimings {
+	return &reconcileTimings{
+		start:     time.Now(),
+		durations: map[string]time.Duration{},
+	}
+}
+
+// track adds the time elapsed since start to the phase, e.g.
+// defer r.timings.track("prune", time.Now())
+func (t *reconcileTimings) track(phase string, start time.Time) {
+	t.add(phase, time.Since(start))
+}
+
+func (t *reconcileTimings) add(phase string, duration time.Duration) {
+	if t == nil {
+		return
+	}
+	if _, ok := t.durations[phase]; !ok {
+		t.phases = append(t.phases, phase)
+	}
+	t.durations[phase] += duration
+}
+
+// String returns the phases in the order they were first tracked, followed by
+// the total time since the reconciliation started, e.g.
+// "render=1.2s,apply/istio-discovery=350ms,prune=80ms,total=45.3s"
+func (t *reconcileTimings) String() string {
+	if t == nil {
+		return ""
+	}
+	entries := make([]string, 0, len(t.phases)+1)
+	for _, phase := range t.phases {
+		entries = append(entries, fmt.Sprintf("%s=%s", phase, t.durations[phase].Round(time.Millisecond)))
+	}
+	entries = append(entries, fmt.Sprintf("total=%s", time.Since(t.start).Round(time.Millisecond)))
+	return strings.Join(entries, ",")
+}
@@ -0,0 +1,29 @@
+package controlplane
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+)
+
+func TestReconcileTimings(t *testing.T) {
+	timings := newReconcileTimings()
+	timings.add("render", 1200*time.Millisecond)
+	timings.add("apply/istio-discovery", 300*time.Millisecond)
+	timings.add("readiness", 20*time.Millisecond)
+	timings.add("apply/istio-discovery", 50*time.Millisecond)
+	timings.add("readiness", 30*time.Millisecond)
+	timings.add("prune", 80400*time.Microsecond)
+
+	value := timings.String()
+	expectedPrefix := "render=1.2s,apply/istio-discovery=350ms,readiness=50ms,prune=80ms,total="
+	assert.True(strings.HasPrefix(value, expectedPrefix), "unexpected timings: "+value, t)
+}
+
+func TestNilReconcileTimings(t *testing.T) {
+	var timings *reconcileTimings
+	timings.track("render", time.Now())
+	assert.Equals(timings.String(), "", "expected no timings", t)
+}
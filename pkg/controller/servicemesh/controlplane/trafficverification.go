@@ -0,0 +1,423 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/maistra/istio-operator/pkg/apis/maistra/status"
+	v2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+)
+
+const (
+	eventReasonTrafficVerification = "TrafficVerification"
+
+	// statusAnnotationTrafficVerificationGeneration is the generation of the
+	// spec the TrafficVerified condition refers to
+	statusAnnotationTrafficVerificationGeneration = "trafficVerificationGeneration"
+	// statusAnnotationTrafficVerificationStartTime is when the canary
+	// resources of the verification in progress were created
+	statusAnnotationTrafficVerificationStartTime = "trafficVerificationStartTime"
+
+	// trafficVerificationLabel is set on the canary resources to the name of
+	// the control plane whose traffic they verify
+	trafficVerificationLabel = common.MetadataNamespace + "/traffic-verification"
+
+	// ingressGatewayServiceName is the Service of the ingress gateway of the
+	// control plane, through which the request is sent
+	ingressGatewayServiceName = "istio-ingressgateway"
+
+	// trafficVerificationPath is requested from the canary workload, which
+	// responds with 200 OK
+	trafficVerificationPath = "/status/200"
+
+	// trafficVerificationPort is the port on which the canary workload serves
+	trafficVerificationPort = 8080
+
+	// trafficVerificationRequestTimeout bounds the request through the ingress
+	// gateway, as it's performed while the control plane is being reconciled
+	trafficVerificationRequestTimeout = 10 * time.Second
+
+	// trafficVerificationEnvoyConfig is the bootstrap configuration of the
+	// Envoy serving the canary responses, unless TrafficVerificationImage is
+	// set.  It responds to trafficVerificationPath on trafficVerificationPort.
+	trafficVerificationEnvoyConfig = `static_resources:
+  listeners:
+  - name: traffic-verification
+    address:
+      socket_address: {address: 0.0.0.0, port_value: 8080}
+    filter_chains:
+    - filters:
+      - name: envoy.filters.network.http_connection_manager
+        typed_config:
+          "@type": type.googleapis.com/envoy.extensions.filters.network.http_connection_manager.v3.HttpConnectionManager
+          stat_prefix: traffic_verification
+          route_config:
+            virtual_hosts:
+            - name: traffic-verification
+              domains: ["*"]
+              routes:
+              - match: {path: /status/200}
+                direct_response: {status: 200}
+          http_filters:
+          - name: envoy.filters.http.router
+            typed_config:
+              "@type": type.googleapis.com/envoy.extensions.filters.http.router.v3.Router
+`
+)
+
+var (
+	istioGatewayGVK        = schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1beta1", Kind: "Gateway"}
+	istioVirtualServiceGVK = schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1beta1", Kind: "VirtualService"}
+)
+
+// trafficVerificationRequester sends a GET request with the given Host header
+// to the URL and returns an error unless the response is 200 OK
+type trafficVerificationRequester func(ctx context.Context, url, host string) error
+
+var sendTrafficVerificationRequest trafficVerificationRequester = func(ctx context.Context, url, host string) error {
+	ctx, cancel := context.WithTimeout(ctx, trafficVerificationRequestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Host = host
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response from %s: %s", url, resp.Status)
+	}
+	return nil
+}
+
+func isTrafficVerificationEnabled(smcp *v2.ServiceMeshControlPlane) bool {
+	verify := smcp.Status.AppliedSpec.Verify
+	return verify != nil && *verify
+}
+
+// isTrafficVerificationInProgress returns true if the canary workload was
+// deployed, but the result of the verification isn't known yet
+func isTrafficVerificationInProgress(smcp *v2.ServiceMeshControlPlane) bool {
+	condition := smcp.Status.GetCondition(status.ConditionTypeTrafficVerified)
+	return isTrafficVerificationEnabled(smcp) && condition.Reason == status.ConditionReasonVerificationInProgress
+}
+
+func trafficVerificationName(smcp *v2.ServiceMeshControlPlane) string {
+	return fmt.Sprintf("%s-traffic-verification", smcp.GetName())
+}
+
+// trafficVerificationHost is the host routed to the canary workload by the
+// ingress gateway.  It isn't resolved, but only sent in the Host header, so it
+// doesn't clash with the hosts of the applications in the mesh.
+func trafficVerificationHost(smcp *v2.ServiceMeshControlPlane) string {
+	return fmt.Sprintf("%s.%s.traffic-verification.maistra.io", trafficVerificationName(smcp), smcp.GetNamespace())
+}
+
+func ingressGatewayNamespace(smcp *v2.ServiceMeshControlPlane) string {
+	gateways := smcp.Status.AppliedSpec.Gateways
+	if gateways != nil && gateways.ClusterIngress != nil && gateways.ClusterIngress.Namespace != "" {
+		return gateways.ClusterIngress.Namespace
+	}
+	return smcp.GetNamespace()
+}
+
+// VerifyTraffic checks that the mesh routes ingress traffic, if requested in
+// spec.verify, and reports the result in the TrafficVerified condition.  Once
+// the control plane is ready, a canary workload with an injected sidecar is
+// deployed in the control plane namespace and exposed through the ingress
+// gateway.  The verification succeeds as soon as a request sent through the
+// ingress gateway reaches the canary, and fails if this doesn't happen within
+// TrafficVerificationTimeout.  The canary resources are deleted either way.
+// Each generation of the spec is only verified once.
+func (r *controlPlaneInstanceReconciler) VerifyTraffic(ctx context.Context) error {
+	if !isTrafficVerificationEnabled(r.Instance) {
+		if !hasCondition(&r.Status.StatusType, status.ConditionTypeTrafficVerified) {
+			return nil
+		}
+		if err := r.deleteTrafficVerificationResources(ctx); err != nil {
+			return err
+		}
+		r.Status.RemoveCondition(status.ConditionTypeTrafficVerified)
+		r.Status.RemoveAnnotation(statusAnnotationTrafficVerificationGeneration)
+		r.Status.RemoveAnnotation(statusAnnotationTrafficVerificationStartTime)
+		return r.PostStatus(ctx)
+	}
+
+	generation := strconv.FormatInt(r.Instance.GetGeneration(), 10)
+	condition := r.Status.GetCondition(status.ConditionTypeTrafficVerified)
+	sameGeneration := hasCondition(&r.Status.StatusType, status.ConditionTypeTrafficVerified) &&
+		r.Status.GetAnnotation(statusAnnotationTrafficVerificationGeneration) == generation
+	if sameGeneration && condition.Reason != status.ConditionReasonVerificationInProgress {
+		return nil
+	}
+
+	if !sameGeneration {
+		// the control plane must be ready, so the ingress gateway and the
+		// injector are available
+		if r.Status.GetCondition(status.ConditionTypeReady).Status != status.ConditionStatusTrue {
+			return nil
+		}
+		return r.startTrafficVerification(ctx, generation)
+	}
+
+	startTime, err := time.Parse(time.RFC3339, r.Status.GetAnnotation(statusAnnotationTrafficVerificationStartTime))
+	if err != nil {
+		// the annotation was tampered with; start over
+		return r.startTrafficVerification(ctx, generation)
+	}
+	timedOut := r.clock.Now().Sub(startTime) >= common.Config.Controller.TrafficVerificationTimeout
+
+	deployment := &appsv1.Deployment{}
+	key := common.ToNamespacedName(r.Instance)
+	key.Name = trafficVerificationName(r.Instance)
+	if err := r.Client.Get(ctx, key, deployment); err != nil {
+		if errors.IsNotFound(err) {
+			return r.startTrafficVerification(ctx, generation)
+		}
+		return err
+	}
+	if deployment.Status.AvailableReplicas == 0 {
+		if timedOut {
+			return r.finishTrafficVerification(ctx, status.ConditionStatusFalse, status.ConditionReasonRequestFailed,
+				fmt.Sprintf("The canary workload did not become available within %s", common.Config.Controller.TrafficVerificationTimeout))
+		}
+		return nil
+	}
+
+	url := fmt.Sprintf("http://%s.%s.svc:80%s", ingressGatewayServiceName, ingressGatewayNamespace(r.Instance), trafficVerificationPath)
+	if err := sendTrafficVerificationRequest(ctx, url, trafficVerificationHost(r.Instance)); err != nil {
+		if timedOut {
+			return r.finishTrafficVerification(ctx, status.ConditionStatusFalse, status.ConditionReasonRequestFailed,
+				fmt.Sprintf("The request to the canary workload through the ingress gateway failed: %s", err))
+		}
+		common.LogFromContext(ctx).Info("Request to canary workload failed, retrying", "error", err.Error())
+		return nil
+	}
+	return r.finishTrafficVerification(ctx, status.ConditionStatusTrue, status.ConditionReasonRequestSucceeded,
+		"A request was routed to the canary workload through the ingress gateway")
+}
+
+// startTrafficVerification creates the canary resources, replacing any that
+// were left over from the verification of a previous generation
+func (r *controlPlaneInstanceReconciler) startTrafficVerification(ctx context.Context, generation string) error {
+	log := common.LogFromContext(ctx)
+
+	if err := r.deleteTrafficVerificationResources(ctx); err != nil {
+		return err
+	}
+
+	gatewayService := &corev1.Service{}
+	gatewayKey := client.ObjectKey{Namespace: ingressGatewayNamespace(r.Instance), Name: ingressGatewayServiceName}
+	if err := r.Client.Get(ctx, gatewayKey, gatewayService); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		r.Status.SetAnnotation(statusAnnotationTrafficVerificationGeneration, generation)
+		return r.finishTrafficVerification(ctx, status.ConditionStatusFalse, status.ConditionReasonIngressGatewayNotFound,
+			fmt.Sprintf("The ingress gateway Service %s/%s does not exist", gatewayKey.Namespace, gatewayKey.Name))
+	}
+
+	if r.trafficVerificationContainer().Image == "" {
+		r.Status.SetAnnotation(statusAnnotationTrafficVerificationGeneration, generation)
+		return r.finishTrafficVerification(ctx, status.ConditionStatusFalse, status.ConditionReasonRequestFailed,
+			"The image of the canary workload is unknown, as the proxy image of the control plane is not set")
+	}
+
+	log.Info("Verifying ingress traffic of ServiceMeshControlPlane")
+	for _, obj := range r.trafficVerificationResources() {
+		if err := r.Client.Create(ctx, obj); err != nil && !errors.IsAlreadyExists(err) {
+			return fmt.Errorf("error creating canary resources: %s", err)
+		}
+	}
+
+	r.Status.SetCondition(status.Condition{
+		Type:    status.ConditionTypeTrafficVerified,
+		Status:  status.ConditionStatusUnknown,
+		Reason:  status.ConditionReasonVerificationInProgress,
+		Message: "Waiting for the canary workload to receive a request through the ingress gateway",
+	})
+	r.Status.SetAnnotation(statusAnnotationTrafficVerificationGeneration, generation)
+	r.Status.SetAnnotation(statusAnnotationTrafficVerificationStartTime, r.clock.Now().UTC().Format(time.RFC3339))
+	r.EventRecorder.Event(r.Instance, corev1.EventTypeNormal, eventReasonTrafficVerification,
+		fmt.Sprintf("Deployed canary workload %s to verify ingress traffic", trafficVerificationName(r.Instance)))
+	return r.PostStatus(ctx)
+}
+
+// finishTrafficVerification deletes the canary resources and records the
+// result of the verification
+func (r *controlPlaneInstanceReconciler) finishTrafficVerification(ctx context.Context, conditionStatus status.ConditionStatus,
+	reason status.ConditionReason, message string,
+) error {
+	if err := r.deleteTrafficVerificationResources(ctx); err != nil {
+		return err
+	}
+	r.Status.SetCondition(status.Condition{
+		Type:    status.ConditionTypeTrafficVerified,
+		Status:  conditionStatus,
+		Reason:  reason,
+		Message: message,
+	})
+	r.Status.RemoveAnnotation(statusAnnotationTrafficVerificationStartTime)
+	eventType := corev1.EventTypeNormal
+	if conditionStatus != status.ConditionStatusTrue {
+		eventType = corev1.EventTypeWarning
+	}
+	r.EventRecorder.Event(r.Instance, eventType, eventReasonTrafficVerification, message)
+	return r.PostStatus(ctx)
+}
+
+func (r *controlPlaneInstanceReconciler) deleteTrafficVerificationResources(ctx context.Context) error {
+	for _, obj := range r.trafficVerificationResources() {
+		if err := r.Client.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil &&
+			!errors.IsNotFound(err) {
+			return fmt.Errorf("error deleting canary resources: %s", err)
+		}
+	}
+	return nil
+}
+
+// trafficVerificationResources returns the canary Deployment, its Service, and
+// the Gateway and VirtualService that route the verification host to it.  They
+// are owned by the control plane, so they are garbage collected with it, but
+// aren't labelled as its components, so they aren't pruned or included in its
+// readiness.
+func (r *controlPlaneInstanceReconciler) trafficVerificationResources() []runtime.Object {
+	name := trafficVerificationName(r.Instance)
+	namespace := r.Instance.GetNamespace()
+	host := trafficVerificationHost(r.Instance)
+	newLabels := func() map[string]string {
+		return map[string]string{
+			"app":                    name,
+			trafficVerificationLabel: r.Instance.GetName(),
+		}
+	}
+	objectMeta := func() metav1.ObjectMeta {
+		return metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    newLabels(),
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(r.Instance, v2.SchemeGroupVersion.WithKind("ServiceMeshControlPlane")),
+			},
+		}
+	}
+
+	replicas := int32(1)
+	podLabels := newLabels()
+	podLabels["sidecar.istio.io/inject"] = "true"
+	deployment := &appsv1.Deployment{
+		ObjectMeta: objectMeta(),
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      podLabels,
+					Annotations: map[string]string{"sidecar.istio.io/inject": "true"},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{r.trafficVerificationContainer()},
+				},
+			},
+		},
+	}
+
+	service := &corev1.Service{
+		ObjectMeta: objectMeta(),
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"app": name},
+			Ports: []corev1.ServicePort{
+				{Name: "http", Port: 80, TargetPort: intstr.FromInt(trafficVerificationPort), Protocol: corev1.ProtocolTCP},
+			},
+		},
+	}
+
+	gateway := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"selector": map[string]interface{}{"istio": "ingressgateway"},
+			"servers": []interface{}{
+				map[string]interface{}{
+					"port":  map[string]interface{}{"number": int64(80), "name": "http", "protocol": "HTTP"},
+					"hosts": []interface{}{host},
+				},
+			},
+		},
+	}}
+	gateway.SetGroupVersionKind(istioGatewayGVK)
+
+	virtualService := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"hosts":    []interface{}{host},
+			"gateways": []interface{}{name},
+			"http": []interface{}{
+				map[string]interface{}{
+					"route": []interface{}{
+						map[string]interface{}{
+							"destination": map[string]interface{}{
+								// short names are resolved in the namespace of the VirtualService
+								"host": name,
+								"port": map[string]interface{}{"number": int64(80)},
+							},
+						},
+					},
+				},
+			},
+		},
+	}}
+	virtualService.SetGroupVersionKind(istioVirtualServiceGVK)
+
+	for _, obj := range []*unstructured.Unstructured{gateway, virtualService} {
+		meta := objectMeta()
+		obj.SetName(meta.Name)
+		obj.SetNamespace(meta.Namespace)
+		obj.SetLabels(meta.Labels)
+		obj.SetOwnerReferences(meta.OwnerReferences)
+	}
+
+	return []runtime.Object{deployment, service, gateway, virtualService}
+}
+
+// trafficVerificationContainer returns the container of the canary workload.
+// Unless TrafficVerificationImage is set, it runs the Envoy of the proxy image
+// of the control plane with a static configuration, so the verification works
+// on disconnected clusters without mirroring another image.  Hot restarts are
+// disabled, as they would clash with the Envoy of the injected sidecar.
+func (r *controlPlaneInstanceReconciler) trafficVerificationContainer() corev1.Container {
+	container := corev1.Container{
+		Name:  "echo",
+		Image: common.Config.Controller.TrafficVerificationImage,
+		Ports: []corev1.ContainerPort{
+			{Name: "http", ContainerPort: trafficVerificationPort, Protocol: corev1.ProtocolTCP},
+		},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("10m"),
+				corev1.ResourceMemory: resource.MustParse("32Mi"),
+			},
+		},
+	}
+	if container.Image == "" {
+		container.Image = expectedProxyImage(r.Instance.Status.AppliedValues.Istio)
+		container.Command = []string{"/usr/local/bin/envoy"}
+		container.Args = []string{"--disable-hot-restart", "--log-level", "warning", "--config-yaml", trafficVerificationEnvoyConfig}
+	}
+	return container
+}
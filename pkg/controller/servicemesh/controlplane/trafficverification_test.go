@@ -0,0 +1,265 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/maistra/istio-operator/pkg/apis/maistra/status"
+	v1 "github.com/maistra/istio-operator/pkg/apis/maistra/v1"
+	maistrav2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/common/test"
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+)
+
+func newTrafficVerificationControlPlane(enabled bool) *maistrav2.ServiceMeshControlPlane {
+	smcp := newControlPlane()
+	smcp.Spec.Verify = &enabled
+	smcp.Status.AppliedSpec = smcp.Spec
+	smcp.Status.AppliedValues.Istio = v1.NewHelmValues(map[string]interface{}{
+		"global": map[string]interface{}{
+			"proxy": map[string]interface{}{"image": newProxyImage},
+		},
+	})
+	smcp.Status.SetCondition(status.Condition{Type: status.ConditionTypeReady, Status: status.ConditionStatusTrue})
+	return smcp
+}
+
+func newIngressGatewayService() *corev1.Service {
+	return &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: ingressGatewayServiceName, Namespace: controlPlaneNamespace}}
+}
+
+func newTrafficVerificationFixture(smcp *maistrav2.ServiceMeshControlPlane) (client.Client, *controlPlaneInstanceReconciler, *clock.FakeClock) {
+	cl, _ := test.CreateClient(smcp, newIngressGatewayService())
+	r := newTestInstanceReconciler(cl, smcp)
+	fakeClock := clock.NewFakeClock(time.Now())
+	r.clock = fakeClock
+	return cl, r, fakeClock
+}
+
+func assertTrafficVerifiedCondition(r *controlPlaneInstanceReconciler, conditionStatus status.ConditionStatus,
+	reason status.ConditionReason, t *testing.T,
+) {
+	t.Helper()
+	condition := r.Status.GetCondition(status.ConditionTypeTrafficVerified)
+	assert.Equals(condition.Status, conditionStatus, "Unexpected status of TrafficVerified condition", t)
+	assert.Equals(condition.Reason, reason, "Unexpected reason of TrafficVerified condition", t)
+}
+
+func assertCanaryResourcesExist(cl client.Client, smcp *maistrav2.ServiceMeshControlPlane, expected bool, t *testing.T) {
+	t.Helper()
+	key := client.ObjectKey{Namespace: smcp.Namespace, Name: trafficVerificationName(smcp)}
+	gateway := &unstructured.Unstructured{}
+	gateway.SetGroupVersionKind(istioGatewayGVK)
+	virtualService := &unstructured.Unstructured{}
+	virtualService.SetGroupVersionKind(istioVirtualServiceGVK)
+	for _, obj := range []runtime.Object{&appsv1.Deployment{}, &corev1.Service{}, gateway, virtualService} {
+		err := cl.Get(ctx, key, obj)
+		if expected {
+			assert.Success(err, fmt.Sprintf("Get %T", obj), t)
+		} else {
+			assert.True(errors.IsNotFound(err), fmt.Sprintf("Expected %T to be deleted", obj), t)
+		}
+	}
+}
+
+func setCanaryAvailable(cl client.Client, smcp *maistrav2.ServiceMeshControlPlane) {
+	deployment := &appsv1.Deployment{}
+	test.PanicOnError(cl.Get(ctx, client.ObjectKey{Namespace: smcp.Namespace, Name: trafficVerificationName(smcp)}, deployment))
+	deployment.Status.AvailableReplicas = 1
+	test.PanicOnError(cl.Status().Update(ctx, deployment))
+}
+
+func TestVerifyTraffic(t *testing.T) {
+	var requests []string
+	var requestErr error
+	defer func(original trafficVerificationRequester) { sendTrafficVerificationRequest = original }(sendTrafficVerificationRequest)
+	sendTrafficVerificationRequest = func(_ context.Context, url, host string) error {
+		requests = append(requests, url+" "+host)
+		return requestErr
+	}
+
+	smcp := newTrafficVerificationControlPlane(true)
+	cl, r, _ := newTrafficVerificationFixture(smcp)
+	isInProgress := func() bool {
+		return isTrafficVerificationInProgress(&maistrav2.ServiceMeshControlPlane{Status: *r.Status})
+	}
+
+	// the canary resources are deployed
+	assert.Success(r.VerifyTraffic(ctx), "VerifyTraffic", t)
+	assertTrafficVerifiedCondition(r, status.ConditionStatusUnknown, status.ConditionReasonVerificationInProgress, t)
+	assertCanaryResourcesExist(cl, smcp, true, t)
+	assert.True(isInProgress(), "Expected traffic verification to be in progress", t)
+
+	// no request is sent until the canary is available
+	assert.Success(r.VerifyTraffic(ctx), "VerifyTraffic", t)
+	assert.Equals(len(requests), 0, "Expected no request before the canary is available", t)
+
+	// failed requests are retried
+	setCanaryAvailable(cl, smcp)
+	requestErr = fmt.Errorf("no healthy upstream")
+	assert.Success(r.VerifyTraffic(ctx), "VerifyTraffic", t)
+	assertTrafficVerifiedCondition(r, status.ConditionStatusUnknown, status.ConditionReasonVerificationInProgress, t)
+	assertCanaryResourcesExist(cl, smcp, true, t)
+
+	requestErr = nil
+	assert.Success(r.VerifyTraffic(ctx), "VerifyTraffic", t)
+	assertTrafficVerifiedCondition(r, status.ConditionStatusTrue, status.ConditionReasonRequestSucceeded, t)
+	assertCanaryResourcesExist(cl, smcp, false, t)
+	assert.False(isInProgress(), "Expected traffic verification to be complete", t)
+	assert.DeepEquals(requests, []string{
+		"http://istio-ingressgateway.cp-namespace.svc:80/status/200 my-mesh-traffic-verification.cp-namespace.traffic-verification.maistra.io",
+		"http://istio-ingressgateway.cp-namespace.svc:80/status/200 my-mesh-traffic-verification.cp-namespace.traffic-verification.maistra.io",
+	}, "Unexpected requests", t)
+
+	// each generation is only verified once
+	assert.Success(r.VerifyTraffic(ctx), "VerifyTraffic", t)
+	assertCanaryResourcesExist(cl, smcp, false, t)
+	assert.Equals(len(requests), 2, "Expected no further requests", t)
+
+	// the condition is removed when the verification is disabled
+	r.Instance.Status.AppliedSpec.Verify = nil
+	assert.Success(r.VerifyTraffic(ctx), "VerifyTraffic", t)
+	assert.False(hasCondition(&r.Status.StatusType, status.ConditionTypeTrafficVerified), "Expected TrafficVerified condition to be removed", t)
+}
+
+func TestVerifyTrafficFailsAfterTimeout(t *testing.T) {
+	defer func(original trafficVerificationRequester) { sendTrafficVerificationRequest = original }(sendTrafficVerificationRequest)
+	sendTrafficVerificationRequest = func(_ context.Context, _, _ string) error {
+		return fmt.Errorf("connection refused")
+	}
+
+	testCases := []struct {
+		name      string
+		available bool
+	}{
+		{name: "canary-unavailable"},
+		{name: "request-failing", available: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			smcp := newTrafficVerificationControlPlane(true)
+			cl, r, fakeClock := newTrafficVerificationFixture(smcp)
+
+			assert.Success(r.VerifyTraffic(ctx), "VerifyTraffic", t)
+			if tc.available {
+				setCanaryAvailable(cl, smcp)
+			}
+			fakeClock.Step(common.Config.Controller.TrafficVerificationTimeout - time.Second)
+			assert.Success(r.VerifyTraffic(ctx), "VerifyTraffic", t)
+			assertTrafficVerifiedCondition(r, status.ConditionStatusUnknown, status.ConditionReasonVerificationInProgress, t)
+
+			fakeClock.Step(time.Second)
+			assert.Success(r.VerifyTraffic(ctx), "VerifyTraffic", t)
+			assertTrafficVerifiedCondition(r, status.ConditionStatusFalse, status.ConditionReasonRequestFailed, t)
+			assertCanaryResourcesExist(cl, smcp, false, t)
+		})
+	}
+}
+
+func TestVerifyTrafficWithoutIngressGateway(t *testing.T) {
+	smcp := newTrafficVerificationControlPlane(true)
+	cl, r, _ := newTrafficVerificationFixture(smcp)
+	test.PanicOnError(cl.Delete(ctx, newIngressGatewayService()))
+
+	assert.Success(r.VerifyTraffic(ctx), "VerifyTraffic", t)
+	assertTrafficVerifiedCondition(r, status.ConditionStatusFalse, status.ConditionReasonIngressGatewayNotFound, t)
+	assertCanaryResourcesExist(cl, smcp, false, t)
+}
+
+func TestTrafficVerificationImage(t *testing.T) {
+	defer func(image string) {
+		common.Config.Controller.TrafficVerificationImage = image
+	}(common.Config.Controller.TrafficVerificationImage)
+
+	smcp := newTrafficVerificationControlPlane(true)
+	cl, r, _ := newTrafficVerificationFixture(smcp)
+	getCanaryContainer := func() corev1.Container {
+		t.Helper()
+		deployment := &appsv1.Deployment{}
+		assert.Success(cl.Get(ctx, client.ObjectKey{Namespace: smcp.Namespace, Name: trafficVerificationName(smcp)}, deployment),
+			"Get Deployment", t)
+		return deployment.Spec.Template.Spec.Containers[0]
+	}
+
+	// the Envoy of the proxy image serves the canary by default
+	common.Config.Controller.TrafficVerificationImage = ""
+	assert.Success(r.VerifyTraffic(ctx), "VerifyTraffic", t)
+	container := getCanaryContainer()
+	assert.Equals(container.Image, newProxyImage, "Expected the proxy image to be used", t)
+	assert.DeepEquals(container.Command, []string{"/usr/local/bin/envoy"}, "Unexpected command", t)
+
+	// an image set explicitly runs its own server
+	common.Config.Controller.TrafficVerificationImage = "registry.example.com/httpbin:latest"
+	test.PanicOnError(r.deleteTrafficVerificationResources(ctx))
+	r.Status.RemoveCondition(status.ConditionTypeTrafficVerified)
+	assert.Success(r.VerifyTraffic(ctx), "VerifyTraffic", t)
+	container = getCanaryContainer()
+	assert.Equals(container.Image, "registry.example.com/httpbin:latest", "Expected the configured image to be used", t)
+	assert.Equals(len(container.Command)+len(container.Args), 0, "Expected the command of the image to be used", t)
+}
+
+func TestVerifyTrafficWithoutProxyImage(t *testing.T) {
+	defer func(image string) {
+		common.Config.Controller.TrafficVerificationImage = image
+	}(common.Config.Controller.TrafficVerificationImage)
+	common.Config.Controller.TrafficVerificationImage = ""
+
+	smcp := newTrafficVerificationControlPlane(true)
+	smcp.Status.AppliedValues.Istio = nil
+	cl, r, _ := newTrafficVerificationFixture(smcp)
+
+	assert.Success(r.VerifyTraffic(ctx), "VerifyTraffic", t)
+	assertTrafficVerifiedCondition(r, status.ConditionStatusFalse, status.ConditionReasonRequestFailed, t)
+	assertCanaryResourcesExist(cl, smcp, false, t)
+}
+
+func TestVerifyTrafficWaitsForReadiness(t *testing.T) {
+	smcp := newTrafficVerificationControlPlane(true)
+	smcp.Status.SetCondition(status.Condition{Type: status.ConditionTypeReady, Status: status.ConditionStatusFalse})
+	cl, r, _ := newTrafficVerificationFixture(smcp)
+
+	assert.Success(r.VerifyTraffic(ctx), "VerifyTraffic", t)
+	assert.False(hasCondition(&r.Status.StatusType, status.ConditionTypeTrafficVerified), "Expected no TrafficVerified condition", t)
+	assertCanaryResourcesExist(cl, smcp, false, t)
+}
+
+func TestVerifyTrafficDisabled(t *testing.T) {
+	smcp := newTrafficVerificationControlPlane(false)
+	cl, tracker := test.CreateClient(smcp, newIngressGatewayService())
+	r := newTestInstanceReconciler(cl, smcp)
+
+	assert.Success(r.VerifyTraffic(ctx), "VerifyTraffic", t)
+	test.AssertNumberOfWriteActions(t, tracker.Actions(), 0)
+}
+
+func TestRecheckIntervalWhileVerifyingTraffic(t *testing.T) {
+	defer func(interval time.Duration) {
+		common.Config.Controller.TrafficVerificationCheckInterval = interval
+	}(common.Config.Controller.TrafficVerificationCheckInterval)
+	common.Config.Controller.TrafficVerificationCheckInterval = 10 * time.Second
+
+	smcp := newTrafficVerificationControlPlane(true)
+	assert.Equals(recheckInterval(smcp), time.Duration(0), "Expected no recheck", t)
+
+	smcp.Status.SetCondition(status.Condition{
+		Type:   status.ConditionTypeTrafficVerified,
+		Status: status.ConditionStatusUnknown,
+		Reason: status.ConditionReasonVerificationInProgress,
+	})
+	assert.Equals(recheckInterval(smcp), 10*time.Second, "Expected traffic verification to be rechecked", t)
+
+	smcp.Status.AppliedSpec.Verify = nil
+	assert.Equals(recheckInterval(smcp), time.Duration(0), "Expected no recheck once disabled", t)
+}
@@ -0,0 +1,280 @@
+package controlplane
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/maistra/istio-operator/pkg/apis/maistra/v1"
+	v2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/podlocality"
+)
+
+const (
+	eventReasonWorkloadUpdate = "WorkloadUpdate"
+
+	// proxyContainerName is the name of the sidecar container injected into
+	// pods
+	proxyContainerName = "istio-proxy"
+
+	// proxyImageAnnotation overrides the proxy image of a pod, which is then
+	// left alone by the update of workloads
+	proxyImageAnnotation = "sidecar.istio.io/proxyImage"
+
+	// podTemplateHashLabel is set by the Deployment controller on its
+	// ReplicaSets and their pods.  It's the suffix of the ReplicaSet names.
+	podTemplateHashLabel = "pod-template-hash"
+)
+
+// proxyValuesPaths are the helm values that affect the injected sidecars.
+// Workloads are restarted when they change.
+var proxyValuesPaths = []string{"global.proxy", "global.proxy_init", "meshConfig"}
+
+func isWorkloadUpdateEnabled(smcp *v2.ServiceMeshControlPlane) bool {
+	proxy := smcp.Status.AppliedSpec.Proxy
+	return proxy != nil && proxy.UpdateWorkloads != nil && *proxy.UpdateWorkloads
+}
+
+// isWorkloadUpdateInProgress returns true if Deployments are being restarted
+// or wait to be restarted
+func isWorkloadUpdateInProgress(smcp *v2.ServiceMeshControlPlane) bool {
+	update := smcp.Status.WorkloadUpdate
+	return isWorkloadUpdateEnabled(smcp) && update != nil && update.Restarting+update.Pending > 0
+}
+
+// expectedProxyImage returns the image of the sidecars injected with the
+// values, like the injection template, or an empty string if the values
+// don't specify it
+func expectedProxyImage(values *v1.HelmValues) string {
+	image, _, _ := values.GetString("global.proxy.image")
+	if strings.Contains(image, "/") {
+		return image
+	}
+	hub, _, _ := values.GetString("global.hub")
+	tag, _, _ := values.GetForceNumberToString("global.tag")
+	if image == "" || hub == "" || tag == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s:%s", hub, image, tag)
+}
+
+// proxyHash returns a hash of the proxy image and the values that affect the
+// injected sidecars
+func proxyHash(values *v1.HelmValues, image string) (string, error) {
+	proxyValues := map[string]interface{}{"image": image}
+	for _, path := range proxyValuesPaths {
+		if value, ok, _ := values.GetFieldNoCopy(path); ok {
+			proxyValues[path] = value
+		}
+	}
+	if globalProxy, ok := proxyValues["global.proxy"].(map[string]interface{}); ok {
+		// enabling the update of workloads doesn't change the sidecars
+		globalProxy = v1.NewHelmValues(globalProxy).DeepCopy().GetContent()
+		delete(globalProxy, "updateWorkloads")
+		proxyValues["global.proxy"] = globalProxy
+	}
+	data, err := json.Marshal(proxyValues)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data))[:16], nil
+}
+
+// workloadUpdate compares the sidecars of the pods of the mesh to the
+// expected proxy image and hash
+type workloadUpdate struct {
+	proxyImage   string
+	proxyHash    string
+	baselineHash string
+}
+
+// isPodOutdated returns true if the sidecar of the pod uses a different image
+// than the control plane injects, or was injected with a different
+// configuration.  Pods without the hash annotation weren't restarted by the
+// update of workloads and are assumed to use the baseline.
+func (u *workloadUpdate) isPodOutdated(pod *corev1.Pod) bool {
+	if u.proxyImage != "" && pod.Annotations[proxyImageAnnotation] == "" {
+		for _, container := range pod.Spec.Containers {
+			if container.Name == proxyContainerName && container.Image != u.proxyImage {
+				return true
+			}
+		}
+	}
+	hash := pod.Annotations[common.ProxyHashKey]
+	if hash == "" {
+		hash = u.baselineHash
+	}
+	return hash != u.proxyHash
+}
+
+// deploymentOfPod returns the Deployment that owns the ReplicaSet of the pod,
+// which is derived from the name of the ReplicaSet, or false if the pod isn't
+// part of a Deployment
+func deploymentOfPod(pod *corev1.Pod) (types.NamespacedName, bool) {
+	owner := metav1.GetControllerOf(pod)
+	templateHash := pod.Labels[podTemplateHashLabel]
+	if owner == nil || owner.Kind != "ReplicaSet" || templateHash == "" ||
+		!strings.HasSuffix(owner.Name, "-"+templateHash) {
+		return types.NamespacedName{}, false
+	}
+	return types.NamespacedName{Namespace: pod.Namespace, Name: strings.TrimSuffix(owner.Name, "-"+templateHash)}, true
+}
+
+// UpdateWorkloads restarts the Deployments in the member namespaces whose
+// sidecars don't match the proxy image or configuration of the control plane,
+// if requested in spec.proxy.updateWorkloads, and posts the progress to
+// status.workloadUpdate.  It is called when the control plane is fully
+// reconciled, so the restarted pods are injected by the updated istiod.  At
+// most WorkloadUpdateMaxConcurrent Deployments are restarted at a time.
+func (r *controlPlaneInstanceReconciler) UpdateWorkloads(ctx context.Context) error {
+	if !isWorkloadUpdateEnabled(r.Instance) {
+		if r.Status.WorkloadUpdate == nil {
+			return nil
+		}
+		r.Status.WorkloadUpdate = nil
+		return r.PostStatus(ctx)
+	}
+
+	values := r.Instance.Status.AppliedValues.Istio
+	image := expectedProxyImage(values)
+	hash, err := proxyHash(values, image)
+	if err != nil {
+		return fmt.Errorf("could not calculate hash of proxy configuration: %s", err)
+	}
+	update := &workloadUpdate{proxyImage: image, proxyHash: hash, baselineHash: hash}
+	if r.Status.WorkloadUpdate != nil && r.Status.WorkloadUpdate.BaselineProxyHash != "" {
+		update.baselineHash = r.Status.WorkloadUpdate.BaselineProxyHash
+	}
+
+	workloadStatus, err := r.updateWorkloads(ctx, update)
+	if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(workloadStatus, r.Status.WorkloadUpdate) {
+		return nil
+	}
+	if previous := r.Status.WorkloadUpdate; previous != nil && previous.Restarting+previous.Pending > 0 &&
+		workloadStatus.Restarting+workloadStatus.Pending == 0 {
+		r.EventRecorder.Event(r.Instance, corev1.EventTypeNormal, eventReasonWorkloadUpdate,
+			fmt.Sprintf("The sidecars of all %d Deployments are up to date", workloadStatus.UpToDate))
+	}
+	r.Status.WorkloadUpdate = workloadStatus
+	return r.PostStatus(ctx)
+}
+
+// updateWorkloads finds the Deployments with outdated sidecars and restarts
+// the next ones, if fewer than the maximum are being restarted
+func (r *controlPlaneInstanceReconciler) updateWorkloads(ctx context.Context, update *workloadUpdate) (*v2.WorkloadUpdateStatus, error) {
+	log := common.LogFromContext(ctx)
+
+	namespaces, err := r.listMeshNamespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	outdated := map[types.NamespacedName]bool{}
+	for _, namespace := range namespaces {
+		// the gateways in the control plane namespace are updated by the
+		// operator
+		if namespace.Name == r.Instance.Namespace {
+			continue
+		}
+		podList := &corev1.PodList{}
+		if err := r.injectedPodReader.List(ctx, podList, client.InNamespace(namespace.Name), client.HasLabels{injectedPodLabel}); err != nil {
+			return nil, fmt.Errorf("could not list pods in namespace %s: %s", namespace.Name, err)
+		}
+		for index := range podList.Items {
+			pod := &podList.Items[index]
+			if pod.Annotations[podlocality.IstioSidecarStatusAnnotation] == "" || pod.DeletionTimestamp != nil {
+				continue
+			}
+			// pods injected by other revisions don't use this control plane
+			if revision := pod.Labels[common.IstioRevisionKey]; revision != "" && revision != r.Instance.Name {
+				continue
+			}
+			if key, ok := deploymentOfPod(pod); ok {
+				outdated[key] = outdated[key] || update.isPodOutdated(pod)
+			}
+		}
+	}
+
+	workloadStatus := &v2.WorkloadUpdateStatus{
+		ProxyImage:        update.proxyImage,
+		ProxyHash:         update.proxyHash,
+		BaselineProxyHash: update.baselineHash,
+	}
+	var pending []*appsv1.Deployment
+	for key, isOutdated := range outdated {
+		if !isOutdated {
+			workloadStatus.UpToDate++
+			continue
+		}
+		deployment := &appsv1.Deployment{}
+		if err := r.Client.Get(ctx, key, deployment); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("could not get Deployment %s: %s", key, err)
+		}
+		if deployment.Spec.Template.Annotations[common.ProxyHashKey] == update.proxyHash {
+			workloadStatus.Restarting++
+		} else {
+			pending = append(pending, deployment)
+		}
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		if pending[i].Namespace != pending[j].Namespace {
+			return pending[i].Namespace < pending[j].Namespace
+		}
+		return pending[i].Name < pending[j].Name
+	})
+	maxConcurrent := common.Config.Controller.WorkloadUpdateMaxConcurrent
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	for _, deployment := range pending {
+		if int(workloadStatus.Restarting) >= maxConcurrent {
+			workloadStatus.Pending++
+			continue
+		}
+		log.Info("Restarting Deployment to update its sidecars", "Deployment", common.ToNamespacedName(deployment))
+		if err := r.restartDeployment(ctx, deployment, update.proxyHash); err != nil {
+			return nil, err
+		}
+		r.EventRecorder.Event(r.Instance, corev1.EventTypeNormal, eventReasonWorkloadUpdate,
+			fmt.Sprintf("Restarting Deployment %s/%s to update its sidecars", deployment.Namespace, deployment.Name))
+		workloadStatus.Restarting++
+	}
+	// once all sidecars are up to date, pods injected later without the hash
+	// annotation use the current configuration, e.g. those of new Deployments
+	if workloadStatus.Restarting+workloadStatus.Pending == 0 {
+		workloadStatus.BaselineProxyHash = update.proxyHash
+	}
+	return workloadStatus, nil
+}
+
+// restartDeployment rolls out new pods for the Deployment by recording the
+// proxy hash on its pod template, which the pods inherit
+func (r *controlPlaneInstanceReconciler) restartDeployment(ctx context.Context, deployment *appsv1.Deployment, hash string) error {
+	patch := client.MergeFrom(deployment.DeepCopy())
+	if deployment.Spec.Template.Annotations == nil {
+		deployment.Spec.Template.Annotations = map[string]string{}
+	}
+	deployment.Spec.Template.Annotations[common.ProxyHashKey] = hash
+	if err := r.Client.Patch(ctx, deployment, patch); err != nil {
+		return fmt.Errorf("could not restart Deployment %s/%s: %s", deployment.Namespace, deployment.Name, err)
+	}
+	return nil
+}
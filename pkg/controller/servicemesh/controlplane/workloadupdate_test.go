@@ -0,0 +1,228 @@
+package controlplane
+
+import (
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/maistra/istio-operator/pkg/apis/maistra/v1"
+	maistrav2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/common/test"
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+)
+
+const (
+	oldProxyImage = "registry.example.com/maistra/proxyv2:2.3.0"
+	newProxyImage = "registry.example.com/maistra/proxyv2:2.4.0"
+)
+
+func newWorkloadUpdateControlPlane(enabled bool) *maistrav2.ServiceMeshControlPlane {
+	smcp := newControlPlane()
+	smcp.Spec.Proxy = &maistrav2.ProxyConfig{UpdateWorkloads: &enabled}
+	smcp.Status.AppliedSpec = smcp.Spec
+	smcp.Status.AppliedValues.Istio = v1.NewHelmValues(map[string]interface{}{
+		"global": map[string]interface{}{
+			"hub": "registry.example.com/maistra",
+			"tag": "2.4.0",
+			"proxy": map[string]interface{}{
+				"image":           "proxyv2",
+				"updateWorkloads": enabled,
+			},
+		},
+		"meshConfig": map[string]interface{}{
+			"enableTracing": false,
+		},
+	})
+	return smcp
+}
+
+// newDeploymentPod returns an injected pod of the ReplicaSet of the Deployment
+func newDeploymentPod(namespace, deployment, image, hash string) *corev1.Pod {
+	pod := newTestPod(namespace, deployment+"-5d8f7b9c4-abcde", true)
+	pod.Labels[podTemplateHashLabel] = "5d8f7b9c4"
+	if hash != "" {
+		pod.Annotations[common.ProxyHashKey] = hash
+	}
+	controller := true
+	pod.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: "apps/v1",
+		Kind:       "ReplicaSet",
+		Name:       deployment + "-5d8f7b9c4",
+		Controller: &controller,
+	}}
+	pod.Spec.Containers = []corev1.Container{
+		{Name: "app", Image: "app:latest"},
+		{Name: proxyContainerName, Image: image},
+	}
+	return pod
+}
+
+func TestExpectedProxyImage(t *testing.T) {
+	cases := []struct {
+		name     string
+		values   map[string]interface{}
+		expected string
+	}{
+		{
+			name: "hub-and-tag",
+			values: map[string]interface{}{"global": map[string]interface{}{
+				"hub": "registry.example.com/maistra", "tag": "2.4.0",
+				"proxy": map[string]interface{}{"image": "proxyv2"},
+			}},
+			expected: newProxyImage,
+		},
+		{
+			name: "image-reference",
+			values: map[string]interface{}{"global": map[string]interface{}{
+				"hub": "registry.example.com/maistra", "tag": "2.4.0",
+				"proxy": map[string]interface{}{"image": "quay.io/maistra/proxyv2-ubi8@sha256:1234"},
+			}},
+			expected: "quay.io/maistra/proxyv2-ubi8@sha256:1234",
+		},
+		{
+			name: "no-hub",
+			values: map[string]interface{}{"global": map[string]interface{}{
+				"proxy": map[string]interface{}{"image": "proxyv2"},
+			}},
+			expected: "",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equals(expectedProxyImage(v1.NewHelmValues(tc.values)), tc.expected, "Unexpected proxy image", t)
+		})
+	}
+}
+
+func TestProxyHashIgnoresUpdateWorkloads(t *testing.T) {
+	disabled := newWorkloadUpdateControlPlane(false).Status.AppliedValues.Istio
+	enabled := newWorkloadUpdateControlPlane(true).Status.AppliedValues.Istio
+	disabledHash, err := proxyHash(disabled, newProxyImage)
+	assert.Success(err, "proxyHash", t)
+	enabledHash, err := proxyHash(enabled, newProxyImage)
+	assert.Success(err, "proxyHash", t)
+	assert.Equals(enabledHash, disabledHash, "Expected enabling the update of workloads not to change the hash", t)
+	otherImageHash, err := proxyHash(enabled, oldProxyImage)
+	assert.Success(err, "proxyHash", t)
+	assert.True(otherImageHash != enabledHash, "Expected the hash to depend on the image", t)
+	_, ok, _ := enabled.GetFieldNoCopy("global.proxy.updateWorkloads")
+	assert.True(ok, "Expected proxyHash not to modify the values", t)
+}
+
+func TestUpdateWorkloads(t *testing.T) {
+	defer func(maxConcurrent int) {
+		common.Config.Controller.WorkloadUpdateMaxConcurrent = maxConcurrent
+	}(common.Config.Controller.WorkloadUpdateMaxConcurrent)
+	common.Config.Controller.WorkloadUpdateMaxConcurrent = 1
+
+	smcp := newWorkloadUpdateControlPlane(true)
+	member := newMemberNamespace("app-namespace", controlPlaneNamespace)
+	objects := []runtime.Object{smcp, member}
+	for _, name := range []string{"details", "ratings", "reviews"} {
+		objects = append(objects, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: member.Name}})
+	}
+	cl, _ := test.CreateClient(append(objects,
+		newDeploymentPod(member.Name, "details", oldProxyImage, ""),
+		newDeploymentPod(member.Name, "ratings", oldProxyImage, ""),
+		newDeploymentPod(member.Name, "reviews", newProxyImage, ""),
+		// pods of other revisions and pods without a Deployment are ignored
+		withRevision(newDeploymentPod(member.Name, "canary", oldProxyImage, ""), "canary"),
+		newTestPod(member.Name, "standalone", true),
+		// the gateways in the control plane namespace are updated by the operator
+		newDeploymentPod(controlPlaneNamespace, "istio-ingressgateway", oldProxyImage, ""))...)
+	r := newTestInstanceReconciler(cl, smcp)
+
+	getTemplateHash := func(name string) string {
+		deployment := &appsv1.Deployment{}
+		test.PanicOnError(cl.Get(ctx, client.ObjectKey{Namespace: member.Name, Name: name}, deployment))
+		return deployment.Spec.Template.Annotations[common.ProxyHashKey]
+	}
+	rollOut := func(name, hash string) {
+		test.PanicOnError(cl.Delete(ctx, newDeploymentPod(member.Name, name, "", "")))
+		test.PanicOnError(cl.Create(ctx, newDeploymentPod(member.Name, name, newProxyImage, hash)))
+	}
+	isInProgress := func() bool {
+		return isWorkloadUpdateInProgress(&maistrav2.ServiceMeshControlPlane{Status: *r.Status})
+	}
+	assertStatus := func(upToDate, restarting, pending int32) {
+		t.Helper()
+		status := r.Status.WorkloadUpdate
+		assert.Equals(status.ProxyImage, newProxyImage, "Unexpected proxy image", t)
+		assert.Equals(status.UpToDate, upToDate, "Unexpected number of up-to-date Deployments", t)
+		assert.Equals(status.Restarting, restarting, "Unexpected number of restarting Deployments", t)
+		assert.Equals(status.Pending, pending, "Unexpected number of pending Deployments", t)
+	}
+
+	// only one Deployment is restarted at a time
+	assert.Success(r.UpdateWorkloads(ctx), "UpdateWorkloads", t)
+	assertStatus(1, 1, 1)
+	hash := r.Status.WorkloadUpdate.ProxyHash
+	assert.Equals(r.Status.WorkloadUpdate.BaselineProxyHash, hash, "Unexpected baseline", t)
+	assert.Equals(getTemplateHash("details"), hash, "Expected details to be restarted", t)
+	assert.Equals(getTemplateHash("ratings"), "", "Expected ratings not to be restarted yet", t)
+	assert.Equals(getTemplateHash("reviews"), "", "Expected reviews not to be restarted", t)
+	assert.True(isInProgress(), "Expected workload update to be in progress", t)
+
+	// the next Deployment is restarted once the rollout is complete
+	assert.Success(r.UpdateWorkloads(ctx), "UpdateWorkloads", t)
+	assertStatus(1, 1, 1)
+	rollOut("details", hash)
+	assert.Success(r.UpdateWorkloads(ctx), "UpdateWorkloads", t)
+	assertStatus(2, 1, 0)
+	assert.Equals(getTemplateHash("ratings"), hash, "Expected ratings to be restarted", t)
+	rollOut("ratings", hash)
+	assert.Success(r.UpdateWorkloads(ctx), "UpdateWorkloads", t)
+	assertStatus(3, 0, 0)
+	assert.False(isInProgress(), "Expected workload update to be complete", t)
+
+	// a change of the mesh config restarts all Deployments
+	test.PanicOnError(r.Instance.Status.AppliedValues.Istio.SetField("meshConfig.enableTracing", true))
+	assert.Success(r.UpdateWorkloads(ctx), "UpdateWorkloads", t)
+	assertStatus(0, 1, 2)
+	assert.True(r.Status.WorkloadUpdate.ProxyHash != hash, "Expected proxy hash to change", t)
+	assert.Equals(r.Status.WorkloadUpdate.BaselineProxyHash, hash, "Expected baseline to be kept", t)
+
+	// the baseline advances once all Deployments are up to date again, so the
+	// pods of new Deployments, which don't have the hash annotation, aren't
+	// restarted
+	newHash := r.Status.WorkloadUpdate.ProxyHash
+	for _, name := range []string{"details", "ratings", "reviews"} {
+		rollOut(name, newHash)
+		assert.Success(r.UpdateWorkloads(ctx), "UpdateWorkloads", t)
+	}
+	assertStatus(3, 0, 0)
+	assert.Equals(r.Status.WorkloadUpdate.BaselineProxyHash, newHash, "Expected baseline to advance", t)
+	test.PanicOnError(cl.Create(ctx, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "productpage", Namespace: member.Name}}))
+	test.PanicOnError(cl.Create(ctx, newDeploymentPod(member.Name, "productpage", newProxyImage, "")))
+	assert.Success(r.UpdateWorkloads(ctx), "UpdateWorkloads", t)
+	assertStatus(4, 0, 0)
+	assert.Equals(getTemplateHash("productpage"), "", "Expected productpage not to be restarted", t)
+
+	// the status is removed when the update of workloads is disabled
+	r.Instance.Status.AppliedSpec.Proxy.UpdateWorkloads = nil
+	assert.Success(r.UpdateWorkloads(ctx), "UpdateWorkloads", t)
+	assert.True(r.Status.WorkloadUpdate == nil, "Expected status.workloadUpdate to be removed", t)
+}
+
+func TestRecheckIntervalWhileUpdatingWorkloads(t *testing.T) {
+	defer func(interval time.Duration) {
+		common.Config.Controller.WorkloadUpdateCheckInterval = interval
+	}(common.Config.Controller.WorkloadUpdateCheckInterval)
+	common.Config.Controller.WorkloadUpdateCheckInterval = 30 * time.Second
+
+	smcp := newWorkloadUpdateControlPlane(true)
+	smcp.Status.WorkloadUpdate = &maistrav2.WorkloadUpdateStatus{UpToDate: 2}
+	assert.Equals(recheckInterval(smcp), time.Duration(0), "Expected no recheck", t)
+
+	smcp.Status.WorkloadUpdate.Pending = 1
+	assert.Equals(recheckInterval(smcp), 30*time.Second, "Expected workload update to be rechecked", t)
+
+	smcp.Status.AppliedSpec.Proxy.UpdateWorkloads = nil
+	assert.Equals(recheckInterval(smcp), time.Duration(0), "Expected no recheck once disabled", t)
+}
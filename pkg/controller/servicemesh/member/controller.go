@@ -11,6 +11,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -65,6 +66,7 @@ func newReconciler(cl client.Client, scheme *runtime.Scheme, eventRecorder recor
 		},
 		cniConfig:              cniConfig,
 		newNamespaceReconciler: newNamespaceReconfilerFunc,
+		clock:                  clock.RealClock{},
 	}
 }
 
@@ -147,6 +149,9 @@ type MemberReconciler struct {
 
 	cniConfig              cni.Config
 	newNamespaceReconciler NewNamespaceReconcilerFunc
+	// clock sets the lastTransitionTime of conditions, so that tests can
+	// control the passing of time
+	clock clock.Clock
 }
 
 type NewNamespaceReconcilerFunc func(ctx context.Context, cl client.Client,
@@ -374,19 +379,20 @@ func (r *MemberReconciler) reportError(ctx context.Context, member *maistrav1.Se
 func (r *MemberReconciler) updateStatus(ctx context.Context, member *maistrav1.ServiceMeshMember, reconciled, ready bool,
 	reason maistrav1.ServiceMeshMemberConditionReason, message string,
 ) error {
+	now := metav1.NewTime(r.clock.Now())
 	member.Status.ObservedGeneration = member.Generation
-	member.Status.SetCondition(maistrav1.ServiceMeshMemberCondition{
+	member.Status.SetConditionAt(maistrav1.ServiceMeshMemberCondition{
 		Type:    maistrav1.ConditionTypeMemberReconciled,
 		Status:  common.BoolToConditionStatus(reconciled),
 		Reason:  reason,
 		Message: message,
-	})
-	member.Status.SetCondition(maistrav1.ServiceMeshMemberCondition{
+	}, now)
+	member.Status.SetConditionAt(maistrav1.ServiceMeshMemberCondition{
 		Type:    maistrav1.ConditionTypeMemberReady,
 		Status:  common.BoolToConditionStatus(ready),
 		Reason:  reason,
 		Message: message,
-	})
+	}, now)
 
 	err := r.Client.Status().Patch(ctx, member, common.NewStatusPatch(member.Status))
 	if err != nil && !errors.IsNotFound(err) {
@@ -14,6 +14,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -67,6 +68,7 @@ func newReconciler(
 		},
 		kialiReconciler:      kialiReconciler,
 		prometheusReconciler: prometheusReconciler,
+		clock:                clock.RealClock{},
 	}
 }
 
@@ -197,6 +199,9 @@ type MemberRollReconciler struct {
 
 	kialiReconciler      KialiReconciler
 	prometheusReconciler PrometheusReconciler
+	// clock sets the lastTransitionTime of conditions, so that tests can
+	// control the passing of time
+	clock clock.Clock
 }
 
 // Reconcile reads that state of the cluster for a ServiceMeshMemberRoll object and makes changes based on the state read
@@ -258,7 +263,7 @@ func (r *MemberRollReconciler) reconcileObject(ctx context.Context, roll *maistr
 
 	if roll.Name != common.MemberRollName {
 		log.Info("Skipping reconciliation of SMMR with invalid name", "project", meshNamespace, "name", roll.Name)
-		setReadyCondition(roll, false, maistrav1.ConditionReasonInvalidName,
+		r.setReadyCondition(roll, false, maistrav1.ConditionReasonInvalidName,
 			fmt.Sprintf("the ServiceMeshMemberRoll name is invalid; must be %q", common.MemberRollName))
 		return reconcile.Result{}, r.updateStatus(ctx, roll)
 	}
@@ -278,7 +283,7 @@ func (r *MemberRollReconciler) reconcileObject(ctx context.Context, roll *maistr
 		reason := maistrav1.ConditionReasonMultipleSMCP
 		message := "Multiple ServiceMeshControlPlane resources exist in the namespace"
 		log.Info("Skipping reconciliation of SMMR", "project", meshNamespace, "reason", reason, "message", message)
-		setReadyCondition(roll, false, reason, message)
+		r.setReadyCondition(roll, false, reason, message)
 		return reconcile.Result{}, r.updateStatus(ctx, roll)
 	}
 
@@ -300,7 +305,7 @@ func (r *MemberRollReconciler) reconcileObject(ctx context.Context, roll *maistr
 			if err != nil {
 				return reconcile.Result{}, err
 			} else if member == nil {
-				setMemberCondition(memberStatusMap, ns, maistrav1.ServiceMeshMemberCondition{
+				r.setMemberCondition(memberStatusMap, ns, maistrav1.ServiceMeshMemberCondition{
 					Type:    maistrav1.ConditionTypeMemberReconciled,
 					Status:  corev1.ConditionFalse,
 					Reason:  maistrav1.ConditionReasonMemberNamespaceNotExists,
@@ -311,7 +316,7 @@ func (r *MemberRollReconciler) reconcileObject(ctx context.Context, roll *maistr
 
 			ref := member.Spec.ControlPlaneRef
 			if ref.Name != mesh.Name || ref.Namespace != meshNamespace {
-				setMemberCondition(memberStatusMap, ns, maistrav1.ServiceMeshMemberCondition{
+				r.setMemberCondition(memberStatusMap, ns, maistrav1.ServiceMeshMemberCondition{
 					Type:    maistrav1.ConditionTypeMemberReconciled,
 					Status:  corev1.ConditionFalse,
 					Reason:  maistrav1.ConditionReasonMemberReferencesDifferentControlPlane,
@@ -354,7 +359,7 @@ func (r *MemberRollReconciler) reconcileObject(ctx context.Context, roll *maistr
 				}
 			}
 		}
-		setMemberCondition(memberStatusMap, member.Namespace, member.Status.GetCondition(maistrav1.ConditionTypeMemberReconciled))
+		r.setMemberCondition(memberStatusMap, member.Namespace, member.Status.GetCondition(maistrav1.ConditionTypeMemberReconciled))
 	}
 
 	// 6. tell Kiali about all the namespaces in the mesh
@@ -421,23 +426,23 @@ func (r *MemberRollReconciler) reconcileObject(ctx context.Context, roll *maistr
 	}
 
 	if mesh == nil {
-		setReadyCondition(roll, false,
+		r.setReadyCondition(roll, false,
 			maistrav1.ConditionReasonSMCPMissing,
 			"No ServiceMeshControlPlane exists in the namespace")
 	} else if len(roll.Status.PendingMembers) > 0 {
-		setReadyCondition(roll, false,
+		r.setReadyCondition(roll, false,
 			maistrav1.ConditionReasonReconcileError,
 			fmt.Sprintf("The following namespaces are not yet configured: %v", roll.Status.PendingMembers))
 	} else if kialiErr != nil {
-		setReadyCondition(roll, false,
+		r.setReadyCondition(roll, false,
 			maistrav1.ConditionReasonReconcileError,
 			fmt.Sprintf("Kiali could not be configured: %v", kialiErr))
 	} else if prometheusErr != nil {
-		setReadyCondition(roll, false,
+		r.setReadyCondition(roll, false,
 			maistrav1.ConditionReasonReconcileError,
 			fmt.Sprintf("Prometheus could not be configured: %v", prometheusErr))
 	} else {
-		setReadyCondition(roll, true,
+		r.setReadyCondition(roll, true,
 			maistrav1.ConditionReasonConfigured,
 			"All namespaces have been configured successfully")
 	}
@@ -486,7 +491,9 @@ func (r *MemberRollReconciler) getRequiredNamespaces(ctx context.Context, smmr *
 	return members, nil
 }
 
-func setMemberCondition(memberStatusMap map[string]maistrav1.ServiceMeshMemberStatusSummary, ns string, condition maistrav1.ServiceMeshMemberCondition) {
+func (r *MemberRollReconciler) setMemberCondition(memberStatusMap map[string]maistrav1.ServiceMeshMemberStatusSummary, ns string,
+	condition maistrav1.ServiceMeshMemberCondition,
+) {
 	memberStatus, exists := memberStatusMap[ns]
 	if !exists {
 		memberStatus = maistrav1.ServiceMeshMemberStatusSummary{
@@ -495,7 +502,7 @@ func setMemberCondition(memberStatusMap map[string]maistrav1.ServiceMeshMemberSt
 		}
 	}
 
-	now := metav1.NewTime(time.Now().Truncate(time.Second))
+	now := metav1.NewTime(r.clock.Now().Truncate(time.Second))
 	for i, prevCondition := range memberStatus.Conditions {
 		if prevCondition.Type == condition.Type {
 			if prevCondition.Status != condition.Status {
@@ -515,13 +522,15 @@ func setMemberCondition(memberStatusMap map[string]maistrav1.ServiceMeshMemberSt
 	memberStatusMap[ns] = memberStatus
 }
 
-func setReadyCondition(roll *maistrav1.ServiceMeshMemberRoll, ready bool, reason maistrav1.ServiceMeshMemberRollConditionReason, message string) {
-	roll.Status.SetCondition(maistrav1.ServiceMeshMemberRollCondition{
+func (r *MemberRollReconciler) setReadyCondition(roll *maistrav1.ServiceMeshMemberRoll, ready bool,
+	reason maistrav1.ServiceMeshMemberRollConditionReason, message string,
+) {
+	roll.Status.SetConditionAt(maistrav1.ServiceMeshMemberRollCondition{
 		Type:    maistrav1.ConditionTypeMemberRollReady,
 		Status:  toConditionStatus(ready),
 		Reason:  reason,
 		Message: message,
-	})
+	}, metav1.NewTime(r.clock.Now()))
 }
 
 func toConditionStatus(ready bool) corev1.ConditionStatus {
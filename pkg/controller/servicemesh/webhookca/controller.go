@@ -240,6 +240,10 @@ func webhookWatchPredicates(webhookCABundleManager WebhookCABundleManager) predi
 	return &predicate.Funcs{
 		CreateFunc: func(event event.CreateEvent) (ok bool) {
 			objName := event.Meta.GetName()
+			if event.Meta.GetLabels()[common.IgnoreCABundleKey] == "true" {
+				// caBundle is managed by the chart, e.g. for webhooks served by an external istiod
+				return false
+			}
 			if _, isCRD := event.Object.(*apixv1.CustomResourceDefinition); !isCRD {
 				for prefix, source := range autoRegistrationMap {
 					if strings.HasPrefix(objName, prefix) {
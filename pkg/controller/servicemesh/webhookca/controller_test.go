@@ -356,6 +356,25 @@ func TestReconcileAutomaticRegistration(t *testing.T) {
 	}
 }
 
+func TestAutomaticRegistrationSkipsWebhooksWithIgnoreCABundleLabel(t *testing.T) {
+	for _, tc := range cases() {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.skipAutoRegistration {
+				t.SkipNow()
+			}
+			_, _, r := createClientAndReconciler(tc.webhook)
+
+			accessor, _ := meta.Accessor(tc.webhook)
+			accessor.SetLabels(map[string]string{common.IgnoreCABundleKey: "true"})
+			watchPredicates := webhookWatchPredicates(r.webhookCABundleManager)
+
+			assert.False(watchPredicates.Create(event.CreateEvent{Meta: accessor, Object: tc.webhook}),
+				"Expected webhook with ignore-ca-bundle label to be ignored", t)
+			assert.False(r.webhookCABundleManager.IsManaged(tc.webhook), "Expected webhook to not be managed", t)
+		})
+	}
+}
+
 func TestReconcileHandlesWebhookConfigsWithoutWebhooks(t *testing.T) {
 	for _, tc := range cases() {
 		t.Run(tc.name, func(t *testing.T) {
@@ -30,6 +30,10 @@ import (
 
 var webhookFailurePolicy = admissionv1.Fail
 
+// smcpValidationWebhookTimeoutSeconds leaves enough time for the optional
+// version approval endpoint, whose requests time out after at most 10s
+var smcpValidationWebhookTimeoutSeconds int32 = 15
+
 const (
 	webhookSecretName    = "maistra-operator-serving-cert"
 	webhookConfigMapName = "maistra-operator-cabundle"
@@ -296,6 +300,7 @@ func newValidatingWebhookConfiguration(namespace string) *admissionv1.Validating
 					admissionv1.Create, admissionv1.Update),
 				FailurePolicy:           &webhookFailurePolicy,
 				SideEffects:             &noneSideEffects,
+				TimeoutSeconds:          &smcpValidationWebhookTimeoutSeconds,
 				AdmissionReviewVersions: []string{"v1beta1"},
 				ClientConfig: admissionv1.WebhookClientConfig{
 					Service: &admissionv1.ServiceReference{
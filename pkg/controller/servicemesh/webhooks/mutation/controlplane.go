@@ -4,11 +4,14 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/go-logr/logr"
 	"gomodules.xyz/jsonpatch/v2"
 	admissionv1beta1 "k8s.io/api/admission/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
@@ -16,6 +19,7 @@ import (
 
 	v1 "github.com/maistra/istio-operator/pkg/apis/maistra/v1"
 	v2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
 	webhookcommon "github.com/maistra/istio-operator/pkg/controller/servicemesh/webhooks/common"
 	"github.com/maistra/istio-operator/pkg/controller/versions"
 )
@@ -24,11 +28,21 @@ type ControlPlaneMutator struct {
 	client          client.Client
 	decoder         *admission.Decoder
 	namespaceFilter webhookcommon.NamespaceFilter
+	platform        PlatformDefaults
 }
 
-func NewControlPlaneMutator(namespaceFilter webhookcommon.NamespaceFilter) *ControlPlaneMutator {
+// PlatformDefaults describes the platform the operator is running on.  The
+// mutator uses it to default fields on newly created control planes whose
+// chart defaults would not work on the current platform.
+type PlatformDefaults struct {
+	// CNIEnabled is true if the operator manages the Istio CNI plugin
+	CNIEnabled bool
+}
+
+func NewControlPlaneMutator(namespaceFilter webhookcommon.NamespaceFilter, platform PlatformDefaults) *ControlPlaneMutator {
 	return &ControlPlaneMutator{
 		namespaceFilter: namespaceFilter,
+		platform:        platform,
 	}
 }
 
@@ -74,11 +88,34 @@ func (v *ControlPlaneMutator) Handle(ctx context.Context, req admission.Request)
 		}
 	}
 
+	// version aliases are replaced with the version they refer to, so the
+	// control plane isn't upgraded when a newer operator resolves them to a
+	// newer version.  Applying the same alias again keeps the version.
+	if resolvedVersion, isAlias := versions.ResolveVersionAlias(currentVersion); isAlias {
+		if req.AdmissionRequest.Operation == admissionv1beta1.Update && mutator.OldAnnotations()[common.VersionAliasKey] == currentVersion {
+			resolvedVersion = mutator.OldVersion()
+		}
+		log.Info("Resolving .spec.version alias", "alias", currentVersion, "version", resolvedVersion)
+		mutator.SetVersion(resolvedVersion)
+		mutator.SetAnnotation(mutator.Object().GetAnnotations(), common.VersionAliasKey, currentVersion)
+	} else if _, hasAlias := mutator.Object().GetAnnotations()[common.VersionAliasKey]; hasAlias && currentVersion != "" &&
+		currentVersion != mutator.OldVersion() {
+		// the version was changed explicitly
+		mutator.RemoveAnnotation(common.VersionAliasKey)
+	}
+
 	if len(mutator.GetProfiles()) == 0 {
 		log.Info("Setting .spec.profiles to default value", "profiles", []string{v1.DefaultTemplate})
 		mutator.SetProfiles([]string{v1.DefaultTemplate})
 	}
 
+	if req.AdmissionRequest.Operation == admissionv1beta1.Create {
+		if err := mutator.SetPlatformDefaults(ctx, &common.ControllerResources{Client: v.client}, v.platform, log); err != nil {
+			log.Error(err, "error applying platform defaults")
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+	}
+
 	patches := mutator.GetPatches()
 	if patches == nil {
 		return admission.Allowed("")
@@ -145,8 +182,12 @@ type smcpmutator interface {
 	OldVersion() string
 	NewVersion() string
 	SetVersion(version string)
+	OldAnnotations() map[string]string
+	SetAnnotation(annotations map[string]string, key, value string)
+	RemoveAnnotation(key string)
 	GetProfiles() []string
 	SetProfiles(profiles []string)
+	SetPlatformDefaults(ctx context.Context, cr *common.ControllerResources, platform PlatformDefaults, log logr.Logger) error
 	GetPatches() []jsonpatch.JsonPatchOperation
 }
 
@@ -162,6 +203,27 @@ func (m *smcppatch) SetVersion(version string) {
 	m.patches = append(m.patches, jsonpatch.NewPatch("add", "/spec/version", version))
 }
 
+// SetAnnotation adds a patch setting the annotation key to value, where
+// annotations are the current annotations of the object
+func (m *smcppatch) SetAnnotation(annotations map[string]string, key, value string) {
+	if annotations == nil {
+		m.patches = append(m.patches, jsonpatch.NewPatch("add", "/metadata/annotations", map[string]interface{}{key: value}))
+		return
+	}
+	m.patches = append(m.patches, jsonpatch.NewPatch("add", "/metadata/annotations/"+escapeJSONPointer(key), value))
+}
+
+// RemoveAnnotation adds a patch removing the annotation key, which must be
+// present
+func (m *smcppatch) RemoveAnnotation(key string) {
+	m.patches = append(m.patches, jsonpatch.NewPatch("remove", "/metadata/annotations/"+escapeJSONPointer(key), nil))
+}
+
+// escapeJSONPointer escapes a token of a JSON pointer, see RFC 6901
+func escapeJSONPointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
 func (m *smcppatch) SetProfiles(profiles []string) {
 	value := make([]interface{}, len(profiles))
 	for index, profile := range profiles {
@@ -170,6 +232,32 @@ func (m *smcppatch) SetProfiles(profiles []string) {
 	m.patches = append(m.patches, jsonpatch.NewPatch("add", "/spec/profiles", value))
 }
 
+// setDefault adds a patch setting the field at path to value, unless the field
+// is already present in obj.  Any missing parent fields are created as part of
+// the patch.
+func (m *smcppatch) setDefault(obj map[string]interface{}, value interface{}, path ...string) {
+	current := obj
+	for index, field := range path {
+		next, ok := current[field]
+		if !ok {
+			for i := len(path) - 1; i > index; i-- {
+				value = map[string]interface{}{path[i]: value}
+			}
+			m.patches = append(m.patches, jsonpatch.NewPatch("add", "/"+strings.Join(path[:index+1], "/"), value))
+			return
+		}
+		if current, ok = next.(map[string]interface{}); !ok {
+			return
+		}
+	}
+}
+
+// hasField returns true if the field at path is present in obj
+func hasField(obj map[string]interface{}, path ...string) bool {
+	_, found, err := unstructured.NestedFieldNoCopy(obj, path...)
+	return found && err == nil
+}
+
 type smcpv1mutator struct {
 	*smcppatch
 	smcp    *v1.ServiceMeshControlPlane
@@ -197,6 +285,13 @@ func (m *smcpv1mutator) OldVersion() string {
 	return m.oldsmcp.Spec.Version
 }
 
+func (m *smcpv1mutator) OldAnnotations() map[string]string {
+	if m.oldsmcp == nil {
+		return nil
+	}
+	return m.oldsmcp.Annotations
+}
+
 func (m *smcpv1mutator) GetProfiles() []string {
 	if len(m.smcp.Spec.Profiles) == 0 {
 		if m.smcp.Spec.Template == "" {
@@ -207,6 +302,11 @@ func (m *smcpv1mutator) GetProfiles() []string {
 	return m.smcp.Spec.Profiles
 }
 
+// SetPlatformDefaults is a no-op for v1 resources, which carry raw helm values
+func (m *smcpv1mutator) SetPlatformDefaults(_ context.Context, _ *common.ControllerResources, _ PlatformDefaults, _ logr.Logger) error {
+	return nil
+}
+
 type smcpv2mutator struct {
 	*smcppatch
 	smcp    *v2.ServiceMeshControlPlane
@@ -234,6 +334,67 @@ func (m *smcpv2mutator) OldVersion() string {
 	return m.oldsmcp.Spec.Version
 }
 
+func (m *smcpv2mutator) OldAnnotations() map[string]string {
+	if m.oldsmcp == nil {
+		return nil
+	}
+	return m.oldsmcp.Annotations
+}
+
 func (m *smcpv2mutator) GetProfiles() []string {
 	return m.smcp.Spec.Profiles
 }
+
+func (m *smcpv2mutator) SetPlatformDefaults(ctx context.Context, cr *common.ControllerResources, platform PlatformDefaults, log logr.Logger) error {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(m.smcp)
+	if err != nil {
+		return err
+	}
+	// profiles are applied beneath the explicit spec, so fields set by a
+	// profile must not be defaulted, as that would override the profile
+	applied, err := m.getAppliedSpec(ctx, cr)
+	if err != nil {
+		log.Info(fmt.Sprintf("skipping platform defaults, as the profiles could not be applied: %s", err))
+		return nil
+	}
+	patchCount := len(m.patches)
+	if !platform.CNIEnabled && !hasField(applied, "spec", "proxy", "networking", "initialization", "type") {
+		m.setDefault(obj, string(v2.ProxyNetworkInitTypeInitContainer), "spec", "proxy", "networking", "initialization", "type")
+		if len(m.patches) > patchCount {
+			log.Info("Setting .spec.proxy.networking.initialization.type to InitContainer, as CNI is disabled for this installation")
+		}
+	}
+	return nil
+}
+
+// getAppliedSpec returns the control plane, as unstructured content, after
+// its profiles have been applied to it
+func (m *smcpv2mutator) getAppliedSpec(ctx context.Context, cr *common.ControllerResources) (map[string]interface{}, error) {
+	smcp := m.smcp.DeepCopy()
+	if smcp.Spec.Version == "" {
+		smcp.Spec.Version = m.DefaultVersion()
+	} else if resolvedVersion, isAlias := versions.ResolveVersionAlias(smcp.Spec.Version); isAlias {
+		// the patches only replace the alias once the mutation is complete
+		smcp.Spec.Version = resolvedVersion
+	}
+	if len(smcp.Spec.Profiles) == 0 {
+		smcp.Spec.Profiles = []string{v1.DefaultTemplate}
+	}
+	version, err := versions.ParseVersion(smcp.Spec.Version)
+	if err != nil {
+		return nil, err
+	}
+	v1smcp := &v1.ServiceMeshControlPlane{}
+	if err := v1smcp.ConvertFrom(smcp); err != nil {
+		return nil, err
+	}
+	appliedV1SMCP := &v1.ServiceMeshControlPlane{ObjectMeta: v1smcp.ObjectMeta}
+	if appliedV1SMCP.Spec, err = version.Strategy().ApplyProfiles(ctx, cr, &v1smcp.Spec, smcp.GetNamespace()); err != nil {
+		return nil, err
+	}
+	appliedV2SMCP := &v2.ServiceMeshControlPlane{}
+	if err := appliedV1SMCP.ConvertTo(appliedV2SMCP); err != nil {
+		return nil, err
+	}
+	return runtime.DefaultUnstructuredConverter.ToUnstructured(appliedV2SMCP)
+}
@@ -1,15 +1,21 @@
 package mutation
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
 	"testing"
 
+	jsonpatch "github.com/evanphx/json-patch"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	maistrav1 "github.com/maistra/istio-operator/pkg/apis/maistra/v1"
 	maistrav2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
 	"github.com/maistra/istio-operator/pkg/controller/common/test"
 	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
 	"github.com/maistra/istio-operator/pkg/controller/versions"
@@ -195,6 +201,101 @@ func TestVersionIsDefaultedToOldSMCPVersionOnUpdate(t *testing.T) {
 	}
 }
 
+func TestVersionAliasIsResolved(t *testing.T) {
+	testCases := []struct {
+		name                string
+		oldVersion          string
+		oldAlias            string
+		version             string
+		annotations         map[string]string
+		expectedVersion     string
+		expectedAnnotations map[string]string
+	}{
+		{
+			name:                "create.latest",
+			version:             versions.LatestVersionAlias,
+			expectedVersion:     versions.DefaultVersion.String(),
+			expectedAnnotations: map[string]string{common.VersionAliasKey: versions.LatestVersionAlias},
+		},
+		{
+			name:            "create.minor-latest",
+			version:         versions.V2_3.String() + "-latest",
+			annotations:     map[string]string{"other": "value"},
+			expectedVersion: versions.V2_3.String(),
+			expectedAnnotations: map[string]string{
+				"other":                "value",
+				common.VersionAliasKey: versions.V2_3.String() + "-latest",
+			},
+		},
+		{
+			name:                "update.same-alias",
+			oldVersion:          versions.V2_3.String(),
+			oldAlias:            versions.LatestVersionAlias,
+			version:             versions.LatestVersionAlias,
+			annotations:         map[string]string{common.VersionAliasKey: versions.LatestVersionAlias},
+			expectedVersion:     versions.V2_3.String(),
+			expectedAnnotations: map[string]string{common.VersionAliasKey: versions.LatestVersionAlias},
+		},
+		{
+			name:                "update.new-alias",
+			oldVersion:          versions.V2_3.String(),
+			version:             versions.LatestVersionAlias,
+			expectedVersion:     versions.DefaultVersion.String(),
+			expectedAnnotations: map[string]string{common.VersionAliasKey: versions.LatestVersionAlias},
+		},
+		{
+			name:                "update.explicit-version",
+			oldVersion:          versions.V2_3.String(),
+			oldAlias:            versions.LatestVersionAlias,
+			version:             versions.V2_4.String(),
+			annotations:         map[string]string{common.VersionAliasKey: versions.LatestVersionAlias},
+			expectedVersion:     versions.V2_4.String(),
+			expectedAnnotations: map[string]string{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			controlPlane := newControlPlaneV2("istio-system")
+			controlPlane.Spec.Version = tc.version
+			controlPlane.Annotations = tc.annotations
+
+			var response admission.Response
+			if tc.oldVersion == "" {
+				mutator := createControlPlaneMutatorTestFixture()
+				response = mutator.Handle(ctx, newCreateRequest(controlPlane))
+			} else {
+				oldControlPlane := newControlPlaneV2("istio-system")
+				oldControlPlane.Spec.Version = tc.oldVersion
+				if tc.oldAlias != "" {
+					oldControlPlane.Annotations = map[string]string{common.VersionAliasKey: tc.oldAlias}
+				}
+				mutator := createControlPlaneMutatorTestFixture(oldControlPlane)
+				response = mutator.Handle(ctx, newUpdateRequest(oldControlPlane, controlPlane))
+			}
+			assert.True(response.Allowed, "Expected the request to be allowed", t)
+
+			mutatedControlPlane := applyPatches(controlPlane, response, t)
+			assert.Equals(mutatedControlPlane.Spec.Version, tc.expectedVersion, "Unexpected version", t)
+			assert.DeepEquals(mutatedControlPlane.Annotations, tc.expectedAnnotations, "Unexpected annotations", t)
+		})
+	}
+}
+
+func applyPatches(controlPlane *maistrav2.ServiceMeshControlPlane, response admission.Response, t *testing.T) *maistrav2.ServiceMeshControlPlane {
+	t.Helper()
+	original, err := json.Marshal(controlPlane)
+	assert.Success(err, "Marshal", t)
+	rawPatch, err := json.Marshal(response.Patches)
+	assert.Success(err, "Marshal", t)
+	patch, err := jsonpatch.DecodePatch(rawPatch)
+	assert.Success(err, "DecodePatch", t)
+	patched, err := patch.Apply(original)
+	assert.Success(err, "Apply", t)
+	mutatedControlPlane := &maistrav2.ServiceMeshControlPlane{}
+	assert.Success(json.Unmarshal(patched, mutatedControlPlane), "Unmarshal", t)
+	return mutatedControlPlane
+}
+
 func TestTemplateIsDefaultedOnUpdate(t *testing.T) {
 	testCases := []struct {
 		name         string
@@ -232,13 +333,131 @@ func TestTemplateIsDefaultedOnUpdate(t *testing.T) {
 	}
 }
 
+func TestPlatformDefaultsAreAppliedOnCreate(t *testing.T) {
+	testCases := []struct {
+		name          string
+		platform      PlatformDefaults
+		controlPlanes func() (runtime.Object, runtime.Object)
+	}{
+		{
+			name:     "cni-disabled.v2",
+			platform: PlatformDefaults{CNIEnabled: false},
+			controlPlanes: func() (runtime.Object, runtime.Object) {
+				controlPlane := newControlPlaneV2("istio-system")
+
+				mutatedControlPlane := controlPlane.DeepCopy()
+				mutatedControlPlane.Spec.Proxy = &maistrav2.ProxyConfig{
+					Networking: &maistrav2.ProxyNetworkingConfig{
+						Initialization: &maistrav2.ProxyNetworkInitConfig{
+							Type: maistrav2.ProxyNetworkInitTypeInitContainer,
+						},
+					},
+				}
+				return controlPlane, mutatedControlPlane
+			},
+		},
+		{
+			name:     "cni-disabled.initialization-set-by-profile.v2",
+			platform: PlatformDefaults{CNIEnabled: false},
+			controlPlanes: func() (runtime.Object, runtime.Object) {
+				controlPlane := newControlPlaneV2("istio-system")
+				controlPlane.Spec.Profiles = []string{"cni-initialization"}
+				return controlPlane, controlPlane.DeepCopy()
+			},
+		},
+		{
+			name:     "cni-disabled.v1",
+			platform: PlatformDefaults{CNIEnabled: false},
+			controlPlanes: func() (runtime.Object, runtime.Object) {
+				controlPlane := newControlPlaneV1("istio-system")
+				return controlPlane, controlPlane.DeepCopy()
+			},
+		},
+	}
+	defer useTestProfiles(t, map[string]string{
+		maistrav1.DefaultTemplate: "",
+		"cni-initialization": `
+  proxy:
+    networking:
+      initialization:
+        type: CNI`,
+	})()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			controlPlane, mutatedControlPlane := tc.controlPlanes()
+			mutator := createControlPlaneMutatorTestFixture()
+			mutator.platform = tc.platform
+			response := mutator.Handle(ctx, newCreateRequest(controlPlane))
+			expectedResponse := PatchResponse(toRawExtension(controlPlane), mutatedControlPlane)
+			if len(expectedResponse.Patches) == 0 {
+				expectedResponse = acceptWithNoMutation
+			}
+			assert.DeepEquals(response, expectedResponse, "Unexpected platform defaults in response", t)
+		})
+	}
+}
+
+func TestPlatformDefaultsAreAppliedWithVersionAlias(t *testing.T) {
+	defer useTestProfiles(t, map[string]string{maistrav1.DefaultTemplate: ""})()
+	controlPlane := newControlPlaneV2("istio-system")
+	controlPlane.Spec.Version = versions.LatestVersionAlias
+	mutator := createControlPlaneMutatorTestFixture()
+	mutator.platform = PlatformDefaults{CNIEnabled: false}
+
+	response := mutator.Handle(ctx, newCreateRequest(controlPlane))
+	assert.True(response.Allowed, "Expected the request to be allowed", t)
+
+	mutatedControlPlane := applyPatches(controlPlane, response, t)
+	assert.Equals(mutatedControlPlane.Spec.Version, versions.DefaultVersion.String(), "Unexpected version", t)
+	assert.DeepEquals(mutatedControlPlane.Spec.Proxy, &maistrav2.ProxyConfig{
+		Networking: &maistrav2.ProxyNetworkingConfig{
+			Initialization: &maistrav2.ProxyNetworkInitConfig{
+				Type: maistrav2.ProxyNetworkInitTypeInitContainer,
+			},
+		},
+	}, "Expected the proxy initialization to be defaulted", t)
+}
+
+func TestPlatformDefaultsAreNotAppliedOnUpdate(t *testing.T) {
+	controlPlane := newControlPlaneV2("istio-system")
+	updatedControlPlane := controlPlane.DeepCopy()
+	updatedControlPlane.SetLabels(map[string]string{"newLabel": "newValue"})
+
+	mutator := createControlPlaneMutatorTestFixture(controlPlane)
+	mutator.platform = PlatformDefaults{}
+	response := mutator.Handle(ctx, newUpdateRequest(controlPlane, updatedControlPlane))
+	assert.DeepEquals(response, acceptWithNoMutation, "Expected mutator to skip platform defaults on update", t)
+}
+
+// useTestProfiles writes the given profile specs to a temporary directory and
+// configures it as the user templates directory.  The returned function
+// restores the previous configuration.
+func useTestProfiles(t *testing.T, profiles map[string]string) func() {
+	dir, err := ioutil.TempDir("", "smcp-templates")
+	if err != nil {
+		t.Fatalf("could not create templates directory: %v", err)
+	}
+	for name, spec := range profiles {
+		content := "apiVersion: maistra.io/v2\nkind: ServiceMeshControlPlane\nspec:" + spec + "\n"
+		if err := ioutil.WriteFile(path.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("could not write profile %s: %v", name, err)
+		}
+	}
+	oldDir := common.Config.Rendering.UserTemplatesDir
+	common.Config.Rendering.UserTemplatesDir = dir
+	return func() {
+		common.Config.Rendering.UserTemplatesDir = oldDir
+		os.RemoveAll(dir)
+	}
+}
+
 func createControlPlaneMutatorTestFixture(clientObjects ...runtime.Object) *ControlPlaneMutator {
 	cl, _ := test.CreateClient(clientObjects...)
 	decoder, err := admission.NewDecoder(test.GetScheme())
 	if err != nil {
 		panic(fmt.Sprintf("Could not create decoder: %s", err))
 	}
-	validator := NewControlPlaneMutator("")
+	validator := NewControlPlaneMutator("", PlatformDefaults{CNIEnabled: true})
 
 	err = validator.InjectClient(cl)
 	if err != nil {
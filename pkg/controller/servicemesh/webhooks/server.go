@@ -36,6 +36,17 @@ func Add(mgr manager.Manager) error {
 
 	if !common.Config.Controller.WebhookManagementEnabled {
 		log.Info("Webhook Config Management is disabled via olm configuration")
+	} else if common.Config.LeaderElection.LeaseEnabled {
+		// all replicas serve the webhooks, but only the replica holding the
+		// lease creates and updates their configuration.  Runnables added to
+		// the manager are only started once the lease is acquired.
+		log.Info("Webhook resources are created once this replica is the leader")
+		operatorNamespace := common.GetOperatorNamespace()
+		if err := mgr.Add(manager.RunnableFunc(func(_ <-chan struct{}) error {
+			return createWebhookResources(ctx, mgr, log, operatorNamespace)
+		})); err != nil {
+			return err
+		}
 	} else {
 		operatorNamespace := common.GetOperatorNamespace()
 		if err := createWebhookResources(ctx, mgr, log, operatorNamespace); err != nil {
@@ -49,6 +60,10 @@ func Add(mgr manager.Manager) error {
 	}
 	namespaceFilter := webhookcommon.NamespaceFilter(watchNamespaceStr)
 
+	platformDefaults := mutation.PlatformDefaults{
+		CNIEnabled: common.Config.OLM.CNIEnabled,
+	}
+
 	hookServer := mgr.GetWebhookServer()
 
 	log.Info("Adding Maistra ServiceMeshControlPlane conversion handler")
@@ -59,12 +74,12 @@ func Add(mgr manager.Manager) error {
 
 	log.Info("Adding Maistra ServiceMeshControlPlane validation handler")
 	hookServer.Register(smcpValidatorServicePath, &webhook.Admission{
-		Handler: validation.NewControlPlaneValidator(namespaceFilter),
+		Handler: validation.NewControlPlaneValidator(namespaceFilter, validation.NewVersionApprover()),
 	})
 
 	log.Info("Adding Maistra ServiceMeshControlPlane mutation handler")
 	hookServer.Register(smcpMutatorServicePath, &webhook.Admission{
-		Handler: mutation.NewControlPlaneMutator(namespaceFilter),
+		Handler: mutation.NewControlPlaneMutator(namespaceFilter, platformDefaults),
 	})
 
 	log.Info("Adding Maistra ServiceMeshMemberRoll validation handler")
@@ -24,11 +24,16 @@ type ControlPlaneValidator struct {
 	client          client.Client
 	decoder         *admission.Decoder
 	namespaceFilter webhookcommon.NamespaceFilter
+	versionApprover VersionApprover
 }
 
-func NewControlPlaneValidator(namespaceFilter webhookcommon.NamespaceFilter) *ControlPlaneValidator {
+// NewControlPlaneValidator creates a new ControlPlaneValidator.  If
+// versionApprover is not nil, it must approve the version of new control
+// planes and version changes of existing control planes.
+func NewControlPlaneValidator(namespaceFilter webhookcommon.NamespaceFilter, versionApprover VersionApprover) *ControlPlaneValidator {
 	return &ControlPlaneValidator{
 		namespaceFilter: namespaceFilter,
+		versionApprover: versionApprover,
 	}
 }
 
@@ -64,6 +69,17 @@ func (v *ControlPlaneValidator) Handle(ctx context.Context, req admission.Reques
 		return badRequest("only one service mesh may be installed per project/namespace")
 	}
 
+	if req.AdmissionRequest.Operation == admissionv1beta1.Create {
+		if conflict, err := v.findConflictingControlPlane(ctx, req.Namespace, req.Name); err != nil {
+			logger.Error(err, "error listing smcp resources")
+			return admission.Errored(http.StatusInternalServerError, err)
+		} else if conflict != nil {
+			return badRequest(fmt.Sprintf("the name of the service mesh conflicts with service mesh %s/%s: "+
+				"the cluster-scoped resources of both meshes, e.g. istiod-%s-%s, would have the same name",
+				conflict.Namespace, conflict.Name, req.Name, req.Namespace))
+		}
+	}
+
 	smcprequest, err := v.decodeRequest(req, logger)
 	if err != nil {
 		logger.Error(err, "error decoding admission request")
@@ -75,6 +91,8 @@ func (v *ControlPlaneValidator) Handle(ctx context.Context, req admission.Reques
 		return badRequest(fmt.Sprintf("Only '%v' versions are supported", versions.GetSupportedVersionNames()))
 	} else if err := v.validateVersion(ctx, smcprequest.New(), smcprequest.NewVersion()); err != nil {
 		return badRequest(err.Error())
+	} else if err := v.approveVersion(ctx, smcprequest); err != nil {
+		return forbidden(err.Error())
 	}
 
 	if req.AdmissionRequest.Operation == admissionv1beta1.Update {
@@ -85,6 +103,24 @@ func (v *ControlPlaneValidator) Handle(ctx context.Context, req admission.Reques
 	return v.validateRequest(ctx, req, smcprequest.NewVersion(), smcprequest.New())
 }
 
+// findConflictingControlPlane returns the control plane in another namespace
+// whose cluster-scoped resources would have the same names as those of the
+// given control plane.  Cluster-scoped resources are named after the control
+// plane's name and namespace, e.g. istiod-<name>-<namespace>, so a mesh named
+// "a-b" in namespace "c" conflicts with a mesh named "a" in namespace "b-c".
+func (v *ControlPlaneValidator) findConflictingControlPlane(ctx context.Context, namespace, name string) (*maistrav2.ServiceMeshControlPlane, error) {
+	smcpList := &maistrav2.ServiceMeshControlPlaneList{}
+	if err := v.client.List(ctx, smcpList); err != nil {
+		return nil, err
+	}
+	for index, smcp := range smcpList.Items {
+		if smcp.Namespace != namespace && smcp.Name+"-"+smcp.Namespace == name+"-"+namespace {
+			return &smcpList.Items[index], nil
+		}
+	}
+	return nil, nil
+}
+
 func (v *ControlPlaneValidator) decodeRequest(req admission.Request, logger logr.Logger) (smcprequest, error) {
 	switch req.Kind.Version {
 	case maistrav1.SchemeGroupVersion.Version:
@@ -155,6 +191,25 @@ func (v *ControlPlaneValidator) validateVersion(ctx context.Context, obj metav1.
 	}
 }
 
+func (v *ControlPlaneValidator) approveVersion(ctx context.Context, smcprequest smcprequest) error {
+	if v.versionApprover == nil {
+		return nil
+	}
+	request := VersionApprovalRequest{
+		Namespace:        smcprequest.New().GetNamespace(),
+		Name:             smcprequest.New().GetName(),
+		RequestedVersion: smcprequest.NewVersion().String(),
+	}
+	if oldVersion := smcprequest.OldVersion(); oldVersion.Version() != versions.InvalidVersion {
+		if oldVersion.Compare(smcprequest.NewVersion()) == 0 {
+			// only version changes require approval
+			return nil
+		}
+		request.CurrentVersion = oldVersion.String()
+	}
+	return v.versionApprover.Approve(ctx, request)
+}
+
 func (v *ControlPlaneValidator) validateRequest(ctx context.Context, req admission.Request, version versions.Version, smcp metav1.Object) admission.Response {
 	return version.Strategy().ValidateRequest(ctx, v.client, req, smcp)
 }
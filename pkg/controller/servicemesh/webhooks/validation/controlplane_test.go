@@ -63,6 +63,18 @@ func TestOnlyOneControlPlaneIsAllowedPerNamespace(t *testing.T) {
 	assert.False(response.Allowed, "Expected validator to reject ServiceMeshControlPlane with bad version", t)
 }
 
+func TestControlPlaneWithConflictingClusterScopedNamesIsRejected(t *testing.T) {
+	controlPlane1 := newControlPlaneWithVersion("basic", "mesh-system", versions.V2_4.String())
+	validator := createControlPlaneValidatorTestFixture(controlPlane1)
+	controlPlane2 := newControlPlaneWithVersion("basic-mesh", "system", versions.V2_4.String())
+	response := validator.Handle(ctx, createCreateRequest(controlPlane2))
+	assert.False(response.Allowed, "Expected validator to reject ServiceMeshControlPlane whose cluster-scoped resource names conflict with another mesh", t)
+
+	controlPlane3 := newControlPlaneWithVersion("basic", "system", versions.V2_4.String())
+	response = validator.Handle(ctx, createCreateRequest(controlPlane3))
+	assert.True(response.Allowed, "Expected validator to allow ServiceMeshControlPlane whose cluster-scoped resource names don't conflict: "+response.Result.Message, t)
+}
+
 func TestControlPlaneValidation(t *testing.T) {
 	enabled := true
 
@@ -257,7 +269,7 @@ func TestControlPlaneValidation(t *testing.T) {
 	}
 }
 
-func TestFullAffinityOnlySupportedForKiali(t *testing.T) {
+func TestFullAffinityOnlySupportedForKialiAndPilot(t *testing.T) {
 	cases := []struct {
 		name                   string
 		allowedForKiali        bool
@@ -386,7 +398,10 @@ func TestFullAffinityOnlySupportedForKiali(t *testing.T) {
 
 					t.Run(v.String(), func(t *testing.T) {
 						response := validator.Handle(ctx, createCreateRequest(&controlPlane))
-						if (tc.allowedForKiali && component == maistrav2.ControlPlaneComponentNameKiali) ||
+						// pilot supports full affinity since v2.4, but still accepts the simplified podAntiAffinity
+						supportsFullAffinity := component == maistrav2.ControlPlaneComponentNameKiali ||
+							(component == maistrav2.ControlPlaneComponentNamePilot && v.AtLeast(versions.V2_4))
+						if (tc.allowedForKiali && supportsFullAffinity) ||
 							(!tc.allowedForKiali && component != maistrav2.ControlPlaneComponentNameKiali) {
 							var reason string
 							if response.Result != nil {
@@ -440,7 +455,7 @@ func createControlPlaneValidatorTestFixture(clientObjects ...runtime.Object) *Co
 	if err != nil {
 		panic(fmt.Sprintf("Could not create decoder: %s", err))
 	}
-	validator := NewControlPlaneValidator("")
+	validator := NewControlPlaneValidator("", nil)
 
 	err = validator.InjectClient(cl)
 	if err != nil {
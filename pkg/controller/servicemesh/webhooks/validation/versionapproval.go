@@ -0,0 +1,151 @@
+package validation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/maistra/istio-operator/pkg/controller/common"
+)
+
+// VersionApprover decides whether a control plane may be created with, or
+// changed to, a specific version
+type VersionApprover interface {
+	Approve(ctx context.Context, request VersionApprovalRequest) error
+}
+
+// VersionApprovalRequest is the body sent to the version approval endpoint
+type VersionApprovalRequest struct {
+	Namespace        string `json:"namespace"`
+	Name             string `json:"name"`
+	CurrentVersion   string `json:"currentVersion,omitempty"`
+	RequestedVersion string `json:"requestedVersion"`
+}
+
+// VersionApprovalResponse is the body returned by the version approval endpoint
+type VersionApprovalResponse struct {
+	Approved bool   `json:"approved"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// maxVersionApprovalTimeout caps the timeout of requests to the version
+// approval endpoint.  It must be well below the TimeoutSeconds of the
+// ServiceMeshControlPlane validating webhook, otherwise the API server would
+// reject the request before the FailurePolicy of the approver is applied.
+const maxVersionApprovalTimeout = 10 * time.Second
+
+type versionApprovalDecision struct {
+	response VersionApprovalResponse
+	expires  time.Time
+}
+
+type httpVersionApprover struct {
+	url           string
+	failurePolicy common.VersionApprovalFailurePolicy
+	cacheTTL      time.Duration
+	client        *http.Client
+
+	mu    sync.Mutex
+	cache map[string]versionApprovalDecision
+	now   func() time.Time
+}
+
+var _ VersionApprover = (*httpVersionApprover)(nil)
+
+// NewVersionApprover returns a VersionApprover that consults the endpoint
+// configured in common.Config.VersionApproval, or nil if no endpoint is
+// configured.
+func NewVersionApprover() VersionApprover {
+	config := common.Config.VersionApproval
+	if config.URL == "" {
+		return nil
+	}
+	return newHTTPVersionApprover(config.URL, config.FailurePolicy, config.CacheTTL, config.Timeout)
+}
+
+func newHTTPVersionApprover(url string, failurePolicy common.VersionApprovalFailurePolicy, cacheTTL, timeout time.Duration) *httpVersionApprover {
+	if timeout <= 0 || timeout > maxVersionApprovalTimeout {
+		timeout = maxVersionApprovalTimeout
+	}
+	return &httpVersionApprover{
+		url:           url,
+		failurePolicy: failurePolicy,
+		cacheTTL:      cacheTTL,
+		client:        &http.Client{Timeout: timeout},
+		cache:         map[string]versionApprovalDecision{},
+		now:           time.Now,
+	}
+}
+
+func (a *httpVersionApprover) Approve(ctx context.Context, request VersionApprovalRequest) error {
+	// decisions are cached per control plane and version change, as the
+	// endpoint may approve a version for some control planes only
+	key := fmt.Sprintf("%s/%s:%s->%s", request.Namespace, request.Name, request.CurrentVersion, request.RequestedVersion)
+	response, cached := a.cachedDecision(key)
+	if !cached {
+		var err error
+		if response, err = a.query(ctx, request); err != nil {
+			if a.failurePolicy == common.VersionApprovalFailurePolicyIgnore {
+				return nil
+			}
+			return fmt.Errorf("could not verify approval of version %s: %s", request.RequestedVersion, err)
+		}
+		a.cacheDecision(key, response)
+	}
+	if !response.Approved {
+		if response.Reason != "" {
+			return fmt.Errorf("version %s was not approved: %s", request.RequestedVersion, response.Reason)
+		}
+		return fmt.Errorf("version %s was not approved", request.RequestedVersion)
+	}
+	return nil
+}
+
+func (a *httpVersionApprover) query(ctx context.Context, request VersionApprovalRequest) (VersionApprovalResponse, error) {
+	response := VersionApprovalResponse{}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return response, err
+	}
+	req, err := http.NewRequest(http.MethodPost, a.url, bytes.NewReader(body))
+	if err != nil {
+		return response, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := a.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return response, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return response, fmt.Errorf("unexpected response status from version approval endpoint: %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return response, fmt.Errorf("could not decode response from version approval endpoint: %s", err)
+	}
+	return response, nil
+}
+
+func (a *httpVersionApprover) cachedDecision(key string) (VersionApprovalResponse, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	decision, ok := a.cache[key]
+	if !ok || a.now().After(decision.expires) {
+		delete(a.cache, key)
+		return VersionApprovalResponse{}, false
+	}
+	return decision.response, true
+}
+
+func (a *httpVersionApprover) cacheDecision(key string, response VersionApprovalResponse) {
+	if a.cacheTTL <= 0 {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.cache[key] = versionApprovalDecision{response: response, expires: a.now().Add(a.cacheTTL)}
+}
@@ -0,0 +1,163 @@
+package validation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+	"github.com/maistra/istio-operator/pkg/controller/versions"
+)
+
+func TestVersionApprover(t *testing.T) {
+	testCases := []struct {
+		name          string
+		status        int
+		response      VersionApprovalResponse
+		failurePolicy common.VersionApprovalFailurePolicy
+		expectError   bool
+	}{
+		{
+			name:        "approved",
+			status:      http.StatusOK,
+			response:    VersionApprovalResponse{Approved: true},
+			expectError: false,
+		},
+		{
+			name:        "rejected",
+			status:      http.StatusOK,
+			response:    VersionApprovalResponse{Approved: false, Reason: "not in catalog"},
+			expectError: true,
+		},
+		{
+			name:          "endpoint-error-fail",
+			status:        http.StatusInternalServerError,
+			failurePolicy: common.VersionApprovalFailurePolicyFail,
+			expectError:   true,
+		},
+		{
+			name:          "endpoint-error-ignore",
+			status:        http.StatusInternalServerError,
+			failurePolicy: common.VersionApprovalFailurePolicyIgnore,
+			expectError:   false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				request := VersionApprovalRequest{}
+				if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+					t.Errorf("could not decode request: %v", err)
+				}
+				assert.Equals(request.RequestedVersion, versions.V2_4.String(), "unexpected requested version", t)
+				w.WriteHeader(tc.status)
+				_ = json.NewEncoder(w).Encode(tc.response)
+			}))
+			defer server.Close()
+
+			approver := newHTTPVersionApprover(server.URL, tc.failurePolicy, time.Minute, time.Second)
+			err := approver.Approve(ctx, VersionApprovalRequest{
+				Namespace:        "istio-system",
+				Name:             "basic",
+				RequestedVersion: versions.V2_4.String(),
+			})
+			if tc.expectError && err == nil {
+				t.Error("expected an error, but version was approved")
+			} else if !tc.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestVersionApproverCachesDecisions(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_ = json.NewEncoder(w).Encode(VersionApprovalResponse{Approved: true})
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	approver := newHTTPVersionApprover(server.URL, common.VersionApprovalFailurePolicyFail, time.Minute, time.Second)
+	approver.now = func() time.Time { return now }
+
+	request := VersionApprovalRequest{Namespace: "istio-system", Name: "basic", RequestedVersion: versions.V2_4.String()}
+	for i := 0; i < 3; i++ {
+		if err := approver.Approve(ctx, request); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	assert.Equals(requests, 1, "expected decision to be cached", t)
+
+	otherRequest := request
+	otherRequest.Namespace = "other-mesh"
+	if err := approver.Approve(ctx, otherRequest); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.Equals(requests, 2, "expected decision not to be reused for another control plane", t)
+
+	now = now.Add(2 * time.Minute)
+	if err := approver.Approve(ctx, request); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.Equals(requests, 3, "expected cached decision to expire", t)
+}
+
+func TestVersionApproverTimeoutIsCapped(t *testing.T) {
+	approver := newHTTPVersionApprover("http://localhost", common.VersionApprovalFailurePolicyIgnore, time.Minute, time.Minute)
+	assert.Equals(approver.client.Timeout, maxVersionApprovalTimeout, "expected timeout to be capped", t)
+}
+
+func TestControlPlaneVersionApproval(t *testing.T) {
+	approver := &fakeVersionApprover{}
+	validator := createControlPlaneValidatorTestFixture()
+	validator.versionApprover = approver
+
+	controlPlane := newControlPlaneWithVersion("my-smcp", "istio-system", versions.V2_3.String())
+	approver.err = errRejected
+	response := validator.Handle(ctx, createCreateRequest(controlPlane))
+	assert.False(response.Allowed, "Expected validator to reject ServiceMeshControlPlane with unapproved version", t)
+	assert.Equals(len(approver.requests), 1, "Expected version approval to be requested", t)
+	assert.Equals(approver.requests[0].CurrentVersion, "", "Unexpected current version", t)
+
+	approver.err = nil
+	response = validator.Handle(ctx, createCreateRequest(controlPlane))
+	assert.True(response.Allowed, "Expected validator to allow ServiceMeshControlPlane with approved version", t)
+
+	// updates that don't change the version don't require approval
+	approver.requests = nil
+	approver.err = errRejected
+	updatedControlPlane := controlPlane.DeepCopy()
+	updatedControlPlane.Spec.Profiles = []string{"small"}
+	validator = createControlPlaneValidatorTestFixture(controlPlane)
+	validator.versionApprover = approver
+	response = validator.Handle(ctx, createUpdateRequest(controlPlane, updatedControlPlane))
+	assert.True(response.Allowed, "Expected validator to allow update without version change", t)
+	assert.Equals(len(approver.requests), 0, "Expected no version approval request", t)
+
+	updatedControlPlane.Spec.Version = versions.V2_4.String()
+	response = validator.Handle(ctx, createUpdateRequest(controlPlane, updatedControlPlane))
+	assert.False(response.Allowed, "Expected validator to reject version change that isn't approved", t)
+	assert.Equals(len(approver.requests), 1, "Expected version approval to be requested", t)
+	assert.Equals(approver.requests[0].CurrentVersion, versions.V2_3.String(), "Unexpected current version", t)
+	assert.Equals(approver.requests[0].RequestedVersion, versions.V2_4.String(), "Unexpected requested version", t)
+}
+
+var errRejected = fmt.Errorf("version was not approved")
+
+type fakeVersionApprover struct {
+	requests []VersionApprovalRequest
+	err      error
+}
+
+func (a *fakeVersionApprover) Approve(_ context.Context, request VersionApprovalRequest) error {
+	a.requests = append(a.requests, request)
+	return a.err
+}
@@ -132,6 +132,7 @@ func (rs *v1xRenderingStrategy) render(ctx context.Context, v Ver, cr *common.Co
 	if err != nil {
 		return nil, fmt.Errorf("unexpected error setting Status.AppliedSpec: %v", err)
 	}
+	removeOperatorOnlyValues(spec.Istio)
 
 	serverVersion, err := cr.DiscoveryClient.ServerVersion()
 	if err != nil {
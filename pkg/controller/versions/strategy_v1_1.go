@@ -160,6 +160,13 @@ func (v *versionStrategyV1_1) ValidateV1(ctx context.Context, cl client.Client,
 func (v *versionStrategyV1_1) ValidateV2(ctx context.Context, cl client.Client, meta *metav1.ObjectMeta, spec *v2.ControlPlaneSpec) error {
 	var allErrors []error
 	allErrors = validatePolicyType(spec, v.Ver, allErrors)
+	allErrors = validateGatewayAPI(spec, v.Ver, allErrors)
+	allErrors = validateExternalControlPlane(spec, v.Ver, allErrors)
+	allErrors = validateIdentity(spec, v.Ver, allErrors)
+	allErrors = validateMeshIdentity(ctx, meta, spec, cl, allErrors)
+	allErrors = validateCertificateAuthority(spec, v.Ver, allErrors)
+	allErrors = validateCARotation(spec, v.Ver, allErrors)
+	allErrors = validateTelemetryDefaults(spec, v.Ver, allErrors)
 	allErrors = validateTelemetryType(spec, v.Ver, allErrors)
 	allErrors = validateGateways(ctx, meta, spec, cl, allErrors)
 	return NewValidationError(allErrors...)
@@ -143,10 +143,19 @@ func (v *versionStrategyV2_0) ValidateV1(ctx context.Context, cl client.Client,
 func (v *versionStrategyV2_0) ValidateV2(ctx context.Context, cl client.Client, meta *metav1.ObjectMeta, spec *v2.ControlPlaneSpec) error {
 	var allErrors []error
 	allErrors = v.validateGlobal(spec, allErrors)
+	allErrors = validateValues(meta, spec, v.GetChartsDir(), allErrors)
 	allErrors = validateGateways(ctx, meta, spec, cl, allErrors)
 	allErrors = validatePolicyType(spec, v.Ver, allErrors)
+	allErrors = validateGatewayAPI(spec, v.Ver, allErrors)
+	allErrors = validateExternalControlPlane(spec, v.Ver, allErrors)
+	allErrors = validateIdentity(spec, v.Ver, allErrors)
+	allErrors = validateMeshIdentity(ctx, meta, spec, cl, allErrors)
+	allErrors = validateCertificateAuthority(spec, v.Ver, allErrors)
+	allErrors = validateCARotation(spec, v.Ver, allErrors)
+	allErrors = validateTelemetryDefaults(spec, v.Ver, allErrors)
 	allErrors = validateTelemetryType(spec, v.Ver, allErrors)
 	allErrors = v.validateProtocolDetection(spec, allErrors)
+	allErrors = validateOverlays(spec, allErrors)
 	return NewValidationError(allErrors...)
 }
 
@@ -245,6 +254,10 @@ func (v *versionStrategyV2_0) Render(ctx context.Context, cr *common.ControllerR
 	if err != nil {
 		return nil, fmt.Errorf("could not set field status.lastAppliedConfiguration.istio.istio_cni.istio_cni_network: %v", err)
 	}
+	err = spec.Istio.SetField("istio_cni.chained", cniConfig.Chained)
+	if err != nil {
+		return nil, fmt.Errorf("could not set field status.lastAppliedConfiguration.istio.istio_cni.chained: %v", err)
+	}
 
 	// Override these globals to match the install namespace
 	err = spec.Istio.SetField("global.istioNamespace", smcp.GetNamespace())
@@ -361,6 +374,7 @@ func (v *versionStrategyV2_0) Render(ctx context.Context, cr *common.ControllerR
 	if err != nil {
 		return nil, fmt.Errorf("unexpected error setting Status.AppliedSpec: %v", err)
 	}
+	removeOperatorOnlyValues(spec.Istio)
 
 	// Read in global.yaml
 	values, err := chartutil.ReadValuesFile(path.Join(v.GetChartsDir(), "global.yaml"))
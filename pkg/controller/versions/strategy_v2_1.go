@@ -141,10 +141,19 @@ func (v *versionStrategyV2_1) ValidateV1(ctx context.Context, cl client.Client,
 func (v *versionStrategyV2_1) ValidateV2(ctx context.Context, cl client.Client, meta *metav1.ObjectMeta, spec *v2.ControlPlaneSpec) error {
 	var allErrors []error
 	allErrors = v.validateGlobal(spec, allErrors)
+	allErrors = validateValues(meta, spec, v.GetChartsDir(), allErrors)
 	allErrors = validateGateways(ctx, meta, spec, cl, allErrors)
 	allErrors = validatePolicyType(spec, v.Ver, allErrors)
+	allErrors = validateGatewayAPI(spec, v.Ver, allErrors)
+	allErrors = validateExternalControlPlane(spec, v.Ver, allErrors)
+	allErrors = validateIdentity(spec, v.Ver, allErrors)
+	allErrors = validateMeshIdentity(ctx, meta, spec, cl, allErrors)
+	allErrors = validateCertificateAuthority(spec, v.Ver, allErrors)
+	allErrors = validateCARotation(spec, v.Ver, allErrors)
+	allErrors = validateTelemetryDefaults(spec, v.Ver, allErrors)
 	allErrors = validateTelemetryType(spec, v.Ver, allErrors)
 	allErrors = v.validateProtocolDetection(spec, allErrors)
+	allErrors = validateOverlays(spec, allErrors)
 	allErrors = v.validateRuntime(spec, allErrors)
 	allErrors = v.validateMixerDisabled(spec, allErrors)
 	allErrors = v.validateAddons(spec, allErrors)
@@ -417,6 +426,7 @@ func (v *versionStrategyV2_1) Render(ctx context.Context, cr *common.ControllerR
 	if err != nil {
 		return nil, fmt.Errorf("unexpected error setting Status.AppliedSpec: %v", err)
 	}
+	removeOperatorOnlyValues(spec.Istio)
 
 	// Read in global.yaml
 	values, err := chartutil.ReadValuesFile(path.Join(v.GetChartsDir(), "global.yaml"))
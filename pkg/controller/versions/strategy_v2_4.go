@@ -132,10 +132,19 @@ func (v *versionStrategyV2_4) ValidateV1(_ context.Context, _ client.Client, _ *
 func (v *versionStrategyV2_4) ValidateV2(ctx context.Context, cl client.Client, meta *metav1.ObjectMeta, spec *v2.ControlPlaneSpec) error {
 	var allErrors []error
 	allErrors = v.validateGlobal(ctx, v.Version(), meta, spec, cl, allErrors)
+	allErrors = validateValues(meta, spec, v.GetChartsDir(), allErrors)
 	allErrors = validateGateways(ctx, meta, spec, cl, allErrors)
 	allErrors = validatePolicyType(spec, v.Ver, allErrors)
+	allErrors = validateGatewayAPI(spec, v.Ver, allErrors)
+	allErrors = validateExternalControlPlane(spec, v.Ver, allErrors)
+	allErrors = validateIdentity(spec, v.Ver, allErrors)
+	allErrors = validateMeshIdentity(ctx, meta, spec, cl, allErrors)
+	allErrors = validateCertificateAuthority(spec, v.Ver, allErrors)
+	allErrors = validateCARotation(spec, v.Ver, allErrors)
+	allErrors = validateTelemetryDefaults(spec, v.Ver, allErrors)
 	allErrors = validateTelemetryType(spec, v.Ver, allErrors)
 	allErrors = validateProtocolDetection(spec, allErrors)
+	allErrors = validateOverlays(spec, allErrors)
 	allErrors = v.validateRuntime(spec, allErrors)
 	allErrors = v.validateMixerDisabled(spec, allErrors)
 	allErrors = v.validateAddons(spec, allErrors)
@@ -157,17 +166,28 @@ func (v *versionStrategyV2_4) validateRuntime(spec *v2.ControlPlaneSpec, allErro
 					".spec.runtime.components.pod.affinity.podAntiAffinity.requiredDuringScheduling and preferredDuringScheduling "+
 					"is not supported for the %q component", v2.ControlPlaneComponentNameKiali))
 			}
+		} else if component == v2.ControlPlaneComponentNamePilot {
+			if config.Pod.Affinity.PodAntiAffinity.PodAntiAffinity != nil &&
+				(config.Pod.Affinity.PodAntiAffinity.RequiredDuringScheduling != nil || config.Pod.Affinity.PodAntiAffinity.PreferredDuringScheduling != nil) {
+				allErrors = append(allErrors, fmt.Errorf("PodAntiAffinity for the %q component must be configured either via "+
+					".spec.runtime.components.pod.affinity.podAntiAffinity.requiredDuringScheduling and preferredDuringScheduling "+
+					"or via requiredDuringSchedulingIgnoredDuringExecution and preferredDuringSchedulingIgnoredDuringExecution, not both",
+					v2.ControlPlaneComponentNamePilot))
+			}
 		} else {
 			if config.Pod.Affinity.NodeAffinity != nil {
-				allErrors = append(allErrors, fmt.Errorf("nodeAffinity is only supported for the %q component", v2.ControlPlaneComponentNameKiali))
+				allErrors = append(allErrors, fmt.Errorf("nodeAffinity is only supported for the %q and %q components",
+					v2.ControlPlaneComponentNameKiali, v2.ControlPlaneComponentNamePilot))
 			}
 			if config.Pod.Affinity.PodAffinity != nil {
-				allErrors = append(allErrors, fmt.Errorf("podAffinity is only supported for the %q component", v2.ControlPlaneComponentNameKiali))
+				allErrors = append(allErrors, fmt.Errorf("podAffinity is only supported for the %q and %q components",
+					v2.ControlPlaneComponentNameKiali, v2.ControlPlaneComponentNamePilot))
 			}
 			if config.Pod.Affinity.PodAntiAffinity.PodAntiAffinity != nil {
 				allErrors = append(allErrors, fmt.Errorf("PodAntiAffinity configured via "+
 					".spec.runtime.components.pod.affinity.podAntiAffinity.requiredDuringSchedulingIgnoredDuringExecution "+
-					"and preferredDuringSchedulingIgnoredDuringExecution is only supported for the %q component", v2.ControlPlaneComponentNameKiali))
+					"and preferredDuringSchedulingIgnoredDuringExecution is only supported for the %q and %q components",
+					v2.ControlPlaneComponentNameKiali, v2.ControlPlaneComponentNamePilot))
 			}
 		}
 	}
@@ -424,6 +444,10 @@ func (v *versionStrategyV2_4) Render(ctx context.Context, cr *common.ControllerR
 	if err != nil {
 		return nil, fmt.Errorf("could not set field status.lastAppliedConfiguration.istio.istio_cni.istio_cni_network: %v", err)
 	}
+	err = spec.Istio.SetField("istio_cni.chained", cniConfig.Chained)
+	if err != nil {
+		return nil, fmt.Errorf("could not set field status.lastAppliedConfiguration.istio.istio_cni.chained: %v", err)
+	}
 
 	// Override these globals to match the install namespace
 	err = spec.Istio.SetField("global.istioNamespace", smcp.GetNamespace())
@@ -562,6 +586,7 @@ func (v *versionStrategyV2_4) Render(ctx context.Context, cr *common.ControllerR
 	if err != nil {
 		return nil, fmt.Errorf("unexpected error setting Status.AppliedSpec: %v", err)
 	}
+	removeOperatorOnlyValues(spec.Istio)
 
 	// Read in global.yaml
 	values, err := chartutil.ReadValuesFile(path.Join(v.GetChartsDir(), "global.yaml"))
@@ -9,6 +9,7 @@ import (
 
 	imagev1 "github.com/openshift/api/image/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
@@ -42,34 +43,6 @@ func (v Ver) GetDefaultTemplatesDir() string {
 	return path.Join(common.Config.Rendering.DefaultTemplatesDir, v.String())
 }
 
-// common code for managing rendering
-// mergeValues merges a map containing input values on top of a map containing
-// base values, giving preference to the base values for conflicts
-func mergeValues(base map[string]interface{}, input map[string]interface{}) map[string]interface{} {
-	if base == nil {
-		base = make(map[string]interface{}, 1)
-	}
-
-	for key, value := range input {
-		// if the key doesn't already exist, add it
-		if _, exists := base[key]; !exists {
-			base[key] = value
-			continue
-		}
-
-		// at this point, key exists in both input and base.
-		// If both are maps, recurse.
-		// If only input is a map, ignore it. We don't want to overrwrite base.
-		// If both are values, again, ignore it since we don't want to overrwrite base.
-		if baseKeyAsMap, baseOK := base[key].(map[string]interface{}); baseOK {
-			if inputAsMap, inputOK := value.(map[string]interface{}); inputOK {
-				base[key] = mergeValues(baseKeyAsMap, inputAsMap)
-			}
-		}
-	}
-	return base
-}
-
 func (v Ver) getSMCPProfile(name string, targetNamespace string) (*v1.ControlPlaneSpec, []string, error) {
 	if strings.Contains(name, "/") {
 		return nil, nil, fmt.Errorf("profile name contains invalid character '/'")
@@ -146,8 +119,8 @@ func (v Ver) recursivelyApplyProfiles(
 		}
 
 		// apply this profile first, then its children
-		smcp.Istio = v1.NewHelmValues(mergeValues(smcp.Istio.GetContent(), profile.Istio.GetContent()))
-		smcp.ThreeScale = v1.NewHelmValues(mergeValues(smcp.ThreeScale.GetContent(), profile.ThreeScale.GetContent()))
+		smcp.Istio = smcp.Istio.Merge(profile.Istio, v1.ListMergeReplace)
+		smcp.ThreeScale = smcp.ThreeScale.Merge(profile.ThreeScale, v1.ListMergeReplace)
 
 		if log.V(5).Enabled() {
 			rawValues, _ := yaml.Marshal(smcp)
@@ -240,6 +213,8 @@ func (v Ver) ApplyProfiles(ctx context.Context, cr *common.ControllerResources,
 		smcpSpec.ThreeScale = v1.NewHelmValues(make(map[string]interface{}))
 	}
 
+	applyControlPlaneDefaults(smcpSpec)
+
 	applyDisconnectedSettings := true
 	if tag, _, _ := smcpSpec.Istio.GetString("global.tag"); tag != "" {
 		// don't update anything
@@ -268,6 +243,27 @@ func (v Ver) ApplyProfiles(ctx context.Context, cr *common.ControllerResources,
 	return spec, err
 }
 
+// applyControlPlaneDefaults merges the defaults configured for all control
+// planes beneath the values of the control plane, before the profiles are
+// applied, so they take precedence over the profiles
+func applyControlPlaneDefaults(smcpSpec *v1.ControlPlaneSpec) {
+	config := common.Config.ControlPlaneDefaults
+	defaults := map[string]interface{}{}
+	if config.PilotResourcesValues != nil {
+		defaults["pilot"] = map[string]interface{}{
+			"resources": runtime.DeepCopyJSONValue(config.PilotResourcesValues),
+		}
+	}
+	if config.PriorityClassName != "" {
+		defaults["global"] = map[string]interface{}{
+			"priorityClassName": config.PriorityClassName,
+		}
+	}
+	if len(defaults) > 0 {
+		smcpSpec.Istio = smcpSpec.Istio.Merge(v1.NewHelmValues(defaults), v1.ListMergeReplace)
+	}
+}
+
 func isEnabled(spec *v1.HelmValues) bool {
 	if enabled, found, _ := spec.GetBool("enabled"); found {
 		return enabled
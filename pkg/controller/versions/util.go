@@ -2,21 +2,29 @@ package versions
 
 import (
 	"context"
+	"encoding/pem"
 	"fmt"
+	"net"
+	"net/url"
+	"regexp"
 	"strings"
 	"time"
 
+	jsonpatch "github.com/evanphx/json-patch"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer/json"
 	"k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
 
 	v1 "github.com/maistra/istio-operator/pkg/apis/maistra/v1"
 	v2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
 	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/featuregates"
 )
 
 func init() {
@@ -260,6 +268,325 @@ func validateProtocolDetection(spec *v2.ControlPlaneSpec, allErrors []error) []e
 	return allErrors
 }
 
+func validateGatewayAPI(spec *v2.ControlPlaneSpec, v Ver, allErrors []error) []error {
+	if spec.Gateways == nil || spec.Gateways.GatewayAPI == nil {
+		return allErrors
+	}
+	gatewayAPI := spec.Gateways.GatewayAPI
+	if v.LessThan(V2_4) {
+		return append(allErrors, fmt.Errorf("spec.gateways.gatewayAPI is not supported in version %s", v.String()))
+	}
+	if gatewayAPI.ControllerMode != nil && *gatewayAPI.ControllerMode && (gatewayAPI.Enabled == nil || !*gatewayAPI.Enabled) {
+		allErrors = append(allErrors, fmt.Errorf("spec.gateways.gatewayAPI.controllerMode requires spec.gateways.gatewayAPI.enabled to be true"))
+	}
+	if parameters := gatewayAPI.GatewayClassParameters; parameters != nil {
+		if gatewayAPI.Enabled == nil || !*gatewayAPI.Enabled {
+			allErrors = append(allErrors,
+				fmt.Errorf("spec.gateways.gatewayAPI.gatewayClassParameters requires spec.gateways.gatewayAPI.enabled to be true"))
+		}
+		if autoscaling := parameters.Autoscaling; autoscaling != nil && autoscaling.Enabled != nil && *autoscaling.Enabled {
+			if autoscaling.MaxReplicas == nil {
+				allErrors = append(allErrors, fmt.Errorf("spec.gateways.gatewayAPI.gatewayClassParameters.autoscaling.maxReplicas must be set"))
+			} else if autoscaling.MinReplicas != nil && *autoscaling.MinReplicas > *autoscaling.MaxReplicas {
+				allErrors = append(allErrors, fmt.Errorf("spec.gateways.gatewayAPI.gatewayClassParameters.autoscaling.minReplicas "+
+					"must not be greater than maxReplicas"))
+			}
+		}
+	}
+	return allErrors
+}
+
+func validateExternalControlPlane(spec *v2.ControlPlaneSpec, v Ver, allErrors []error) []error {
+	if spec.Cluster == nil || spec.Cluster.ExternalControlPlane == nil ||
+		spec.Cluster.ExternalControlPlane.Enabled == nil || !*spec.Cluster.ExternalControlPlane.Enabled {
+		return allErrors
+	}
+	if v.LessThan(V2_4) {
+		return append(allErrors, fmt.Errorf("spec.cluster.externalControlPlane is not supported in version %s", v.String()))
+	}
+	externalControlPlane := spec.Cluster.ExternalControlPlane
+	if externalControlPlane.Address == "" {
+		allErrors = append(allErrors, fmt.Errorf("spec.cluster.externalControlPlane.address must be specified"))
+	} else if net.ParseIP(externalControlPlane.Address) == nil && strings.ContainsAny(externalControlPlane.Address, ":/") {
+		allErrors = append(allErrors, fmt.Errorf("spec.cluster.externalControlPlane.address must be a host name or IP address without scheme or port: %q",
+			externalControlPlane.Address))
+	}
+	if externalControlPlane.CABundle == "" {
+		allErrors = append(allErrors, fmt.Errorf("spec.cluster.externalControlPlane.caBundle must be specified"))
+	} else if block, _ := pem.Decode([]byte(externalControlPlane.CABundle)); block == nil {
+		allErrors = append(allErrors, fmt.Errorf("spec.cluster.externalControlPlane.caBundle must be PEM encoded"))
+	}
+	return allErrors
+}
+
+func validateIdentity(spec *v2.ControlPlaneSpec, v Ver, allErrors []error) []error {
+	if spec.Security == nil {
+		return allErrors
+	}
+	if spec.Security.JwksResolverCA != "" {
+		if block, _ := pem.Decode([]byte(spec.Security.JwksResolverCA)); block == nil {
+			allErrors = append(allErrors, fmt.Errorf("spec.security.jwksResolverCA must be a PEM encoded certificate"))
+		}
+	}
+	if spec.Security.Identity == nil {
+		return allErrors
+	}
+	if thirdParty := spec.Security.Identity.ThirdParty; thirdParty != nil {
+		if strings.ContainsAny(thirdParty.Audience, ", ") {
+			allErrors = append(allErrors, fmt.Errorf("spec.security.identity.thirdParty.audience must not contain commas or spaces: %q",
+				thirdParty.Audience))
+		}
+		for index, audience := range thirdParty.TrustedAudiences {
+			if audience == "" || strings.ContainsAny(audience, ", ") {
+				allErrors = append(allErrors, fmt.Errorf("spec.security.identity.thirdParty.trustedAudiences[%d] must not be empty "+
+					"or contain commas or spaces: %q", index, audience))
+			}
+		}
+	}
+	if spec.Security.Identity.OIDC == nil {
+		return allErrors
+	}
+	if v.LessThan(V2_0) {
+		return append(allErrors, fmt.Errorf("spec.security.identity.oidc is not supported in version %s", v.String()))
+	}
+	oidc := spec.Security.Identity.OIDC
+	if oidc.Issuer == "" {
+		allErrors = append(allErrors, fmt.Errorf("spec.security.identity.oidc.issuer must be specified"))
+	} else if err := validateHTTPSURL(oidc.Issuer); err != nil {
+		allErrors = append(allErrors, fmt.Errorf("spec.security.identity.oidc.issuer: %s", err))
+	}
+	if oidc.JwksURI != "" {
+		if err := validateHTTPSURL(oidc.JwksURI); err != nil {
+			allErrors = append(allErrors, fmt.Errorf("spec.security.identity.oidc.jwksUri: %s", err))
+		}
+	}
+	return allErrors
+}
+
+// defaultTrustDomain is the trust domain of control planes that don't
+// specify spec.security.trust.domain
+const defaultTrustDomain = "cluster.local"
+
+// trustDomainRegex matches the characters allowed in SPIFFE trust domains
+var trustDomainRegex = regexp.MustCompile(`^[a-z0-9._-]+$`)
+
+func getTrustDomain(spec *v2.ControlPlaneSpec) string {
+	if spec.Security != nil && spec.Security.Trust != nil && spec.Security.Trust.Domain != "" {
+		return spec.Security.Trust.Domain
+	}
+	return defaultTrustDomain
+}
+
+func getMeshID(spec *v2.ControlPlaneSpec) string {
+	if spec.Cluster == nil {
+		return ""
+	}
+	return spec.Cluster.MeshID
+}
+
+func validateMeshIdentity(ctx context.Context, meta *metav1.ObjectMeta, spec *v2.ControlPlaneSpec, cl client.Client, allErrors []error) []error {
+	if getMeshID(spec) == "" || !featuregates.Default.Enabled(featuregates.MeshTrustDomainCheck) {
+		return validateMeshIdentityInternal(meta, spec, nil, allErrors)
+	}
+	smcps := v2.ServiceMeshControlPlaneList{}
+	if err := cl.List(ctx, &smcps); err != nil {
+		return append(allErrors, err)
+	}
+	return validateMeshIdentityInternal(meta, spec, smcps.Items, allErrors)
+}
+
+// validateMeshIdentityInternal validates the trust domain and mesh ID of the
+// control plane.  Control planes with the same mesh ID belong to the same
+// mesh, so mutual TLS between their workloads only works if they also use the
+// same trust domain.  Only the control planes in this cluster can be checked.
+func validateMeshIdentityInternal(meta *metav1.ObjectMeta, spec *v2.ControlPlaneSpec, smcps []v2.ServiceMeshControlPlane,
+	allErrors []error) []error {
+	if spec.Security != nil && spec.Security.Trust != nil {
+		trust := spec.Security.Trust
+		if trust.Domain != "" && (len(trust.Domain) > 255 || !trustDomainRegex.MatchString(trust.Domain)) {
+			allErrors = append(allErrors, fmt.Errorf("spec.security.trust.domain must only contain lowercase letters, digits, "+
+				"dots, dashes and underscores: %q", trust.Domain))
+		}
+		for index, domain := range trust.AdditionalDomains {
+			if len(domain) > 255 || !trustDomainRegex.MatchString(domain) {
+				allErrors = append(allErrors, fmt.Errorf("spec.security.trust.additionalDomains[%d] must only contain lowercase letters, "+
+					"digits, dots, dashes and underscores: %q", index, domain))
+			}
+		}
+	}
+	meshID := getMeshID(spec)
+	if meshID == "" {
+		return allErrors
+	}
+	if errs := validation.IsValidLabelValue(meshID); len(errs) > 0 {
+		return append(allErrors, fmt.Errorf("spec.cluster.meshID is invalid: %s", strings.Join(errs, ", ")))
+	}
+	trustDomain := getTrustDomain(spec)
+	for index := range smcps {
+		smcp := &smcps[index]
+		if smcp.Namespace == meta.GetNamespace() && smcp.Name == meta.GetName() {
+			continue
+		}
+		if getMeshID(&smcp.Spec) == meshID && getTrustDomain(&smcp.Spec) != trustDomain {
+			allErrors = append(allErrors, fmt.Errorf("the trust domain %q differs from the trust domain %q of control plane %s/%s, "+
+				"which has the same mesh ID %q", trustDomain, getTrustDomain(&smcp.Spec), smcp.Namespace, smcp.Name, meshID))
+		}
+	}
+	return allErrors
+}
+
+func validateCertificateAuthority(spec *v2.ControlPlaneSpec, v Ver, allErrors []error) []error {
+	if spec.Security == nil || spec.Security.CertificateAuthority == nil {
+		return allErrors
+	}
+	switch spec.Security.CertificateAuthority.Type {
+	case v2.CertificateAuthorityTypeCertManager:
+		// istiod must be able to reach istio-csr, as its own CA is disabled.
+		// Older control planes were accepted without the address, so it's
+		// only required as of v2.4 to keep them valid.
+		certManager := spec.Security.CertificateAuthority.CertManager
+		if v.AtLeast(V2_4) && (certManager == nil || certManager.Address == "") {
+			allErrors = append(allErrors, fmt.Errorf("spec.security.certificateAuthority.cert-manager.address must be specified"))
+		}
+		return allErrors
+	case v2.CertificateAuthorityTypeKubernetes:
+	default:
+		return allErrors
+	}
+	if v.LessThan(V2_4) {
+		return append(allErrors, fmt.Errorf("spec.security.certificateAuthority.type %q is not supported in version %s",
+			v2.CertificateAuthorityTypeKubernetes, v.String()))
+	}
+	kubernetes := spec.Security.CertificateAuthority.Kubernetes
+	if kubernetes == nil || kubernetes.SignerName == "" {
+		return append(allErrors, fmt.Errorf("spec.security.certificateAuthority.kubernetes.signerName must be specified"))
+	}
+	// signer names are qualified with a domain, e.g. example.com/istio-ca
+	domain, name := kubernetes.SignerName, ""
+	if index := strings.Index(domain, "/"); index >= 0 {
+		domain, name = domain[:index], domain[index+1:]
+	}
+	if name == "" || len(validation.IsDNS1123Subdomain(domain)) > 0 {
+		allErrors = append(allErrors, fmt.Errorf("spec.security.certificateAuthority.kubernetes.signerName must be of the form <domain>/<name>: %q",
+			kubernetes.SignerName))
+	}
+	return allErrors
+}
+
+func validateCARotation(spec *v2.ControlPlaneSpec, v Ver, allErrors []error) []error {
+	if spec.Security == nil || spec.Security.CARotation == nil {
+		return allErrors
+	}
+	if v.LessThan(V2_0) {
+		// citadel doesn't trust multiple roots
+		return append(allErrors, fmt.Errorf("spec.security.caRotation is not supported in version %s", v.String()))
+	}
+	// only the istiod CA signs certificates with the cacerts secret
+	if ca := spec.Security.CertificateAuthority; ca != nil && ca.Type != "" && ca.Type != v2.CertificateAuthorityTypeIstiod {
+		allErrors = append(allErrors, fmt.Errorf("spec.security.caRotation requires spec.security.certificateAuthority.type %q, but is %q",
+			v2.CertificateAuthorityTypeIstiod, ca.Type))
+	}
+	caRotation := spec.Security.CARotation
+	if caRotation.SecretName == "" || caRotation.SecretName == "cacerts" {
+		allErrors = append(allErrors, fmt.Errorf("spec.security.caRotation.secretName must name a secret other than cacerts"))
+	} else if errs := validation.IsDNS1123Subdomain(caRotation.SecretName); len(errs) > 0 {
+		allErrors = append(allErrors, fmt.Errorf("spec.security.caRotation.secretName is invalid: %s", strings.Join(errs, ", ")))
+	}
+	if caRotation.WorkloadCertRefreshPeriod != "" {
+		if period, err := time.ParseDuration(caRotation.WorkloadCertRefreshPeriod); err != nil || period <= 0 {
+			allErrors = append(allErrors, fmt.Errorf("spec.security.caRotation.workloadCertRefreshPeriod must be a positive duration: %q",
+				caRotation.WorkloadCertRefreshPeriod))
+		}
+	}
+	return allErrors
+}
+
+// defaultExtensionProviders are the extension providers istiod adds to every
+// mesh config
+var defaultExtensionProviders = sets.NewString("prometheus", "stackdriver", "envoy")
+
+func validateTelemetryDefaults(spec *v2.ControlPlaneSpec, v Ver, allErrors []error) []error {
+	defaults := spec.TelemetryDefaults
+	if defaults == nil {
+		return allErrors
+	}
+	if v.LessThan(V2_4) {
+		return append(allErrors, fmt.Errorf("spec.telemetryDefaults is not supported in version %s", v.String()))
+	}
+	providers := sets.NewString().Union(defaultExtensionProviders)
+	if spec.MeshConfig != nil {
+		for _, provider := range spec.MeshConfig.ExtensionProviders {
+			if provider != nil {
+				providers.Insert(provider.Name)
+			}
+		}
+	}
+	validateProviders := func(path string, names []string) {
+		for _, name := range names {
+			if !providers.Has(name) {
+				allErrors = append(allErrors, fmt.Errorf("%s references unknown extension provider %q", path, name))
+			}
+		}
+	}
+	if defaults.Tracing != nil {
+		validateProviders("spec.telemetryDefaults.tracing.providers", defaults.Tracing.Providers)
+	}
+	if defaults.Metrics != nil {
+		validateProviders("spec.telemetryDefaults.metrics.providers", defaults.Metrics.Providers)
+	}
+	if defaults.AccessLogging != nil {
+		validateProviders("spec.telemetryDefaults.accessLogging.providers", defaults.AccessLogging.Providers)
+	}
+	return allErrors
+}
+
+func validateHTTPSURL(rawURL string) error {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if parsedURL.Scheme != "https" || parsedURL.Host == "" {
+		return fmt.Errorf("%q is not an https URL", rawURL)
+	}
+	return nil
+}
+
+// removeOperatorOnlyValues removes the values that aren't consumed by the
+// charts. Overlays and verify are stored in the values so they survive the
+// conversion to v1, but they are applied by the operator using
+// Status.AppliedSpec, so they must be removed after it has been set.
+func removeOperatorOnlyValues(values *v1.HelmValues) {
+	values.RemoveField("overlays")
+	values.RemoveField("verify")
+}
+
+func validateOverlays(spec *v2.ControlPlaneSpec, allErrors []error) []error {
+	for index, overlay := range spec.Overlays {
+		if overlay.Kind == "" || overlay.Name == "" {
+			allErrors = append(allErrors, fmt.Errorf("spec.overlays[%d]: kind and name must be specified", index))
+		}
+		patch, err := yaml.YAMLToJSON([]byte(overlay.Patch))
+		if err != nil {
+			allErrors = append(allErrors, fmt.Errorf("spec.overlays[%d]: failed parsing patch: %s", index, err.Error()))
+			continue
+		}
+		switch overlay.Type {
+		case "", v2.OverlayPatchTypeStrategicMerge:
+			var obj map[string]interface{}
+			if err := yaml.Unmarshal(patch, &obj); err != nil {
+				allErrors = append(allErrors, fmt.Errorf("spec.overlays[%d]: strategic merge patch must be an object: %s", index, err.Error()))
+			}
+		case v2.OverlayPatchTypeJSON6902:
+			if _, err := jsonpatch.DecodePatch(patch); err != nil {
+				allErrors = append(allErrors, fmt.Errorf("spec.overlays[%d]: invalid JSON6902 patch: %s", index, err.Error()))
+			}
+		default:
+			allErrors = append(allErrors, fmt.Errorf("spec.overlays[%d]: unsupported patch type %q", index, overlay.Type))
+		}
+	}
+	return allErrors
+}
+
 func errForEnabledValue(obj *v1.HelmValues, path string) error {
 	val, ok, _ := obj.GetFieldNoCopy(path)
 	if ok {
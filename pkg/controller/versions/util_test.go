@@ -1,11 +1,19 @@
 package versions
 
 import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
 	"testing"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	maistrav1 "github.com/maistra/istio-operator/pkg/apis/maistra/v1"
 	maistrav2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/common/helm"
 )
 
 const controlPlaneNamespace = "cp-namespace"
@@ -100,3 +108,889 @@ func newGatewayConfig(namespace string, enabled *bool) *maistrav2.GatewayConfig
 		},
 	}
 }
+
+func TestValidateOverlays(t *testing.T) {
+	testCases := []struct {
+		name        string
+		overlay     maistrav2.OverlayConfig
+		expectError bool
+	}{
+		{
+			name: "strategic-merge",
+			overlay: maistrav2.OverlayConfig{
+				Kind:  "Deployment",
+				Name:  "istiod-basic",
+				Patch: "spec:\n  replicas: 2\n",
+			},
+			expectError: false,
+		},
+		{
+			name: "json6902",
+			overlay: maistrav2.OverlayConfig{
+				Kind:  "Deployment",
+				Name:  "istiod-basic",
+				Type:  maistrav2.OverlayPatchTypeJSON6902,
+				Patch: "- op: replace\n  path: /spec/replicas\n  value: 2\n",
+			},
+			expectError: false,
+		},
+		{
+			name: "missing-name",
+			overlay: maistrav2.OverlayConfig{
+				Kind:  "Deployment",
+				Patch: "spec:\n  replicas: 2\n",
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid-json6902",
+			overlay: maistrav2.OverlayConfig{
+				Kind:  "Deployment",
+				Name:  "istiod-basic",
+				Type:  maistrav2.OverlayPatchTypeJSON6902,
+				Patch: "spec:\n  replicas: 2\n",
+			},
+			expectError: true,
+		},
+		{
+			name: "unknown-type",
+			overlay: maistrav2.OverlayConfig{
+				Kind:  "Deployment",
+				Name:  "istiod-basic",
+				Type:  "Kustomize",
+				Patch: "spec:\n  replicas: 2\n",
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := &maistrav2.ControlPlaneSpec{
+				Overlays: []maistrav2.OverlayConfig{tc.overlay},
+			}
+			allErrors := validateOverlays(spec, []error{})
+			if tc.expectError {
+				if len(allErrors) == 0 {
+					t.Fatal("Expected errors, but none were returned")
+				}
+			} else {
+				if len(allErrors) > 0 {
+					t.Fatalf("Unexpected errors: %v", allErrors)
+				}
+			}
+		})
+	}
+}
+
+func TestRemoveOperatorOnlyValues(t *testing.T) {
+	values := maistrav1.NewHelmValues(map[string]interface{}{
+		"overlays": []interface{}{
+			map[string]interface{}{"kind": "Deployment", "name": "istiod-basic"},
+		},
+		"verify": true,
+		"global": map[string]interface{}{"hub": "quay.io/maistra"},
+	})
+
+	removeOperatorOnlyValues(values)
+
+	if _, found, _ := values.GetFieldNoCopy("overlays"); found {
+		t.Errorf("expected overlays to be removed from the values")
+	}
+	if _, found, _ := values.GetFieldNoCopy("verify"); found {
+		t.Errorf("expected verify to be removed from the values")
+	}
+	if hub, _, _ := values.GetString("global.hub"); hub != "quay.io/maistra" {
+		t.Errorf("expected other values to be preserved, got global.hub %q", hub)
+	}
+}
+
+func TestValidateValues(t *testing.T) {
+	chartsDir, err := ioutil.TempDir("", "charts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(chartsDir)
+	chartPath := path.Join(chartsDir, "istio-discovery")
+	if err := os.MkdirAll(path.Join(chartPath, "templates"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		path.Join(chartPath, "Chart.yaml"):  "name: istio-discovery\nversion: 1.0.0\n",
+		path.Join(chartPath, "values.yaml"): "pilot:\n  enabled: true\n",
+		path.Join(chartsDir, "global.yaml"): "global:\n  hub: quay.io/maistra\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	charts := map[string]chartRenderingDetails{DiscoveryChart: {path: "istio-discovery"}}
+	defaults, err := chartDefaultValues(chartsDir, charts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := json.Marshal(helm.ValuesSchema(defaults))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path.Join(chartsDir, ValuesSchemaFile), schema, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name          string
+		strict        bool
+		techPreview   map[string]interface{}
+		expectedError string
+	}{
+		{
+			name: "not-strict",
+			techPreview: map[string]interface{}{
+				"istio_cni": map[string]interface{}{"enable": true},
+			},
+		},
+		{
+			name:   "known-values",
+			strict: true,
+			techPreview: map[string]interface{}{
+				"pilot":                                  map[string]interface{}{"enabled": false},
+				"global":                                 map[string]interface{}{"hub": "quay.io/custom"},
+				"istio_cni":                              map[string]interface{}{"enabled": true},
+				maistrav2.TechPreviewControlPlaneModeKey: maistrav2.TechPreviewControlPlaneModeValueClusterScoped,
+			},
+		},
+		{
+			name:   "unknown-values",
+			strict: true,
+			techPreview: map[string]interface{}{
+				"pilot":     map[string]interface{}{"enable": false},
+				"istio_cni": map[string]interface{}{"enable": true},
+			},
+			expectedError: "unknown values in spec.techPreview: istio_cni.enable, pilot.enable",
+		},
+		{
+			name: "unknown-values-not-strict",
+			techPreview: map[string]interface{}{
+				"pilot": map[string]interface{}{"traceSampling1": 1.0},
+			},
+		},
+		{
+			name: "invalid-values",
+			techPreview: map[string]interface{}{
+				"pilot": "enabled",
+			},
+			expectedError: "invalid value in spec.techPreview: pilot: Invalid type. Expected: [object,null], given: string",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			meta := &metav1.ObjectMeta{}
+			if tc.strict {
+				meta.Annotations = map[string]string{common.StrictValuesKey: "true"}
+			}
+			spec := &maistrav2.ControlPlaneSpec{
+				TechPreview: maistrav1.NewHelmValues(tc.techPreview),
+			}
+			allErrors := validateValues(meta, spec, chartsDir, nil)
+			if tc.expectedError == "" {
+				if len(allErrors) > 0 {
+					t.Fatalf("Unexpected errors: %v", allErrors)
+				}
+			} else if len(allErrors) != 1 || !strings.Contains(allErrors[0].Error(), tc.expectedError) {
+				t.Fatalf("Expected error %q, got %v", tc.expectedError, allErrors)
+			}
+		})
+	}
+}
+
+func TestValidateGatewayAPI(t *testing.T) {
+	enabled, disabled := true, false
+	minReplicas, maxReplicas := int32(2), int32(5)
+	testCases := []struct {
+		name        string
+		version     Ver
+		gatewayAPI  *maistrav2.GatewayAPIConfig
+		expectError bool
+	}{
+		{
+			name:        "unset",
+			version:     V2_3,
+			expectError: false,
+		},
+		{
+			name:    "enabled",
+			version: V2_4,
+			gatewayAPI: &maistrav2.GatewayAPIConfig{
+				Enablement: maistrav2.Enablement{Enabled: &enabled},
+			},
+			expectError: false,
+		},
+		{
+			name:    "controller-mode",
+			version: V2_4,
+			gatewayAPI: &maistrav2.GatewayAPIConfig{
+				Enablement:     maistrav2.Enablement{Enabled: &enabled},
+				ControllerMode: &enabled,
+			},
+			expectError: false,
+		},
+		{
+			name:    "controller-mode-without-enabled",
+			version: V2_4,
+			gatewayAPI: &maistrav2.GatewayAPIConfig{
+				Enablement:     maistrav2.Enablement{Enabled: &disabled},
+				ControllerMode: &enabled,
+			},
+			expectError: true,
+		},
+		{
+			name:    "unsupported-version",
+			version: V2_3,
+			gatewayAPI: &maistrav2.GatewayAPIConfig{
+				Enablement: maistrav2.Enablement{Enabled: &enabled},
+			},
+			expectError: true,
+		},
+		{
+			name:    "gateway-class-parameters",
+			version: V2_4,
+			gatewayAPI: &maistrav2.GatewayAPIConfig{
+				Enablement: maistrav2.Enablement{Enabled: &enabled},
+				GatewayClassParameters: &maistrav2.GatewayClassParametersConfig{
+					ServiceAnnotations: map[string]string{"service.beta.kubernetes.io/aws-load-balancer-type": "nlb"},
+					Autoscaling: &maistrav2.AutoScalerConfig{
+						Enablement:  maistrav2.Enablement{Enabled: &enabled},
+						MinReplicas: &minReplicas,
+						MaxReplicas: &maxReplicas,
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name:    "gateway-class-parameters-without-enabled",
+			version: V2_4,
+			gatewayAPI: &maistrav2.GatewayAPIConfig{
+				GatewayClassParameters: &maistrav2.GatewayClassParametersConfig{
+					ServiceAnnotations: map[string]string{"service.beta.kubernetes.io/aws-load-balancer-type": "nlb"},
+				},
+			},
+			expectError: true,
+		},
+		{
+			name:    "gateway-class-parameters-autoscaling-without-max-replicas",
+			version: V2_4,
+			gatewayAPI: &maistrav2.GatewayAPIConfig{
+				Enablement: maistrav2.Enablement{Enabled: &enabled},
+				GatewayClassParameters: &maistrav2.GatewayClassParametersConfig{
+					Autoscaling: &maistrav2.AutoScalerConfig{
+						Enablement: maistrav2.Enablement{Enabled: &enabled},
+					},
+				},
+			},
+			expectError: true,
+		},
+		{
+			name:    "gateway-class-parameters-autoscaling-min-greater-than-max",
+			version: V2_4,
+			gatewayAPI: &maistrav2.GatewayAPIConfig{
+				Enablement: maistrav2.Enablement{Enabled: &enabled},
+				GatewayClassParameters: &maistrav2.GatewayClassParametersConfig{
+					Autoscaling: &maistrav2.AutoScalerConfig{
+						Enablement:  maistrav2.Enablement{Enabled: &enabled},
+						MinReplicas: &maxReplicas,
+						MaxReplicas: &minReplicas,
+					},
+				},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := &maistrav2.ControlPlaneSpec{
+				Gateways: &maistrav2.GatewaysConfig{
+					GatewayAPI: tc.gatewayAPI,
+				},
+			}
+			allErrors := validateGatewayAPI(spec, tc.version, []error{})
+			if tc.expectError {
+				if len(allErrors) == 0 {
+					t.Fatal("Expected errors, but none were returned")
+				}
+			} else {
+				if len(allErrors) > 0 {
+					t.Fatalf("Unexpected errors: %v", allErrors)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateExternalControlPlane(t *testing.T) {
+	enabled, disabled := true, false
+	caBundle := "-----BEGIN CERTIFICATE-----\nMIIBszCCAVmgAwIBAgIUYQ==\n-----END CERTIFICATE-----\n"
+	testCases := []struct {
+		name                 string
+		version              Ver
+		externalControlPlane *maistrav2.ExternalControlPlaneConfig
+		expectError          bool
+	}{
+		{
+			name:    "disabled",
+			version: V2_3,
+			externalControlPlane: &maistrav2.ExternalControlPlaneConfig{
+				Enablement: maistrav2.Enablement{Enabled: &disabled},
+			},
+			expectError: false,
+		},
+		{
+			name:    "host-name",
+			version: V2_4,
+			externalControlPlane: &maistrav2.ExternalControlPlaneConfig{
+				Enablement: maistrav2.Enablement{Enabled: &enabled},
+				Address:    "istiod.example.com",
+				CABundle:   caBundle,
+			},
+			expectError: false,
+		},
+		{
+			name:    "ip-address",
+			version: V2_4,
+			externalControlPlane: &maistrav2.ExternalControlPlaneConfig{
+				Enablement: maistrav2.Enablement{Enabled: &enabled},
+				Address:    "fd00::1",
+				CABundle:   caBundle,
+			},
+			expectError: false,
+		},
+		{
+			name:    "unsupported-version",
+			version: V2_3,
+			externalControlPlane: &maistrav2.ExternalControlPlaneConfig{
+				Enablement: maistrav2.Enablement{Enabled: &enabled},
+				Address:    "istiod.example.com",
+				CABundle:   caBundle,
+			},
+			expectError: true,
+		},
+		{
+			name:    "missing-address",
+			version: V2_4,
+			externalControlPlane: &maistrav2.ExternalControlPlaneConfig{
+				Enablement: maistrav2.Enablement{Enabled: &enabled},
+				CABundle:   caBundle,
+			},
+			expectError: true,
+		},
+		{
+			name:    "address-with-port",
+			version: V2_4,
+			externalControlPlane: &maistrav2.ExternalControlPlaneConfig{
+				Enablement: maistrav2.Enablement{Enabled: &enabled},
+				Address:    "istiod.example.com:15012",
+				CABundle:   caBundle,
+			},
+			expectError: true,
+		},
+		{
+			name:    "invalid-ca-bundle",
+			version: V2_4,
+			externalControlPlane: &maistrav2.ExternalControlPlaneConfig{
+				Enablement: maistrav2.Enablement{Enabled: &enabled},
+				Address:    "istiod.example.com",
+				CABundle:   "not a certificate",
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := &maistrav2.ControlPlaneSpec{
+				Cluster: &maistrav2.ControlPlaneClusterConfig{
+					ExternalControlPlane: tc.externalControlPlane,
+				},
+			}
+			allErrors := validateExternalControlPlane(spec, tc.version, []error{})
+			if tc.expectError {
+				if len(allErrors) == 0 {
+					t.Fatal("Expected errors, but none were returned")
+				}
+			} else {
+				if len(allErrors) > 0 {
+					t.Fatalf("Unexpected errors: %v", allErrors)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateIdentity(t *testing.T) {
+	testCases := []struct {
+		name        string
+		version     Ver
+		security    *maistrav2.SecurityConfig
+		expectError bool
+	}{
+		{
+			name:    "oidc",
+			version: V2_4,
+			security: &maistrav2.SecurityConfig{
+				Identity: &maistrav2.IdentityConfig{
+					OIDC: &maistrav2.OIDCIdentityConfig{
+						Issuer:  "https://issuer.example.com",
+						JwksURI: "https://issuer.example.com/keys",
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name:    "oidc-unsupported-version",
+			version: V1_1,
+			security: &maistrav2.SecurityConfig{
+				Identity: &maistrav2.IdentityConfig{
+					OIDC: &maistrav2.OIDCIdentityConfig{
+						Issuer: "https://issuer.example.com",
+					},
+				},
+			},
+			expectError: true,
+		},
+		{
+			name:    "oidc-missing-issuer",
+			version: V2_4,
+			security: &maistrav2.SecurityConfig{
+				Identity: &maistrav2.IdentityConfig{
+					OIDC: &maistrav2.OIDCIdentityConfig{},
+				},
+			},
+			expectError: true,
+		},
+		{
+			name:    "oidc-http-jwks-uri",
+			version: V2_4,
+			security: &maistrav2.SecurityConfig{
+				Identity: &maistrav2.IdentityConfig{
+					OIDC: &maistrav2.OIDCIdentityConfig{
+						Issuer:  "https://issuer.example.com",
+						JwksURI: "http://issuer.example.com/keys",
+					},
+				},
+			},
+			expectError: true,
+		},
+		{
+			name:    "third-party-audiences",
+			version: V2_4,
+			security: &maistrav2.SecurityConfig{
+				Identity: &maistrav2.IdentityConfig{
+					Type: maistrav2.IdentityConfigTypeThirdParty,
+					ThirdParty: &maistrav2.ThirdPartyIdentityConfig{
+						Audience:         "my-audience",
+						TrustedAudiences: []string{"istio-ca"},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name:    "third-party-invalid-audience",
+			version: V2_4,
+			security: &maistrav2.SecurityConfig{
+				Identity: &maistrav2.IdentityConfig{
+					Type: maistrav2.IdentityConfigTypeThirdParty,
+					ThirdParty: &maistrav2.ThirdPartyIdentityConfig{
+						Audience: "my audience",
+					},
+				},
+			},
+			expectError: true,
+		},
+		{
+			name:    "third-party-invalid-trusted-audience",
+			version: V2_4,
+			security: &maistrav2.SecurityConfig{
+				Identity: &maistrav2.IdentityConfig{
+					Type: maistrav2.IdentityConfigTypeThirdParty,
+					ThirdParty: &maistrav2.ThirdPartyIdentityConfig{
+						TrustedAudiences: []string{"a,b"},
+					},
+				},
+			},
+			expectError: true,
+		},
+		{
+			name:    "invalid-jwks-resolver-ca",
+			version: V2_4,
+			security: &maistrav2.SecurityConfig{
+				JwksResolverCA: "not a certificate",
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := &maistrav2.ControlPlaneSpec{
+				Security: tc.security,
+			}
+			allErrors := validateIdentity(spec, tc.version, []error{})
+			if tc.expectError {
+				if len(allErrors) == 0 {
+					t.Fatal("Expected errors, but none were returned")
+				}
+			} else {
+				if len(allErrors) > 0 {
+					t.Fatalf("Unexpected errors: %v", allErrors)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateMeshIdentity(t *testing.T) {
+	newControlPlane := func(namespace, meshID, trustDomain string) maistrav2.ServiceMeshControlPlane {
+		smcp := maistrav2.ServiceMeshControlPlane{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "basic",
+				Namespace: namespace,
+			},
+		}
+		if meshID != "" {
+			smcp.Spec.Cluster = &maistrav2.ControlPlaneClusterConfig{MeshID: meshID}
+		}
+		if trustDomain != "" {
+			smcp.Spec.Security = &maistrav2.SecurityConfig{Trust: &maistrav2.TrustConfig{Domain: trustDomain}}
+		}
+		return smcp
+	}
+	testCases := []struct {
+		name        string
+		smcp        maistrav2.ServiceMeshControlPlane
+		others      []maistrav2.ServiceMeshControlPlane
+		expectError bool
+	}{
+		{
+			name:        "defaults",
+			smcp:        newControlPlane(controlPlaneNamespace, "", ""),
+			expectError: false,
+		},
+		{
+			name:        "invalid-trust-domain",
+			smcp:        newControlPlane(controlPlaneNamespace, "", "Example.COM"),
+			expectError: true,
+		},
+		{
+			name:        "invalid-mesh-id",
+			smcp:        newControlPlane(controlPlaneNamespace, "my mesh", ""),
+			expectError: true,
+		},
+		{
+			name: "same-mesh-same-trust-domain",
+			smcp: newControlPlane(controlPlaneNamespace, "my-mesh", "example.com"),
+			others: []maistrav2.ServiceMeshControlPlane{
+				newControlPlane(controlPlaneNamespace, "my-mesh", "other.example.com"),
+				newControlPlane("other-namespace", "my-mesh", "example.com"),
+			},
+			expectError: false,
+		},
+		{
+			name: "same-mesh-different-trust-domain",
+			smcp: newControlPlane(controlPlaneNamespace, "my-mesh", ""),
+			others: []maistrav2.ServiceMeshControlPlane{
+				newControlPlane("other-namespace", "my-mesh", "example.com"),
+			},
+			expectError: true,
+		},
+		{
+			name: "different-mesh-different-trust-domain",
+			smcp: newControlPlane(controlPlaneNamespace, "my-mesh", ""),
+			others: []maistrav2.ServiceMeshControlPlane{
+				newControlPlane("other-namespace", "other-mesh", "example.com"),
+				newControlPlane("no-mesh-id", "", "example.com"),
+			},
+			expectError: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			allErrors := validateMeshIdentityInternal(&tc.smcp.ObjectMeta, &tc.smcp.Spec, tc.others, []error{})
+			if tc.expectError {
+				if len(allErrors) == 0 {
+					t.Fatal("Expected errors, but none were returned")
+				}
+			} else {
+				if len(allErrors) > 0 {
+					t.Fatalf("Unexpected errors: %v", allErrors)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateCertificateAuthority(t *testing.T) {
+	testCases := []struct {
+		name        string
+		version     Ver
+		ca          *maistrav2.CertificateAuthorityConfig
+		expectError bool
+	}{
+		{
+			name:    "istiod",
+			version: V2_3,
+			ca: &maistrav2.CertificateAuthorityConfig{
+				Type: maistrav2.CertificateAuthorityTypeIstiod,
+			},
+			expectError: false,
+		},
+		{
+			name:    "cert-manager",
+			version: V2_3,
+			ca: &maistrav2.CertificateAuthorityConfig{
+				Type: maistrav2.CertificateAuthorityTypeCertManager,
+				CertManager: &maistrav2.CertManagerCertificateAuthorityConfig{
+					Address: "cert-manager-istio-csr.cert-manager.svc:443",
+				},
+			},
+			expectError: false,
+		},
+		{
+			name:    "cert-manager-missing-address-v2.3",
+			version: V2_3,
+			ca: &maistrav2.CertificateAuthorityConfig{
+				Type: maistrav2.CertificateAuthorityTypeCertManager,
+			},
+			expectError: false,
+		},
+		{
+			name:    "cert-manager-missing-address",
+			version: V2_4,
+			ca: &maistrav2.CertificateAuthorityConfig{
+				Type: maistrav2.CertificateAuthorityTypeCertManager,
+			},
+			expectError: true,
+		},
+		{
+			name:    "kubernetes",
+			version: V2_4,
+			ca: &maistrav2.CertificateAuthorityConfig{
+				Type: maistrav2.CertificateAuthorityTypeKubernetes,
+				Kubernetes: &maistrav2.KubernetesCertificateAuthorityConfig{
+					SignerName: "clusterissuers.cert-manager.io/istio-ca",
+				},
+			},
+			expectError: false,
+		},
+		{
+			name:    "kubernetes-unsupported-version",
+			version: V2_3,
+			ca: &maistrav2.CertificateAuthorityConfig{
+				Type: maistrav2.CertificateAuthorityTypeKubernetes,
+				Kubernetes: &maistrav2.KubernetesCertificateAuthorityConfig{
+					SignerName: "clusterissuers.cert-manager.io/istio-ca",
+				},
+			},
+			expectError: true,
+		},
+		{
+			name:    "kubernetes-missing-signer-name",
+			version: V2_4,
+			ca: &maistrav2.CertificateAuthorityConfig{
+				Type: maistrav2.CertificateAuthorityTypeKubernetes,
+			},
+			expectError: true,
+		},
+		{
+			name:    "kubernetes-unqualified-signer-name",
+			version: V2_4,
+			ca: &maistrav2.CertificateAuthorityConfig{
+				Type: maistrav2.CertificateAuthorityTypeKubernetes,
+				Kubernetes: &maistrav2.KubernetesCertificateAuthorityConfig{
+					SignerName: "istio-ca",
+				},
+			},
+			expectError: true,
+		},
+		{
+			name:    "kubernetes-invalid-signer-domain",
+			version: V2_4,
+			ca: &maistrav2.CertificateAuthorityConfig{
+				Type: maistrav2.CertificateAuthorityTypeKubernetes,
+				Kubernetes: &maistrav2.KubernetesCertificateAuthorityConfig{
+					SignerName: "Example_Com/istio-ca",
+				},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := &maistrav2.ControlPlaneSpec{
+				Security: &maistrav2.SecurityConfig{
+					CertificateAuthority: tc.ca,
+				},
+			}
+			allErrors := validateCertificateAuthority(spec, tc.version, []error{})
+			if tc.expectError {
+				if len(allErrors) == 0 {
+					t.Fatal("Expected errors, but none were returned")
+				}
+			} else {
+				if len(allErrors) > 0 {
+					t.Fatalf("Unexpected errors: %v", allErrors)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateCARotation(t *testing.T) {
+	testCases := []struct {
+		name        string
+		version     Ver
+		ca          *maistrav2.CertificateAuthorityConfig
+		caRotation  *maistrav2.CARotationConfig
+		expectError bool
+	}{
+		{
+			name:        "default-ca",
+			version:     V2_4,
+			caRotation:  &maistrav2.CARotationConfig{SecretName: "cacerts-new"},
+			expectError: false,
+		},
+		{
+			name:        "istiod-ca",
+			version:     V2_4,
+			ca:          &maistrav2.CertificateAuthorityConfig{Type: maistrav2.CertificateAuthorityTypeIstiod},
+			caRotation:  &maistrav2.CARotationConfig{SecretName: "cacerts-new", WorkloadCertRefreshPeriod: "48h"},
+			expectError: false,
+		},
+		{
+			name:        "cert-manager-ca",
+			version:     V2_4,
+			ca:          &maistrav2.CertificateAuthorityConfig{Type: maistrav2.CertificateAuthorityTypeCertManager},
+			caRotation:  &maistrav2.CARotationConfig{SecretName: "cacerts-new"},
+			expectError: true,
+		},
+		{
+			name:        "citadel",
+			version:     V1_1,
+			caRotation:  &maistrav2.CARotationConfig{SecretName: "cacerts-new"},
+			expectError: true,
+		},
+		{
+			name:        "missing-secret-name",
+			version:     V2_4,
+			caRotation:  &maistrav2.CARotationConfig{},
+			expectError: true,
+		},
+		{
+			name:        "cacerts",
+			version:     V2_4,
+			caRotation:  &maistrav2.CARotationConfig{SecretName: "cacerts"},
+			expectError: true,
+		},
+		{
+			name:        "invalid-refresh-period",
+			version:     V2_4,
+			caRotation:  &maistrav2.CARotationConfig{SecretName: "cacerts-new", WorkloadCertRefreshPeriod: "1d"},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := &maistrav2.ControlPlaneSpec{
+				Security: &maistrav2.SecurityConfig{
+					CertificateAuthority: tc.ca,
+					CARotation:           tc.caRotation,
+				},
+			}
+			allErrors := validateCARotation(spec, tc.version, []error{})
+			if tc.expectError {
+				if len(allErrors) == 0 {
+					t.Fatal("Expected errors, but none were returned")
+				}
+			} else {
+				if len(allErrors) > 0 {
+					t.Fatalf("Unexpected errors: %v", allErrors)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateTelemetryDefaults(t *testing.T) {
+	testCases := []struct {
+		name              string
+		version           Ver
+		telemetryDefaults *maistrav2.TelemetryDefaultsConfig
+		expectError       bool
+	}{
+		{
+			name:        "unset",
+			version:     V2_3,
+			expectError: false,
+		},
+		{
+			name:    "default-providers",
+			version: V2_4,
+			telemetryDefaults: &maistrav2.TelemetryDefaultsConfig{
+				Metrics:       &maistrav2.TelemetryDefaultsMetricsConfig{Providers: []string{"prometheus"}},
+				AccessLogging: &maistrav2.TelemetryDefaultsAccessLoggingConfig{Providers: []string{"envoy"}},
+			},
+			expectError: false,
+		},
+		{
+			name:    "extension-provider",
+			version: V2_4,
+			telemetryDefaults: &maistrav2.TelemetryDefaultsConfig{
+				Tracing: &maistrav2.TelemetryDefaultsTracingConfig{Providers: []string{"otel"}},
+			},
+			expectError: false,
+		},
+		{
+			name:    "unknown-provider",
+			version: V2_4,
+			telemetryDefaults: &maistrav2.TelemetryDefaultsConfig{
+				Tracing: &maistrav2.TelemetryDefaultsTracingConfig{Providers: []string{"zipkin"}},
+			},
+			expectError: true,
+		},
+		{
+			name:    "unsupported-version",
+			version: V2_3,
+			telemetryDefaults: &maistrav2.TelemetryDefaultsConfig{
+				Metrics: &maistrav2.TelemetryDefaultsMetricsConfig{Providers: []string{"prometheus"}},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := &maistrav2.ControlPlaneSpec{
+				MeshConfig: &maistrav2.MeshConfig{
+					ExtensionProviders: []*maistrav2.ExtensionProviderConfig{{Name: "otel"}},
+				},
+				TelemetryDefaults: tc.telemetryDefaults,
+			}
+			allErrors := validateTelemetryDefaults(spec, tc.version, []error{})
+			if tc.expectError {
+				if len(allErrors) == 0 {
+					t.Fatal("Expected errors, but none were returned")
+				}
+			} else {
+				if len(allErrors) > 0 {
+					t.Fatalf("Unexpected errors: %v", allErrors)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package versions
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/xeipuuv/gojsonschema"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/helm/pkg/chartutil"
+
+	v2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/common/helm"
+)
+
+// ValuesSchemaFile is the name of the JSON schema of the helm values in the
+// charts directory of each version, which is generated by GenerateValuesSchema
+const ValuesSchemaFile = "values.schema.json"
+
+// chartMappings are the charts rendered for each version
+var chartMappings = map[Ver]map[string]chartRenderingDetails{
+	V2_0: v2_0ChartMapping,
+	V2_1: v2_1ChartMapping,
+	V2_2: v2_2ChartMapping,
+	V2_3: v2_3ChartMapping,
+	V2_4: v2_4ChartMapping,
+}
+
+// operatorValues are the values the operator sets while rendering the charts,
+// which aren't included in the default values of the charts
+var operatorValues = map[string]interface{}{
+	"revision": "",
+	"istio_cni": map[string]interface{}{
+		"enabled":           false,
+		"istio_cni_network": "",
+	},
+}
+
+var (
+	valuesSchemasMutex sync.Mutex
+	// valuesSchemas caches the loaded schemas by file name.  A nil schema
+	// means that the file doesn't exist.
+	valuesSchemas = map[string]*gojsonschema.Schema{}
+)
+
+// GenerateValuesSchema returns the JSON schema of the helm values accepted by
+// the charts of the version, see helm.ValuesSchema.  It's derived from the
+// default values of the charts, the global.yaml file and the values set by
+// the operator.
+func GenerateValuesSchema(v Version) ([]byte, error) {
+	charts, ok := chartMappings[v.Version()]
+	if !ok {
+		return nil, fmt.Errorf("version %s doesn't support values schemas", v)
+	}
+	defaults, err := chartDefaultValues(v.GetChartsDir(), charts)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(helm.ValuesSchema(defaults), "", "  ")
+}
+
+func chartDefaultValues(chartsDir string, charts map[string]chartRenderingDetails) (map[string]interface{}, error) {
+	chartPaths := make([]string, 0, len(charts))
+	for _, chart := range charts {
+		// charts of components that are no longer supported, e.g. mixer in
+		// 2.1, aren't shipped
+		chartPath := path.Join(chartsDir, chart.path)
+		if _, err := os.Stat(chartPath); os.IsNotExist(err) {
+			continue
+		}
+		chartPaths = append(chartPaths, chartPath)
+	}
+	defaults, err := helm.DefaultValues(chartPaths...)
+	if err != nil {
+		return nil, fmt.Errorf("error reading default values of charts: %v", err)
+	}
+	helm.MergeValues(defaults, runtime.DeepCopyJSON(operatorValues))
+	globalValues, err := chartutil.ReadValuesFile(path.Join(chartsDir, "global.yaml"))
+	if err == nil {
+		helm.MergeValues(defaults, globalValues)
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error reading global.yaml file: %v", err)
+	}
+	return defaults, nil
+}
+
+// loadValuesSchema returns the schema in the charts directory, or nil if the
+// charts don't include a schema
+func loadValuesSchema(chartsDir string) (*gojsonschema.Schema, error) {
+	fileName := path.Join(chartsDir, ValuesSchemaFile)
+	valuesSchemasMutex.Lock()
+	defer valuesSchemasMutex.Unlock()
+	if schema, ok := valuesSchemas[fileName]; ok {
+		return schema, nil
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if os.IsNotExist(err) {
+		valuesSchemas[fileName] = nil
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(data))
+	if err != nil {
+		return nil, fmt.Errorf("error loading %s: %v", fileName, err)
+	}
+	valuesSchemas[fileName] = schema
+	return schema, nil
+}
+
+// validateValues validates the values in spec.techPreview against the schema
+// of the charts.  Values of the wrong type, e.g. a string where the charts
+// expect a map, are always rejected, as they would make rendering fail.
+// Values that don't correspond to a default value of any of the charts are
+// only rejected if the control plane has opted in using the strict-values
+// annotation.  Without it, such values are passed to the charts, which
+// silently ignore them, e.g. a typo such as istio_cni.enable.
+func validateValues(meta *metav1.ObjectMeta, spec *v2.ControlPlaneSpec, chartsDir string, allErrors []error) []error {
+	if spec.TechPreview == nil {
+		return allErrors
+	}
+	values := spec.TechPreview.DeepCopy().GetContent()
+	delete(values, v2.TechPreviewControlPlaneModeKey)
+	if len(values) == 0 {
+		return allErrors
+	}
+	schema, err := loadValuesSchema(chartsDir)
+	if err != nil {
+		return append(allErrors, fmt.Errorf("error loading values schema: %v", err))
+	} else if schema == nil {
+		return allErrors
+	}
+	unknown, invalid, err := helm.ValidateValues(schema, values)
+	if err != nil {
+		return append(allErrors, fmt.Errorf("error validating spec.techPreview: %v", err))
+	}
+	for _, message := range invalid {
+		allErrors = append(allErrors, fmt.Errorf("invalid value in spec.techPreview: %s", message))
+	}
+	if len(unknown) > 0 && meta.GetAnnotations()[common.StrictValuesKey] == "true" {
+		allErrors = append(allErrors, fmt.Errorf("unknown values in spec.techPreview: %s", strings.Join(unknown, ", ")))
+	}
+	return allErrors
+}
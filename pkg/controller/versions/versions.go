@@ -3,6 +3,7 @@ package versions
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -223,6 +224,32 @@ func (v Ver) IsSupported() (supported bool) {
 }
 
 // ParseVersion returns a version for the specified string
+const (
+	// LatestVersionAlias refers to the newest version supported by the
+	// operator
+	LatestVersionAlias = "latest"
+
+	// latestVersionAliasSuffix turns a version into an alias of its newest
+	// patch release, e.g. v2.4-latest
+	latestVersionAliasSuffix = "-" + LatestVersionAlias
+)
+
+// ResolveVersionAlias returns the version an alias refers to.  "latest" refers
+// to the newest version supported by the operator, and e.g. "v2.4-latest" to
+// the newest patch release of v2.4, which is the one the operator ships, i.e.
+// v2.4.  The second return value is false if str isn't a known alias.
+func ResolveVersionAlias(str string) (string, bool) {
+	if str == LatestVersionAlias {
+		return DefaultVersion.String(), true
+	}
+	if strings.HasSuffix(str, latestVersionAliasSuffix) {
+		if v, ok := stringToVersion[strings.TrimSuffix(str, latestVersionAliasSuffix)]; ok {
+			return v.String(), true
+		}
+	}
+	return str, false
+}
+
 func ParseVersion(str string) (Ver, error) {
 	if v, ok := stringToVersion[str]; ok {
 		return v, nil
@@ -30,3 +30,22 @@ func TestBadVersionString(t *testing.T) {
 		t.Errorf("ParseVersion() should have returned an error for version InvalidVersion")
 	}
 }
+
+func TestResolveVersionAlias(t *testing.T) {
+	testCases := []struct {
+		alias    string
+		expected string
+		isAlias  bool
+	}{
+		{alias: "latest", expected: DefaultVersion.String(), isAlias: true},
+		{alias: "v2.3-latest", expected: "v2.3", isAlias: true},
+		{alias: "v2.3", expected: "v2.3", isAlias: false},
+		{alias: "v9.9-latest", expected: "v9.9-latest", isAlias: false},
+		{alias: "", expected: "", isAlias: false},
+	}
+	for _, tc := range testCases {
+		if version, isAlias := ResolveVersionAlias(tc.alias); version != tc.expected || isAlias != tc.isAlias {
+			t.Errorf("ResolveVersionAlias(%q) returned (%q, %t), expected (%q, %t)", tc.alias, version, isAlias, tc.expected, tc.isAlias)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package featuregates
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Stage is the maturity of a feature
+type Stage string
+
+const (
+	// Alpha features are experimental and disabled by default
+	Alpha Stage = "ALPHA"
+	// Beta features are enabled by default, but can still be disabled
+	Beta Stage = "BETA"
+	// GA features are always enabled.  Their gates are only kept until the
+	// checks are removed from the code.
+	GA Stage = "GA"
+)
+
+// Feature is the name of a feature gate
+type Feature string
+
+const (
+	// CustomInjectionTemplates adds the injection templates in the ConfigMaps
+	// labelled maistra.io/injection-templates to the injector of the control
+	// plane
+	CustomInjectionTemplates Feature = "CustomInjectionTemplates"
+
+	// MeshTrustDomainCheck rejects control planes whose trust domain differs
+	// from that of the other control planes with the same spec.cluster.meshID
+	MeshTrustDomainCheck Feature = "MeshTrustDomainCheck"
+)
+
+// FeatureSpec describes a feature gate
+type FeatureSpec struct {
+	// Default is whether the feature is enabled unless it's set explicitly
+	Default bool
+	// Stage is the maturity of the feature
+	Stage Stage
+}
+
+var defaultFeatures = map[Feature]FeatureSpec{
+	CustomInjectionTemplates: {Default: true, Stage: Beta},
+	MeshTrustDomainCheck:     {Default: true, Stage: Beta},
+}
+
+// Default is the feature gate consumed by the controllers.  It's set from the
+// --featureGates flag or the controller.featureGates key of the operator
+// configuration when the operator starts.
+var Default = NewFeatureGate(defaultFeatures)
+
+// FeatureGate tracks whether the known features are enabled
+type FeatureGate struct {
+	lock    sync.RWMutex
+	known   map[Feature]FeatureSpec
+	enabled map[Feature]bool
+}
+
+// NewFeatureGate returns a FeatureGate for the known features, all of which
+// are set to their defaults
+func NewFeatureGate(known map[Feature]FeatureSpec) *FeatureGate {
+	return &FeatureGate{
+		known:   known,
+		enabled: map[Feature]bool{},
+	}
+}
+
+// Set enables or disables the features in value, a comma-separated list of
+// Feature=true|false pairs, e.g. "CustomInjectionTemplates=false".  Features
+// that aren't listed keep their current setting.  Nothing is changed if value
+// contains an unknown feature, an invalid setting, or disables a GA feature.
+func (g *FeatureGate) Set(value string) error {
+	settings := map[string]bool{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("missing setting of feature gate %q, expected %s=true|false", pair, pair)
+		}
+		enabled, err := strconv.ParseBool(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return fmt.Errorf("invalid setting of feature gate %s: %q", strings.TrimSpace(parts[0]), parts[1])
+		}
+		settings[strings.TrimSpace(parts[0])] = enabled
+	}
+	return g.SetFromMap(settings)
+}
+
+// SetFromMap enables or disables the features in settings, see Set
+func (g *FeatureGate) SetFromMap(settings map[string]bool) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	for name, enabled := range settings {
+		spec, ok := g.known[Feature(name)]
+		if !ok {
+			return fmt.Errorf("unknown feature gate %s, known feature gates are: %s", name, strings.Join(g.knownFeatures(), ", "))
+		}
+		if spec.Stage == GA && !enabled {
+			return fmt.Errorf("feature gate %s is GA and cannot be disabled", name)
+		}
+	}
+	for name, enabled := range settings {
+		g.enabled[Feature(name)] = enabled
+	}
+	return nil
+}
+
+// Enabled returns true if the feature is enabled.  Unknown features are
+// never enabled.
+func (g *FeatureGate) Enabled(feature Feature) bool {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+	if enabled, ok := g.enabled[feature]; ok {
+		return enabled
+	}
+	return g.known[feature].Default
+}
+
+// KnownFeatures returns a description of the known features, e.g. for the
+// help text of a flag
+func (g *FeatureGate) KnownFeatures() []string {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+	return g.knownFeatures()
+}
+
+func (g *FeatureGate) knownFeatures() []string {
+	features := make([]string, 0, len(g.known))
+	for feature, spec := range g.known {
+		features = append(features, fmt.Sprintf("%s=true|false (%s - default=%t)", feature, spec.Stage, spec.Default))
+	}
+	sort.Strings(features)
+	return features
+}
+
+// String returns the settings of all known features in the format accepted
+// by Set
+func (g *FeatureGate) String() string {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+	settings := make([]string, 0, len(g.known))
+	for feature, spec := range g.known {
+		enabled, ok := g.enabled[feature]
+		if !ok {
+			enabled = spec.Default
+		}
+		settings = append(settings, fmt.Sprintf("%s=%t", feature, enabled))
+	}
+	sort.Strings(settings)
+	return strings.Join(settings, ",")
+}
@@ -0,0 +1,70 @@
+package featuregates
+
+import (
+	"testing"
+
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+)
+
+const (
+	alphaFeature Feature = "AlphaFeature"
+	betaFeature  Feature = "BetaFeature"
+	gaFeature    Feature = "GAFeature"
+)
+
+func newTestFeatureGate() *FeatureGate {
+	return NewFeatureGate(map[Feature]FeatureSpec{
+		alphaFeature: {Default: false, Stage: Alpha},
+		betaFeature:  {Default: true, Stage: Beta},
+		gaFeature:    {Default: true, Stage: GA},
+	})
+}
+
+func TestDefaults(t *testing.T) {
+	g := newTestFeatureGate()
+	assert.False(g.Enabled(alphaFeature), "Expected alpha feature to be disabled by default", t)
+	assert.True(g.Enabled(betaFeature), "Expected beta feature to be enabled by default", t)
+	assert.True(g.Enabled(gaFeature), "Expected GA feature to be enabled by default", t)
+	assert.False(g.Enabled("Unknown"), "Expected unknown feature to be disabled", t)
+	assert.Equals(g.String(), "AlphaFeature=false,BetaFeature=true,GAFeature=true", "Unexpected settings", t)
+}
+
+func TestSet(t *testing.T) {
+	g := newTestFeatureGate()
+	assert.Success(g.Set(" AlphaFeature=true, BetaFeature=false,"), "Set", t)
+	assert.True(g.Enabled(alphaFeature), "Expected alpha feature to be enabled", t)
+	assert.False(g.Enabled(betaFeature), "Expected beta feature to be disabled", t)
+
+	// features that aren't listed keep their setting
+	assert.Success(g.Set("BetaFeature=true"), "Set", t)
+	assert.True(g.Enabled(alphaFeature), "Expected alpha feature to stay enabled", t)
+	assert.True(g.Enabled(betaFeature), "Expected beta feature to be enabled", t)
+
+	assert.Success(g.Set(""), "Set", t)
+	assert.Equals(g.String(), "AlphaFeature=true,BetaFeature=true,GAFeature=true", "Unexpected settings", t)
+}
+
+func TestSetRejectsInvalidSettings(t *testing.T) {
+	testCases := map[string]string{
+		"unknown":    "Unknown=true",
+		"no-setting": "AlphaFeature",
+		"not-a-bool": "AlphaFeature=maybe",
+		"ga":         "GAFeature=false",
+	}
+	for name, value := range testCases {
+		t.Run(name, func(t *testing.T) {
+			g := newTestFeatureGate()
+			assert.Failure(g.Set("BetaFeature=false,"+value), "Set", t)
+			// nothing is changed
+			assert.True(g.Enabled(betaFeature), "Expected beta feature to keep its default", t)
+		})
+	}
+}
+
+func TestKnownFeatures(t *testing.T) {
+	assert.DeepEquals(newTestFeatureGate().KnownFeatures(), []string{
+		"AlphaFeature=true|false (ALPHA - default=false)",
+		"BetaFeature=true|false (BETA - default=true)",
+		"GAFeature=true|false (GA - default=true)",
+	}, "Unexpected known features", t)
+}
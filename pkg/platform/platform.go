@@ -0,0 +1,96 @@
+// Package platform detects the Kubernetes distribution the operator is running
+// on, so that settings that differ between distributions don't have to be
+// configured by the user.
+package platform
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+)
+
+// Platform identifies a Kubernetes distribution
+type Platform string
+
+const (
+	// Kubernetes is any distribution that isn't recognized.  The chart defaults
+	// are used for it.
+	Kubernetes Platform = "Kubernetes"
+	OpenShift  Platform = "OpenShift"
+	GKE        Platform = "GKE"
+	EKS        Platform = "EKS"
+	K3s        Platform = "k3s"
+)
+
+// detector recognizes a platform by the API groups served by its API server,
+// or by a marker in the API server's version
+type detector struct {
+	platform  Platform
+	apiGroups []string
+	// versionMarker is a substring of the git version of the platform's API
+	// server, e.g. v1.27.4+k3s1
+	versionMarker string
+}
+
+// detectors are checked in order.  OpenShift is checked first, as it also runs
+// on the infrastructure of cloud providers, e.g. as ROSA on AWS.
+var detectors = []detector{
+	{
+		platform:  OpenShift,
+		apiGroups: []string{"route.openshift.io", "config.openshift.io"},
+	},
+	{
+		platform:      K3s,
+		apiGroups:     []string{"k3s.cattle.io"},
+		versionMarker: "+k3s",
+	},
+	{
+		platform:      GKE,
+		apiGroups:     []string{"networking.gke.io", "nodemanagement.gke.io"},
+		versionMarker: "-gke.",
+	},
+	{
+		platform:      EKS,
+		apiGroups:     []string{"crd.k8s.amazonaws.com", "vpcresources.k8s.aws"},
+		versionMarker: "-eks-",
+	},
+}
+
+// Detect returns the platform whose API server dc talks to.  It returns
+// Kubernetes if the platform isn't recognized.
+func Detect(dc discovery.DiscoveryInterface) (Platform, error) {
+	groups, err := dc.ServerGroups()
+	if err != nil {
+		return Kubernetes, err
+	}
+	servedGroups := sets.NewString()
+	if groups != nil {
+		for _, group := range groups.Groups {
+			servedGroups.Insert(group.Name)
+		}
+	}
+	serverVersion, err := dc.ServerVersion()
+	if err != nil {
+		return Kubernetes, err
+	}
+
+	for _, d := range detectors {
+		if servedGroups.HasAny(d.apiGroups...) ||
+			(d.versionMarker != "" && strings.Contains(serverVersion.GitVersion, d.versionMarker)) {
+			return d.platform, nil
+		}
+	}
+	return Kubernetes, nil
+}
+
+// CNIBinDir returns the directory on the nodes in which the container runtime
+// looks for CNI plugins, or an empty string if it's the default /opt/cni/bin.
+// The directories of k3s depend on the CNI plugin it was installed with, so the
+// defaults are used for it.
+func (p Platform) CNIBinDir() string {
+	if p == GKE {
+		return "/home/kubernetes/bin"
+	}
+	return ""
+}
@@ -0,0 +1,86 @@
+package platform
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/maistra/istio-operator/pkg/controller/common/test/assert"
+)
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		name       string
+		groups     []string
+		gitVersion string
+		expected   Platform
+	}{
+		{
+			name:       "kubernetes",
+			groups:     []string{"apps", "batch"},
+			gitVersion: "v1.27.3",
+			expected:   Kubernetes,
+		},
+		{
+			name:       "openshift",
+			groups:     []string{"apps", "route.openshift.io"},
+			gitVersion: "v1.27.4+4e87926",
+			expected:   OpenShift,
+		},
+		{
+			name:       "openshift-on-aws",
+			groups:     []string{"route.openshift.io", "crd.k8s.amazonaws.com"},
+			gitVersion: "v1.27.4+4e87926",
+			expected:   OpenShift,
+		},
+		{
+			name:       "gke-by-group",
+			groups:     []string{"apps", "networking.gke.io"},
+			gitVersion: "v1.27.3",
+			expected:   GKE,
+		},
+		{
+			name:       "gke-by-version",
+			groups:     []string{"apps"},
+			gitVersion: "v1.27.3-gke.100",
+			expected:   GKE,
+		},
+		{
+			name:       "eks",
+			groups:     []string{"apps"},
+			gitVersion: "v1.27.4-eks-2d98532",
+			expected:   EKS,
+		},
+		{
+			name:       "k3s",
+			groups:     []string{"apps", "k3s.cattle.io"},
+			gitVersion: "v1.27.4+k3s1",
+			expected:   K3s,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resources := make([]*metav1.APIResourceList, 0, len(tc.groups))
+			for _, group := range tc.groups {
+				resources = append(resources, &metav1.APIResourceList{GroupVersion: group + "/v1"})
+			}
+			dc := &fake.FakeDiscovery{
+				Fake:               &clienttesting.Fake{Resources: resources},
+				FakedServerVersion: &version.Info{GitVersion: tc.gitVersion},
+			}
+			p, err := Detect(dc)
+			assert.Success(err, "Detect", t)
+			assert.Equals(p, tc.expected, "Unexpected platform", t)
+		})
+	}
+}
+
+func TestCNIBinDir(t *testing.T) {
+	assert.Equals(GKE.CNIBinDir(), "/home/kubernetes/bin", "Unexpected CNI bin dir for GKE", t)
+	assert.Equals(OpenShift.CNIBinDir(), "", "Expected default CNI bin dir for OpenShift", t)
+	assert.Equals(Kubernetes.CNIBinDir(), "", "Expected default CNI bin dir for Kubernetes", t)
+}
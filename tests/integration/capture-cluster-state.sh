@@ -0,0 +1,54 @@
+#!/bin/bash
+
+
+# Copyright 2022 Red Hat, Inc.
+
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Captures the state of the cluster after a failed test into the directory
+# given as the first parameter, so the failure can be debugged without
+# rerunning the test.  Errors are ignored, as the cluster may be only
+# partially set up.
+
+# Check unset variables
+set -u
+
+OUT="${1:?the directory to capture the cluster state into must be given}"
+NS="${NS:-openshift-operators}"
+
+mkdir -p "${OUT}"
+
+# operator logs, including the previous container if the operator crashed
+kubectl logs -n "${NS}" deployment/istio-operator --all-containers --timestamps \
+    > "${OUT}/operator.log" 2>&1
+kubectl logs -n "${NS}" deployment/istio-operator --all-containers --timestamps --previous \
+    > "${OUT}/operator-previous.log" 2>&1
+
+# maistra resources
+kubectl get smcp,smmr,smm --all-namespaces -o yaml > "${OUT}/maistra-resources.yaml" 2>&1
+
+# events, pods and deployments of all namespaces
+kubectl get events --all-namespaces --sort-by=.lastTimestamp -o wide > "${OUT}/events.txt" 2>&1
+kubectl get pods --all-namespaces -o wide > "${OUT}/pods.txt" 2>&1
+kubectl get deployments --all-namespaces -o yaml > "${OUT}/deployments.yaml" 2>&1
+
+# logs of the control planes
+for SMCP_NS in $(kubectl get smcp --all-namespaces -o=jsonpath='{.items[*].metadata.namespace}' 2>/dev/null | tr ' ' '\n' | sort -u); do
+    mkdir -p "${OUT}/${SMCP_NS}"
+    for POD in $(kubectl get pods -n "${SMCP_NS}" -o=jsonpath='{.items[*].metadata.name}' 2>/dev/null); do
+        kubectl logs -n "${SMCP_NS}" "${POD}" --all-containers --timestamps \
+            > "${OUT}/${SMCP_NS}/${POD}.log" 2>&1
+    done
+done
+
+echo "Cluster state captured in ${OUT}"
@@ -22,14 +22,34 @@ set -u
 # Print commands
 set -x
 
+set -o pipefail
+
+read-manifest() { # prints a manifest from a local path or a URL
+    if [[ "$1" == http://* || "$1" == https://* ]]; then
+        curl -sSfL "$1"
+    else
+        cat "$1"
+    fi
+}
+
 install-operator-k8s() { # installs istio-operator on kubernetes
     local ROOT
     ROOT="$(git rev-parse --show-toplevel)"
     local TAG
     TAG="${TAG:-$(git rev-parse HEAD)}"
     local NS="${NS:-openshift-operators}"
-    # var name IMAGE is in kind provisioner
-    local OPERATOR_IMAGE="${OPERATOR_IMAGE:-localhost:5000/istio-operator-integ}:${TAG}"
+    # if OPERATOR_REF is set, the manifests and the image of that released
+    # version are installed instead of the build under test
+    local OPERATOR_REF="${OPERATOR_REF:-}"
+    local SOURCE="${ROOT}"
+    local OPERATOR_IMAGE
+    if [[ -n "${OPERATOR_REF}" ]]; then
+        SOURCE="https://raw.githubusercontent.com/maistra/istio-operator/${OPERATOR_REF}"
+        OPERATOR_IMAGE="${RELEASED_OPERATOR_IMAGE:-quay.io/maistra/istio-ubi8-operator:${MAISTRA_VERSION}}"
+    else
+        # var name IMAGE is in kind provisioner
+        OPERATOR_IMAGE="${OPERATOR_IMAGE:-localhost:5000/istio-operator-integ}:${TAG}"
+    fi
     local ISTIO_CNI_IMAGE_NAME="${ISTIO_CNI_IMAGE_NAME:-quay.io/maistra-dev/istio-cni-ubi8-integ:latest}"
     local PILOT_IMAGE_NAME="${PILOT_IMAGE_NAME:-quay.io/maistra-dev/pilot-ubi8-integ:latest}"
     local PROXY_IMAGE_NAME="${PROXY_IMAGE_NAME:-quay.io/maistra-dev/proxyv2-ubi8-integ:latest}"
@@ -42,29 +62,33 @@ install-operator-k8s() { # installs istio-operator on kubernetes
 
     kubectl get ns "$NS" >/dev/null 2>&1 || kubectl create namespace "$NS"
 
-    kubectl apply -f "${ROOT}/manifests-maistra/${MAISTRA_VERSION}/servicemeshcontrolplanes.crd.yaml"
-    kubectl apply -f "${ROOT}/manifests-maistra/${MAISTRA_VERSION}/servicemeshmemberrolls.crd.yaml"
-    kubectl apply -f "${ROOT}/manifests-maistra/${MAISTRA_VERSION}/servicemeshmembers.crd.yaml"
+    read-manifest "${SOURCE}/manifests-maistra/${MAISTRA_VERSION}/servicemeshcontrolplanes.crd.yaml" | kubectl apply -f -
+    read-manifest "${SOURCE}/manifests-maistra/${MAISTRA_VERSION}/servicemeshmemberrolls.crd.yaml" | kubectl apply -f -
+    read-manifest "${SOURCE}/manifests-maistra/${MAISTRA_VERSION}/servicemeshmembers.crd.yaml" | kubectl apply -f -
 
-    sed -e "s/namespace: istio-operator/namespace: $NS/g" "${ROOT}/deploy/src/rbac.yaml" | kubectl apply -n "$NS" -f -
-    sed -e "s/namespace: istio-operator/namespace: $NS/g" "${ROOT}/deploy/src/serviceaccount.yaml" | kubectl apply -n "$NS" -f -
-    sed -e "s/namespace: istio-operator/namespace: $NS/g" "${ROOT}/deploy/src/service.yaml" | kubectl apply -n "$NS" -f -
+    read-manifest "${SOURCE}/deploy/src/rbac.yaml" | sed -e "s/namespace: istio-operator/namespace: $NS/g" | kubectl apply -n "$NS" -f -
+    read-manifest "${SOURCE}/deploy/src/serviceaccount.yaml" | sed -e "s/namespace: istio-operator/namespace: $NS/g" | kubectl apply -n "$NS" -f -
+    read-manifest "${SOURCE}/deploy/src/service.yaml" | sed -e "s/namespace: istio-operator/namespace: $NS/g" | kubectl apply -n "$NS" -f -
 
-    openssl req -x509 -newkey rsa:4096 -keyout /tmp/key.pem -out /tmp/cert.pem -sha256 -days 365 -nodes -subj "/CN=istio-operator" -addext "subjectAltName = DNS:maistra-admission-controller.$NS.svc"
+    # the serving certificate is kept when the operator is upgraded
+    if ! kubectl get -n "$NS" secret maistra-operator-serving-cert >/dev/null 2>&1; then
+        openssl req -x509 -newkey rsa:4096 -keyout /tmp/key.pem -out /tmp/cert.pem -sha256 -days 365 -nodes -subj "/CN=istio-operator" -addext "subjectAltName = DNS:maistra-admission-controller.$NS.svc"
 
-    kubectl create -n "$NS" secret tls maistra-operator-serving-cert --key=/tmp/key.pem --cert=/tmp/cert.pem
-    kubectl create -n "$NS" configmap maistra-operator-cabundle --from-file=service-ca.crt=/tmp/cert.pem
+        kubectl create -n "$NS" secret tls maistra-operator-serving-cert --key=/tmp/key.pem --cert=/tmp/cert.pem
+        kubectl create -n "$NS" configmap maistra-operator-cabundle --from-file=service-ca.crt=/tmp/cert.pem
+    fi
 
-    sed -e "s@quay.io/maistra/istio-ubi8-operator:${MAISTRA_VERSION}@${OPERATOR_IMAGE}@g" \
+    read-manifest "${SOURCE}/deploy/src/deployment-maistra.yaml" \
+        | sed -e "s@quay.io/maistra/istio-ubi8-operator:${MAISTRA_VERSION}@${OPERATOR_IMAGE}@g" \
         -e "s@namespace: istio-operator@namespace: $NS@g" \
         -e "s@quay.io/maistra/istio-cni-ubi8:${MAISTRA_VERSION}@${ISTIO_CNI_IMAGE_NAME}@g" \
         -e "s@quay.io/maistra/pilot-ubi8:${MAISTRA_VERSION}@${PILOT_IMAGE_NAME}@g" \
         -e "s@quay.io/maistra/proxyv2-ubi8:${MAISTRA_VERSION}@${PROXY_IMAGE_NAME}@g" \
-        "${ROOT}/deploy/src/deployment-maistra.yaml" \
         | tee "/tmp/deployment.yaml"
         kubectl apply -f /tmp/deployment.yaml
 
     # check istio-operator pod running
+    kubectl rollout status -n "${NS}" deployment/istio-operator --timeout 180s
     kubectl wait --for condition=Ready -n "${NS}" pod -l name=istio-operator --timeout 180s
 }
 
@@ -19,6 +19,7 @@ WD=$(dirname "$0")
 WD=$(cd "$WD"; pwd)
 export CLUSTER_NAME
 CLUSTER_NAME="maistra-operator-$(date +%s)"
+export ARTIFACTS="${ARTIFACTS:-$(mktemp -d)}"
 
 # Exit immediately for non zero status
 set -e
@@ -27,11 +28,25 @@ set -u
 # Print commands
 set -x
 
+# step prints the name of a test step with a timestamp
+function step() {
+  { set +x; } 2>/dev/null
+  echo "--------------------------------"
+  echo "$(date -u '+%Y-%m-%dT%H:%M:%SZ') ${1}"
+  echo "--------------------------------"
+  { set -x; } 2>/dev/null
+}
+
 # cleanup_kind_cluster takes a single parameter CLUSTER_NAME
-# and deletes the KinD cluster with that name
+# and deletes the KinD cluster with that name.  If the test failed, the
+# state of the cluster is captured into ${ARTIFACTS} first.
 function cleanup_kind_cluster() {
-  echo "Test exited with exit code $?."
+  local EXIT_CODE=$?
+  echo "Test exited with exit code ${EXIT_CODE}."
   CLUSTER_NAME="${1}"
+  if [[ "${EXIT_CODE}" != 0 ]]; then
+    "${WD}"/capture-cluster-state.sh "${ARTIFACTS}/cluster-state" || true
+  fi
   if [[ -z "${SKIP_CLEANUP:-}" ]]; then
     echo "Cleaning up kind cluster"
     kind delete cluster --name "${CLUSTER_NAME}" -v9 || true
@@ -43,30 +58,28 @@ function cleanup_kind_cluster() {
 trap "cleanup_kind_cluster ${CLUSTER_NAME}" EXIT
 
 # provision a kind cluster
-echo "--------------------------------"
-echo "Provision a kind cluster"
-echo "--------------------------------"
+step "Provision a kind cluster"
 "${WD}"/istio-integ-suite-kind.sh
 
-echo "--------------------------------"
-echo "Build an operator image"
-echo "--------------------------------"
+step "Build an operator image"
 "${WD}"/build-operator.sh
 
-# deploy operator in kind
-echo "--------------------------------"
-echo "Deploy istio operator in kind"
-echo "--------------------------------"
-"${WD}"/deploy-operator.sh
-# wait for validation webhook
-echo "Wait 30s for validation webhook..."
-sleep 30
+if [[ "${1:-}" == "upgrade" ]]; then
+    # install a released operator and upgrade it to the build under test
+    step "Upgrade istio operator from ${UPGRADE_FROM_REF:-} in kind"
+    "${WD}"/operator-upgrade-test.sh
+else
+    # deploy operator in kind
+    step "Deploy istio operator in kind"
+    "${WD}"/deploy-operator.sh
+    # wait for validation webhook
+    echo "Wait 30s for validation webhook..."
+    sleep 30
 
-# create a SMCP and test httpbin
-echo "--------------------------------"
-echo "Create a SMCP and test httpbin"
-echo "--------------------------------"
-"${WD}"/smcp-httpbin-test.sh
+    # create a SMCP and test httpbin
+    step "Create a SMCP and test httpbin"
+    "${WD}"/smcp-httpbin-test.sh
+fi
 
 # delete the kind cluster
 cleanup_kind_cluster "${CLUSTER_NAME}"
\ No newline at end of file
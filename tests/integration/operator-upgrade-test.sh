@@ -0,0 +1,125 @@
+#!/bin/bash
+
+
+# Copyright 2022 Red Hat, Inc.
+
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Installs a released operator, creates a SMCP, upgrades the operator to the
+# build under test and checks that the new operator adopts the control plane
+# without restarting its workloads.
+#
+# UPGRADE_FROM_REF is the git ref of maistra/istio-operator that the released
+# operator is installed from.  UPGRADE_FROM_VERSION defaults to the
+# MAISTRA_VERSION in the Makefile of that ref.
+
+# Exit immediately for non zero status
+set -e
+# Check unset variables
+set -u
+# Print commands
+set -x
+
+set -o pipefail
+
+WD=$(dirname "$0")
+WD=$(cd "$WD"; pwd)
+ROOT="$(git rev-parse --show-toplevel)"
+CR="${ROOT}/deploy/examples/maistra_v2_servicemeshcontrolplane_cr_minimal.yaml"
+SMCP_NS="${SMCP_NS:-istio-system}"
+NS="${NS:-openshift-operators}"
+UPGRADE_FROM_REF="${UPGRADE_FROM_REF:?UPGRADE_FROM_REF must be set to the git ref of the released operator}"
+STATUS_LOG="/tmp/smcp-ready-conditions.log"
+
+released-operator-version() { # prints the MAISTRA_VERSION of the released operator
+    if [[ -n "${UPGRADE_FROM_VERSION:-}" ]]; then
+        echo "${UPGRADE_FROM_VERSION}"
+        return
+    fi
+    curl -sSfL "https://raw.githubusercontent.com/maistra/istio-operator/${UPGRADE_FROM_REF}/Makefile" \
+        | sed -n -e 's/^MAISTRA_VERSION *?\?= *//p'
+}
+
+install-released-operator() {
+    local VERSION
+    VERSION="$(released-operator-version)"
+    OPERATOR_REF="${UPGRADE_FROM_REF}" MAISTRA_VERSION="${VERSION}" "${WD}"/deploy-operator.sh
+    # wait for validation webhook
+    echo "Wait 30s for validation webhook..."
+    sleep 30
+}
+
+create-control-plane() { # creates a SMCP with a mini CR
+    kubectl get ns "${SMCP_NS}" >/dev/null 2>&1 || kubectl create namespace "${SMCP_NS}"
+    kubectl apply -n "${SMCP_NS}" -f "${CR}"
+    kubectl wait --for condition=Ready -n "${SMCP_NS}" smcp/minimal --timeout 300s
+    kubectl get -n "${SMCP_NS}" smcp -o wide
+}
+
+control-plane-pods() { # prints the name, uid and restart count of all control plane pods
+    kubectl get -n "${SMCP_NS}" pod --sort-by=.metadata.name \
+        -o=jsonpath='{range .items[*]}{.metadata.name}{" "}{.metadata.uid}{" "}{.status.containerStatuses[*].restartCount}{"\n"}{end}'
+}
+
+watch-ready-condition() { # records every change of the Ready condition of the SMCP
+    kubectl get -n "${SMCP_NS}" smcp/minimal --watch \
+        -o=jsonpath='{range .status.conditions[?(@.type=="Ready")]}{.status}{" "}{.reason}{" "}{.message}{"\n"}{end}' \
+        > "${STATUS_LOG}" &
+    WATCH_PID=$!
+}
+
+wait-for-adoption() { # waits until the SMCP was reconciled by the new operator
+    local OLD_OPERATOR_VERSION="$1"
+    for _ in $(seq 60); do
+        if [[ "$(kubectl get -n "${SMCP_NS}" smcp/minimal -o=jsonpath='{.status.operatorVersion}')" != "${OLD_OPERATOR_VERSION}" ]]; then
+            kubectl wait --for condition=Reconciled -n "${SMCP_NS}" smcp/minimal --timeout 300s
+            kubectl wait --for condition=Ready -n "${SMCP_NS}" smcp/minimal --timeout 300s
+            return
+        fi
+        sleep 5
+    done
+    echo "SMCP was not reconciled by the new operator"
+    return 1
+}
+
+check-upgrade() {
+    local OLD_PODS="$1"
+    local NEW_PODS
+    NEW_PODS="$(control-plane-pods)"
+    if [[ "${OLD_PODS}" != "${NEW_PODS}" ]]; then
+        echo "Control plane pods were restarted or replaced during the operator upgrade:"
+        diff <(echo "${OLD_PODS}") <(echo "${NEW_PODS}") || true
+        return 1
+    fi
+
+    # the SMCP is expected to report the operator update, but its components
+    # must stay available
+    echo "Ready conditions reported during the operator upgrade:"
+    cat "${STATUS_LOG}"
+    if grep -E '^(False|Unknown) (ComponentsNotReady|ProbeError) ' "${STATUS_LOG}"; then
+        echo "Control plane components became unavailable during the operator upgrade"
+        return 1
+    fi
+}
+
+install-released-operator
+create-control-plane
+
+OLD_OPERATOR_VERSION="$(kubectl get -n "${SMCP_NS}" smcp/minimal -o=jsonpath='{.status.operatorVersion}')"
+OLD_PODS="$(control-plane-pods)"
+watch-ready-condition
+trap 'kill ${WATCH_PID} 2>/dev/null || true' EXIT
+
+"${WD}"/deploy-operator.sh
+wait-for-adoption "${OLD_OPERATOR_VERSION}"
+check-upgrade "${OLD_PODS}"
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path"
+
+	"github.com/operator-framework/operator-sdk/pkg/log/zap"
+	"github.com/spf13/pflag"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/maistra/istio-operator/pkg/controller/common"
+	"github.com/maistra/istio-operator/pkg/controller/versions"
+)
+
+var log = logf.Log.WithName("cmd")
+
+// valuesschema generates the JSON schema of the helm values of each supported
+// version, which is used to validate spec.techPreview.  It must be run after
+// the charts have been updated.
+func main() {
+	// Add the zap logger flag set to the CLI. The flag set must
+	// be added before calling pflag.Parse().
+	pflag.CommandLine.AddFlagSet(zap.FlagSet())
+
+	// Add flags registered by imported packages (e.g. glog and
+	// controller-runtime)
+	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
+
+	pflag.StringVar(&common.Config.Rendering.ResourceDir, "resourceDir", "resources", "The directory containing the helm charts of each version")
+
+	pflag.Parse()
+
+	logf.SetLogger(zap.Logger())
+
+	for _, v := range versions.AllV2Versions {
+		outFileName := path.Join(v.GetChartsDir(), versions.ValuesSchemaFile)
+		schema, err := versions.GenerateValuesSchema(v)
+		if err != nil {
+			log.Error(err, fmt.Sprintf("error generating values schema for version %s", v))
+			panic(err)
+		}
+		log.Info(fmt.Sprintf("writing values schema for version %s to %s", v, outFileName))
+		if err := ioutil.WriteFile(outFileName, append(schema, '\n'), 0o664); err != nil {
+			log.Error(err, fmt.Sprintf("error writing file %s", outFileName))
+			panic(err)
+		}
+	}
+	log.Info("completed")
+}